@@ -0,0 +1,59 @@
+package yaci
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/client"
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+var DescriptorsCmd = &cobra.Command{
+	Use:   "descriptors [address] [flags]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Write the protocol buffer descriptors of a gRPC server to a file",
+	Long: `Fetch the protocol buffer descriptors of a gRPC server with the reflection API and write them to a file,
+as a serialized google.protobuf.FileDescriptorSet. The file decodes the blocks of a block store extracted with
+extract --block-store --descriptor-set. The gRPC server must run the same version of the chain as the node of the block store.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			if err := parent.PreRunE(parent, args); err != nil {
+				return err
+			}
+		}
+
+		// Bind the flags when the command runs, as some flags are shared with other commands.
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := viper.GetString("output")
+		if path == "" {
+			return fmt.Errorf("missing output file")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		handleInterrupt(cancel)
+
+		descriptors, err := client.FetchDescriptors(ctx, args[0], viper.GetBool("insecure"), viper.GetInt("max-recv-msg-size"))
+		if err != nil {
+			return err
+		}
+		if err := reflection.WriteFileDescriptorSet(path, descriptors); err != nil {
+			return err
+		}
+
+		slog.Info("Descriptors written", "file", path, "files", len(descriptors))
+		return nil
+	},
+}
+
+func init() {
+	DescriptorsCmd.Flags().StringP("output", "o", "", "File the descriptor set is written to")
+	DescriptorsCmd.Flags().BoolP("insecure", "k", false, "Skip TLS certificate verification (INSECURE)")
+	DescriptorsCmd.Flags().IntP("max-recv-msg-size", "m", 4194304, "Maximum gRPC message size in bytes (advanced)")
+}
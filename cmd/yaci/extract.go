@@ -10,20 +10,33 @@ import (
 
 	"github.com/manifest-network/yaci/internal/client"
 	"github.com/manifest-network/yaci/internal/config"
+	"github.com/manifest-network/yaci/internal/metrics/collectors"
+	"github.com/manifest-network/yaci/internal/reflection"
+	"github.com/manifest-network/yaci/internal/source"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 var (
 	extractConfig config.ExtractConfig
-	gRPCClient    *client.GRPCClient
+	// sources holds one block source per extracted chain: a gRPC server, a CometBFT RPC server or a block store
+	sources []source.BlockSource
+	// extractCtx is the context of the extraction, canceled on interrupt
+	extractCtx context.Context
 )
 
 var ExtractCmd = &cobra.Command{
-	Use:   "extract [address]",
-	Args:  cobra.ExactArgs(1),
+	Use:   "extract [address]...",
+	Args:  cobra.MinimumNArgs(1),
 	Short: "Extract chain data to various output formats",
-	Long:  `Extract blockchain data and output it in the specified format.`,
+	Long: `Extract blockchain data and output it in the specified format.
+Several addresses can be given to extract several chains concurrently into the same output.
+An address is a gRPC address, or the URL of a CometBFT RPC server, e.g., http://localhost:26657, which also provides
+the events of the blocks. With --block-store, the blocks are read from the data directory of a stopped node instead,
+without address.`,
 	PreRunE: func(cmd *cobra.Command, args []string) error {
 		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
 			if err := parent.PreRunE(parent, args); err != nil {
@@ -36,22 +49,95 @@ var ExtractCmd = &cobra.Command{
 			return fmt.Errorf("invalid Extract configuration: %w", err)
 		}
 
+		if len(args) > 1 && (extractConfig.BlockStart != 0 || extractConfig.BlockStop != 0) {
+			return fmt.Errorf("cannot set --start or --stop flags with several addresses")
+		}
+
 		slog.Debug("Command-line arguments", "extractConfig", extractConfig)
-		slog.Debug("gRPC endpoint", "address", args[0])
+		slog.Debug("Endpoints", "addresses", args)
 
 		ctx, cancel := context.WithCancel(context.Background())
 		handleInterrupt(cancel)
+		extractCtx = ctx
+
+		sources = nil
+		if extractConfig.BlockStore != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot set addresses with --block-store")
+			}
+			return openBlockStore(ctx)
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("missing address")
+		}
 
-		var err error
-		gRPCClient, err = client.NewGRPCClient(ctx, args[0], extractConfig.Insecure, extractConfig.MaxRecvMsgSize)
-		if err != nil {
-			return fmt.Errorf("failed to initialize gRPC: %w", err)
+		for _, address := range args {
+			if source.IsCometBFTAddress(address) {
+				src, err := newCometBFTSource(ctx, address)
+				if err != nil {
+					return fmt.Errorf("failed to initialize CometBFT RPC for %s: %w", address, err)
+				}
+				sources = append(sources, src)
+				continue
+			}
+
+			gRPCClient, err := client.NewGRPCClient(ctx, address, extractConfig.Insecure, extractConfig.MaxRecvMsgSize)
+			if err != nil {
+				return fmt.Errorf("failed to initialize gRPC for %s: %w", address, err)
+			}
+			sources = append(sources, source.NewGRPC(gRPCClient, extractConfig.MaxRetries))
 		}
 
 		return nil
 	},
 }
 
+// openBlockStore opens the block store of the extraction, decoded with the descriptor set
+func openBlockStore(ctx context.Context) error {
+	files, err := reflection.ReadFileDescriptorSet(extractConfig.DescriptorSet)
+	if err != nil {
+		return err
+	}
+	resolver := reflection.NewCustomResolver(ctx, files, nil, 0)
+
+	blockStore, err := source.OpenBlockStore(extractConfig.BlockStore, extractConfig.BlockStoreBackend, resolver)
+	if err != nil {
+		return fmt.Errorf("failed to open the block store: %w", err)
+	}
+	sources = []source.BlockSource{blockStore}
+	return nil
+}
+
+// newCometBFTSource returns the source of a CometBFT RPC server, decoded with the descriptor set or, if it is not set,
+// with the descriptors queried from the node. The snapshots and metrics query the state of the chain, which requires
+// a gRPC server.
+func newCometBFTSource(ctx context.Context, address string) (*source.CometBFT, error) {
+	if extractConfig.ValidatorSnapshots > 0 || extractConfig.WasmContractSnapshots > 0 || extractConfig.EnablePrometheus {
+		return nil, fmt.Errorf("cannot take snapshots or enable Prometheus with a CometBFT RPC server, they require a gRPC server")
+	}
+	return openCometBFTSource(ctx, address, extractConfig.DescriptorSet, extractConfig.MaxRetries)
+}
+
+// openCometBFTSource returns the source of a CometBFT RPC server, decoded with the descriptor set or, if it is empty,
+// with the descriptors queried from the node
+func openCometBFTSource(ctx context.Context, address, descriptorSet string, maxRetries uint) (*source.CometBFT, error) {
+	var files *protoregistry.Files
+	var err error
+	if descriptorSet != "" {
+		files, err = reflection.ReadFileDescriptorSet(descriptorSet)
+	} else {
+		var descriptors []*descriptorpb.FileDescriptorProto
+		if descriptors, err = source.FetchCometBFTDescriptors(ctx, address, maxRetries); err == nil {
+			files, err = reflection.BuildFileDescriptorSet(descriptors)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	resolver := reflection.NewCustomResolver(ctx, files, nil, 0)
+	return source.NewCometBFT(address, resolver, maxRetries), nil
+}
+
 func init() {
 	ExtractCmd.PersistentFlags().BoolP("insecure", "k", false, "Skip TLS certificate verification (INSECURE)")
 	ExtractCmd.PersistentFlags().Bool("live", false, "Enable live monitoring")
@@ -64,6 +150,22 @@ func init() {
 	ExtractCmd.PersistentFlags().IntP("max-recv-msg-size", "m", 4194304, "Maximum gRPC message size in bytes (advanced)")
 	ExtractCmd.PersistentFlags().Bool("enable-prometheus", false, "Enable Prometheus metrics server")
 	ExtractCmd.PersistentFlags().String("prometheus-addr", "0.0.0.0:2112", "Address and port of the Prometheus metrics server")
+	ExtractCmd.PersistentFlags().Bool("locked-tokens-by-account", false, "Report the locked tokens metric per vesting account")
+	ExtractCmd.PersistentFlags().Bool("locked-tokens-by-end-date", false, "Report the locked tokens metric per vesting schedule end date")
+	ExtractCmd.PersistentFlags().StringSlice("token-denoms", nil, "Denoms reported by the token flow metrics (default: all denoms)")
+	ExtractCmd.PersistentFlags().StringSlice("token-mint-events", []string{"coinbase"}, "Event types counted as minted by the token flow metrics")
+	ExtractCmd.PersistentFlags().StringSlice("token-burn-events", []string{"burn"}, "Event types counted as burned by the token flow metrics")
+	ExtractCmd.PersistentFlags().StringSlice("token-transfer-events", []string{"transfer"}, "Event types counted as transferred by the token flow metrics")
+	ExtractCmd.PersistentFlags().Duration("validator-snapshot-interval", 0, "Interval between validator set snapshots, e.g., 1h (default: disabled)")
+	ExtractCmd.PersistentFlags().Duration("wasm-contract-snapshot-interval", 0, "Interval between CosmWasm contract info snapshots, e.g., 24h (default: disabled)")
+	ExtractCmd.PersistentFlags().Bool("index-signatures", false, "Index the validator signatures of the last commit of each block")
+	ExtractCmd.PersistentFlags().Bool("gov-outcomes", false, "Query the outcomes of the gov proposals from a gRPC server, which does not provide the end block events")
+	ExtractCmd.PersistentFlags().Int64("missed-blocks-window", collectors.DefaultMissedBlocksWindow, "Number of blocks over which the missed blocks metrics are computed")
+	ExtractCmd.PersistentFlags().Int64("gas-metrics-window", collectors.DefaultGasMetricsWindow, "Number of blocks over which the gas price and block fullness metrics are computed")
+	ExtractCmd.PersistentFlags().String("block-store", "", "Data directory of a stopped node whose block store is extracted instead of a gRPC server")
+	ExtractCmd.PersistentFlags().String("block-store-backend", string(source.BackendGoLevelDB), "Database backend of the node: goleveldb or pebble")
+	ExtractCmd.PersistentFlags().String("descriptor-set", "", "File of the descriptors of the chain, required with --block-store, e.g., written by the descriptors command; queried from the node with a CometBFT RPC address if not set")
+	addRetentionFlags(ExtractCmd.PersistentFlags())
 
 	if err := viper.BindPFlags(ExtractCmd.PersistentFlags()); err != nil {
 		slog.Error("Failed to bind ExtractCmd flags", "error", err)
@@ -72,6 +174,14 @@ func init() {
 	ExtractCmd.AddCommand(PostgresCmd)
 }
 
+// addRetentionFlags adds the flags of the retention policy, shared by the extraction and the prune command
+func addRetentionFlags(flags *pflag.FlagSet) {
+	flags.Uint64("retain-blocks", 0, "Number of latest blocks kept, older blocks are pruned (default: disabled)")
+	flags.Duration("retain-duration", 0, "Age of the oldest block kept, e.g., 720h, older blocks are pruned (default: disabled)")
+	flags.Bool("keep-normalized", false, "Keep the normalized data of the pruned blocks, only prune their raw JSON")
+	flags.Bool("detach-partitions", false, "Detach the pruned partitions instead of dropping them, e.g., to archive them")
+}
+
 // handleInterrupt handles interrupt signals for graceful shutdown.
 func handleInterrupt(cancel context.CancelFunc) {
 	// Handle interrupt signals for graceful shutdown
@@ -14,6 +14,28 @@ func TestExtractCmd(t *testing.T) {
 	assert.Error(t, err)
 	assert.ErrorContains(t, err, "cannot set --live and --stop flags together")
 
+	// The snapshots require a gRPC server
+	t.Cleanup(func() {
+		_ = yaci.ExtractCmd.PersistentFlags().Set("validator-snapshot-interval", "0")
+	})
+	_, err = executeCommand(yaci.RootCmd, "extract", "postgres", "http://localhost:26657", "--live=false", "--stop", "0", "--validator-snapshot-interval", "1h")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "they require a gRPC server")
+
+	// --block-store requires a descriptor set
+	t.Cleanup(func() {
+		_ = yaci.ExtractCmd.PersistentFlags().Set("block-store", "")
+		_ = yaci.ExtractCmd.PersistentFlags().Set("descriptor-set", "")
+	})
+	_, err = executeCommand(yaci.RootCmd, "extract", "postgres", "--live=false", "--stop", "0", "--validator-snapshot-interval", "0", "--block-store", "/tmp/node/data")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "missing --descriptor-set")
+
+	// --block-store and addresses are mutually exclusive
+	_, err = executeCommand(yaci.RootCmd, "extract", "postgres", "foobar", "--live=false", "--stop", "0", "--block-store", "/tmp/node/data", "--descriptor-set", "descriptors.binpb")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "cannot set addresses with --block-store")
+
 	// Show help
 	output, err := executeCommand(yaci.RootCmd, "extract")
 	assert.NoError(t, err)
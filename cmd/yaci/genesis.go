@@ -0,0 +1,109 @@
+package yaci
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/client"
+	"github.com/manifest-network/yaci/internal/config"
+	"github.com/manifest-network/yaci/internal/genesis"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+	"github.com/manifest-network/yaci/internal/output/postgresql"
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+var ImportGenesisCmd = &cobra.Command{
+	Use:   "import-genesis [genesis-file] [address] [flags]",
+	Args:  cobra.RangeArgs(1, 2),
+	Short: "Import the genesis state of a chain into a PostgreSQL database",
+	Long: `Import the accounts, balances, vesting accounts and validators of a genesis file into the genesis tables of a
+PostgreSQL database, as the state of the chain at height 0. The genesis balances are included in the balances at any
+height, and the genesis vesting accounts in the locked tokens metric.
+
+The genesis is decoded with the protocol buffer descriptors of the chain, fetched from the gRPC server at the given
+address or read from --descriptor-set. The genesis previously imported for the chain is replaced.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			if err := parent.PreRunE(parent, args); err != nil {
+				return err
+			}
+		}
+
+		// Bind the flags when the command runs, as some flags are shared with other commands.
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		postgresConfig := config.LoadPostgresConfigFromCLI()
+		if err := postgresConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid PostgreSQL configuration: %w", err)
+		}
+
+		var address string
+		if len(args) > 1 {
+			address = args[1]
+		}
+		genesisConfig := config.LoadImportGenesisConfigFromCLI(address)
+		if err := genesisConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid Import Genesis configuration: %w", err)
+		}
+
+		slog.Debug("Command-line arguments", "genesisConfig", genesisConfig)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		handleInterrupt(cancel)
+
+		resolver, err := genesisResolver(ctx, genesisConfig)
+		if err != nil {
+			return err
+		}
+		g, err := genesis.ReadFile(args[0], resolver)
+		if err != nil {
+			return err
+		}
+
+		outputHandler, err := postgresql.NewPostgresOutputHandler(postgresConfig.ConnString, postgresConfig.Schema, postgresConfig.AnonRole, postgresConfig.RawStorage, postgresConfig.SkipMigrations)
+		if err != nil {
+			return fmt.Errorf("failed to create PostgreSQL output handler: %w", err)
+		}
+		defer outputHandler.Close()
+
+		if err := outputHandler.WriteGenesis(ctx, g); err != nil {
+			return err
+		}
+		// The consensus addresses of the genesis validators map the signatures of the first blocks to the validators
+		return outputHandler.WriteValidatorConsensusAddresses(ctx, g.ChainID, 0, normalize.ValidatorConsensusAddresses(g.Validators))
+	},
+}
+
+// genesisResolver returns the resolver decoding the genesis, from the descriptor set or from the gRPC server
+func genesisResolver(ctx context.Context, cfg config.ImportGenesisConfig) (*reflection.CustomResolver, error) {
+	if cfg.DescriptorSet != "" {
+		files, err := reflection.ReadFileDescriptorSet(cfg.DescriptorSet)
+		if err != nil {
+			return nil, err
+		}
+		return reflection.NewCustomResolver(ctx, files, nil, 0), nil
+	}
+
+	gRPCClient, err := client.NewGRPCClient(ctx, cfg.Address, cfg.Insecure, cfg.MaxRecvMsgSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize gRPC: %w", err)
+	}
+	return gRPCClient.Resolver, nil
+}
+
+func init() {
+	ImportGenesisCmd.Flags().StringP("postgres-conn", "p", "", "PostgreSQL connection string")
+	ImportGenesisCmd.Flags().String("postgres-schema", models.DefaultSchema, "PostgreSQL schema of the indexed data")
+	ImportGenesisCmd.Flags().String("postgres-anon-role", models.DefaultAnonRole, "Role granted the read access of the anonymous web clients, e.g., PostgREST, by the migrations")
+	ImportGenesisCmd.Flags().Bool("skip-migrations", false, "Do not run the migrations, which must be run with the migrate command")
+	ImportGenesisCmd.Flags().String("descriptor-set", "", "File holding the protocol buffer descriptors decoding the genesis, written by the descriptors command")
+	ImportGenesisCmd.Flags().BoolP("insecure", "k", false, "Skip TLS certificate verification (INSECURE)")
+	ImportGenesisCmd.Flags().IntP("max-recv-msg-size", "m", 4194304, "Maximum gRPC message size in bytes (advanced)")
+}
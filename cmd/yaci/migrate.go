@@ -0,0 +1,152 @@
+package yaci
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/config"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/output/postgresql"
+)
+
+var MigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Manage the migrations of a PostgreSQL schema",
+	Long: `Apply, roll back or inspect the migrations of the PostgreSQL schema of the indexed data.
+The extraction runs the pending migrations when it starts, unless --skip-migrations is set, e.g., when its
+database user is not allowed to change the schema. The migrations are then run by this command with such a user.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			return parent.PreRunE(parent, args)
+		}
+		return nil
+	},
+}
+
+// migratePreRunE binds the flags of the migrate subcommands when they run, as `postgres-conn` is shared with other commands
+func migratePreRunE(cmd *cobra.Command, args []string) error {
+	if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+		if err := parent.PreRunE(parent, args); err != nil {
+			return err
+		}
+	}
+	return viper.BindPFlags(cmd.Flags())
+}
+
+// runMigrator runs a function with the migrator of the configured schema
+func runMigrator(run func(migrator *postgresql.Migrator) error) error {
+	postgresConfig := config.LoadPostgresConfigFromCLI()
+	if err := postgresConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid PostgreSQL configuration: %w", err)
+	}
+
+	migrator, err := postgresql.NewMigrator(postgresConfig.ConnString, postgresConfig.Schema, postgresConfig.AnonRole)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return run(migrator)
+}
+
+var MigrateUpCmd = &cobra.Command{
+	Use:     "up [flags]",
+	Args:    cobra.NoArgs,
+	Short:   "Apply the pending migrations",
+	PreRunE: migratePreRunE,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(func(migrator *postgresql.Migrator) error {
+			return migrator.Up()
+		})
+	},
+}
+
+var MigrateDownCmd = &cobra.Command{
+	Use:     "down [steps] [flags]",
+	Args:    cobra.MaximumNArgs(1),
+	Short:   "Roll back the latest migrations (default: 1)",
+	PreRunE: migratePreRunE,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		steps := uint64(1)
+		if len(args) == 1 {
+			var err error
+			if steps, err = strconv.ParseUint(args[0], 10, 32); err != nil || steps == 0 {
+				return fmt.Errorf("invalid number of steps %q, expected a positive integer", args[0])
+			}
+		}
+		return runMigrator(func(migrator *postgresql.Migrator) error {
+			return migrator.Down(uint(steps))
+		})
+	},
+}
+
+var MigrateGotoCmd = &cobra.Command{
+	Use:     "goto [version] [flags]",
+	Args:    cobra.ExactArgs(1),
+	Short:   "Apply or roll back the migrations up to a version",
+	PreRunE: migratePreRunE,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q, expected a non-negative integer", args[0])
+		}
+		return runMigrator(func(migrator *postgresql.Migrator) error {
+			return migrator.Goto(uint(version))
+		})
+	},
+}
+
+var MigrateForceCmd = &cobra.Command{
+	Use:   "force [version] [flags]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Set the migration version without running any migration",
+	Long: `Set the migration version of the schema without running any migration, and clear its dirty state.
+Use it after fixing the schema manually when a migration failed. A version of -1 records that no migration is applied.`,
+	PreRunE: migratePreRunE,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return fmt.Errorf("invalid version %q, expected -1 or a non-negative integer", args[0])
+		}
+		return runMigrator(func(migrator *postgresql.Migrator) error {
+			return migrator.Force(version)
+		})
+	},
+}
+
+var MigrateStatusCmd = &cobra.Command{
+	Use:     "status [flags]",
+	Args:    cobra.NoArgs,
+	Short:   "Print the migration version of the schema and the pending migrations",
+	PreRunE: migratePreRunE,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMigrator(func(migrator *postgresql.Migrator) error {
+			status, err := migrator.Status()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "version: %d\n", status.Version)
+			fmt.Fprintf(out, "dirty: %t\n", status.Dirty)
+			fmt.Fprintf(out, "latest: %d\n", status.Latest)
+			fmt.Fprintf(out, "pending: %v\n", status.Pending)
+			return nil
+		})
+	},
+}
+
+func init() {
+	MigrateCmd.PersistentFlags().StringP("postgres-conn", "p", "", "PostgreSQL connection string")
+	MigrateCmd.PersistentFlags().String("postgres-schema", models.DefaultSchema, "PostgreSQL schema of the indexed data")
+	MigrateCmd.PersistentFlags().String("postgres-anon-role", models.DefaultAnonRole, "Role granted the read access of the anonymous web clients, e.g., PostgREST, by the migrations")
+
+	MigrateCmd.AddCommand(MigrateUpCmd)
+	MigrateCmd.AddCommand(MigrateDownCmd)
+	MigrateCmd.AddCommand(MigrateGotoCmd)
+	MigrateCmd.AddCommand(MigrateForceCmd)
+	MigrateCmd.AddCommand(MigrateStatusCmd)
+}
@@ -0,0 +1,36 @@
+package yaci_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/manifest-network/yaci/cmd/yaci"
+)
+
+func TestMigrateCmd(t *testing.T) {
+	// Missing PostgreSQL connection string
+	_, err := executeCommand(yaci.RootCmd, "migrate", "up", "--logLevel", "info", "-p", "")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "missing PostgreSQL connection string")
+
+	// Invalid anonymous role, which is used as is in the migrations
+	_, err = executeCommand(yaci.RootCmd, "migrate", "up", "--logLevel", "info", "-p", "postgres://localhost/db", "--postgres-anon-role", "web_anon; DROP ROLE postgres")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid PostgreSQL anonymous role")
+
+	// Invalid number of steps
+	_, err = executeCommand(yaci.RootCmd, "migrate", "down", "0", "--logLevel", "info")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid number of steps")
+
+	// Invalid version
+	_, err = executeCommand(yaci.RootCmd, "migrate", "force", "--logLevel", "info", "--", "-2")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "invalid version")
+
+	// Show help
+	output, err := executeCommand(yaci.RootCmd, "migrate")
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Apply, roll back or inspect the migrations")
+}
@@ -2,12 +2,17 @@ package yaci
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/manifest-network/yaci/internal/metrics"
+	"github.com/manifest-network/yaci/internal/metrics/collectors"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/output"
 	"github.com/manifest-network/yaci/internal/output/postgresql"
+	"github.com/manifest-network/yaci/internal/source"
 	"github.com/manifest-network/yaci/internal/utils"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -29,15 +34,35 @@ var PostgresRunE = func(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse PostgreSQL connection string: %w", err)
 	}
 
-	outputHandler, err := postgresql.NewPostgresOutputHandler(postgresConfig.ConnString)
+	outputHandler, err := postgresql.NewPostgresOutputHandler(postgresConfig.ConnString, postgresConfig.Schema, postgresConfig.AnonRole, postgresConfig.RawStorage, postgresConfig.SkipMigrations)
 	if err != nil {
 		return fmt.Errorf("failed to create PostgreSQL output handler: %w", err)
 	}
 	defer outputHandler.Close()
 
+	for _, src := range sources {
+		if closer, ok := src.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
 	if extractConfig.EnablePrometheus {
+		if len(sources) > 1 {
+			return fmt.Errorf("the Prometheus metrics server supports a single chain, run a separate process per chain")
+		}
+		gRPCSource, ok := sources[0].(*source.GRPC)
+		if !ok {
+			return fmt.Errorf("the Prometheus metrics server requires a gRPC server")
+		}
+		gRPCClient := gRPCSource.Client()
 		slog.Info("Starting Prometheus metrics server...")
 
+		// The metrics are restricted to the chain of the gRPC server, as the database can hold several chains.
+		chainID, err := utils.GetChainIDWithRetry(gRPCClient, extractConfig.MaxRetries)
+		if err != nil {
+			return fmt.Errorf("failed to get the chain ID: %w", err)
+		}
+
 		// The total unique addresses metric requires to know the Bech32 prefix of the chain.
 		// Query the gRPC server for the Bech32 prefix.
 		bech32Prefix, err := utils.GetBech32PrefixWithRetry(gRPCClient, extractConfig.MaxRetries)
@@ -47,13 +72,32 @@ var PostgresRunE = func(cmd *cobra.Command, args []string) error {
 		slog.Debug("Bech32 prefix retrieved", "bech32_prefix", bech32Prefix)
 
 		db := stdlib.OpenDBFromPool(outputHandler.GetPool())
-		_, err = metrics.CreateMetricsServer(db, bech32Prefix, extractConfig.PrometheusListenAddr)
+		lockedTokensOpts := collectors.LockedTokensOptions{
+			ByAccount: extractConfig.LockedTokensByAccount,
+			ByEndDate: extractConfig.LockedTokensByEndDate,
+		}
+		tokenFlowOpts := collectors.TokenFlowOptions{
+			Denoms:             extractConfig.TokenDenoms,
+			MintEventTypes:     extractConfig.TokenMintEvents,
+			BurnEventTypes:     extractConfig.TokenBurnEvents,
+			TransferEventTypes: extractConfig.TokenTransferEvents,
+		}
+		missedBlocksOpts := collectors.MissedBlocksOptions{Window: extractConfig.MissedBlocksWindow}
+
+		// The block fullness metric requires the maximum block gas; it is not reported if it cannot be retrieved.
+		gasOpts := collectors.GasOptions{Window: extractConfig.GasMetricsWindow}
+		gasOpts.MaxBlockGas, err = utils.GetMaxBlockGasWithRetry(gRPCClient, extractConfig.MaxRetries)
+		if err != nil {
+			slog.Warn("Failed to get the maximum block gas, the block fullness metric is disabled", "error", err)
+		}
+
+		_, err = metrics.CreateMetricsServer(db, bech32Prefix, extractConfig.PrometheusListenAddr, collectors.ChainID(chainID), lockedTokensOpts, tokenFlowOpts, missedBlocksOpts, gasOpts)
 		if err != nil {
 			return fmt.Errorf("failed to start metrics server: %w", err)
 		}
 	}
 
-	return extractor.Extract(gRPCClient, outputHandler, extractConfig)
+	return extractor.ExtractChains(extractCtx, sources, outputHandler, extractConfig)
 }
 
 var PostgresCmd = &cobra.Command{
@@ -73,6 +117,10 @@ var PostgresCmd = &cobra.Command{
 
 func init() {
 	PostgresCmd.Flags().StringP("postgres-conn", "p", "", "PosftgreSQL connection string")
+	PostgresCmd.Flags().String("postgres-schema", models.DefaultSchema, "PostgreSQL schema of the indexed data")
+	PostgresCmd.Flags().String("postgres-anon-role", models.DefaultAnonRole, "Role granted the read access of the anonymous web clients, e.g., PostgREST, by the migrations")
+	PostgresCmd.Flags().String("raw-storage", string(output.RawStorageJSONB), "Storage of the raw JSON: jsonb, zstd (compressed) or drop (fetched from a node on demand)")
+	PostgresCmd.Flags().Bool("skip-migrations", false, "Do not run the migrations, which must be run with the migrate command")
 	if err := viper.BindPFlags(PostgresCmd.Flags()); err != nil {
 		slog.Error("Failed to bind postgresCmd flags", "error", err)
 	}
@@ -184,14 +184,15 @@ func testPrometheusMetrics(t *testing.T) {
 		require.Contains(t, body, "yaci_transactions_total_count{source=\"postgres\"} 48")
 		// 3000000umfx were burned by the MFX to PWR conversion
 		// 123umfx were burned by a POA proposal
-		require.Contains(t, body, "yaci_tokenomics_total_burn_amount{source=\"postgres\"} 3.000123e+06")
-		require.Contains(t, body, "yaci_tokenomics_total_payout_amount{source=\"postgres\"} 7.54321e+06")
+		require.Contains(t, body, "yaci_tokenomics_burned_amount{denom=\"umfx\",source=\"postgres\"} 3.000123e+06")
+		// 7543210umfx were minted by payouts
+		require.Contains(t, body, "yaci_tokenomics_minted_amount{denom=\"umfx\",source=\"postgres\"} 7.54321e+06")
 		// 6000000factory/.../upwr were minted by the MFX to PWR conversion
 		// 123factory/.../upwr were minted by a POA proposal
-		require.Contains(t, body, "yaci_tokenomics_total_pwr_minted_amount{source=\"postgres\"} 6.000123e+06")
+		require.Contains(t, body, "yaci_tokenomics_minted_amount{denom=\"factory/manifest1afk9zr2hn2jsac63h4hm60vl9z3e5u69gndzf7c99cqge3vzwjzsfmy9qj/upwr\",source=\"postgres\"} 6.000123e+06")
 		// 12factory/.../upwr were burned by a POA proposal
-		require.Contains(t, body, "yaci_tokenomics_total_pwr_burned_amount{source=\"postgres\"} 12")
-		require.Contains(t, body, "yaci_locked_tokens_count{amount=\"2000000000\",denom=\"umfx\",source=\"postgres\"} 1")
+		require.Contains(t, body, "yaci_tokenomics_burned_amount{denom=\"factory/manifest1afk9zr2hn2jsac63h4hm60vl9z3e5u69gndzf7c99cqge3vzwjzsfmy9qj/upwr\",source=\"postgres\"} 12")
+		require.Contains(t, body, "yaci_locked_tokens_amount{denom=\"umfx\",source=\"postgres\"} 2e+09")
 	})
 }
 
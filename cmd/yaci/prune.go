@@ -0,0 +1,97 @@
+package yaci
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/config"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/output/postgresql"
+)
+
+var PruneCmd = &cobra.Command{
+	Use:   "prune [flags]",
+	Args:  cobra.NoArgs,
+	Short: "Prune the old blocks of a chain from a PostgreSQL database",
+	Long: `Prune the blocks of a chain older than the retained blocks or duration from a PostgreSQL database.
+The partitions holding only pruned blocks are dropped, or detached with --detach-partitions. The normalized data of the
+pruned blocks is kept with --keep-normalized; only their raw JSON is pruned then.
+
+The pruned blocks are not indexed again: they are reported neither as the earliest block nor as missing blocks.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			if err := parent.PreRunE(parent, args); err != nil {
+				return err
+			}
+		}
+
+		// Bind the flags when the command runs, as some flags are shared with other commands.
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		postgresConfig := config.LoadPostgresConfigFromCLI()
+		if err := postgresConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid PostgreSQL configuration: %w", err)
+		}
+
+		pruneConfig := config.LoadPruneConfigFromCLI()
+		if err := pruneConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid Prune configuration: %w", err)
+		}
+
+		slog.Debug("Command-line arguments", "pruneConfig", pruneConfig)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		handleInterrupt(cancel)
+
+		outputHandler, err := postgresql.NewPostgresOutputHandler(postgresConfig.ConnString, postgresConfig.Schema, postgresConfig.AnonRole, postgresConfig.RawStorage, postgresConfig.SkipMigrations)
+		if err != nil {
+			return fmt.Errorf("failed to create PostgreSQL output handler: %w", err)
+		}
+		defer outputHandler.Close()
+
+		chainID, err := prunedChainID(ctx, postgresConfig, pruneConfig.ChainID)
+		if err != nil {
+			return err
+		}
+
+		floor, err := outputHandler.Prune(ctx, chainID, pruneConfig.Retention)
+		if err != nil {
+			return err
+		}
+		if floor == 0 {
+			slog.Info("No block to prune", "chain_id", chainID)
+		}
+		return nil
+	},
+}
+
+// prunedChainID returns the pruned chain: the configured chain, or the only indexed chain
+func prunedChainID(ctx context.Context, postgresConfig config.PostgresConfig, chainID string) (string, error) {
+	poolConfig, err := postgresql.ParsePoolConfig(postgresConfig.ConnString, postgresConfig.Schema)
+	if err != nil {
+		return "", err
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+	defer pool.Close()
+
+	return selectChainID(ctx, pool, chainID)
+}
+
+func init() {
+	PruneCmd.Flags().StringP("postgres-conn", "p", "", "PostgreSQL connection string")
+	PruneCmd.Flags().String("postgres-schema", models.DefaultSchema, "PostgreSQL schema of the indexed data")
+	PruneCmd.Flags().String("postgres-anon-role", models.DefaultAnonRole, "Role granted the read access of the anonymous web clients, e.g., PostgREST, by the migrations")
+	PruneCmd.Flags().Bool("skip-migrations", false, "Do not run the migrations, which must be run with the migrate command")
+	PruneCmd.Flags().String("chain-id", "", "Pruned chain (default: the only indexed chain)")
+	addRetentionFlags(PruneCmd.Flags())
+}
@@ -70,6 +70,12 @@ func init() {
 	viper.AutomaticEnv()
 
 	RootCmd.AddCommand(ExtractCmd)
+	RootCmd.AddCommand(ServeCmd)
+	RootCmd.AddCommand(VerifyCmd)
+	RootCmd.AddCommand(PruneCmd)
+	RootCmd.AddCommand(MigrateCmd)
+	RootCmd.AddCommand(DescriptorsCmd)
+	RootCmd.AddCommand(ImportGenesisCmd)
 	RootCmd.AddCommand(versionCmd)
 }
 
@@ -0,0 +1,137 @@
+package yaci
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/api"
+	"github.com/manifest-network/yaci/internal/api/graphql"
+	"github.com/manifest-network/yaci/internal/client"
+	"github.com/manifest-network/yaci/internal/config"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/output/postgresql"
+	"github.com/manifest-network/yaci/internal/source"
+	"github.com/manifest-network/yaci/internal/utils"
+)
+
+var ServeCmd = &cobra.Command{
+	Use:   "serve [flags]",
+	Args:  cobra.NoArgs,
+	Short: "Serve the indexed chain data over an HTTP/JSON API",
+	Long:  `Serve a read-only HTTP/JSON API over the chain data indexed in a PostgreSQL database.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			if err := parent.PreRunE(parent, args); err != nil {
+				return err
+			}
+		}
+
+		// Bind the flags when the command runs, as `postgres-conn` is shared with other commands.
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		postgresConfig := config.LoadPostgresConfigFromCLI()
+		if err := postgresConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid PostgreSQL configuration: %w", err)
+		}
+
+		serveConfig := config.LoadServeConfigFromCLI()
+		if err := serveConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid Serve configuration: %w", err)
+		}
+
+		slog.Debug("Command-line arguments", "serveConfig", serveConfig)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		handleInterrupt(cancel)
+
+		poolConfig, err := postgresql.ParsePoolConfig(postgresConfig.ConnString, postgresConfig.Schema)
+		if err != nil {
+			return err
+		}
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		defer pool.Close()
+
+		chainID, err := selectChainID(ctx, pool, serveConfig.ChainID)
+		if err != nil {
+			return err
+		}
+
+		server := api.NewServer(pool, postgresConfig.Schema, chainID)
+		if serveConfig.RawSource != "" {
+			fetcher, err := rawFetcher(ctx, serveConfig, chainID)
+			if err != nil {
+				return err
+			}
+			server.Store().SetRawFetcher(fetcher)
+		}
+		if serveConfig.EnableGraphQL {
+			schema, err := graphql.NewSchema(server.Store(), server.Listener())
+			if err != nil {
+				return fmt.Errorf("failed to create GraphQL schema: %w", err)
+			}
+			server.Handle("/graphql", graphql.NewHandler(schema))
+		}
+
+		return server.ListenAndServe(ctx, serveConfig.ListenAddr)
+	},
+}
+
+// selectChainID returns the chain a command applies to: the configured chain, or the only indexed chain.
+// An error is returned when the database holds several chains and none is configured.
+func selectChainID(ctx context.Context, pool *pgxpool.Pool, chainID string) (string, error) {
+	if chainID != "" {
+		return chainID, nil
+	}
+
+	chainIDs, err := api.NewStore(pool, "").ChainIDs(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(chainIDs) > 1 {
+		return "", fmt.Errorf("the database holds several chains %v, set --chain-id", chainIDs)
+	}
+	if len(chainIDs) == 1 {
+		chainID = chainIDs[0]
+	}
+	return chainID, nil
+}
+
+// rawFetcher returns the fetcher of the raw JSON that is not stored, from a gRPC server of the served chain
+func rawFetcher(ctx context.Context, cfg config.ServeConfig, chainID string) (*source.GRPC, error) {
+	gRPCClient, err := client.NewGRPCClient(ctx, cfg.RawSource, cfg.Insecure, cfg.MaxRecvMsgSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize gRPC: %w", err)
+	}
+
+	sourceChainID, err := utils.GetChainIDWithRetry(gRPCClient, cfg.MaxRetries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the chain ID of the raw source: %w", err)
+	}
+	if chainID != "" && sourceChainID != chainID {
+		return nil, fmt.Errorf("the raw source serves chain %s, not the served chain %s", sourceChainID, chainID)
+	}
+
+	return source.NewGRPC(gRPCClient, cfg.MaxRetries), nil
+}
+
+func init() {
+	ServeCmd.Flags().StringP("postgres-conn", "p", "", "PostgreSQL connection string")
+	ServeCmd.Flags().String("postgres-schema", models.DefaultSchema, "PostgreSQL schema of the indexed data")
+	ServeCmd.Flags().String("listen-addr", "0.0.0.0:8080", "Address and port of the API server")
+	ServeCmd.Flags().Bool("enable-graphql", false, "Serve a GraphQL endpoint on /graphql")
+	ServeCmd.Flags().String("chain-id", "", "Chain served by the API (default: the only indexed chain)")
+	ServeCmd.Flags().String("raw-source", "", "gRPC address the raw JSON that is not stored is fetched from (default: none)")
+	ServeCmd.Flags().BoolP("insecure", "k", false, "Skip TLS certificate verification of the raw source (INSECURE)")
+	ServeCmd.Flags().UintP("max-retries", "r", 3, "Maximum number of retries for failed gRPC queries to the raw source")
+	ServeCmd.Flags().IntP("max-recv-msg-size", "m", 4194304, "Maximum gRPC message size in bytes (advanced)")
+}
@@ -0,0 +1,401 @@
+package yaci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/api"
+	"github.com/manifest-network/yaci/internal/client"
+	"github.com/manifest-network/yaci/internal/config"
+	"github.com/manifest-network/yaci/internal/extractor"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/output"
+	"github.com/manifest-network/yaci/internal/output/postgresql"
+	"github.com/manifest-network/yaci/internal/source"
+	"github.com/manifest-network/yaci/internal/utils"
+	"github.com/manifest-network/yaci/internal/verify"
+)
+
+var VerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the indexed data against the chain",
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			return parent.PreRunE(parent, args)
+		}
+		return nil
+	},
+}
+
+var VerifyBalancesCmd = &cobra.Command{
+	Use:   "balances [address] [flags]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Spot-check the indexed balances against the bank module",
+	Long: `Compare the balances reconstructed from the indexed balance changes with the balances returned by
+the cosmos.bank.v1beta1.Query/Balance gRPC method at the same height. The node must not have pruned its state at that height.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			if err := parent.PreRunE(parent, args); err != nil {
+				return err
+			}
+		}
+
+		// Bind the flags when the command runs, as some flags are shared with other commands.
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		postgresConfig := config.LoadPostgresConfigFromCLI()
+		if err := postgresConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid PostgreSQL configuration: %w", err)
+		}
+
+		verifyConfig := config.LoadVerifyBalancesConfigFromCLI()
+		if err := verifyConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid Verify configuration: %w", err)
+		}
+
+		slog.Debug("Command-line arguments", "verifyConfig", verifyConfig)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		handleInterrupt(cancel)
+
+		poolConfig, err := postgresql.ParsePoolConfig(postgresConfig.ConnString, postgresConfig.Schema)
+		if err != nil {
+			return err
+		}
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		defer pool.Close()
+
+		gRPCClient, err := client.NewGRPCClient(ctx, args[0], verifyConfig.Insecure, verifyConfig.MaxRecvMsgSize)
+		if err != nil {
+			return fmt.Errorf("failed to initialize gRPC: %w", err)
+		}
+
+		chainID, err := utils.GetChainIDWithRetry(gRPCClient, verifyConfig.MaxRetries)
+		if err != nil {
+			return fmt.Errorf("failed to get the chain ID: %w", err)
+		}
+
+		height := verifyConfig.Height
+		if height == 0 {
+			if height, err = api.NewStore(pool, chainID).LatestBlockHeight(ctx); err != nil {
+				return err
+			}
+		}
+
+		targets, err := balanceTargets(ctx, pool, chainID, verifyConfig, height)
+		if err != nil {
+			return err
+		}
+
+		checks, err := verify.Balances(ctx, pool, gRPCClient, chainID, targets, height, verifyConfig.MaxRetries)
+		if err != nil {
+			return err
+		}
+
+		var mismatches, failures int
+		for _, check := range checks {
+			switch {
+			case check.Error != nil:
+				failures++
+				slog.Warn("Failed to query balance", "address", check.Address, "denom", check.Denom, "height", check.Height, "error", check.Error)
+			case !check.Match():
+				mismatches++
+				slog.Error("Balance mismatch", "address", check.Address, "denom", check.Denom, "height", check.Height, "indexed", check.Indexed, "node", check.Node)
+			default:
+				slog.Info("Balance verified", "address", check.Address, "denom", check.Denom, "height", check.Height, "amount", check.Indexed)
+			}
+		}
+
+		slog.Info("Balance verification done", "height", height, "checked", len(checks), "mismatches", mismatches, "failures", failures)
+		if mismatches > 0 {
+			return fmt.Errorf("%d balance mismatches at height %d", mismatches, height)
+		}
+		return nil
+	},
+}
+
+// balanceTargets returns the balances to verify: every denomination of the given accounts, or a random sample
+func balanceTargets(ctx context.Context, pool *pgxpool.Pool, chainID string, cfg config.VerifyBalancesConfig, height uint64) ([]verify.BalanceTarget, error) {
+	if len(cfg.Accounts) == 0 {
+		return verify.SampleBalanceTargets(ctx, pool, chainID, cfg.Denom, height, cfg.Samples)
+	}
+
+	var targets []verify.BalanceTarget
+	for _, account := range cfg.Accounts {
+		if cfg.Denom != "" {
+			targets = append(targets, verify.BalanceTarget{Address: account, Denom: cfg.Denom})
+			continue
+		}
+		accountTargets, err := verify.AddressBalanceTargets(ctx, pool, chainID, account, height)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, accountTargets...)
+	}
+	return targets, nil
+}
+
+var VerifyBlocksCmd = &cobra.Command{
+	Use:   "blocks [address] [flags]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Audit the indexed blocks and transactions against the chain",
+	Long: `Compare the indexed blocks with the blocks returned by the cosmos.tx.v1beta1.Service/GetBlockWithTxs gRPC method:
+the block hashes, the number of transactions, the missing or extra transactions, and the link of each block header to
+the hash of the previous indexed block. Every block from --start to --stop is verified, or a random sample of them with
+--samples. The default range is the indexed blocks above the pruning floor.
+
+The discrepant blocks are extracted again with --repair, from --repair-source if set, and verified again. The blocks
+indexed with their block events are only repaired from a source providing them, e.g., a CometBFT RPC URL. A JSON report
+of the verified blocks is written with --report.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		if parent := cmd.Parent(); parent != nil && parent.PreRunE != nil {
+			if err := parent.PreRunE(parent, args); err != nil {
+				return err
+			}
+		}
+
+		// Bind the flags when the command runs, as some flags are shared with other commands.
+		return viper.BindPFlags(cmd.Flags())
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		postgresConfig := config.LoadPostgresConfigFromCLI()
+		if err := postgresConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid PostgreSQL configuration: %w", err)
+		}
+
+		verifyConfig := config.LoadVerifyBlocksConfigFromCLI()
+		if err := verifyConfig.Validate(); err != nil {
+			return fmt.Errorf("invalid Verify configuration: %w", err)
+		}
+
+		slog.Debug("Command-line arguments", "verifyConfig", verifyConfig)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		handleInterrupt(cancel)
+
+		poolConfig, err := postgresql.ParsePoolConfig(postgresConfig.ConnString, postgresConfig.Schema)
+		if err != nil {
+			return err
+		}
+		pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+		if err != nil {
+			return fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+		}
+		defer pool.Close()
+
+		gRPCClient, err := client.NewGRPCClient(ctx, args[0], verifyConfig.Insecure, verifyConfig.MaxRecvMsgSize)
+		if err != nil {
+			return fmt.Errorf("failed to initialize gRPC: %w", err)
+		}
+
+		chainID, err := utils.GetChainIDWithRetry(gRPCClient, verifyConfig.MaxRetries)
+		if err != nil {
+			return fmt.Errorf("failed to get the chain ID: %w", err)
+		}
+
+		from, to, err := verify.BlockRange(ctx, pool, chainID)
+		if err != nil {
+			return err
+		}
+		if verifyConfig.BlockStart != 0 {
+			from = verifyConfig.BlockStart
+		}
+		if verifyConfig.BlockStop != 0 {
+			to = verifyConfig.BlockStop
+		}
+		if to == 0 || from > to {
+			slog.Info("No block to verify", "chain_id", chainID)
+			return nil
+		}
+
+		gRPCSource := source.NewGRPC(gRPCClient, verifyConfig.MaxRetries)
+		heights := verify.SampleHeights(from, to, verifyConfig.Samples)
+		checks, err := verify.Blocks(ctx, pool, gRPCSource, chainID, heights, verifyConfig.MaxConcurrency)
+		if err != nil {
+			return err
+		}
+
+		if verifyConfig.Repair {
+			repairSource, err := newRepairSource(ctx, gRPCSource, verifyConfig)
+			if err != nil {
+				return err
+			}
+			if err := repairBlocks(ctx, pool, repairSource, gRPCSource, chainID, postgresConfig, verifyConfig, checks); err != nil {
+				return err
+			}
+		}
+
+		var unrepaired int
+		for _, check := range checks {
+			switch {
+			case check.Error != "":
+				slog.Warn("Failed to query block", "height", check.Height, "error", check.Error)
+			case !check.Match():
+				if !check.Repaired {
+					unrepaired++
+				}
+				slog.Error("Block discrepancy", "height", check.Height, "issues", check.Issues, "missing_txs", check.MissingTxs, "extra_txs", check.ExtraTxs, "repaired", check.Repaired)
+			default:
+				slog.Debug("Block verified", "height", check.Height)
+			}
+		}
+
+		report := verify.NewBlocksReport(chainID, from, to, checks)
+		if verifyConfig.Report != "" {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal the report: %w", err)
+			}
+			if err := os.WriteFile(verifyConfig.Report, data, 0o644); err != nil {
+				return fmt.Errorf("failed to write the report: %w", err)
+			}
+		}
+
+		slog.Info("Block verification done", "from", from, "to", to, "checked", report.Checked, "discrepancies", report.Discrepancies, "repaired", report.Repaired, "failures", report.Failures)
+		if unrepaired > 0 {
+			return fmt.Errorf("%d discrepant blocks from height %d to %d", unrepaired, from, to)
+		}
+		return nil
+	},
+}
+
+// newRepairSource returns the source the discrepant blocks are extracted from: the repair source, a gRPC address or a
+// CometBFT RPC URL, or the verified gRPC server if it is not set
+func newRepairSource(ctx context.Context, gRPCSource *source.GRPC, verifyConfig config.VerifyBlocksConfig) (source.BlockSource, error) {
+	address := verifyConfig.RepairSource
+	switch {
+	case address == "":
+		return gRPCSource, nil
+	case source.IsCometBFTAddress(address):
+		src, err := openCometBFTSource(ctx, address, verifyConfig.DescriptorSet, verifyConfig.MaxRetries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize CometBFT RPC for %s: %w", address, err)
+		}
+		return src, nil
+	default:
+		gRPCClient, err := client.NewGRPCClient(ctx, address, verifyConfig.Insecure, verifyConfig.MaxRecvMsgSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize gRPC for %s: %w", address, err)
+		}
+		return source.NewGRPC(gRPCClient, verifyConfig.MaxRetries), nil
+	}
+}
+
+// repairBlocks extracts the discrepant blocks again, replacing them in the output, and verifies them again. The previous
+// block of a broken hash link is extracted again as well. The blocks indexed with their block events are not repaired
+// from a source that does not provide them, which would delete the changes derived from these events.
+func repairBlocks(ctx context.Context, pool *pgxpool.Pool, src source.BlockSource, fetcher verify.BlockFetcher, chainID string, postgresConfig config.PostgresConfig, verifyConfig config.VerifyBlocksConfig, checks []verify.BlockCheck) error {
+	var outputHandler output.OutputHandler
+	for i, check := range checks {
+		if check.Error != "" || check.Match() {
+			continue
+		}
+
+		heights := []uint64{check.Height}
+		if slices.Contains(check.Issues, verify.IssueBrokenHashLink) {
+			heights = []uint64{check.Height - 1, check.Height}
+		}
+		if !source.ProvidesBlockEvents(src) {
+			skip, err := hasIndexedBlockEvents(ctx, pool, chainID, heights)
+			if err != nil {
+				return err
+			}
+			if skip {
+				slog.Warn("Not repairing block indexed with its block events from a source that does not provide them, set --repair-source to a CometBFT RPC URL", "height", check.Height)
+				continue
+			}
+		}
+
+		if outputHandler == nil {
+			handler, err := postgresql.NewPostgresOutputHandler(postgresConfig.ConnString, postgresConfig.Schema, postgresConfig.AnonRole, postgresConfig.RawStorage, postgresConfig.SkipMigrations)
+			if err != nil {
+				return fmt.Errorf("failed to create PostgreSQL output handler: %w", err)
+			}
+			defer handler.Close()
+			outputHandler = handler
+		}
+
+		var extractErr error
+		for _, height := range heights {
+			// The gov outcomes are only queried for the blocks ending the voting period of an indexed proposal, i.e., if
+			// they were recorded with --gov-outcomes
+			if extractErr = extractor.ExtractBlock(ctx, src, height, outputHandler, verifyConfig.IndexSignatures, true); extractErr != nil {
+				slog.Error("Failed to repair block", "height", height, "error", extractErr)
+				break
+			}
+		}
+		if extractErr != nil {
+			continue
+		}
+
+		recheck, err := verify.Block(ctx, pool, fetcher, chainID, check.Height)
+		if err != nil {
+			return err
+		}
+		if !recheck.Match() {
+			slog.Error("Block still discrepant after its repair", "height", check.Height, "issues", recheck.Issues, "error", recheck.Error)
+			continue
+		}
+		checks[i].Repaired = true
+		slog.Info("Block repaired", "height", check.Height)
+	}
+	return nil
+}
+
+// hasIndexedBlockEvents returns whether one of the indexed blocks at the heights was extracted with its block events
+func hasIndexedBlockEvents(ctx context.Context, pool *pgxpool.Pool, chainID string, heights []uint64) (bool, error) {
+	for _, height := range heights {
+		hasEvents, err := verify.IndexedBlockHasEvents(ctx, pool, chainID, height)
+		if err != nil || hasEvents {
+			return hasEvents, err
+		}
+	}
+	return false, nil
+}
+
+func init() {
+	VerifyBalancesCmd.Flags().StringP("postgres-conn", "p", "", "PostgreSQL connection string")
+	VerifyBalancesCmd.Flags().String("postgres-schema", models.DefaultSchema, "PostgreSQL schema of the indexed data")
+	VerifyBalancesCmd.Flags().StringSlice("account", nil, "Addresses to verify (default: a random sample)")
+	VerifyBalancesCmd.Flags().String("denom", "", "Denom to verify (default: all denoms)")
+	VerifyBalancesCmd.Flags().Uint64("height", 0, "Height of the verified balances (default: latest indexed block)")
+	VerifyBalancesCmd.Flags().Uint("samples", 10, "Number of random balances to verify when no account is set")
+	VerifyBalancesCmd.Flags().BoolP("insecure", "k", false, "Skip TLS certificate verification (INSECURE)")
+	VerifyBalancesCmd.Flags().UintP("max-retries", "r", 3, "Maximum number of retries for failed gRPC queries")
+	VerifyBalancesCmd.Flags().IntP("max-recv-msg-size", "m", 4194304, "Maximum gRPC message size in bytes (advanced)")
+
+	VerifyBlocksCmd.Flags().StringP("postgres-conn", "p", "", "PostgreSQL connection string")
+	VerifyBlocksCmd.Flags().String("postgres-schema", models.DefaultSchema, "PostgreSQL schema of the indexed data")
+	VerifyBlocksCmd.Flags().String("postgres-anon-role", models.DefaultAnonRole, "Role granted the read access of the anonymous web clients, e.g., PostgREST, by the migrations")
+	VerifyBlocksCmd.Flags().Bool("skip-migrations", false, "Do not run the migrations when repairing, which must be run with the migrate command")
+	VerifyBlocksCmd.Flags().String("raw-storage", string(output.RawStorageJSONB), "Storage of the raw JSON of the repaired blocks: jsonb, zstd (compressed) or drop")
+	VerifyBlocksCmd.Flags().Uint64P("start", "s", 0, "Start block height (default: earliest indexed block)")
+	VerifyBlocksCmd.Flags().Uint64P("stop", "e", 0, "Stop block height (default: latest indexed block)")
+	VerifyBlocksCmd.Flags().Uint("samples", 0, "Number of random blocks to verify in the range (default: every block)")
+	VerifyBlocksCmd.Flags().UintP("max-concurrency", "c", 10, "Maximum number of blocks verified concurrently")
+	VerifyBlocksCmd.Flags().Bool("repair", false, "Extract the discrepant blocks again")
+	VerifyBlocksCmd.Flags().String("repair-source", "", "gRPC address or CometBFT RPC URL the discrepant blocks are extracted from, required to repair the blocks indexed with their block events (default: the verified address)")
+	VerifyBlocksCmd.Flags().String("descriptor-set", "", "File of the descriptors of the chain decoding the blocks of a CometBFT RPC repair source; queried from the node if not set")
+	VerifyBlocksCmd.Flags().Bool("index-signatures", false, "Index the validator signatures of the repaired blocks")
+	VerifyBlocksCmd.Flags().String("report", "", "File the JSON report of the verified blocks is written to")
+	VerifyBlocksCmd.Flags().BoolP("insecure", "k", false, "Skip TLS certificate verification (INSECURE)")
+	VerifyBlocksCmd.Flags().UintP("max-retries", "r", 3, "Maximum number of retries for failed gRPC queries")
+	VerifyBlocksCmd.Flags().IntP("max-recv-msg-size", "m", 4194304, "Maximum gRPC message size in bytes (advanced)")
+
+	VerifyCmd.AddCommand(VerifyBalancesCmd)
+	VerifyCmd.AddCommand(VerifyBlocksCmd)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+func TestCursor(t *testing.T) {
+	c := Cursor{Height: 42, ID: "abcd", MessageIndex: 3}
+	decoded, err := DecodeCursor(EncodeCursor(c))
+	require.NoError(t, err)
+	require.Equal(t, &c, decoded)
+
+	decoded, err = DecodeCursor("")
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+
+	_, err = DecodeCursor("not a cursor!")
+	require.Error(t, err)
+}
+
+func TestNewPage(t *testing.T) {
+	cursorOf := func(h uint64) Cursor { return Cursor{Height: h} }
+
+	page := NewPage([]uint64{5, 4, 3}, 2, cursorOf)
+	require.Equal(t, []uint64{5, 4}, page.Items)
+	next, err := DecodeCursor(page.NextCursor)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), next.Height)
+
+	page = NewPage([]uint64{5, 4}, 2, cursorOf)
+	require.Equal(t, []uint64{5, 4}, page.Items)
+	require.Empty(t, page.NextCursor)
+
+	page = NewPage[uint64](nil, 2, cursorOf)
+	require.NotNil(t, page.Items)
+}
+
+func TestPageParams(t *testing.T) {
+	limit, cursor, err := pageParams(httptest.NewRequest(http.MethodGet, "/v1/blocks", nil))
+	require.NoError(t, err)
+	require.Equal(t, DefaultPageSize, limit)
+	require.Nil(t, cursor)
+
+	limit, _, err = pageParams(httptest.NewRequest(http.MethodGet, "/v1/blocks?limit=100000", nil))
+	require.NoError(t, err)
+	require.Equal(t, MaxPageSize, limit)
+
+	_, _, err = pageParams(httptest.NewRequest(http.MethodGet, "/v1/blocks?limit=0", nil))
+	require.Error(t, err)
+}
+
+func TestWriteJSONETag(t *testing.T) {
+	rec := httptest.NewRecorder()
+	writeJSON(rec, httptest.NewRequest(http.MethodGet, "/", nil), http.StatusOK, map[string]int{"height": 1})
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.JSONEq(t, `{"height": 1}`, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"other", `+etag)
+	rec = httptest.NewRecorder()
+	writeJSON(rec, req, http.StatusOK, map[string]int{"height": 1})
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Empty(t, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	writeJSON(rec, req, http.StatusOK, map[string]int{"height": 2})
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestListener(t *testing.T) {
+	l := NewListener(nil, models.DefaultSchema, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	notifications := l.Subscribe(ctx)
+
+	l.broadcast(models.BlockNotification{Height: 42, TxHashes: []string{"abcd"}})
+	require.Equal(t, models.BlockNotification{Height: 42, TxHashes: []string{"abcd"}}, <-notifications)
+
+	// Slow subscribers are dropped
+	for i := 0; i <= subscriberBufferSize; i++ {
+		l.broadcast(models.BlockNotification{Height: uint64(i)})
+	}
+	for range notifications {
+	}
+
+	// Subscriptions end with their context
+	notifications = l.Subscribe(ctx)
+	cancel()
+	_, ok := <-notifications
+	require.False(t, ok)
+}
+
+func TestStreamNotifications(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sse := &eventStream{w: rec, flusher: rec}
+
+	// Blocks 5 and 7 were replayed up to the latest height 7, while block 6 was not indexed yet
+	replayed := map[uint64]struct{}{5: {}, 7: {}}
+	notifications := make(chan models.BlockNotification, 4)
+	notifications <- models.BlockNotification{Height: 7}
+	notifications <- models.BlockNotification{Height: 6}
+	notifications <- models.BlockNotification{Height: 3}
+	notifications <- models.BlockNotification{Height: 8}
+	close(notifications)
+
+	var sent []uint64
+	streamNotifications(context.Background(), sse, notifications, replayed, func(_ context.Context, _ *eventStream, height uint64) bool {
+		sent = append(sent, height)
+		return true
+	})
+
+	// The blocks filling a gap below the latest replayed block are sent, the replayed blocks are not sent again
+	require.Equal(t, []uint64{6, 3, 8}, sent)
+}
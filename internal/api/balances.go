@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// handleGetAddressBalances returns the balances of an address at the `height` query parameter, or at the latest indexed block.
+// The balances are marked incomplete when the balance ledger may miss balance changes at the height.
+func (s *Server) handleGetAddressBalances(w http.ResponseWriter, r *http.Request) {
+	var height uint64
+	var err error
+	if v := r.URL.Query().Get("height"); v != "" {
+		if height, err = strconv.ParseUint(v, 10, 63); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid height")
+			return
+		}
+	} else if height, err = s.store.LatestBlockHeight(r.Context()); err != nil {
+		writeQueryError(w, "failed to get latest block height", err)
+		return
+	}
+
+	address := r.PathValue("address")
+	balances, err := s.store.GetBalances(r.Context(), address, height)
+	if err != nil {
+		writeQueryError(w, "failed to get balances", err)
+		return
+	}
+	if balances == nil {
+		balances = []Balance{}
+	}
+	complete, err := s.store.BalanceLedgerComplete(r.Context(), height)
+	if err != nil {
+		writeQueryError(w, "failed to check the balance ledger", err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, Balances{Address: address, Height: height, Balances: balances, Complete: complete})
+}
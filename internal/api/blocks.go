@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+func (s *Server) handleGetBlock(w http.ResponseWriter, r *http.Request) {
+	height, err := strconv.ParseUint(r.PathValue("height"), 10, 63)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid height")
+		return
+	}
+
+	block, err := s.store.GetBlock(r.Context(), height)
+	if err != nil {
+		writeQueryError(w, "failed to get block", err)
+		return
+	}
+	if block == nil {
+		writeError(w, http.StatusNotFound, "block not found")
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, block)
+}
+
+// handleListBlocks lists block summaries in the optional [from, to] height range.
+// Blocks are sorted by descending height unless `order=asc` is set.
+func (s *Server) handleListBlocks(w http.ResponseWriter, r *http.Request) {
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var filter BlockFilter
+	if v := r.URL.Query().Get("from"); v != "" {
+		if filter.FromHeight, err = strconv.ParseUint(v, 10, 63); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid from height")
+			return
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if filter.ToHeight, err = strconv.ParseUint(v, 10, 63); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid to height")
+			return
+		}
+	}
+
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		writeError(w, http.StatusBadRequest, "invalid order, expected asc or desc")
+		return
+	}
+
+	blocks, err := s.store.ListBlocks(r.Context(), filter, limit, cursor)
+	if err != nil {
+		writeQueryError(w, "failed to list blocks", err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, NewPage(blocks, limit, BlockCursor))
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+)
+
+// handleListEvents lists the events matching the `type`, `key` and `value` filters, newest first.
+// At least one of `type` or `key` is required so the query can use the events indices.
+func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	q := r.URL.Query()
+	filter := EventFilter{Type: q.Get("type"), Key: q.Get("key"), Value: q.Get("value")}
+	if filter.Type == "" && filter.Key == "" {
+		writeError(w, http.StatusBadRequest, "missing type or key filter")
+		return
+	}
+	if filter.Value != "" && filter.Key == "" {
+		writeError(w, http.StatusBadRequest, "value filter requires a key filter")
+		return
+	}
+
+	events, err := s.store.ListEvents(r.Context(), filter, limit, cursor)
+	if err != nil {
+		writeQueryError(w, "failed to list events", err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, NewPage(events, limit, EventCursor))
+}
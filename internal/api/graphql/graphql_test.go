@@ -0,0 +1,60 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/api"
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+func TestHandler(t *testing.T) {
+	schema, err := NewSchema(nil, api.NewListener(nil, models.DefaultSchema, ""))
+	require.NoError(t, err)
+	h := NewHandler(schema)
+
+	t.Run("MissingQuery", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Introspection", func(t *testing.T) {
+		body := `{"query": "{ __schema { queryType { name } subscriptionType { name } } }"}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var result struct {
+			Data struct {
+				Schema struct {
+					QueryType        struct{ Name string } `json:"queryType"`
+					SubscriptionType struct{ Name string } `json:"subscriptionType"`
+				} `json:"__schema"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		require.Equal(t, "Query", result.Data.Schema.QueryType.Name)
+		require.Equal(t, "Subscription", result.Data.Schema.SubscriptionType.Name)
+	})
+
+	t.Run("InvalidArgument", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, `/graphql?query={blocks(first:0){pageInfo{hasNextPage}}}`, nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "errors")
+	})
+
+	t.Run("QueryLimits", func(t *testing.T) {
+		body := `{"query": "{ blocks(first: 500) { nodes { transactions(first: 500) { nodes { hash } } } } }"}`
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "exceeds the maximum complexity")
+	})
+}
@@ -0,0 +1,176 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// maxRequestSize is the maximum size of a GraphQL request body
+const maxRequestSize = 1 << 20
+
+type request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+type handler struct {
+	schema graphql.Schema
+}
+
+// NewHandler returns an HTTP handler executing GraphQL requests against the schema.
+// Queries are accepted as GET parameters or as a JSON POST body.
+// Subscriptions are streamed as server-sent events when the client accepts `text/event-stream`.
+// The operations exceeding the query limits are rejected before they are executed.
+func NewHandler(schema graphql.Schema) http.Handler {
+	return &handler{schema: schema}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := parseRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, "missing query")
+		return
+	}
+
+	params := graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		VariableValues: req.Variables,
+		OperationName:  req.OperationName,
+		Context:        withLoaders(r.Context()),
+	}
+
+	doc, invalid := h.validate(params)
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		h.serveSubscription(w, r, params, invalid)
+		return
+	}
+	if invalid != nil {
+		writeJSON(w, http.StatusOK, invalid)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, graphql.Execute(graphql.ExecuteParams{
+		Schema:        params.Schema,
+		AST:           doc,
+		OperationName: params.OperationName,
+		Args:          params.VariableValues,
+		Context:       params.Context,
+	}))
+}
+
+// validate parses the request and validates it with the specified rules and the query limits, returning the result
+// holding the errors if the request is invalid
+func (h *handler) validate(params graphql.Params) (*ast.Document, *graphql.Result) {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(params.RequestString), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return nil, &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	rules := append(append([]graphql.ValidationRuleFn{}, graphql.SpecifiedRules...), queryLimitsRule(params.VariableValues))
+	if validation := graphql.ValidateDocument(&h.schema, doc, rules); !validation.IsValid {
+		return nil, &graphql.Result{Errors: validation.Errors}
+	}
+	return doc, nil
+}
+
+// serveSubscription streams each result of the operation as a `next` event, followed by a `complete` event.
+// An invalid operation is streamed as a single result holding its errors.
+func (h *handler) serveSubscription(w http.ResponseWriter, r *http.Request, params graphql.Params, invalid *graphql.Result) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var results chan *graphql.Result
+	if invalid != nil {
+		results = make(chan *graphql.Result, 1)
+		results <- invalid
+		close(results)
+	} else {
+		results = graphql.Subscribe(params)
+	}
+	// The results channel is unbuffered: drain it so the subscription goroutine can exit.
+	defer func() {
+		for range results {
+		}
+	}()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case result, ok := <-results:
+			if !ok {
+				fmt.Fprint(w, "event: complete\ndata: \n\n")
+				flusher.Flush()
+				return
+			}
+
+			data, err := json.Marshal(result)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: next\ndata: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+func parseRequest(r *http.Request) (request, error) {
+	var req request
+
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return req, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid request body: %w", err)
+		}
+	default:
+		return req, fmt.Errorf("unsupported method %s", r.Method)
+	}
+
+	return req, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"errors": []map[string]string{{"message": message}},
+	})
+}
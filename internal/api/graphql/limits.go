@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
+	"github.com/graphql-go/graphql/language/visitor"
+
+	"github.com/manifest-network/yaci/internal/api"
+)
+
+const (
+	// maxQueryDepth is the maximum nesting of the fields of an operation. The relationships of the schema have cycles,
+	// e.g., transaction.messages.transaction, so the depth of a query is otherwise unbounded.
+	maxQueryDepth = 10
+	// maxQueryComplexity is the maximum complexity of an operation, an estimate of the number of resolved fields
+	maxQueryComplexity = 10000
+	// listSizeEstimate is the estimated number of items of the lists that are not paginated, e.g., the messages of a
+	// transaction
+	listSizeEstimate = 10
+)
+
+// queryLimitsRule returns a validation rule rejecting the operations deeper than maxQueryDepth or more complex than
+// maxQueryComplexity, before they are executed.
+// The complexity of a field is one plus the complexity of its selections, multiplied by the page size of the
+// paginated fields, i.e., the `first` argument or its variable, and by listSizeEstimate for the other lists.
+// The introspection fields are not counted.
+func queryLimitsRule(variables map[string]interface{}) graphql.ValidationRuleFn {
+	return func(context *graphql.ValidationContext) *graphql.ValidationRuleInstance {
+		visitorOpts := &visitor.VisitorOptions{
+			KindFuncMap: map[string]visitor.NamedVisitFuncs{
+				kinds.OperationDefinition: {
+					Kind: func(p visitor.VisitFuncParams) (string, interface{}) {
+						operation, ok := p.Node.(*ast.OperationDefinition)
+						if !ok {
+							return visitor.ActionSkip, nil
+						}
+						rootType := operationType(context.Schema(), operation.Operation)
+						if rootType == nil {
+							return visitor.ActionSkip, nil
+						}
+
+						c := &limitsCounter{context: context, variables: variables, fragments: map[string]bool{}}
+						depth, complexity := c.selectionSet(operation.SelectionSet, rootType)
+						if depth > maxQueryDepth {
+							context.ReportError(gqlerrors.NewError(
+								fmt.Sprintf("query depth %d exceeds the maximum depth %d", depth, maxQueryDepth),
+								[]ast.Node{operation}, "", nil, []int{}, nil,
+							))
+						}
+						if complexity > maxQueryComplexity {
+							context.ReportError(gqlerrors.NewError(
+								fmt.Sprintf("query complexity %d exceeds the maximum complexity %d", complexity, maxQueryComplexity),
+								[]ast.Node{operation}, "", nil, []int{}, nil,
+							))
+						}
+						return visitor.ActionSkip, nil
+					},
+				},
+			},
+		}
+		return &graphql.ValidationRuleInstance{VisitorOpts: visitorOpts}
+	}
+}
+
+// operationType returns the root type of an operation
+func operationType(schema *graphql.Schema, operation string) *graphql.Object {
+	switch operation {
+	case ast.OperationTypeQuery:
+		return schema.QueryType()
+	case ast.OperationTypeMutation:
+		return schema.MutationType()
+	case ast.OperationTypeSubscription:
+		return schema.SubscriptionType()
+	}
+	return nil
+}
+
+// limitsCounter computes the depth and the complexity of the selections of an operation
+type limitsCounter struct {
+	context   *graphql.ValidationContext
+	variables map[string]interface{}
+	// fragments are the fragments being counted, to stop at the fragment cycles, which are reported by another rule
+	fragments map[string]bool
+}
+
+// selectionSet returns the depth and the complexity of the selections on a type
+func (c *limitsCounter) selectionSet(set *ast.SelectionSet, parent *graphql.Object) (int, int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	for _, selection := range set.Selections {
+		var d, n int
+		switch s := selection.(type) {
+		case *ast.Field:
+			d, n = c.field(s, parent)
+		case *ast.InlineFragment:
+			d, n = c.selectionSet(s.SelectionSet, c.fragmentType(s.TypeCondition, parent))
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			fragment := c.context.Fragment(name)
+			if fragment == nil || c.fragments[name] {
+				continue
+			}
+			c.fragments[name] = true
+			d, n = c.selectionSet(fragment.SelectionSet, c.fragmentType(fragment.TypeCondition, parent))
+			delete(c.fragments, name)
+		}
+		depth = max(depth, d)
+		complexity += n
+	}
+	return depth, complexity
+}
+
+// field returns the depth and the complexity of a field and its selections
+func (c *limitsCounter) field(field *ast.Field, parent *graphql.Object) (int, int) {
+	name := field.Name.Value
+	if parent == nil || strings.HasPrefix(name, "__") {
+		return 0, 0
+	}
+	def, ok := parent.Fields()[name]
+	if !ok {
+		// Unknown fields are reported by another rule
+		return 1, 1
+	}
+
+	fieldType, _ := graphql.GetNamed(def.Type).(*graphql.Object)
+	depth, complexity := c.selectionSet(field.SelectionSet, fieldType)
+	return depth + 1, 1 + c.multiplier(field, def, parent)*complexity
+}
+
+// multiplier returns the estimated number of items of a field: the page size of the paginated fields, whose nodes are
+// counted once, and listSizeEstimate for the other lists
+func (c *limitsCounter) multiplier(field *ast.Field, def *graphql.FieldDefinition, parent *graphql.Object) int {
+	for _, arg := range def.Args {
+		if arg.Name() == "first" {
+			return c.pageSize(field)
+		}
+	}
+	if _, ok := graphql.GetNullable(def.Type).(*graphql.List); ok && !strings.HasSuffix(parent.Name(), "Connection") {
+		return listSizeEstimate
+	}
+	return 1
+}
+
+// pageSize returns the page size requested by the `first` argument of a field, bounded as the resolvers bound it
+func (c *limitsCounter) pageSize(field *ast.Field) int {
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if first, err := strconv.Atoi(v.Value); err == nil {
+				return max(1, min(first, api.MaxPageSize))
+			}
+		case *ast.Variable:
+			if first, ok := c.variables[v.Name.Value].(float64); ok {
+				return max(1, min(int(first), api.MaxPageSize))
+			}
+		}
+		return api.MaxPageSize
+	}
+	return api.DefaultPageSize
+}
+
+// fragmentType returns the type of a fragment's condition, or the parent type if the fragment has no condition
+func (c *limitsCounter) fragmentType(condition *ast.Named, parent *graphql.Object) *graphql.Object {
+	if condition == nil || condition.Name == nil {
+		return parent
+	}
+	if t, ok := c.context.Schema().Type(condition.Name.Value).(*graphql.Object); ok {
+		return t
+	}
+	return parent
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/api"
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+func TestQueryLimitsRule(t *testing.T) {
+	schema, err := NewSchema(nil, api.NewListener(nil, models.DefaultSchema, ""))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		err       string
+	}{
+		{
+			name:  "Nested",
+			query: `{ blocks(first: 10) { nodes { hash transactions(first: 20) { nodes { hash messages { type } } } } } }`,
+		},
+		{
+			name:  "Introspection",
+			query: `{ __schema { types { name fields { name type { name ofType { name ofType { name ofType { name } } } } } } } }`,
+		},
+		{
+			name:  "Depth",
+			query: `{ transaction(hash: "a") { messages { transaction { messages { transaction { messages { transaction { messages { transaction { messages { transaction { hash } } } } } } } } } } } }`,
+			err:   "query depth 12 exceeds the maximum depth 10",
+		},
+		{
+			name:  "FragmentDepth",
+			query: `{ transaction(hash: "a") { ...tx } } fragment tx on Transaction { messages { transaction { messages { transaction { messages { transaction { messages { transaction { messages { transaction { hash } } } } } } } } } } }`,
+			err:   "query depth 12 exceeds the maximum depth 10",
+		},
+		{
+			name:  "Complexity",
+			query: `{ blocks(first: 500) { nodes { transactions(first: 500) { nodes { hash } } } } }`,
+			err:   "exceeds the maximum complexity",
+		},
+		{
+			name:      "VariableComplexity",
+			query:     `query($n: Int) { blocks(first: $n) { nodes { transactions(first: $n) { nodes { hash } } } } }`,
+			variables: map[string]interface{}{"n": float64(1000)},
+			err:       "exceeds the maximum complexity",
+		},
+		{
+			name:      "SmallVariable",
+			query:     `query($n: Int) { blocks(first: $n) { nodes { transactions(first: $n) { nodes { hash } } } } }`,
+			variables: map[string]interface{}{"n": float64(10)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			require.NoError(t, err)
+
+			result := graphql.ValidateDocument(&schema, doc, []graphql.ValidationRuleFn{queryLimitsRule(tt.variables)})
+			if tt.err == "" {
+				require.True(t, result.IsValid, "%v", result.Errors)
+				return
+			}
+			require.False(t, result.IsValid)
+			require.Contains(t, result.Errors[0].Message, tt.err)
+		})
+	}
+}
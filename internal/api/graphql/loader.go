@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/manifest-network/yaci/internal/api"
+)
+
+// batchLoader loads values by key in batches. The resolvers of the fields at a level of the query request their keys
+// and return a thunk; the executor resolves the thunks once the level is walked, and the first thunk loads all the
+// requested keys with a single query. The loaded values are cached for the request.
+type batchLoader[K comparable, V any] struct {
+	mu      sync.Mutex
+	load    func(ctx context.Context, keys []K) (map[K]V, error)
+	pending []K
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newBatchLoader[K comparable, V any](load func(ctx context.Context, keys []K) (map[K]V, error)) *batchLoader[K, V] {
+	return &batchLoader[K, V]{load: load, values: map[K]V{}, errs: map[K]error{}}
+}
+
+// Load requests a key and returns the thunk of its value, the zero value if it does not exist
+func (l *batchLoader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	if _, loaded := l.values[key]; !loaded && l.errs[key] == nil {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if keys := l.pending; len(keys) > 0 {
+			l.pending = nil
+			values, err := l.load(ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.values[k] = values[k]
+			}
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// loaders are the batch loaders of a request
+type loaders struct {
+	blocks       *batchLoader[uint64, *api.Block]
+	transactions *batchLoader[string, *api.Transaction]
+	messages     *batchLoader[string, []api.Message]
+}
+
+func newLoaders(store *api.Store) *loaders {
+	return &loaders{
+		blocks: newBatchLoader(func(ctx context.Context, heights []uint64) (map[uint64]*api.Block, error) {
+			blocks, err := store.GetBlocks(ctx, heights)
+			if err != nil {
+				return nil, err
+			}
+			byHeight := make(map[uint64]*api.Block, len(blocks))
+			for i := range blocks {
+				byHeight[blocks[i].Height] = &blocks[i]
+			}
+			return byHeight, nil
+		}),
+		transactions: newBatchLoader(func(ctx context.Context, hashes []string) (map[string]*api.Transaction, error) {
+			txs, err := store.GetTransactions(ctx, hashes)
+			if err != nil {
+				return nil, err
+			}
+			byHash := make(map[string]*api.Transaction, len(txs))
+			for i := range txs {
+				byHash[txs[i].Hash] = &txs[i]
+			}
+			return byHash, nil
+		}),
+		messages: newBatchLoader(func(ctx context.Context, hashes []string) (map[string][]api.Message, error) {
+			messages, err := store.ListTransactionsMessages(ctx, hashes)
+			if err != nil {
+				return nil, err
+			}
+			byHash := make(map[string][]api.Message, len(hashes))
+			for _, m := range messages {
+				byHash[m.TxHash] = append(byHash[m.TxHash], m)
+			}
+			return byHash, nil
+		}),
+	}
+}
+
+// loadersKey is the context key of the loaders of a request
+type loadersKey struct{}
+
+// withLoaders returns a context holding the loaders of a request, created on first use
+func withLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loadersHolder{})
+}
+
+type loadersHolder struct {
+	once    sync.Once
+	loaders *loaders
+}
+
+// loaders returns the loaders of the request, or loaders that are not shared if the context holds none
+func (r *resolver) loaders(ctx context.Context) *loaders {
+	holder, ok := ctx.Value(loadersKey{}).(*loadersHolder)
+	if !ok {
+		return newLoaders(r.store)
+	}
+	holder.once.Do(func() {
+		holder.loaders = newLoaders(r.store)
+	})
+	return holder.loaders
+}
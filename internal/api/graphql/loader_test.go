@@ -0,0 +1,54 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchLoader(t *testing.T) {
+	ctx := context.Background()
+	var batches [][]int
+	loader := newBatchLoader(func(_ context.Context, keys []int) (map[int]string, error) {
+		batches = append(batches, keys)
+		if keys[0] < 0 {
+			return nil, errors.New("negative key")
+		}
+		values := map[int]string{}
+		for _, k := range keys {
+			if k != 3 {
+				values[k] = string(rune('a' + k))
+			}
+		}
+		return values, nil
+	})
+
+	// The keys requested before the first thunk is called are loaded together
+	first, second, missing := loader.Load(ctx, 0), loader.Load(ctx, 1), loader.Load(ctx, 3)
+	v, err := second()
+	require.NoError(t, err)
+	require.Equal(t, "b", v)
+	v, err = first()
+	require.NoError(t, err)
+	require.Equal(t, "a", v)
+	v, err = missing()
+	require.NoError(t, err)
+	require.Empty(t, v)
+	require.Equal(t, [][]int{{0, 1, 3}}, batches)
+
+	// The loaded keys are cached
+	v, err = loader.Load(ctx, 1)()
+	require.NoError(t, err)
+	require.Equal(t, "b", v)
+	require.Len(t, batches, 1)
+
+	// The errors are returned to every thunk of the batch
+	failed, other := loader.Load(ctx, -1), loader.Load(ctx, 2)
+	_, err = failed()
+	require.ErrorContains(t, err, "negative key")
+	_, err = other()
+	require.ErrorContains(t, err, "negative key")
+	require.Len(t, batches, 2)
+}
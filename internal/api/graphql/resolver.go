@@ -0,0 +1,332 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/manifest-network/yaci/internal/api"
+)
+
+type resolver struct {
+	store    *api.Store
+	notifier api.BlockNotifier
+}
+
+// address is the source of the Address type
+type address struct {
+	Address string `json:"address"`
+}
+
+func (r *resolver) block(p graphql.ResolveParams) (interface{}, error) {
+	height, _ := p.Args["height"].(int)
+	if height < 0 {
+		return nil, errors.New("height must not be negative")
+	}
+	return nilIfAbsent(r.store.GetBlock(p.Context, uint64(height)))
+}
+
+func (r *resolver) blocks(p graphql.ResolveParams) (interface{}, error) {
+	limit, cursor, err := pageParams(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter api.BlockFilter
+	if from, ok := p.Args["fromHeight"].(int); ok && from > 0 {
+		filter.FromHeight = uint64(from)
+	}
+	if to, ok := p.Args["toHeight"].(int); ok && to > 0 {
+		filter.ToHeight = uint64(to)
+	}
+	filter.Ascending, _ = p.Args["ascending"].(bool)
+
+	blocks, err := r.store.ListBlocks(p.Context, filter, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection(api.NewPage(blocks, limit, api.BlockCursor)), nil
+}
+
+// blockData loads the raw data of blocks listed without it
+func (r *resolver) blockData(p graphql.ResolveParams) (interface{}, error) {
+	b, err := asBlock(p.Source)
+	if err != nil {
+		return nil, err
+	}
+	if b.Data != nil {
+		return b.Data, nil
+	}
+
+	load := r.loaders(p.Context).blocks.Load(p.Context, b.Height)
+	return func() (interface{}, error) {
+		full, err := load()
+		if err != nil || full == nil {
+			return nil, err
+		}
+		return full.Data, nil
+	}, nil
+}
+
+func (r *resolver) blockTransactions(p graphql.ResolveParams) (interface{}, error) {
+	b, err := asBlock(p.Source)
+	if err != nil {
+		return nil, err
+	}
+	return r.listTransactions(p, api.TransactionFilter{Height: int64(b.Height)})
+}
+
+func (r *resolver) transaction(p graphql.ResolveParams) (interface{}, error) {
+	hash, _ := p.Args["hash"].(string)
+	return nilIfAbsent(r.store.GetTransaction(p.Context, hash))
+}
+
+func (r *resolver) transactions(p graphql.ResolveParams) (interface{}, error) {
+	var filter api.TransactionFilter
+	if height, ok := p.Args["height"].(int); ok {
+		filter.Height = int64(height)
+	}
+	return r.listTransactions(p, filter)
+}
+
+func (r *resolver) listTransactions(p graphql.ResolveParams, filter api.TransactionFilter) (interface{}, error) {
+	limit, cursor, err := pageParams(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	if success, ok := p.Args["success"].(bool); ok {
+		filter.Success = &success
+	}
+
+	txs, err := r.store.ListTransactions(p.Context, filter, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection(api.NewPage(txs, limit, api.TransactionCursor)), nil
+}
+
+// transactionData loads the raw data of transactions listed without it
+func (r *resolver) transactionData(p graphql.ResolveParams) (interface{}, error) {
+	tx, err := asTransaction(p.Source)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Data != nil {
+		return tx.Data, nil
+	}
+
+	load := r.loaders(p.Context).transactions.Load(p.Context, tx.Hash)
+	return func() (interface{}, error) {
+		full, err := load()
+		if err != nil || full == nil {
+			return nil, err
+		}
+		return full.Data, nil
+	}, nil
+}
+
+func (r *resolver) transactionBlock(p graphql.ResolveParams) (interface{}, error) {
+	tx, err := asTransaction(p.Source)
+	if err != nil {
+		return nil, err
+	}
+	load := r.loaders(p.Context).blocks.Load(p.Context, uint64(tx.Height))
+	return func() (interface{}, error) {
+		return nilIfAbsent(load())
+	}, nil
+}
+
+func (r *resolver) transactionMessages(p graphql.ResolveParams) (interface{}, error) {
+	tx, err := asTransaction(p.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	messageType, _ := p.Args["type"].(string)
+	load := r.loaders(p.Context).messages.Load(p.Context, tx.Hash)
+	return func() (interface{}, error) {
+		messages, err := load()
+		if err != nil || messageType == "" {
+			return messages, err
+		}
+
+		var filtered []api.Message
+		for _, m := range messages {
+			if m.Type != nil && *m.Type == messageType {
+				filtered = append(filtered, m)
+			}
+		}
+		return filtered, nil
+	}, nil
+}
+
+func (r *resolver) transactionEvents(p graphql.ResolveParams) (interface{}, error) {
+	tx, err := asTransaction(p.Source)
+	if err != nil {
+		return nil, err
+	}
+	return r.listEvents(p, api.EventFilter{TxHash: tx.Hash, TxHeight: tx.Height})
+}
+
+// parentTransaction resolves the transaction of a message or an event
+func (r *resolver) parentTransaction(p graphql.ResolveParams) (interface{}, error) {
+	var hash string
+	switch v := p.Source.(type) {
+	case api.Message:
+		hash = v.TxHash
+	case api.Event:
+		hash = v.TxHash
+	default:
+		return nil, fmt.Errorf("unexpected source type %T", p.Source)
+	}
+	load := r.loaders(p.Context).transactions.Load(p.Context, hash)
+	return func() (interface{}, error) {
+		return nilIfAbsent(load())
+	}, nil
+}
+
+func (r *resolver) messageSender(p graphql.ResolveParams) (interface{}, error) {
+	m, ok := p.Source.(api.Message)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source type %T", p.Source)
+	}
+	if m.Sender == nil {
+		return nil, nil
+	}
+	return address{Address: *m.Sender}, nil
+}
+
+// messageEvents resolves the events emitted by a top-level message
+func (r *resolver) messageEvents(p graphql.ResolveParams) (interface{}, error) {
+	m, ok := p.Source.(api.Message)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source type %T", p.Source)
+	}
+
+	return r.listEvents(p, api.EventFilter{TxHash: m.TxHash, MsgIndex: &m.Index})
+}
+
+// listEvents lists the events of a transaction or a message in order
+func (r *resolver) listEvents(p graphql.ResolveParams, filter api.EventFilter) (interface{}, error) {
+	limit, cursor, err := pageParams(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	filter.Type, _ = p.Args["type"].(string)
+	filter.Ascending = true
+
+	events, err := r.store.ListEvents(p.Context, filter, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection(api.NewPage(events, limit, api.EventCursor)), nil
+}
+
+func (r *resolver) address(p graphql.ResolveParams) (interface{}, error) {
+	addr, _ := p.Args["address"].(string)
+	return address{Address: addr}, nil
+}
+
+func (r *resolver) addressMessages(p graphql.ResolveParams) (interface{}, error) {
+	a, ok := p.Source.(address)
+	if !ok {
+		return nil, fmt.Errorf("unexpected source type %T", p.Source)
+	}
+
+	limit, cursor, err := pageParams(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	messageType, _ := p.Args["type"].(string)
+
+	addressMessages, err := r.store.ListAddressMessages(p.Context, a.Address, messageType, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	page := api.NewPage(addressMessages, limit, api.AddressMessageCursor)
+	messages := make([]api.Message, len(page.Items))
+	for i, m := range page.Items {
+		messages[i] = m.Message
+	}
+	return newConnection(api.Page[api.Message]{Items: messages, NextCursor: page.NextCursor}), nil
+}
+
+func (r *resolver) events(p graphql.ResolveParams) (interface{}, error) {
+	limit, cursor, err := pageParams(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	var filter api.EventFilter
+	filter.Type, _ = p.Args["type"].(string)
+	filter.Key, _ = p.Args["key"].(string)
+	filter.Value, _ = p.Args["value"].(string)
+	if filter.Type == "" && filter.Key == "" {
+		return nil, errors.New("missing type or key filter")
+	}
+	if filter.Value != "" && filter.Key == "" {
+		return nil, errors.New("value filter requires a key filter")
+	}
+
+	events, err := r.store.ListEvents(p.Context, filter, limit, cursor)
+	if err != nil {
+		return nil, err
+	}
+	return newConnection(api.NewPage(events, limit, api.EventCursor)), nil
+}
+
+// subscribeNewBlocks streams the newly indexed blocks until the subscription context is done
+func (r *resolver) subscribeNewBlocks(p graphql.ResolveParams) (interface{}, error) {
+	blocks := make(chan interface{})
+	go func() {
+		defer close(blocks)
+		for height := range r.notifier.SubscribeBlocks(p.Context) {
+			block, err := r.store.GetBlock(p.Context, height)
+			if err != nil {
+				slog.Warn("Failed to get new block", "height", height, "error", err)
+				continue
+			}
+			if block == nil {
+				continue
+			}
+
+			select {
+			case blocks <- block:
+			case <-p.Context.Done():
+				return
+			}
+		}
+	}()
+	return blocks, nil
+}
+
+func asBlock(source interface{}) (*api.Block, error) {
+	switch v := source.(type) {
+	case *api.Block:
+		return v, nil
+	case api.Block:
+		return &v, nil
+	}
+	return nil, fmt.Errorf("unexpected source type %T", source)
+}
+
+func asTransaction(source interface{}) (*api.Transaction, error) {
+	switch v := source.(type) {
+	case *api.Transaction:
+		return v, nil
+	case api.Transaction:
+		return &v, nil
+	}
+	return nil, fmt.Errorf("unexpected source type %T", source)
+}
+
+// nilIfAbsent converts a nil pointer result to an untyped nil, so GraphQL resolves it to null
+func nilIfAbsent[T any](v *T, err error) (interface{}, error) {
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return v, nil
+}
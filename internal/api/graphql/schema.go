@@ -0,0 +1,270 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/manifest-network/yaci/internal/api"
+)
+
+// jsonScalar serializes raw JSON values, e.g., transaction fees and message metadata
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Arbitrary JSON value",
+	Serialize: func(value interface{}) interface{} {
+		raw, ok := value.(json.RawMessage)
+		if !ok || raw == nil {
+			return nil
+		}
+		var v interface{}
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return nil
+		}
+		return v
+	},
+	ParseValue: func(value interface{}) interface{} {
+		return value
+	},
+	ParseLiteral: func(valueAST ast.Value) interface{} {
+		return nil
+	},
+})
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"endCursor":   &graphql.Field{Type: graphql.String},
+		"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+	},
+})
+
+// connection is the GraphQL representation of a page
+type connection struct {
+	Nodes    interface{} `json:"nodes"`
+	PageInfo pageInfo    `json:"pageInfo"`
+}
+
+type pageInfo struct {
+	EndCursor   *string `json:"endCursor"`
+	HasNextPage bool    `json:"hasNextPage"`
+}
+
+func newConnection[T any](page api.Page[T]) connection {
+	c := connection{Nodes: page.Items}
+	if page.NextCursor != "" {
+		c.PageInfo = pageInfo{EndCursor: &page.NextCursor, HasNextPage: true}
+	}
+	return c
+}
+
+func connectionType(name string, nodeType graphql.Output) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name + "Connection",
+		Fields: graphql.Fields{
+			"nodes":    &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(nodeType)))},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType)},
+		},
+	})
+}
+
+// pageArgs are the arguments of the paginated fields
+var pageArgs = graphql.FieldConfigArgument{
+	"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: api.DefaultPageSize},
+	"after": &graphql.ArgumentConfig{Type: graphql.String},
+}
+
+func withPageArgs(args graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	for k, v := range pageArgs {
+		args[k] = v
+	}
+	return args
+}
+
+// pageParams parses the `first` and `after` arguments
+func pageParams(args map[string]interface{}) (int, *api.Cursor, error) {
+	first, _ := args["first"].(int)
+	if first < 1 {
+		return 0, nil, errors.New("first must be positive")
+	}
+	after, _ := args["after"].(string)
+	cursor, err := api.DecodeCursor(after)
+	if err != nil {
+		return 0, nil, err
+	}
+	return min(first, api.MaxPageSize), cursor, nil
+}
+
+// NewSchema builds the GraphQL schema over the store.
+// New blocks are streamed from the notifier to the `newBlocks` subscription.
+func NewSchema(store *api.Store, notifier api.BlockNotifier) (graphql.Schema, error) {
+	r := &resolver{store: store, notifier: notifier}
+
+	blockType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Block",
+		Fields: graphql.Fields{
+			"height":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"hash":    &graphql.Field{Type: graphql.String},
+			"time":    &graphql.Field{Type: graphql.String},
+			"txCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"data":    &graphql.Field{Type: jsonScalar, Resolve: r.blockData},
+		},
+	})
+	transactionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transaction",
+		Fields: graphql.Fields{
+			"hash":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"height":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"timestamp":   &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"fee":         &graphql.Field{Type: jsonScalar},
+			"memo":        &graphql.Field{Type: graphql.String},
+			"error":       &graphql.Field{Type: graphql.String},
+			"proposalIds": &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"gasWanted":   &graphql.Field{Type: graphql.Int},
+			"gasUsed":     &graphql.Field{Type: graphql.Int},
+			"feePayer":    &graphql.Field{Type: graphql.String},
+			"feeGranter":  &graphql.Field{Type: graphql.String},
+			"data":        &graphql.Field{Type: jsonScalar, Resolve: r.transactionData},
+		},
+	})
+	messageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Message",
+		Fields: graphql.Fields{
+			"txHash":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"index":       &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"parentIndex": &graphql.Field{Type: graphql.Int},
+			"path":        &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.Int))},
+			"type":        &graphql.Field{Type: graphql.String},
+			"sender":      &graphql.Field{Type: graphql.String},
+			"mentions":    &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+			"metadata":    &graphql.Field{Type: jsonScalar},
+		},
+	})
+	attributeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "EventAttribute",
+		Fields: graphql.Fields{
+			"key":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"value": &graphql.Field{Type: graphql.String},
+		},
+	})
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Event",
+		Fields: graphql.Fields{
+			"txHash":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"height":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"index":      &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"msgIndex":   &graphql.Field{Type: graphql.Int},
+			"type":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"attributes": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(attributeType)))},
+		},
+	})
+	addressType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Address",
+		Fields: graphql.Fields{
+			"address": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	blockConnectionType := connectionType("Block", blockType)
+	transactionConnectionType := connectionType("Transaction", transactionType)
+	messageConnectionType := connectionType("Message", messageType)
+	eventConnectionType := connectionType("Event", eventType)
+
+	// Relationships, added once all the types exist
+	blockType.AddFieldConfig("transactions", &graphql.Field{
+		Type:    transactionConnectionType,
+		Args:    withPageArgs(graphql.FieldConfigArgument{"success": &graphql.ArgumentConfig{Type: graphql.Boolean}}),
+		Resolve: r.blockTransactions,
+	})
+	transactionType.AddFieldConfig("block", &graphql.Field{Type: blockType, Resolve: r.transactionBlock})
+	transactionType.AddFieldConfig("messages", &graphql.Field{
+		Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(messageType))),
+		Args:    graphql.FieldConfigArgument{"type": &graphql.ArgumentConfig{Type: graphql.String}},
+		Resolve: r.transactionMessages,
+	})
+	transactionType.AddFieldConfig("events", &graphql.Field{
+		Type:    eventConnectionType,
+		Args:    withPageArgs(graphql.FieldConfigArgument{"type": &graphql.ArgumentConfig{Type: graphql.String}}),
+		Resolve: r.transactionEvents,
+	})
+	messageType.AddFieldConfig("transaction", &graphql.Field{Type: transactionType, Resolve: r.parentTransaction})
+	messageType.AddFieldConfig("senderAddress", &graphql.Field{Type: addressType, Resolve: r.messageSender})
+	messageType.AddFieldConfig("events", &graphql.Field{
+		Type:    eventConnectionType,
+		Args:    withPageArgs(graphql.FieldConfigArgument{"type": &graphql.ArgumentConfig{Type: graphql.String}}),
+		Resolve: r.messageEvents,
+	})
+	eventType.AddFieldConfig("transaction", &graphql.Field{Type: transactionType, Resolve: r.parentTransaction})
+	addressType.AddFieldConfig("messages", &graphql.Field{
+		Type:    messageConnectionType,
+		Args:    withPageArgs(graphql.FieldConfigArgument{"type": &graphql.ArgumentConfig{Type: graphql.String}}),
+		Resolve: r.addressMessages,
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"block": &graphql.Field{
+				Type:    blockType,
+				Args:    graphql.FieldConfigArgument{"height": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}},
+				Resolve: r.block,
+			},
+			"blocks": &graphql.Field{
+				Type: blockConnectionType,
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"fromHeight": &graphql.ArgumentConfig{Type: graphql.Int},
+					"toHeight":   &graphql.ArgumentConfig{Type: graphql.Int},
+					"ascending":  &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				}),
+				Resolve: r.blocks,
+			},
+			"transaction": &graphql.Field{
+				Type:    transactionType,
+				Args:    graphql.FieldConfigArgument{"hash": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: r.transaction,
+			},
+			"transactions": &graphql.Field{
+				Type: transactionConnectionType,
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"height":  &graphql.ArgumentConfig{Type: graphql.Int},
+					"success": &graphql.ArgumentConfig{Type: graphql.Boolean},
+				}),
+				Resolve: r.transactions,
+			},
+			"address": &graphql.Field{
+				Type:    addressType,
+				Args:    graphql.FieldConfigArgument{"address": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: r.address,
+			},
+			"events": &graphql.Field{
+				Type: eventConnectionType,
+				Args: withPageArgs(graphql.FieldConfigArgument{
+					"type":  &graphql.ArgumentConfig{Type: graphql.String},
+					"key":   &graphql.ArgumentConfig{Type: graphql.String},
+					"value": &graphql.ArgumentConfig{Type: graphql.String},
+				}),
+				Resolve: r.events,
+			},
+		},
+	})
+
+	subscriptionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"newBlocks": &graphql.Field{
+				Type:      graphql.NewNonNull(blockType),
+				Subscribe: r.subscribeNewBlocks,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        queryType,
+		Subscription: subscriptionType,
+	})
+}
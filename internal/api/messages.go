@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+)
+
+// handleListAddressMessages lists the messages relevant to an address, newest first.
+// The messages can be restricted to a message type with the `type` query parameter.
+func (s *Server) handleListAddressMessages(w http.ResponseWriter, r *http.Request) {
+	limit, cursor, err := pageParams(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := s.store.ListAddressMessages(r.Context(), r.PathValue("address"), r.URL.Query().Get("type"), limit, cursor)
+	if err != nil {
+		writeQueryError(w, "failed to list address messages", err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, NewPage(messages, limit, AddressMessageCursor))
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// subscriberBufferSize is the number of notifications buffered for each subscriber
+const subscriberBufferSize = 64
+
+// listenRetryDelay is the delay before listening again after the listening connection failed
+const listenRetryDelay = 5 * time.Second
+
+// BlockNotifier notifies subscribers of newly indexed blocks
+type BlockNotifier interface {
+	// SubscribeBlocks streams the heights of the blocks indexed after the call, until the context is done
+	SubscribeBlocks(ctx context.Context) <-chan uint64
+}
+
+// Listener listens to the block notifications sent by the indexer and fans them out to the subscribers.
+// A single PostgreSQL connection is used, whatever the number of subscribers.
+// When the chain ID is set, the notifications of the other chains are ignored.
+type Listener struct {
+	pool        *pgxpool.Pool
+	channel     string
+	chainID     string
+	mu          sync.Mutex
+	subscribers map[chan models.BlockNotification]struct{}
+}
+
+func NewListener(pool *pgxpool.Pool, schema, chainID string) *Listener {
+	return &Listener{
+		pool:        pool,
+		channel:     models.BlockNotificationChannel(schema),
+		chainID:     chainID,
+		subscribers: make(map[chan models.BlockNotification]struct{}),
+	}
+}
+
+// Run listens to the block notifications until the context is done, reconnecting on failures
+func (l *Listener) Run(ctx context.Context) {
+	for {
+		if err := l.listen(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Failed to listen to block notifications", "error", err, "retryIn", listenRetryDelay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection stays in LISTEN mode, so it is taken out of the pool
+	pgConn := conn.Hijack()
+	defer pgConn.Close(context.Background())
+
+	if _, err := pgConn.Exec(ctx, "LISTEN "+l.channel); err != nil {
+		return err
+	}
+	slog.Info("Listening to block notifications", "channel", l.channel)
+
+	for {
+		n, err := pgConn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var notification models.BlockNotification
+		if err := json.Unmarshal([]byte(n.Payload), &notification); err != nil {
+			slog.Warn("Failed to parse block notification", "payload", n.Payload, "error", err)
+			continue
+		}
+		if l.chainID != "" && notification.ChainID != l.chainID {
+			continue
+		}
+		l.broadcast(notification)
+	}
+}
+
+// broadcast sends the notification to every subscriber.
+// Subscribers that are not keeping up are closed rather than blocking the others.
+func (l *Listener) broadcast(notification models.BlockNotification) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- notification:
+		default:
+			slog.Warn("Dropping slow block notification subscriber", "height", notification.Height)
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe streams the block notifications received after the call.
+// The channel is closed when the context is done, or when the subscriber is not keeping up.
+func (l *Listener) Subscribe(ctx context.Context) <-chan models.BlockNotification {
+	ch := make(chan models.BlockNotification, subscriberBufferSize)
+
+	l.mu.Lock()
+	l.subscribers[ch] = struct{}{}
+	l.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.subscribers[ch]; ok {
+			delete(l.subscribers, ch)
+			close(ch)
+		}
+	}()
+
+	return ch
+}
+
+func (l *Listener) SubscribeBlocks(ctx context.Context) <-chan uint64 {
+	heights := make(chan uint64)
+	go func() {
+		defer close(heights)
+		for notification := range l.Subscribe(ctx) {
+			select {
+			case heights <- notification.Height:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return heights
+}
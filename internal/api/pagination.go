@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// Cursor is the position of the last item of a page.
+// Only the fields relevant to the paginated resource are set.
+type Cursor struct {
+	Height       uint64 `json:"h,omitempty"`
+	ID           string `json:"i,omitempty"`
+	MessageIndex int64  `json:"m,omitempty"`
+	EventIndex   int64  `json:"e,omitempty"`
+}
+
+// Page is the envelope of paginated responses
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// EncodeCursor encodes a cursor into an opaque URL-safe string
+func EncodeCursor(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decodes a cursor encoded by EncodeCursor.
+// An empty string decodes to a nil cursor, i.e., the first page.
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return &c, nil
+}
+
+// pageParams parses the `limit` and `cursor` query parameters
+func pageParams(r *http.Request) (int, *Cursor, error) {
+	limit := DefaultPageSize
+	if s := r.URL.Query().Get("limit"); s != "" {
+		l, err := strconv.Atoi(s)
+		if err != nil || l < 1 {
+			return 0, nil, fmt.Errorf("invalid limit: %s", s)
+		}
+		limit = min(l, MaxPageSize)
+	}
+
+	cursor, err := DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return limit, cursor, nil
+}
+
+// NewPage builds a page from up to limit+1 items.
+// The extra item, if any, is dropped and signals that a next page exists.
+func NewPage[T any](items []T, limit int, cursorOf func(T) Cursor) Page[T] {
+	page := Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = EncodeCursor(cursorOf(page.Items[limit-1]))
+	}
+	return page
+}
+
+// BlockCursor returns the cursor of a block in a block list
+func BlockCursor(b Block) Cursor {
+	return Cursor{Height: b.Height}
+}
+
+// TransactionCursor returns the cursor of a transaction in a transaction list
+func TransactionCursor(tx Transaction) Cursor {
+	return Cursor{Height: uint64(tx.Height), ID: tx.Hash}
+}
+
+// AddressMessageCursor returns the cursor of a message in an address message list
+func AddressMessageCursor(m AddressMessage) Cursor {
+	return Cursor{Height: uint64(m.Height), ID: m.TxHash, MessageIndex: m.Index}
+}
+
+// EventCursor returns the cursor of an event in an event list
+func EventCursor(e Event) Cursor {
+	return Cursor{Height: uint64(e.Height), ID: e.TxHash, EventIndex: e.Index}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Server serves a read-only HTTP/JSON API over the data indexed in PostgreSQL
+type Server struct {
+	store    *Store
+	listener *Listener
+	mux      *http.ServeMux
+}
+
+// NewServer returns a server over the data indexed in a schema, restricted to a chain when the chain ID is set.
+// The pool must resolve the unqualified table names in that schema.
+func NewServer(pool *pgxpool.Pool, schema, chainID string) *Server {
+	s := &Server{
+		store:    NewStore(pool, chainID),
+		listener: NewListener(pool, schema, chainID),
+		mux:      http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /v1/blocks", s.handleListBlocks)
+	s.mux.HandleFunc("GET /v1/blocks/{height}", s.handleGetBlock)
+	s.mux.HandleFunc("GET /v1/txs/{hash}", s.handleGetTransaction)
+	s.mux.HandleFunc("GET /v1/addresses/{address}/messages", s.handleListAddressMessages)
+	s.mux.HandleFunc("GET /v1/addresses/{address}/balances", s.handleGetAddressBalances)
+	s.mux.HandleFunc("GET /v1/events", s.handleListEvents)
+	s.mux.HandleFunc("GET /v1/stream/blocks", s.handleStreamBlocks)
+	s.mux.HandleFunc("GET /v1/stream/txs", s.handleStreamTransactions)
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+
+	return s
+}
+
+// Store returns the store used by the server
+func (s *Server) Store() *Store {
+	return s.store
+}
+
+// Listener returns the listener of the block notifications used by the server
+func (s *Server) Listener() *Listener {
+	return s.listener
+}
+
+// Handle registers an additional handler on the server, e.g., an optional endpoint
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the API on addr until the context is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		// Cancel the requests, including the streams, when shutting down
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	go s.listener.Run(ctx)
+
+	errChan := make(chan error, 1)
+	go func() {
+		slog.Info("Starting API server", "address", addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			return fmt.Errorf("failed to start API server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		slog.Info("Shutting down API server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	}
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if err := s.store.Ping(r.Context()); err != nil {
+		writeError(w, http.StatusServiceUnavailable, "database unavailable")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// writeQueryError logs a database error and hides its details from the client
+func writeQueryError(w http.ResponseWriter, msg string, err error) {
+	slog.Error(msg, "error", err)
+	writeError(w, http.StatusInternalServerError, msg)
+}
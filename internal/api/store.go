@@ -0,0 +1,495 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+// Store queries the chain data indexed in PostgreSQL.
+// The List methods return up to limit+1 items, see NewPage.
+// When the chain ID is set, only the data of that chain is returned; it is required when the database holds several chains.
+type Store struct {
+	pool    *pgxpool.Pool
+	chainID string
+	fetcher RawFetcher
+}
+
+// RawFetcher fetches the raw JSON of the blocks and transactions that is not stored, e.g., from a node
+type RawFetcher interface {
+	FetchBlock(ctx context.Context, height uint64) ([]byte, error)
+	FetchTransaction(ctx context.Context, hash string) ([]byte, error)
+}
+
+func NewStore(pool *pgxpool.Pool, chainID string) *Store {
+	return &Store{pool: pool, chainID: chainID}
+}
+
+// SetRawFetcher sets the fetcher of the raw JSON that was dropped by the extraction. Without a fetcher,
+// the blocks and transactions are returned without their raw JSON.
+func (s *Store) SetRawFetcher(fetcher RawFetcher) {
+	s.fetcher = fetcher
+}
+
+// BlockFilter filters the blocks by height range
+type BlockFilter struct {
+	FromHeight uint64
+	ToHeight   uint64 // No upper bound when zero
+	Ascending  bool
+}
+
+// TransactionFilter filters the transactions. Zero values are ignored.
+// The address, message and event filters are meant to be combined with a height.
+type TransactionFilter struct {
+	Height      int64
+	Success     *bool
+	Address     string // Sender or mention of a message
+	MessageType string
+	EventType   string
+	EventKey    string
+	EventValue  string // Requires EventKey
+}
+
+// EventFilter filters the events. Zero values are ignored.
+// The transaction height is meant to be combined with the transaction hash; it is looked up when only the hash is set.
+type EventFilter struct {
+	TxHash   string
+	TxHeight int64
+	MsgIndex *int64
+	Type     string
+	Key      string
+	Value    string
+	// Ascending lists the events oldest first, e.g., the events of a transaction in order
+	Ascending bool
+}
+
+// blockColumns are the columns of the block summaries, read from the raw JSON of the blocks written before they were stored
+const blockColumns = `
+  id,
+  COALESCE(hash, data->'blockId'->>'hash'),
+  COALESCE(time, data->'block'->'header'->>'time'),
+  COALESCE(tx_count, jsonb_array_length(data->'block'->'data'->'txs'), 0)`
+
+const transactionColumns = `t.id, t.height, t.timestamp, t.fee, t.memo, t.error, t.proposal_ids, t.gas_wanted, t.gas_used, t.fee_payer, t.fee_granter`
+
+const messageColumns = `id, message_index, parent_index, path, type, sender, mentions, metadata`
+
+func (s *Store) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// ChainIDs returns the IDs of the indexed chains
+func (s *Store) ChainIDs(ctx context.Context) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `SELECT chain_id FROM chains ORDER BY chain_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chains: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// chainFilter appends the filter restricting the column to the chain of the store, if any
+func (s *Store) chainFilter(filters []string, args []any, column string) ([]string, []any) {
+	if s.chainID == "" {
+		return filters, args
+	}
+	args = append(args, s.chainID)
+	return append(filters, fmt.Sprintf("%s = $%d", column, len(args))), args
+}
+
+// GetBlock returns the block at the given height with its raw data, or nil if it does not exist
+func (s *Store) GetBlock(ctx context.Context, height uint64) (*Block, error) {
+	blocks, err := s.GetBlocks(ctx, []uint64{height})
+	if err != nil || len(blocks) == 0 {
+		return nil, err
+	}
+	return &blocks[0], nil
+}
+
+// GetBlocks returns the blocks at the given heights with their raw data, in no particular order.
+// The blocks that do not exist are omitted.
+func (s *Store) GetBlocks(ctx context.Context, heights []uint64) ([]Block, error) {
+	ids := make([]int64, len(heights))
+	for i, height := range heights {
+		ids[i] = int64(height)
+	}
+	filters, args := s.chainFilter([]string{"id = ANY($1::bigint[])"}, []any{ids}, "chain_id")
+
+	rows, err := s.pool.Query(ctx, `SELECT `+blockColumns+`, data, data_zstd FROM blocks_raw WHERE `+strings.Join(filters, " AND "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+	blocks, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Block, error) {
+		var b Block
+		var data, compressed []byte
+		if err := row.Scan(&b.Height, &b.Hash, &b.Time, &b.TxCount, &data, &compressed); err != nil {
+			return b, err
+		}
+		b.Data, err = output.DecodeRaw(data, compressed)
+		return b, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocks: %w", err)
+	}
+
+	for i := range blocks {
+		if blocks[i].Data == nil && s.fetcher != nil {
+			if blocks[i].Data, err = s.fetcher.FetchBlock(ctx, blocks[i].Height); err != nil {
+				return nil, fmt.Errorf("failed to fetch block: %w", err)
+			}
+		}
+	}
+	return blocks, nil
+}
+
+// LatestBlockHeight returns the height of the latest indexed block, or zero if there is none
+func (s *Store) LatestBlockHeight(ctx context.Context) (uint64, error) {
+	filters, args := s.chainFilter([]string{"TRUE"}, nil, "chain_id")
+
+	var height *uint64
+	if err := s.pool.QueryRow(ctx, `SELECT MAX(id) FROM blocks_raw WHERE `+strings.Join(filters, " AND "), args...).Scan(&height); err != nil {
+		return 0, fmt.Errorf("failed to get latest block height: %w", err)
+	}
+	if height == nil {
+		return 0, nil
+	}
+	return *height, nil
+}
+
+// ListBlocks lists block summaries, newest first unless the filter is ascending
+func (s *Store) ListBlocks(ctx context.Context, filter BlockFilter, limit int, cursor *Cursor) ([]Block, error) {
+	to := filter.ToHeight
+	if to == 0 {
+		to = 1<<63 - 1
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.Ascending {
+		order, cmp = "ASC", ">"
+	}
+
+	args := []any{filter.FromHeight, to, limit + 1}
+	filters := []string{"id BETWEEN $1::bigint AND $2::bigint"}
+	if cursor != nil {
+		args = append(args, cursor.Height)
+		filters = append(filters, fmt.Sprintf("id %s $%d::bigint", cmp, len(args)))
+	}
+	filters, args = s.chainFilter(filters, args, "chain_id")
+	query := `SELECT ` + blockColumns + ` FROM blocks_raw WHERE ` + strings.Join(filters, " AND ") +
+		fmt.Sprintf(" ORDER BY id %s LIMIT $3", order)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list blocks: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Block, error) {
+		var b Block
+		err := row.Scan(&b.Height, &b.Hash, &b.Time, &b.TxCount)
+		return b, err
+	})
+}
+
+// GetTransaction returns the transaction with the given hash with its raw data, or nil if it does not exist.
+// The messages are not loaded.
+func (s *Store) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
+	txs, err := s.GetTransactions(ctx, []string{hash})
+	if err != nil || len(txs) == 0 {
+		return nil, err
+	}
+	return &txs[0], nil
+}
+
+// GetTransactions returns the transactions with the given hashes with their raw data, in no particular order.
+// The transactions that do not exist are omitted, and the messages are not loaded.
+func (s *Store) GetTransactions(ctx context.Context, hashes []string) ([]Transaction, error) {
+	ids := make([]string, len(hashes))
+	for i, hash := range hashes {
+		ids[i] = strings.ToLower(hash)
+	}
+	filters, args := s.chainFilter([]string{"t.id = ANY($1::varchar[])"}, []any{ids}, "t.chain_id")
+
+	// The raw JSON may have been pruned while the normalized transaction was kept
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+transactionColumns+`, r.data, r.data_zstd
+		FROM transactions_main t
+		LEFT JOIN transactions_raw r ON r.id = t.id AND r.height = t.height
+		WHERE `+strings.Join(filters, " AND "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	txs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (Transaction, error) {
+		var tx Transaction
+		var data, compressed []byte
+		if err := row.Scan(&tx.Hash, &tx.Height, &tx.Timestamp, &tx.Fee, &tx.Memo, &tx.Error, &tx.ProposalIDs, &tx.GasWanted, &tx.GasUsed, &tx.FeePayer, &tx.FeeGranter, &data, &compressed); err != nil {
+			return tx, err
+		}
+		tx.Data, err = output.DecodeRaw(data, compressed)
+		return tx, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	for i := range txs {
+		if txs[i].Data == nil && s.fetcher != nil {
+			if txs[i].Data, err = s.fetcher.FetchTransaction(ctx, txs[i].Hash); err != nil {
+				return nil, fmt.Errorf("failed to fetch transaction: %w", err)
+			}
+		}
+	}
+	return txs, nil
+}
+
+// ListTransactions lists transactions without their raw data, newest first
+func (s *Store) ListTransactions(ctx context.Context, filter TransactionFilter, limit int, cursor *Cursor) ([]Transaction, error) {
+	args := []any{limit + 1}
+	filters := []string{"TRUE"}
+	if filter.Height != 0 {
+		args = append(args, filter.Height)
+		filters = append(filters, fmt.Sprintf("t.height = $%d", len(args)))
+	}
+	if filter.Success != nil {
+		if *filter.Success {
+			filters = append(filters, "t.error IS NULL")
+		} else {
+			filters = append(filters, "t.error IS NOT NULL")
+		}
+	}
+	if filter.Address != "" {
+		args = append(args, filter.Address)
+		filters = append(filters, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM messages_main m WHERE m.id = t.id AND m.height = t.height AND (m.sender = $%[1]d OR $%[1]d = ANY(m.mentions)))", len(args)))
+	}
+	if filter.MessageType != "" {
+		args = append(args, filter.MessageType)
+		filters = append(filters, fmt.Sprintf("EXISTS (SELECT 1 FROM messages_main m WHERE m.id = t.id AND m.height = t.height AND m.type = $%d)", len(args)))
+	}
+	if filter.EventType != "" || filter.EventKey != "" {
+		eventFilters := []string{"e.id = t.id", "e.height = t.height"}
+		if filter.EventType != "" {
+			args = append(args, filter.EventType)
+			eventFilters = append(eventFilters, fmt.Sprintf("e.event_type = $%d", len(args)))
+		}
+		if filter.EventKey != "" {
+			args = append(args, filter.EventKey)
+			eventFilters = append(eventFilters, fmt.Sprintf("e.attr_key = $%d", len(args)))
+		}
+		if filter.EventValue != "" {
+			args = append(args, filter.EventValue)
+			eventFilters = append(eventFilters, fmt.Sprintf("e.attr_value = $%d", len(args)))
+		}
+		filters = append(filters, "EXISTS (SELECT 1 FROM events_main e WHERE "+strings.Join(eventFilters, " AND ")+")")
+	}
+	if cursor != nil {
+		args = append(args, int64(cursor.Height), cursor.ID)
+		n := len(args)
+		filters = append(filters, fmt.Sprintf("(t.height, t.id) < ($%d::bigint, $%d::varchar)", n-1, n))
+	}
+	filters, args = s.chainFilter(filters, args, "t.chain_id")
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions_main t
+		WHERE `+strings.Join(filters, " AND ")+`
+		ORDER BY t.height DESC, t.id DESC
+		LIMIT $1
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Transaction, error) {
+		var tx Transaction
+		err := row.Scan(&tx.Hash, &tx.Height, &tx.Timestamp, &tx.Fee, &tx.Memo, &tx.Error, &tx.ProposalIDs, &tx.GasWanted, &tx.GasUsed, &tx.FeePayer, &tx.FeeGranter)
+		return tx, err
+	})
+}
+
+// ListTransactionMessages lists the messages of a transaction, top level messages first
+func (s *Store) ListTransactionMessages(ctx context.Context, hash string) ([]Message, error) {
+	return s.ListTransactionsMessages(ctx, []string{hash})
+}
+
+// ListTransactionsMessages lists the messages of several transactions, by transaction and top level messages first.
+// The heights of the transactions restrict the scanned partitions.
+func (s *Store) ListTransactionsMessages(ctx context.Context, hashes []string) ([]Message, error) {
+	ids := make([]string, len(hashes))
+	for i, hash := range hashes {
+		ids[i] = strings.ToLower(hash)
+	}
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+messageColumns+`
+		FROM messages_main
+		WHERE id = ANY($1::varchar[])
+		  AND height = ANY(ARRAY(SELECT height FROM transactions_main WHERE id = ANY($1::varchar[])))
+		ORDER BY id, message_index
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transaction messages: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Message, error) {
+		var m Message
+		err := row.Scan(&m.TxHash, &m.Index, &m.ParentIndex, &m.Path, &m.Type, &m.Sender, &m.Mentions, &m.Metadata)
+		return m, err
+	})
+}
+
+// ListAddressMessages lists the messages relevant to an address, newest first.
+// Relevance is defined by the `get_messages_for_address` function.
+// The messages can optionally be restricted to a message type.
+func (s *Store) ListAddressMessages(ctx context.Context, address, messageType string, limit int, cursor *Cursor) ([]AddressMessage, error) {
+	args := []any{address, limit + 1}
+	filters := []string{"TRUE"}
+	if messageType != "" {
+		args = append(args, messageType)
+		filters = append(filters, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if cursor != nil {
+		args = append(args, int64(cursor.Height), cursor.ID, cursor.MessageIndex)
+		n := len(args)
+		filters = append(filters, fmt.Sprintf("(height, id, message_index) < ($%d::bigint, $%d::varchar, $%d::bigint)", n-2, n-1, n))
+	}
+	if s.chainID != "" {
+		args = append(args, s.chainID)
+		filters = append(filters, fmt.Sprintf("EXISTS (SELECT 1 FROM transactions_main t WHERE t.id = m.id AND t.chain_id = $%d)", len(args)))
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+messageColumns+`, fee, memo, height, timestamp, error, proposal_ids
+		FROM get_messages_for_address($1) m
+		WHERE `+strings.Join(filters, " AND ")+`
+		ORDER BY height DESC, id DESC, message_index DESC
+		LIMIT $2
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address messages: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (AddressMessage, error) {
+		var m AddressMessage
+		err := row.Scan(&m.TxHash, &m.Index, &m.ParentIndex, &m.Path, &m.Type, &m.Sender, &m.Mentions, &m.Metadata,
+			&m.Fee, &m.Memo, &m.Height, &m.Timestamp, &m.Error, &m.ProposalIDs)
+		return m, err
+	})
+}
+
+// ListEvents lists the events matching the filter, newest first unless the filter is ascending
+func (s *Store) ListEvents(ctx context.Context, filter EventFilter, limit int, cursor *Cursor) ([]Event, error) {
+	// Only the provided filters are added to the query to keep the query plans index-friendly
+	args := []any{limit + 1}
+	filters := []string{"TRUE"}
+	addFilter := func(format string, arg any) {
+		args = append(args, arg)
+		filters = append(filters, fmt.Sprintf(format, len(args)))
+	}
+	if filter.TxHash != "" {
+		addFilter("e.id = $%d", strings.ToLower(filter.TxHash))
+		// The height of the transaction restricts the scanned partitions
+		if filter.TxHeight == 0 {
+			addFilter("e.height = (SELECT height FROM transactions_main WHERE id = $%d)", strings.ToLower(filter.TxHash))
+		}
+	}
+	if filter.TxHeight != 0 {
+		addFilter("e.height = $%d::bigint", filter.TxHeight)
+	}
+	if filter.MsgIndex != nil {
+		addFilter("e.msg_index = $%d::bigint", *filter.MsgIndex)
+	}
+	if filter.Type != "" {
+		addFilter("e.event_type = $%d", filter.Type)
+	}
+	if filter.Key != "" {
+		addFilter("e.attr_key = $%d", filter.Key)
+	}
+	if filter.Value != "" {
+		// Matches the (attr_key, digest(attr_value)) index
+		addFilter("digest(COALESCE(e.attr_value, ''), 'sha256') = digest($%d::text, 'sha256')", filter.Value)
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.Ascending {
+		order, cmp = "ASC", ">"
+	}
+	if cursor != nil {
+		args = append(args, int64(cursor.Height), cursor.ID, cursor.EventIndex)
+		n := len(args)
+		filters = append(filters, fmt.Sprintf("(e.height, e.id, e.event_index) %s ($%d::bigint, $%d::varchar, $%d::bigint)", cmp, n-2, n-1, n))
+	}
+	if s.chainID != "" {
+		args = append(args, s.chainID)
+		filters = append(filters, fmt.Sprintf("EXISTS (SELECT 1 FROM transactions_main t WHERE t.id = e.id AND t.height = e.height AND t.chain_id = $%d)", len(args)))
+	}
+
+	// The page of matching events is selected first; only their attributes are aggregated
+	query := fmt.Sprintf(`
+		WITH matches AS (
+		  SELECT DISTINCT e.id, e.height, e.event_index
+		  FROM events_main e
+		  WHERE %s
+		  ORDER BY e.height %[2]s, e.id %[2]s, e.event_index %[2]s
+		  LIMIT $1
+		)
+		SELECT
+		  m.id,
+		  m.height,
+		  m.event_index,
+		  ev.msg_index,
+		  ev.event_type,
+		  jsonb_agg(jsonb_build_object('key', ev.attr_key, 'value', ev.attr_value) ORDER BY ev.attr_index)
+		FROM matches m
+		JOIN events_main ev ON ev.id = m.id AND ev.height = m.height AND ev.event_index = m.event_index
+		GROUP BY m.id, m.height, m.event_index, ev.msg_index, ev.event_type
+		ORDER BY m.height %[2]s, m.id %[2]s, m.event_index %[2]s
+	`, strings.Join(filters, " AND "), order)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Event, error) {
+		var e Event
+		var attributes []byte
+		if err := row.Scan(&e.TxHash, &e.Height, &e.Index, &e.MsgIndex, &e.Type, &attributes); err != nil {
+			return e, err
+		}
+		return e, json.Unmarshal(attributes, &e.Attributes)
+	})
+}
+
+// GetBalances returns the balances of an address at a height, reconstructed from the indexed balance changes and the
+// imported genesis balances. The balance changes emitted outside of transactions, e.g., the minted coins and the
+// staking rewards, are only indexed when the block source provides the block events, which a gRPC source does not:
+// the balances are only accurate if BalanceLedgerComplete.
+func (s *Store) GetBalances(ctx context.Context, address string, height uint64) ([]Balance, error) {
+	var chainID *string
+	if s.chainID != "" {
+		chainID = &s.chainID
+	}
+	rows, err := s.pool.Query(ctx, `SELECT denom, amount::text FROM get_balance_at($1, $2::bigint, $3)`, address, height, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (Balance, error) {
+		var b Balance
+		err := row.Scan(&b.Denom, &b.Amount)
+		return b, err
+	})
+}
+
+// BalanceLedgerComplete returns whether the balances reconstructed at a height are complete, i.e., the genesis is
+// imported, the chain is indexed from its initial height without pruning, and every block up to the height is indexed
+// with its block events. See `balance_ledger_complete`.
+func (s *Store) BalanceLedgerComplete(ctx context.Context, height uint64) (bool, error) {
+	var chainID *string
+	if s.chainID != "" {
+		chainID = &s.chainID
+	}
+	var complete bool
+	if err := s.pool.QueryRow(ctx, `SELECT balance_ledger_complete($1::bigint, $2)`, height, chainID).Scan(&complete); err != nil {
+		return false, fmt.Errorf("failed to check the balance ledger: %w", err)
+	}
+	return complete, nil
+}
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// maxStreamReplay is the maximum number of blocks replayed to a client resuming a stream
+const maxStreamReplay = 1000
+
+// streamKeepAlive is the interval at which a comment is sent on idle streams
+const streamKeepAlive = 15 * time.Second
+
+// streamBlockFunc sends the server-sent events of a block, returning false to end the stream
+type streamBlockFunc func(ctx context.Context, sse *eventStream, height uint64) bool
+
+type eventStream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// send writes a server-sent event. The block height is used as event ID so clients can resume the stream.
+func (s *eventStream) send(event string, height uint64, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\nid: %d\ndata: %s\n\n", event, height, data); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+func (s *eventStream) keepAlive() error {
+	if _, err := fmt.Fprint(s.w, ": keep-alive\n\n"); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// handleStreamBlocks streams the summary of the newly indexed blocks as `block` events
+func (s *Server) handleStreamBlocks(w http.ResponseWriter, r *http.Request) {
+	s.stream(w, r, func(ctx context.Context, sse *eventStream, height uint64) bool {
+		block, err := s.store.GetBlock(ctx, height)
+		if err != nil || block == nil {
+			return err == nil
+		}
+		block.Data = nil
+		return sse.send("block", height, block) == nil
+	})
+}
+
+// handleStreamTransactions streams the newly indexed transactions as `tx` events.
+// The transactions can be filtered by address, message type and event attribute.
+func (s *Server) handleStreamTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := TransactionFilter{
+		Address:     query.Get("address"),
+		MessageType: query.Get("message_type"),
+		EventType:   query.Get("event_type"),
+		EventKey:    query.Get("event_key"),
+		EventValue:  query.Get("event_value"),
+	}
+	if filter.EventValue != "" && filter.EventKey == "" {
+		writeError(w, http.StatusBadRequest, "event_value filter requires an event_key filter")
+		return
+	}
+
+	s.stream(w, r, func(ctx context.Context, sse *eventStream, height uint64) bool {
+		blockFilter := filter
+		blockFilter.Height = int64(height)
+		txs, err := s.store.ListTransactions(ctx, blockFilter, math.MaxInt32-1, nil)
+		if err != nil {
+			return false
+		}
+
+		slices.Reverse(txs)
+		for _, tx := range txs {
+			if sse.send("tx", height, tx) != nil {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// stream serves a server-sent events stream, calling sendBlock for each newly indexed block.
+// Clients resuming the stream with a `Last-Event-ID` header get the indexed blocks above that height first. The blocks
+// below it are still sent when they are indexed later.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, sendBlock streamBlockFunc) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	var lastHeight uint64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		var err error
+		if lastHeight, err = strconv.ParseUint(v, 10, 63); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid Last-Event-ID")
+			return
+		}
+	}
+
+	ctx := r.Context()
+	// Subscribe before replaying so that no block is missed in between
+	notifications := s.listener.Subscribe(ctx)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	sse := &eventStream{w: w, flusher: flusher}
+
+	// The blocks are not indexed in height order when extracted concurrently, so the replayed heights are tracked instead
+	// of a single height: a block filling a gap below the latest replayed block is still sent when notified
+	replayed := make(map[uint64]struct{})
+	if lastHeight > 0 {
+		latest, err := s.store.LatestBlockHeight(ctx)
+		if err != nil {
+			return
+		}
+		if latest > lastHeight+maxStreamReplay {
+			lastHeight = latest - maxStreamReplay
+		}
+		if latest > lastHeight {
+			blocks, err := s.store.ListBlocks(ctx, BlockFilter{FromHeight: lastHeight + 1, ToHeight: latest, Ascending: true}, maxStreamReplay, nil)
+			if err != nil {
+				return
+			}
+			for _, block := range blocks {
+				if !sendBlock(ctx, sse, block.Height) {
+					return
+				}
+				replayed[block.Height] = struct{}{}
+			}
+		}
+	}
+
+	streamNotifications(ctx, sse, notifications, replayed, sendBlock)
+}
+
+// streamNotifications calls sendBlock for each notified block, except the replayed ones, until the context is done or
+// the client is too slow
+func streamNotifications(ctx context.Context, sse *eventStream, notifications <-chan models.BlockNotification, replayed map[uint64]struct{}, sendBlock streamBlockFunc) {
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if sse.keepAlive() != nil {
+				return
+			}
+		case notification, ok := <-notifications:
+			if !ok {
+				// The client was too slow; it can resume the stream from the last event ID
+				return
+			}
+			if _, ok := replayed[notification.Height]; ok {
+				// The block was indexed after subscribing, and already replayed
+				delete(replayed, notification.Height)
+				continue
+			}
+			if !sendBlock(ctx, sse, notification.Height) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+)
+
+func (s *Server) handleGetTransaction(w http.ResponseWriter, r *http.Request) {
+	hash := r.PathValue("hash")
+
+	tx, err := s.store.GetTransaction(r.Context(), hash)
+	if err != nil {
+		writeQueryError(w, "failed to get transaction", err)
+		return
+	}
+	if tx == nil {
+		writeError(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+
+	tx.Messages, err = s.store.ListTransactionMessages(r.Context(), hash)
+	if err != nil {
+		writeQueryError(w, "failed to get transaction messages", err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, tx)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Block is a block summary, with the raw block data when requested by height
+type Block struct {
+	Height  uint64          `json:"height"`
+	Hash    *string         `json:"hash"`
+	Time    *string         `json:"time"`
+	TxCount int             `json:"tx_count"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Transaction is a parsed transaction with its messages and raw data
+type Transaction struct {
+	Hash        string          `json:"hash"`
+	Height      int64           `json:"height"`
+	Timestamp   time.Time       `json:"timestamp"`
+	Fee         json.RawMessage `json:"fee"`
+	Memo        *string         `json:"memo"`
+	Error       *string         `json:"error"`
+	ProposalIDs []string        `json:"proposal_ids"`
+	GasWanted   *int64          `json:"gas_wanted"`
+	GasUsed     *int64          `json:"gas_used"`
+	FeePayer    *string         `json:"fee_payer"`
+	FeeGranter  *string         `json:"fee_granter"`
+	Messages    []Message       `json:"messages"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// Message is a parsed transaction message.
+// Nested messages, e.g., the messages of an authz MsgExec, have a parent message and a path in the message tree.
+type Message struct {
+	TxHash      string          `json:"tx_hash"`
+	Index       int64           `json:"message_index"`
+	ParentIndex *int64          `json:"parent_index"`
+	Path        []int32         `json:"path"`
+	Type        *string         `json:"type"`
+	Sender      *string         `json:"sender"`
+	Mentions    []string        `json:"mentions"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+// AddressMessage is a message relevant to an address, with the fields of its transaction
+type AddressMessage struct {
+	Message
+	Fee         json.RawMessage `json:"fee"`
+	Memo        *string         `json:"memo"`
+	Height      int64           `json:"height"`
+	Timestamp   time.Time       `json:"timestamp"`
+	Error       *string         `json:"error"`
+	ProposalIDs []string        `json:"proposal_ids"`
+}
+
+// Event is a transaction event with its attributes
+type Event struct {
+	TxHash     string           `json:"tx_hash"`
+	Height     int64            `json:"height"`
+	Index      int64            `json:"event_index"`
+	MsgIndex   *int64           `json:"msg_index"`
+	Type       string           `json:"type"`
+	Attributes []EventAttribute `json:"attributes"`
+}
+
+// EventAttribute is a key/value attribute of an event
+type EventAttribute struct {
+	Key   string  `json:"key"`
+	Value *string `json:"value"`
+}
+
+// Balances are the balances of an address at a height, reconstructed from the indexed balance changes
+type Balances struct {
+	Address  string    `json:"address"`
+	Height   uint64    `json:"height"`
+	Balances []Balance `json:"balances"`
+	// Complete is false when the balances may miss balance changes, see Store.BalanceLedgerComplete
+	Complete bool `json:"complete"`
+}
+
+// Balance is the balance of an address in a denomination
+type Balance struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
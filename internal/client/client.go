@@ -11,6 +11,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 var keepaliveParams = keepalive.ClientParameters{
@@ -50,6 +51,19 @@ func NewGRPCClient(ctx context.Context, address string, insecure bool, maxCallRe
 	}, nil
 }
 
+// FetchDescriptors fetches the protocol buffer descriptors of a gRPC server, e.g., to extract the blocks of a block store
+func FetchDescriptors(ctx context.Context, address string, insecure bool, maxCallRecvMsgSize int) ([]*descriptorpb.FileDescriptorProto, error) {
+	conn := dial(ctx, address, insecure, maxCallRecvMsgSize)
+	defer conn.Close()
+
+	slog.Info("Fetching protocol buffer descriptors from gRPC server... This may take a while.")
+	descriptors, err := reflection.FetchAllDescriptors(ctx, conn, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch descriptors: %w", err)
+	}
+	return descriptors, nil
+}
+
 func dial(ctx context.Context, address string, insecure bool, maxCallRecvMsgSize int) *grpc.ClientConn {
 	var opts []grpc.DialOption
 	opts = append(opts, grpc.WithKeepaliveParams(keepaliveParams))
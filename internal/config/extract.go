@@ -4,22 +4,49 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/output"
+	"github.com/manifest-network/yaci/internal/source"
 )
 
 type ExtractConfig struct {
-	MaxConcurrency       uint
-	MaxRetries           uint
-	BlockTime            uint
-	BlockStart           uint64
-	BlockStop            uint64
-	LiveMonitoring       bool
-	Insecure             bool
-	ReIndex              bool
-	MaxRecvMsgSize       int
-	EnablePrometheus     bool
-	PrometheusListenAddr string
+	MaxConcurrency        uint
+	MaxRetries            uint
+	BlockTime             uint
+	BlockStart            uint64
+	BlockStop             uint64
+	LiveMonitoring        bool
+	Insecure              bool
+	ReIndex               bool
+	MaxRecvMsgSize        int
+	EnablePrometheus      bool
+	PrometheusListenAddr  string
+	LockedTokensByAccount bool
+	LockedTokensByEndDate bool
+	TokenDenoms           []string
+	TokenMintEvents       []string
+	TokenBurnEvents       []string
+	TokenTransferEvents   []string
+	ValidatorSnapshots    time.Duration
+	WasmContractSnapshots time.Duration
+	IndexSignatures       bool
+	// GovOutcomes records the outcomes of the gov proposals from a source without the end block events, i.e., a gRPC
+	// server, by querying the proposals whose voting period ends by a block
+	GovOutcomes        bool
+	MissedBlocksWindow int64
+	GasMetricsWindow   int64
+	// Retention prunes the old blocks while they are extracted, if enabled
+	Retention output.RetentionPolicy
+	// BlockStore is the data directory of a stopped node whose blocks are extracted instead of those of a gRPC server
+	BlockStore string
+	// BlockStoreBackend is the database backend of the node
+	BlockStoreBackend source.Backend
+	// DescriptorSet is the file of the descriptors of the chain, required to decode the blocks of the block store.
+	// The descriptors of a CometBFT RPC server are queried from the node if it is not set.
+	DescriptorSet string
 }
 
 func (c ExtractConfig) Validate() error {
@@ -27,6 +54,32 @@ func (c ExtractConfig) Validate() error {
 		return fmt.Errorf("cannot set --live and --stop flags together")
 	}
 
+	if c.ValidatorSnapshots < 0 {
+		return fmt.Errorf("validator-snapshot-interval must not be negative")
+	}
+
+	if c.WasmContractSnapshots < 0 {
+		return fmt.Errorf("wasm-contract-snapshot-interval must not be negative")
+	}
+
+	if err := validateRetentionPolicy(c.Retention); err != nil {
+		return err
+	}
+
+	if c.BlockStore != "" {
+		if err := c.validateBlockStore(); err != nil {
+			return err
+		}
+	}
+
+	if c.MissedBlocksWindow <= 0 {
+		return fmt.Errorf("missed-blocks-window must be positive")
+	}
+
+	if c.GasMetricsWindow <= 0 {
+		return fmt.Errorf("gas-metrics-window must be positive")
+	}
+
 	if c.EnablePrometheus {
 		host, port, err := net.SplitHostPort(c.PrometheusListenAddr)
 		if err != nil {
@@ -44,18 +97,51 @@ func (c ExtractConfig) Validate() error {
 	return nil
 }
 
+// validateBlockStore validates the extraction from a block store, which cannot query the state of the chain
+func (c ExtractConfig) validateBlockStore() error {
+	if c.DescriptorSet == "" {
+		return fmt.Errorf("missing --descriptor-set, required with --block-store")
+	}
+	if err := c.BlockStoreBackend.Validate(); err != nil {
+		return err
+	}
+	if c.LiveMonitoring {
+		return fmt.Errorf("cannot set --live and --block-store flags together")
+	}
+	if c.IndexSignatures || c.ValidatorSnapshots > 0 || c.WasmContractSnapshots > 0 || c.EnablePrometheus {
+		return fmt.Errorf("cannot index signatures, take snapshots or enable Prometheus with --block-store, they require a running node")
+	}
+	return nil
+}
+
 func LoadExtractConfigFromCLI() ExtractConfig {
 	return ExtractConfig{
-		MaxConcurrency:       viper.GetUint("max-concurrency"),
-		MaxRetries:           viper.GetUint("max-retries"),
-		BlockTime:            viper.GetUint("block-time"),
-		BlockStart:           viper.GetUint64("start"),
-		BlockStop:            viper.GetUint64("stop"),
-		LiveMonitoring:       viper.GetBool("live"),
-		Insecure:             viper.GetBool("insecure"),
-		ReIndex:              viper.GetBool("reindex"),
-		MaxRecvMsgSize:       viper.GetInt("max-recv-msg-size"),
-		EnablePrometheus:     viper.GetBool("enable-prometheus"),
-		PrometheusListenAddr: viper.GetString("prometheus-addr"),
+		MaxConcurrency:        viper.GetUint("max-concurrency"),
+		MaxRetries:            viper.GetUint("max-retries"),
+		BlockTime:             viper.GetUint("block-time"),
+		BlockStart:            viper.GetUint64("start"),
+		BlockStop:             viper.GetUint64("stop"),
+		LiveMonitoring:        viper.GetBool("live"),
+		Insecure:              viper.GetBool("insecure"),
+		ReIndex:               viper.GetBool("reindex"),
+		MaxRecvMsgSize:        viper.GetInt("max-recv-msg-size"),
+		EnablePrometheus:      viper.GetBool("enable-prometheus"),
+		PrometheusListenAddr:  viper.GetString("prometheus-addr"),
+		LockedTokensByAccount: viper.GetBool("locked-tokens-by-account"),
+		LockedTokensByEndDate: viper.GetBool("locked-tokens-by-end-date"),
+		TokenDenoms:           viper.GetStringSlice("token-denoms"),
+		TokenMintEvents:       viper.GetStringSlice("token-mint-events"),
+		TokenBurnEvents:       viper.GetStringSlice("token-burn-events"),
+		TokenTransferEvents:   viper.GetStringSlice("token-transfer-events"),
+		ValidatorSnapshots:    viper.GetDuration("validator-snapshot-interval"),
+		WasmContractSnapshots: viper.GetDuration("wasm-contract-snapshot-interval"),
+		IndexSignatures:       viper.GetBool("index-signatures"),
+		GovOutcomes:           viper.GetBool("gov-outcomes"),
+		MissedBlocksWindow:    viper.GetInt64("missed-blocks-window"),
+		GasMetricsWindow:      viper.GetInt64("gas-metrics-window"),
+		Retention:             loadRetentionPolicyFromCLI(),
+		BlockStore:            viper.GetString("block-store"),
+		BlockStoreBackend:     source.Backend(viper.GetString("block-store-backend")),
+		DescriptorSet:         viper.GetString("descriptor-set"),
 	}
 }
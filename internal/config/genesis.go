@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+type ImportGenesisConfig struct {
+	// Address is the gRPC server the descriptors are fetched from, unless DescriptorSet is set
+	Address string
+	// DescriptorSet is a file holding the descriptors decoding the genesis, e.g., written by `yaci descriptors`
+	DescriptorSet  string
+	Insecure       bool
+	MaxRecvMsgSize int
+}
+
+func (c ImportGenesisConfig) Validate() error {
+	if c.Address == "" && c.DescriptorSet == "" {
+		return fmt.Errorf("missing address or --descriptor-set, required to decode the genesis")
+	}
+	if c.Address != "" && c.DescriptorSet != "" {
+		return fmt.Errorf("cannot set an address with --descriptor-set")
+	}
+	return nil
+}
+
+// LoadImportGenesisConfigFromCLI loads the configuration of the genesis import; the address is the optional argument
+// of the command
+func LoadImportGenesisConfigFromCLI(address string) ImportGenesisConfig {
+	return ImportGenesisConfig{
+		Address:        address,
+		DescriptorSet:  viper.GetString("descriptor-set"),
+		Insecure:       viper.GetBool("insecure"),
+		MaxRecvMsgSize: viper.GetInt("max-recv-msg-size"),
+	}
+}
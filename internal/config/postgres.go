@@ -2,13 +2,32 @@ package config
 
 import (
 	"fmt"
+	"regexp"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+var (
+	// schemaRegex matches the schema names that are used as is in the SQL statements, i.e., lowercase unquoted identifiers,
+	// short enough for the migrations table and notification channel names derived from them
+	schemaRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,44}$`)
+	// roleRegex matches the role names that are used as is in the SQL statements, i.e., lowercase unquoted identifiers
+	roleRegex = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
 )
 
 type PostgresConfig struct {
 	ConnString string
+	// Schema is the schema of the indexed data, so that several instances can share a database
+	Schema string
+	// AnonRole is the role granted the read access of the anonymous web clients, e.g., PostgREST, by the migrations
+	AnonRole string
+	// RawStorage is the storage mode of the raw JSON written by the extraction
+	RawStorage output.RawStorage
+	// SkipMigrations does not run the migrations, which are then run separately with `yaci migrate`
+	SkipMigrations bool
 }
 
 func (c PostgresConfig) Validate() error {
@@ -21,11 +40,27 @@ func (c PostgresConfig) Validate() error {
 		return fmt.Errorf("failed to parse PostgreSQL connection string: %w", err)
 	}
 
+	if !schemaRegex.MatchString(c.Schema) {
+		return fmt.Errorf("invalid PostgreSQL schema %q, expected lowercase letters, digits and underscores", c.Schema)
+	}
+
+	if !roleRegex.MatchString(c.AnonRole) {
+		return fmt.Errorf("invalid PostgreSQL anonymous role %q, expected lowercase letters, digits and underscores", c.AnonRole)
+	}
+
+	if err := c.RawStorage.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func LoadPostgresConfigFromCLI() PostgresConfig {
 	return PostgresConfig{
-		ConnString: viper.GetString("postgres-conn"),
+		ConnString:     viper.GetString("postgres-conn"),
+		Schema:         viper.GetString("postgres-schema"),
+		AnonRole:       viper.GetString("postgres-anon-role"),
+		RawStorage:     output.RawStorage(viper.GetString("raw-storage")),
+		SkipMigrations: viper.GetBool("skip-migrations"),
 	}
 }
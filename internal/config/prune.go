@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+type PruneConfig struct {
+	// ChainID is the pruned chain; it is required when the database holds several chains
+	ChainID   string
+	Retention output.RetentionPolicy
+}
+
+func (c PruneConfig) Validate() error {
+	if !c.Retention.Enabled() {
+		return fmt.Errorf("nothing to prune, set --retain-blocks or --retain-duration")
+	}
+	return validateRetentionPolicy(c.Retention)
+}
+
+func LoadPruneConfigFromCLI() PruneConfig {
+	return PruneConfig{
+		ChainID:   viper.GetString("chain-id"),
+		Retention: loadRetentionPolicyFromCLI(),
+	}
+}
+
+func validateRetentionPolicy(policy output.RetentionPolicy) error {
+	if policy.Duration < 0 {
+		return fmt.Errorf("retain-duration must not be negative")
+	}
+	return nil
+}
+
+func loadRetentionPolicyFromCLI() output.RetentionPolicy {
+	return output.RetentionPolicy{
+		Blocks:           viper.GetUint64("retain-blocks"),
+		Duration:         viper.GetDuration("retain-duration"),
+		KeepNormalized:   viper.GetBool("keep-normalized"),
+		DetachPartitions: viper.GetBool("detach-partitions"),
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/spf13/viper"
+)
+
+type ServeConfig struct {
+	ListenAddr    string
+	EnableGraphQL bool
+	// ChainID restricts the API to a chain; it is required when the database holds several chains
+	ChainID string
+	// RawSource is the gRPC server the raw JSON that is not stored is fetched from, if any
+	RawSource      string
+	Insecure       bool
+	MaxRetries     uint
+	MaxRecvMsgSize int
+}
+
+func (c ServeConfig) Validate() error {
+	_, port, err := net.SplitHostPort(c.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("invalid listen-addr format, expected host:port: %w", err)
+	}
+
+	if _, err := strconv.Atoi(port); err != nil {
+		return fmt.Errorf("invalid port in listen-addr: %w", err)
+	}
+
+	if c.RawSource != "" && c.MaxRetries == 0 {
+		return fmt.Errorf("max-retries must be greater than 0")
+	}
+
+	return nil
+}
+
+func LoadServeConfigFromCLI() ServeConfig {
+	return ServeConfig{
+		ListenAddr:     viper.GetString("listen-addr"),
+		EnableGraphQL:  viper.GetBool("enable-graphql"),
+		ChainID:        viper.GetString("chain-id"),
+		RawSource:      viper.GetString("raw-source"),
+		Insecure:       viper.GetBool("insecure"),
+		MaxRetries:     viper.GetUint("max-retries"),
+		MaxRecvMsgSize: viper.GetInt("max-recv-msg-size"),
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+type VerifyBalancesConfig struct {
+	Accounts       []string
+	Denom          string
+	Height         uint64
+	Samples        uint
+	MaxRetries     uint
+	Insecure       bool
+	MaxRecvMsgSize int
+}
+
+func (c VerifyBalancesConfig) Validate() error {
+	if len(c.Accounts) == 0 && c.Samples == 0 {
+		return fmt.Errorf("nothing to verify, set --account or --samples")
+	}
+	if c.MaxRetries == 0 {
+		return fmt.Errorf("max-retries must be greater than 0")
+	}
+	return nil
+}
+
+func LoadVerifyBalancesConfigFromCLI() VerifyBalancesConfig {
+	return VerifyBalancesConfig{
+		Accounts:       viper.GetStringSlice("account"),
+		Denom:          viper.GetString("denom"),
+		Height:         viper.GetUint64("height"),
+		Samples:        viper.GetUint("samples"),
+		MaxRetries:     viper.GetUint("max-retries"),
+		Insecure:       viper.GetBool("insecure"),
+		MaxRecvMsgSize: viper.GetInt("max-recv-msg-size"),
+	}
+}
+
+type VerifyBlocksConfig struct {
+	BlockStart     uint64
+	BlockStop      uint64
+	Samples        uint
+	MaxConcurrency uint
+	Repair         bool
+	// RepairSource is the gRPC address or CometBFT RPC URL the discrepant blocks are extracted from, or empty to use the
+	// verified gRPC server, which does not provide the block events
+	RepairSource string
+	// DescriptorSet is the file of the descriptors decoding the blocks of a CometBFT RPC repair source, queried from the
+	// node if it is not set
+	DescriptorSet   string
+	IndexSignatures bool
+	Report          string
+	MaxRetries      uint
+	Insecure        bool
+	MaxRecvMsgSize  int
+}
+
+func (c VerifyBlocksConfig) Validate() error {
+	if c.BlockStop != 0 && c.BlockStart > c.BlockStop {
+		return fmt.Errorf("start must not be greater than stop")
+	}
+	if c.MaxConcurrency == 0 {
+		return fmt.Errorf("max-concurrency must be greater than 0")
+	}
+	if c.MaxRetries == 0 {
+		return fmt.Errorf("max-retries must be greater than 0")
+	}
+	if !c.Repair && (c.RepairSource != "" || c.DescriptorSet != "") {
+		return fmt.Errorf("cannot set --repair-source or --descriptor-set without --repair")
+	}
+	return nil
+}
+
+func LoadVerifyBlocksConfigFromCLI() VerifyBlocksConfig {
+	return VerifyBlocksConfig{
+		BlockStart:      viper.GetUint64("start"),
+		BlockStop:       viper.GetUint64("stop"),
+		Samples:         viper.GetUint("samples"),
+		MaxConcurrency:  viper.GetUint("max-concurrency"),
+		Repair:          viper.GetBool("repair"),
+		RepairSource:    viper.GetString("repair-source"),
+		DescriptorSet:   viper.GetString("descriptor-set"),
+		IndexSignatures: viper.GetBool("index-signatures"),
+		Report:          viper.GetString("report"),
+		MaxRetries:      viper.GetUint("max-retries"),
+		Insecure:        viper.GetBool("insecure"),
+		MaxRecvMsgSize:  viper.GetInt("max-recv-msg-size"),
+	}
+}
@@ -2,22 +2,22 @@ package extractor
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 
-	"github.com/manifest-network/yaci/internal/client"
 	"github.com/manifest-network/yaci/internal/config"
 	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
 	"github.com/manifest-network/yaci/internal/output"
-	"github.com/manifest-network/yaci/internal/utils"
+	"github.com/manifest-network/yaci/internal/source"
 	"github.com/schollz/progressbar/v3"
 	"golang.org/x/sync/errgroup"
 )
 
-// extractBlocksAndTransactions extracts blocks and transactions from the gRPC server.
-func extractBlocksAndTransactions(gRPCClient *client.GRPCClient, start, stop uint64, outputHandler output.OutputHandler, maxConcurrency, maxRetries uint) error {
+// extractBlocksAndTransactions extracts blocks and transactions from the source.
+func extractBlocksAndTransactions(ctx context.Context, src source.BlockSource, start, stop uint64, outputHandler output.OutputHandler, maxConcurrency uint, indexSignatures, govOutcomes bool) error {
 	displayProgress := start != stop
 	if displayProgress {
 		slog.Info("Extracting blocks and transactions", "range", fmt.Sprintf("[%d, %d]", start, stop))
@@ -26,26 +26,13 @@ func extractBlocksAndTransactions(gRPCClient *client.GRPCClient, start, stop uin
 	}
 	var bar *progressbar.ProgressBar
 	if displayProgress {
-		bar = progressbar.NewOptions64(
-			int64(stop-start+1),
-			progressbar.OptionClearOnFinish(),
-			progressbar.OptionSetDescription("Processing blocks..."),
-			progressbar.OptionShowCount(),
-			progressbar.OptionShowIts(),
-			progressbar.OptionSetTheme(progressbar.Theme{
-				Saucer:        "=",
-				SaucerHead:    ">",
-				SaucerPadding: " ",
-				BarStart:      "[",
-				BarEnd:        "]",
-			}),
-		)
-		if err := bar.RenderBlank(); err != nil {
-			return fmt.Errorf("failed to render progress bar: %w", err)
+		var err error
+		if bar, err = newProgressBar(start, stop); err != nil {
+			return err
 		}
 	}
 
-	if err := processBlocks(gRPCClient, start, stop, outputHandler, maxConcurrency, maxRetries, bar); err != nil {
+	if err := processBlocks(ctx, src, start, stop, outputHandler, maxConcurrency, indexSignatures, govOutcomes, bar); err != nil {
 		return fmt.Errorf("failed to process blocks and transactions: %w", err)
 	}
 
@@ -58,17 +45,48 @@ func extractBlocksAndTransactions(gRPCClient *client.GRPCClient, start, stop uin
 	return nil
 }
 
-// processMissingBlocks processes missing blocks by fetching them from the gRPC server.
-func processMissingBlocks(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, cfg config.ExtractConfig) error {
-	missingBlockIds, err := outputHandler.GetMissingBlockIds(gRPCClient.Ctx)
+// newProgressBar returns a progress bar of the processing of the blocks from a height to another
+func newProgressBar(start, stop uint64) (*progressbar.ProgressBar, error) {
+	bar := progressbar.NewOptions64(
+		int64(stop-start+1),
+		progressbar.OptionClearOnFinish(),
+		progressbar.OptionSetDescription("Processing blocks..."),
+		progressbar.OptionShowCount(),
+		progressbar.OptionShowIts(),
+		progressbar.OptionSetTheme(progressbar.Theme{
+			Saucer:        "=",
+			SaucerHead:    ">",
+			SaucerPadding: " ",
+			BarStart:      "[",
+			BarEnd:        "]",
+		}),
+	)
+	if err := bar.RenderBlank(); err != nil {
+		return nil, fmt.Errorf("failed to render progress bar: %w", err)
+	}
+	return bar, nil
+}
+
+// processMissingBlocks processes missing blocks by fetching them from the source.
+// The missing blocks older than the earliest block of the source are skipped.
+func processMissingBlocks(ctx context.Context, src source.BlockSource, outputHandler output.OutputHandler, chainID string, cfg config.ExtractConfig) error {
+	missingBlockIds, err := outputHandler.GetMissingBlockIds(ctx, chainID)
 	if err != nil {
 		return fmt.Errorf("failed to get missing block IDs: %w", err)
 	}
 
 	if len(missingBlockIds) > 0 {
 		slog.Warn("Missing blocks detected", "count", len(missingBlockIds))
+		earliestRemoteBlock, err := src.EarliestHeight(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get the earliest block: %w", err)
+		}
 		for _, blockID := range missingBlockIds {
-			if err := processSingleBlockWithRetry(gRPCClient, blockID, outputHandler, cfg.MaxRetries); err != nil {
+			if blockID < earliestRemoteBlock {
+				slog.Warn("Missing block older than the earliest block of the source", "height", blockID)
+				continue
+			}
+			if err := processSingleBlock(ctx, src, blockID, outputHandler, cfg.IndexSignatures, cfg.GovOutcomes); err != nil {
 				return fmt.Errorf("failed to process missing block %d: %w", blockID, err)
 			}
 		}
@@ -77,8 +95,8 @@ func processMissingBlocks(gRPCClient *client.GRPCClient, outputHandler output.Ou
 }
 
 // processBlocks processes blocks in parallel using goroutines.
-func processBlocks(gRPCClient *client.GRPCClient, start, stop uint64, outputHandler output.OutputHandler, maxConcurrency, maxRetries uint, bar *progressbar.ProgressBar) error {
-	eg, ctx := errgroup.WithContext(gRPCClient.Ctx)
+func processBlocks(ctx context.Context, src source.BlockSource, start, stop uint64, outputHandler output.OutputHandler, maxConcurrency uint, indexSignatures, govOutcomes bool, bar *progressbar.ProgressBar) error {
+	eg, ctx := errgroup.WithContext(ctx)
 	sem := make(chan struct{}, maxConcurrency)
 
 	for height := start; height <= stop; height++ {
@@ -90,16 +108,10 @@ func processBlocks(gRPCClient *client.GRPCClient, start, stop uint64, outputHand
 		blockHeight := height
 		sem <- struct{}{}
 
-		clientWithCtx := &client.GRPCClient{
-			Conn:     gRPCClient.Conn,
-			Ctx:      ctx,
-			Resolver: gRPCClient.Resolver,
-		}
-
 		eg.Go(func() error {
 			defer func() { <-sem }()
 
-			err := processSingleBlockWithRetry(clientWithCtx, blockHeight, outputHandler, maxRetries)
+			err := processSingleBlock(ctx, src, blockHeight, outputHandler, indexSignatures, govOutcomes)
 			if err != nil {
 				if !errors.Is(err, context.Canceled) {
 					slog.Error("Block processing error",
@@ -108,7 +120,7 @@ func processBlocks(gRPCClient *client.GRPCClient, start, stop uint64, outputHand
 						"errorType", fmt.Sprintf("%T", err))
 					return err
 				}
-				slog.Error("Failed to process block", "height", blockHeight, "error", err, "retries", maxRetries)
+				slog.Error("Failed to process block", "height", blockHeight, "error", err)
 				return fmt.Errorf("failed to process block %d: %w", blockHeight, err)
 			}
 
@@ -128,43 +140,145 @@ func processBlocks(gRPCClient *client.GRPCClient, start, stop uint64, outputHand
 	return nil
 }
 
-// processSingleBlockWithRetry fetches a block and its transactions from the gRPC server with retries.
-// It unmarshals the block data and writes it to the output handler.
-func processSingleBlockWithRetry(gRPCClient *client.GRPCClient, blockHeight uint64, outputHandler output.OutputHandler, maxRetries uint) error {
-	blockJsonParams := []byte(fmt.Sprintf(`{"height": %d}`, blockHeight))
-
-	// Get block data with retries
-	blockJsonBytes, err := utils.GetGRPCResponse(
-		gRPCClient,
-		blockMethodFullName,
-		maxRetries,
-		blockJsonParams,
-	)
+// processSingleBlock gets a block and its transactions from the source, which retries the failed requests, and writes
+// them to the output handler.
+// When the signatures are indexed, the validator set of the previous block is fetched as well. When the gov outcomes
+// are recorded from a source without the end block events, the gov proposals are queried, see govOutcomes.
+func processSingleBlock(ctx context.Context, src source.BlockSource, blockHeight uint64, outputHandler output.OutputHandler, indexSignatures, recordGovOutcomes bool) error {
+	block, transactions, err := src.Block(ctx, blockHeight)
 	if err != nil {
-		return fmt.Errorf("failed to get block data: %w", err)
+		return err
 	}
-
-	// Create block model
-	block := &models.Block{
-		ID:   blockHeight,
-		Data: blockJsonBytes,
+	if block.ChainID == "" {
+		return fmt.Errorf("block %d has no chain ID", blockHeight)
 	}
 
-	var data map[string]interface{}
-	if err := json.Unmarshal(blockJsonBytes, &data); err != nil {
-		return fmt.Errorf("failed to unmarshal block JSON: %w", err)
+	if indexSignatures && blockHeight > 1 {
+		validatorSetSource, ok := src.(source.ValidatorSetSource)
+		if !ok {
+			return fmt.Errorf("the source does not provide the validator sets, required to index the signatures")
+		}
+		block.ValidatorSet, err = validatorSetSource.ValidatorSet(ctx, blockHeight-1)
+		if err != nil {
+			return fmt.Errorf("failed to get validator set: %w", err)
+		}
 	}
 
-	transactions, err := extractTransactions(gRPCClient, data, maxRetries)
-	if err != nil {
-		return fmt.Errorf("failed to extract transactions from block: %w", err)
+	normalize.Block(block)
+	votingSource, queryVotingProposals := src.(source.VotingProposalSource)
+	queryVotingProposals = queryVotingProposals && !block.HasEvents
+	if queryVotingProposals {
+		if recordGovOutcomes {
+			govOutcomes(ctx, votingSource, outputHandler, block)
+		}
+	} else if proposalSource, ok := src.(source.ProposalSource); ok {
+		govTallies(ctx, proposalSource, block)
+	}
+	if err := normalize.Transactions(transactions); err != nil {
+		return fmt.Errorf("failed to normalize transactions: %w", err)
+	}
+	if queryVotingProposals && recordGovOutcomes {
+		govVotingEndTimes(ctx, votingSource, block.ID, transactions)
 	}
 
 	// Write block with transactions to the output handler
-	err = outputHandler.WriteBlockWithTransactions(gRPCClient.Ctx, block, transactions)
+	err = outputHandler.WriteBlockWithTransactions(ctx, block, transactions)
 	if err != nil {
 		return fmt.Errorf("failed to write block with transactions: %w", err)
 	}
 
 	return nil
 }
+
+// govTallies adds the final tallies of the gov proposals ended by the block, queried at its height as the end block
+// events do not carry them. The tally is not recorded when the node pruned the state at that height.
+func govTallies(ctx context.Context, src source.ProposalSource, block *models.Block) {
+	for _, id := range normalize.EndedGovProposals(block.Governance) {
+		response, err := src.GovProposal(ctx, block.ID, id)
+		if err != nil {
+			slog.Warn("Failed to query the final tally of proposal", "height", block.ID, "proposal_id", id, "error", err)
+			continue
+		}
+		if tally, ok := normalize.GovProposalTally(response); ok {
+			block.Governance.Tallies = append(block.Governance.Tallies, tally)
+		}
+	}
+}
+
+// govOutcomes adds the status changes and final tallies of the gov proposals ended by a block whose source does not
+// provide the end block events. The proposals are only queried if the voting period of an indexed proposal ends by the
+// block time, see output.OutputHandler.HasEndingGovProposals: the proposals in their voting period at the previous height
+// whose voting period ends by the block time are then queried at its height. The outcomes are not recorded when the node
+// pruned the state at that height.
+func govOutcomes(ctx context.Context, src source.VotingProposalSource, outputHandler output.OutputHandler, block *models.Block) {
+	if block.ID <= 1 {
+		return
+	}
+	blockTime, err := time.Parse(time.RFC3339Nano, block.Time)
+	if err != nil {
+		return
+	}
+	ending, err := outputHandler.HasEndingGovProposals(ctx, block.ChainID, block.ID, blockTime)
+	if err != nil {
+		slog.Warn("Failed to get the gov proposals ending at the block", "height", block.ID, "error", err)
+		return
+	}
+	if !ending {
+		return
+	}
+
+	proposals, err := src.GovVotingProposals(ctx, block.ID-1)
+	if err != nil {
+		slog.Warn("Failed to query the gov proposals in their voting period", "height", block.ID-1, "error", err)
+		return
+	}
+
+	for _, id := range normalize.VotingEndedGovProposals(proposals, block.Time) {
+		response, err := src.GovProposal(ctx, block.ID, id)
+		if err != nil {
+			slog.Warn("Failed to query the outcome of proposal", "height", block.ID, "proposal_id", id, "error", err)
+			continue
+		}
+		if change, ok := normalize.GovProposalStatusChange(response); ok {
+			block.Governance.StatusChanges = append(block.Governance.StatusChanges, change)
+			if tally, ok := normalize.GovProposalTally(response); ok {
+				block.Governance.Tallies = append(block.Governance.Tallies, tally)
+			}
+			continue
+		}
+		// An expedited proposal that did not pass is converted to a regular one, whose voting period is extended
+		if change, ok := normalize.GovProposalVotingPeriod(response); ok {
+			block.Governance.StatusChanges = append(block.Governance.StatusChanges, change)
+		}
+	}
+}
+
+// govVotingEndTimes sets the end of the voting period of the gov proposals entering it in the transactions, queried at
+// the height of the block, from which the blocks ending their voting period are found, see govOutcomes
+func govVotingEndTimes(ctx context.Context, src source.ProposalSource, height uint64, transactions []*models.Transaction) {
+	for _, tx := range transactions {
+		if tx.Normalized == nil {
+			continue
+		}
+		gov := &tx.Normalized.Governance
+		for i, change := range gov.StatusChanges {
+			if change.Module != models.ProposalModuleGov || change.Status != "PROPOSAL_STATUS_VOTING_PERIOD" {
+				continue
+			}
+			response, err := src.GovProposal(ctx, height, change.ProposalID)
+			if err != nil {
+				slog.Warn("Failed to query the voting end time of proposal", "height", height, "proposal_id", change.ProposalID, "error", err)
+				continue
+			}
+			if votingPeriod, ok := normalize.GovProposalVotingPeriod(response); ok {
+				gov.StatusChanges[i].VotingEndTime = votingPeriod.VotingEndTime
+			}
+		}
+	}
+}
+
+// ExtractBlock extracts a single block and its transactions from the source, replacing the block in the output,
+// e.g., to repair it
+func ExtractBlock(ctx context.Context, src source.BlockSource, height uint64, outputHandler output.OutputHandler, indexSignatures, govOutcomes bool) error {
+	return processSingleBlock(ctx, src, height, outputHandler, indexSignatures, govOutcomes)
+}
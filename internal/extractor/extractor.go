@@ -1,44 +1,90 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
-	"github.com/manifest-network/yaci/internal/client"
 	"github.com/manifest-network/yaci/internal/config"
 	"github.com/manifest-network/yaci/internal/output"
-	"github.com/manifest-network/yaci/internal/utils"
+	"github.com/manifest-network/yaci/internal/source"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
-	blockMethodFullName = "cosmos.tx.v1beta1.Service.GetBlockWithTxs"
-	txMethodFullName    = "cosmos.tx.v1beta1.Service.GetTx"
+	// consensusAddressesInterval is the interval between the updates of the validator consensus addresses
+	consensusAddressesInterval = time.Hour
+	// pruningInterval is the interval between the prunings of the blocks outside the retention policy
+	pruningInterval = time.Hour
 )
 
-// Extract extracts blocks and transactions from a gRPC server.
-func Extract(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, config config.ExtractConfig) error {
+// ExtractChains extracts the blocks and transactions of several sources concurrently, one per chain.
+// The extraction of every chain stops when one of them fails.
+func ExtractChains(ctx context.Context, sources []source.BlockSource, outputHandler output.OutputHandler, config config.ExtractConfig) error {
+	if len(sources) == 1 {
+		return Extract(ctx, sources[0], outputHandler, config)
+	}
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, src := range sources {
+		eg.Go(func() error {
+			return Extract(ctx, src, outputHandler, config)
+		})
+	}
+	return eg.Wait()
+}
+
+// Extract extracts blocks and transactions from a source.
+// The blocks are stored under the chain ID of the source, so several chains can share an output.
+// The snapshots query the state of the chain, so they are only taken from a gRPC server.
+func Extract(ctx context.Context, src source.BlockSource, outputHandler output.OutputHandler, config config.ExtractConfig) error {
+	chainID, err := src.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the chain ID: %w", err)
+	}
+	slog.Info("Chain ID retrieved", "chain_id", chainID)
+
 	// Check if the missing block check should be skipped before setting the block range
 	skipMissingBlockCheck := shouldSkipMissingBlockCheck(config)
 
-	if err := setBlockRange(gRPCClient, outputHandler, &config); err != nil {
+	if err := setBlockRange(ctx, src, outputHandler, chainID, &config); err != nil {
 		return err
 	}
 
 	if !skipMissingBlockCheck {
-		if err := processMissingBlocks(gRPCClient, outputHandler, config); err != nil {
+		if err := processMissingBlocks(ctx, src, outputHandler, chainID, config); err != nil {
 			return err
 		}
 	}
 
+	// The snapshots are taken while the blocks are extracted
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if gRPCSource, ok := src.(*source.GRPC); ok {
+		slog.Warn("The gRPC source does not provide the block events: the balance ledger misses the balance changes of the begin and end blockers", "chain_id", chainID)
+		gRPCClient := gRPCSource.Client()
+		go runSnapshots(ctx, "consensus_addresses", gRPCClient, outputHandler, chainID, consensusAddressesInterval, config.MaxRetries, snapshotConsensusAddresses)
+		if config.ValidatorSnapshots > 0 {
+			go runSnapshots(ctx, "validators", gRPCClient, outputHandler, chainID, config.ValidatorSnapshots, config.MaxRetries, snapshotValidators)
+		}
+		if config.WasmContractSnapshots > 0 {
+			go runSnapshots(ctx, "wasm_contracts", gRPCClient, outputHandler, chainID, config.WasmContractSnapshots, config.MaxRetries, snapshotWasmContracts)
+		}
+	}
+	if config.Retention.Enabled() {
+		go runPruning(ctx, outputHandler, chainID, config.Retention, pruningInterval)
+	}
+
 	if config.LiveMonitoring {
-		slog.Info("Starting live extraction", "block_time", config.BlockTime)
-		err := extractLiveBlocksAndTransactions(gRPCClient, config.BlockStart, outputHandler, config.BlockTime, config.MaxConcurrency, config.MaxRetries)
+		slog.Info("Starting live extraction", "chain_id", chainID, "block_time", config.BlockTime)
+		err := extractLiveBlocksAndTransactions(ctx, src, config.BlockStart, outputHandler, config.BlockTime, config.MaxConcurrency, config.IndexSignatures, config.GovOutcomes)
 		if err != nil {
 			return fmt.Errorf("failed to process live blocks and transactions: %w", err)
 		}
 	} else {
-		slog.Info("Starting extraction", "start", config.BlockStart, "stop", config.BlockStop)
-		err := extractBlocksAndTransactions(gRPCClient, config.BlockStart, config.BlockStop, outputHandler, config.MaxConcurrency, config.MaxRetries)
+		slog.Info("Starting extraction", "chain_id", chainID, "start", config.BlockStart, "stop", config.BlockStop)
+		err := extractBlocksAndTransactions(ctx, src, config.BlockStart, config.BlockStop, outputHandler, config.MaxConcurrency, config.IndexSignatures, config.GovOutcomes)
 		if err != nil {
 			return fmt.Errorf("failed to process blocks and transactions: %w", err)
 		}
@@ -48,46 +94,63 @@ func Extract(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler,
 }
 
 // setBlockRange sets correct the block range based on the configuration.
-// If the start block is not set, it will be set to the latest block in the database.
-// If the stop block is not set, it will be set to the latest block in the gRPC server.
-// If the start block is greater than the stop block, an error will be returned.
-func setBlockRange(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, cfg *config.ExtractConfig) error {
+// If the start block is not set, it will be set to the latest block of the chain in the database.
+// If the stop block is not set, it will be set to the latest block of the source.
+// The blocks below the pruning floor of the chain are not indexed again: a start block computed below the floor is
+// raised to the floor, and a start block set below the floor returns an error.
+// If the start block is greater than the stop block, or older than the earliest block of the source, an error will be returned.
+func setBlockRange(ctx context.Context, src source.BlockSource, outputHandler output.OutputHandler, chainID string, cfg *config.ExtractConfig) error {
+	earliestRemoteBlock, err := src.EarliestHeight(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the earliest block: %w", err)
+	}
+	floor, err := outputHandler.GetPruningFloor(ctx, chainID)
+	if err != nil {
+		return err
+	}
+	if cfg.BlockStart != 0 && !cfg.ReIndex && cfg.BlockStart < floor {
+		return fmt.Errorf("start block %d is below the pruning floor %d of the chain", cfg.BlockStart, floor)
+	}
+
 	if cfg.ReIndex {
 		slog.Info("Reindexing entire database...")
-		// TODO: Get the earliest block from the gRPC server
-		// See https://github.com/manifest-network/yaci/issues/28
-		cfg.BlockStart = 1
-		earliestLocalBlock, err := outputHandler.GetEarliestBlock(gRPCClient.Ctx)
+		cfg.BlockStart = earliestRemoteBlock
+		earliestLocalBlock, err := outputHandler.GetEarliestBlock(ctx, chainID)
 		if err != nil {
 			return fmt.Errorf("failed to get the earliest local block: %w", err)
 		}
 		if earliestLocalBlock != nil {
-			cfg.BlockStart = earliestLocalBlock.ID
+			cfg.BlockStart = max(earliestLocalBlock.ID, earliestRemoteBlock)
 		}
 		cfg.BlockStop = 0
 	}
 
 	if cfg.BlockStart == 0 {
-		// TODO: Get the earliest block from the gRPC server
-		// See https://github.com/manifest-network/yaci/issues/28
-		cfg.BlockStart = 1
-		latestLocalBlock, err := outputHandler.GetLatestBlock(gRPCClient.Ctx)
+		cfg.BlockStart = earliestRemoteBlock
+		latestLocalBlock, err := outputHandler.GetLatestBlock(ctx, chainID)
 		if err != nil {
 			return fmt.Errorf("failed to get the latest block: %w", err)
 		}
 		if latestLocalBlock != nil {
-			cfg.BlockStart = latestLocalBlock.ID + 1
+			cfg.BlockStart = max(latestLocalBlock.ID+1, earliestRemoteBlock)
 		}
 	}
+	if cfg.BlockStart < floor {
+		slog.Info("Starting at the pruning floor", "chain_id", chainID, "floor", floor)
+		cfg.BlockStart = floor
+	}
 
 	if cfg.BlockStop == 0 {
-		latestRemoteBlock, err := utils.GetLatestBlockHeightWithRetry(gRPCClient, cfg.MaxRetries)
+		latestRemoteBlock, err := src.LatestHeight(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to get the latest block: %w", err)
 		}
 		cfg.BlockStop = latestRemoteBlock
 	}
 
+	if cfg.BlockStart < earliestRemoteBlock {
+		return fmt.Errorf("start block is older than the earliest block %d of the source", earliestRemoteBlock)
+	}
 	if cfg.BlockStart > cfg.BlockStop {
 		return fmt.Errorf("start block is greater than stop block")
 	}
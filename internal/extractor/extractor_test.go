@@ -0,0 +1,258 @@
+package extractor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/config"
+	"github.com/manifest-network/yaci/internal/extractor"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+// fakeSource is a block source holding the blocks from a height to another
+type fakeSource struct {
+	earliest, latest uint64
+}
+
+func (s fakeSource) ChainID(_ context.Context) (string, error) {
+	return "test-1", nil
+}
+
+func (s fakeSource) EarliestHeight(_ context.Context) (uint64, error) {
+	return s.earliest, nil
+}
+
+func (s fakeSource) LatestHeight(_ context.Context) (uint64, error) {
+	return s.latest, nil
+}
+
+func (s fakeSource) Block(_ context.Context, height uint64) (*models.Block, []*models.Transaction, error) {
+	if height < s.earliest || height > s.latest {
+		return nil, nil, fmt.Errorf("block %d not found", height)
+	}
+	data := fmt.Sprintf(`{"blockId": {"hash": "HASH%d"}, "block": {"header": {"chainId": "test-1"}, "data": {"txs": []}}}`, height)
+	return &models.Block{ID: height, ChainID: "test-1", Data: []byte(data)}, nil, nil
+}
+
+// fakeProposalSource is a block source whose blocks end gov proposal 7, as the end block events of the Cosmos SDK
+type fakeProposalSource struct {
+	fakeSource
+}
+
+func (s fakeProposalSource) Block(ctx context.Context, height uint64) (*models.Block, []*models.Transaction, error) {
+	block, transactions, err := s.fakeSource.Block(ctx, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	attr := func(key, value string) models.EventAttribute {
+		return models.EventAttribute{Key: key, Value: &value}
+	}
+	block.Events = []models.Event{
+		{Index: 0, Type: "coin_spent", Attributes: []models.EventAttribute{attr("spender", "manifest10d07y265gmmuvt4z0w9aw880jnsr700jmq3jzm"), attr("amount", "10000000umfx"), attr("mode", "EndBlock")}},
+		{Index: 1, Type: "coin_received", Attributes: []models.EventAttribute{attr("receiver", "manifest1proposer"), attr("amount", "10000000umfx"), attr("mode", "EndBlock")}},
+		{Index: 2, Type: "active_proposal", Attributes: []models.EventAttribute{attr("proposal_id", "7"), attr("proposal_result", "proposal_passed"), attr("proposal_log", "proposal passed"), attr("mode", "EndBlock")}},
+	}
+	block.HasEvents = true
+	return block, transactions, nil
+}
+
+func (s fakeProposalSource) GovProposal(_ context.Context, height, proposalID uint64) (json.RawMessage, error) {
+	if height != 11 || proposalID != 7 {
+		return nil, fmt.Errorf("proposal %d not found at height %d", proposalID, height)
+	}
+	return json.RawMessage(`{"proposal": {"id": "7", "status": "PROPOSAL_STATUS_PASSED", "finalTallyResult": {
+	  "yesCount": "600", "abstainCount": "0", "noCount": "100", "noWithVetoCount": "1"}}}`), nil
+}
+
+// fakeVotingProposalSource is a block source without the block events, as the gRPC source, whose block 11 ends the
+// voting period of gov proposal 7 and of the expedited gov proposal 8, converted to a regular proposal
+type fakeVotingProposalSource struct {
+	fakeSource
+	mu      *sync.Mutex
+	queries *int
+}
+
+func (s fakeVotingProposalSource) Block(ctx context.Context, height uint64) (*models.Block, []*models.Transaction, error) {
+	block, transactions, err := s.fakeSource.Block(ctx, height)
+	if err != nil {
+		return nil, nil, err
+	}
+	block.Data = []byte(fmt.Sprintf(`{"blockId": {"hash": "HASH%d"}, "block": {"header": {"chainId": "test-1", "time": "2024-05-01T12:00:%02dZ"}, "data": {"txs": []}}}`, height, height))
+	return block, transactions, nil
+}
+
+func (s fakeVotingProposalSource) GovVotingProposals(_ context.Context, height uint64) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	*s.queries++
+	s.mu.Unlock()
+	if height < 10 || height > 11 {
+		return nil, fmt.Errorf("state at height %d is pruned", height)
+	}
+	return []json.RawMessage{
+		json.RawMessage(`{"id": "7", "status": "PROPOSAL_STATUS_VOTING_PERIOD", "votingEndTime": "2024-05-01T12:00:10.5Z"}`),
+		json.RawMessage(`{"id": "8", "status": "PROPOSAL_STATUS_VOTING_PERIOD", "votingEndTime": "2024-05-01T12:00:11Z"}`),
+		json.RawMessage(`{"id": "9", "status": "PROPOSAL_STATUS_VOTING_PERIOD", "votingEndTime": "2024-05-01T12:00:30Z"}`),
+	}, nil
+}
+
+func (s fakeVotingProposalSource) GovProposal(_ context.Context, height, proposalID uint64) (json.RawMessage, error) {
+	switch {
+	case height == 11 && proposalID == 7:
+		return json.RawMessage(`{"proposal": {"id": "7", "status": "PROPOSAL_STATUS_REJECTED", "finalTallyResult": {
+		  "yesCount": "100", "abstainCount": "0", "noCount": "600", "noWithVetoCount": "0"}}}`), nil
+	case height == 11 && proposalID == 8:
+		return json.RawMessage(`{"proposal": {"id": "8", "status": "PROPOSAL_STATUS_VOTING_PERIOD", "expedited": false, "votingEndTime": "2024-05-01T12:00:40Z"}}`), nil
+	}
+	return nil, fmt.Errorf("proposal %d not found at height %d", proposalID, height)
+}
+
+// fakeOutput is an output recording the written blocks
+type fakeOutput struct {
+	output.OutputHandler
+	mu      sync.Mutex
+	blocks  []uint64
+	missing []uint64
+	latest  uint64
+	floor   uint64
+	tallies []models.ProposalTally
+	changes []models.ProposalStatusChange
+	// ending are the heights by which the voting period of an indexed gov proposal ends
+	ending []uint64
+}
+
+func (o *fakeOutput) WriteBlockWithTransactions(_ context.Context, block *models.Block, _ []*models.Transaction) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.blocks = append(o.blocks, block.ID)
+	o.tallies = append(o.tallies, block.Governance.Tallies...)
+	o.changes = append(o.changes, block.Governance.StatusChanges...)
+	return nil
+}
+
+func (o *fakeOutput) GetLatestBlock(_ context.Context, _ string) (*models.Block, error) {
+	if o.latest == 0 {
+		return nil, nil
+	}
+	return &models.Block{ID: o.latest}, nil
+}
+
+func (o *fakeOutput) GetEarliestBlock(_ context.Context, _ string) (*models.Block, error) {
+	return nil, nil
+}
+
+func (o *fakeOutput) GetPruningFloor(_ context.Context, _ string) (uint64, error) {
+	return o.floor, nil
+}
+
+func (o *fakeOutput) HasEndingGovProposals(_ context.Context, _ string, height uint64, _ time.Time) (bool, error) {
+	return slices.Contains(o.ending, height), nil
+}
+
+func (o *fakeOutput) GetMissingBlockIds(_ context.Context, _ string) ([]uint64, error) {
+	return o.missing, nil
+}
+
+func TestExtract(t *testing.T) {
+	cfg := config.ExtractConfig{MaxConcurrency: 2}
+
+	// The extraction resumes after the latest block of the output, and fills the missing blocks of the source
+	out := &fakeOutput{latest: 15, missing: []uint64{5, 12}}
+	require.NoError(t, extractor.Extract(context.Background(), fakeSource{earliest: 10, latest: 20}, out, cfg))
+	slices.Sort(out.blocks)
+	require.Equal(t, []uint64{12, 16, 17, 18, 19, 20}, out.blocks)
+
+	// The extraction starts at the earliest block of the source
+	out = &fakeOutput{}
+	require.NoError(t, extractor.Extract(context.Background(), fakeSource{earliest: 10, latest: 12}, out, cfg))
+	slices.Sort(out.blocks)
+	require.Equal(t, []uint64{10, 11, 12}, out.blocks)
+
+	// The blocks older than the earliest block of the source cannot be extracted
+	cfg.BlockStart, cfg.BlockStop = 5, 12
+	err := extractor.Extract(context.Background(), fakeSource{earliest: 10, latest: 12}, &fakeOutput{}, cfg)
+	require.ErrorContains(t, err, "older than the earliest block")
+
+	// The blocks below the pruning floor are not indexed again
+	cfg.BlockStart, cfg.BlockStop = 0, 0
+	out = &fakeOutput{floor: 11}
+	require.NoError(t, extractor.Extract(context.Background(), fakeSource{earliest: 10, latest: 12}, out, cfg))
+	slices.Sort(out.blocks)
+	require.Equal(t, []uint64{11, 12}, out.blocks)
+
+	cfg.BlockStart, cfg.BlockStop = 10, 12
+	err = extractor.Extract(context.Background(), fakeSource{earliest: 10, latest: 12}, &fakeOutput{floor: 11}, cfg)
+	require.ErrorContains(t, err, "below the pruning floor 11")
+
+	// The signatures require the validator sets
+	cfg.BlockStart, cfg.BlockStop, cfg.IndexSignatures = 11, 12, true
+	err = extractor.Extract(context.Background(), fakeSource{earliest: 10, latest: 12}, &fakeOutput{}, cfg)
+	require.ErrorContains(t, err, "does not provide the validator sets")
+}
+
+func TestExtractGovTallies(t *testing.T) {
+	cfg := config.ExtractConfig{MaxConcurrency: 1, BlockStart: 11, BlockStop: 11}
+
+	// The final tally of an ended gov proposal is queried at the height of the end block events
+	out := &fakeOutput{}
+	require.NoError(t, extractor.Extract(context.Background(), fakeProposalSource{fakeSource{earliest: 10, latest: 12}}, out, cfg))
+	require.Equal(t, []models.ProposalTally{
+		{Module: models.ProposalModuleGov, ProposalID: 7, Yes: "600", Abstain: "0", No: "100", NoWithVeto: "1"},
+	}, out.tallies)
+
+	// The tally is not recorded when the proposal cannot be queried, e.g., when the state is pruned
+	out = &fakeOutput{}
+	cfg.BlockStart, cfg.BlockStop = 12, 12
+	require.NoError(t, extractor.Extract(context.Background(), fakeProposalSource{fakeSource{earliest: 10, latest: 12}}, out, cfg))
+	require.Equal(t, []uint64{12}, out.blocks)
+	require.Empty(t, out.tallies)
+}
+
+func TestExtractGovOutcomesWithoutBlockEvents(t *testing.T) {
+	var queries int
+	src := fakeVotingProposalSource{fakeSource{earliest: 10, latest: 12}, &sync.Mutex{}, &queries}
+	cfg := config.ExtractConfig{MaxConcurrency: 1, BlockStart: 11, BlockStop: 11, GovOutcomes: true}
+
+	// The proposals in their voting period at the previous height whose voting period ends by the block time are queried
+	// at the height of the block; the expedited proposal that stays in its voting period gets a new voting end time
+	out := &fakeOutput{ending: []uint64{11}}
+	require.NoError(t, extractor.Extract(context.Background(), src, out, cfg))
+	votingEnd := time.Date(2024, 5, 1, 12, 0, 40, 0, time.UTC)
+	require.Equal(t, []models.ProposalStatusChange{
+		{Module: models.ProposalModuleGov, ProposalID: 7, Status: "PROPOSAL_STATUS_REJECTED"},
+		{Module: models.ProposalModuleGov, ProposalID: 8, Status: "PROPOSAL_STATUS_VOTING_PERIOD", VotingEndTime: &votingEnd},
+	}, out.changes)
+	require.Equal(t, []models.ProposalTally{
+		{Module: models.ProposalModuleGov, ProposalID: 7, Yes: "100", Abstain: "0", No: "600", NoWithVeto: "0"},
+	}, out.tallies)
+	require.Equal(t, 1, queries)
+
+	// The proposals are not queried when no indexed voting period ends by the block
+	out = &fakeOutput{}
+	require.NoError(t, extractor.Extract(context.Background(), src, out, cfg))
+	require.Equal(t, []uint64{11}, out.blocks)
+	require.Empty(t, out.changes)
+	require.Equal(t, 1, queries)
+
+	// The proposals are not queried without --gov-outcomes
+	out = &fakeOutput{ending: []uint64{11}}
+	cfg.GovOutcomes = false
+	require.NoError(t, extractor.Extract(context.Background(), src, out, cfg))
+	require.Empty(t, out.changes)
+	require.Equal(t, 1, queries)
+
+	// The outcomes are not recorded when the state is pruned
+	out = &fakeOutput{ending: []uint64{10}}
+	cfg.BlockStart, cfg.BlockStop, cfg.GovOutcomes = 10, 10, true
+	require.NoError(t, extractor.Extract(context.Background(), src, out, cfg))
+	require.Equal(t, []uint64{10}, out.blocks)
+	require.Empty(t, out.changes)
+	require.Empty(t, out.tallies)
+}
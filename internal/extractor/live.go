@@ -1,30 +1,30 @@
 package extractor
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	"github.com/manifest-network/yaci/internal/client"
 	"github.com/manifest-network/yaci/internal/output"
-	"github.com/manifest-network/yaci/internal/utils"
+	"github.com/manifest-network/yaci/internal/source"
 )
 
 // extractLiveBlocksAndTransactions monitors the chain and processes new blocks as they are produced.
-func extractLiveBlocksAndTransactions(gRPCClient *client.GRPCClient, start uint64, outputHandler output.OutputHandler, blockTime, maxConcurrency, maxRetries uint) error {
+func extractLiveBlocksAndTransactions(ctx context.Context, src source.BlockSource, start uint64, outputHandler output.OutputHandler, blockTime, maxConcurrency uint, indexSignatures, govOutcomes bool) error {
 	currentHeight := start - 1
 	for {
 		select {
-		case <-gRPCClient.Ctx.Done():
+		case <-ctx.Done():
 			return nil
 		default:
 			// Get the latest block height
-			latestHeight, err := utils.GetLatestBlockHeightWithRetry(gRPCClient, maxRetries)
+			latestHeight, err := src.LatestHeight(ctx)
 			if err != nil {
 				return fmt.Errorf("failed to get latest block height: %w", err)
 			}
 
 			if latestHeight > currentHeight {
-				err = extractBlocksAndTransactions(gRPCClient, currentHeight+1, latestHeight, outputHandler, maxConcurrency, maxRetries)
+				err = extractBlocksAndTransactions(ctx, src, currentHeight+1, latestHeight, outputHandler, maxConcurrency, indexSignatures, govOutcomes)
 				if err != nil {
 					return fmt.Errorf("failed to process blocks and transactions: %w", err)
 				}
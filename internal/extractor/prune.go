@@ -0,0 +1,27 @@
+package extractor
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+// runPruning prunes the blocks of a chain outside the retention policy every interval, until the context is done.
+// Failed prunings are logged and do not stop the extraction.
+func runPruning(ctx context.Context, outputHandler output.OutputHandler, chainID string, policy output.RetentionPolicy, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := outputHandler.Prune(ctx, chainID, policy); err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to prune blocks", "chain_id", chainID, "error", err, "retryIn", interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
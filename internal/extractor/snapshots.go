@@ -0,0 +1,94 @@
+package extractor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/client"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+	"github.com/manifest-network/yaci/internal/output"
+	"github.com/manifest-network/yaci/internal/utils"
+)
+
+// snapshotFunc writes a snapshot of the state of a chain at the given height
+type snapshotFunc func(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, chainID string, height uint64, maxRetries uint) error
+
+// runSnapshots writes a snapshot at the latest height every interval, until the context is done.
+// Failed snapshots are logged and do not stop the extraction.
+func runSnapshots(ctx context.Context, name string, gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, chainID string, interval time.Duration, maxRetries uint, snapshot snapshotFunc) {
+	clientWithCtx := &client.GRPCClient{
+		Conn:     gRPCClient.Conn,
+		Ctx:      ctx,
+		Resolver: gRPCClient.Resolver,
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		height, err := utils.GetLatestBlockHeightWithRetry(clientWithCtx, maxRetries)
+		if err == nil {
+			err = snapshot(clientWithCtx, outputHandler, chainID, height, maxRetries)
+		}
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("Failed to write snapshot", "snapshot", name, "chain_id", chainID, "error", err, "retryIn", interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// snapshotValidators writes a snapshot of the validator set
+func snapshotValidators(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, chainID string, height uint64, maxRetries uint) error {
+	validators, err := utils.GetValidatorsAtHeightWithRetry(gRPCClient, height, maxRetries)
+	if err != nil {
+		return fmt.Errorf("failed to get validators: %w", err)
+	}
+
+	snapshot := &models.ValidatorSnapshot{ChainID: chainID, Height: height, Time: time.Now().UTC(), Validators: validators}
+	if err := outputHandler.WriteValidatorSnapshot(gRPCClient.Ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to write validator snapshot: %w", err)
+	}
+
+	slog.Info("Validator set snapshot written", "height", height, "validators", len(validators))
+	return nil
+}
+
+// snapshotWasmContracts writes a snapshot of the information of the CosmWasm contracts
+func snapshotWasmContracts(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, chainID string, height uint64, maxRetries uint) error {
+	contracts, err := utils.GetWasmContractsAtHeightWithRetry(gRPCClient, height, maxRetries)
+	if err != nil {
+		return fmt.Errorf("failed to get contracts: %w", err)
+	}
+
+	snapshot := &models.WasmContractSnapshot{ChainID: chainID, Height: height, Time: time.Now().UTC(), Contracts: contracts}
+	if err := outputHandler.WriteWasmContractSnapshot(gRPCClient.Ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to write contract snapshot: %w", err)
+	}
+
+	slog.Info("Contract snapshot written", "height", height, "contracts", len(contracts))
+	return nil
+}
+
+// snapshotConsensusAddresses writes the consensus addresses of the validators, mapping the proposers and
+// signatures to the validator operator addresses
+func snapshotConsensusAddresses(gRPCClient *client.GRPCClient, outputHandler output.OutputHandler, chainID string, height uint64, maxRetries uint) error {
+	validators, err := utils.GetValidatorsAtHeightWithRetry(gRPCClient, height, maxRetries)
+	if err != nil {
+		return fmt.Errorf("failed to get validators: %w", err)
+	}
+
+	addresses := normalize.ValidatorConsensusAddresses(validators)
+	if err := outputHandler.WriteValidatorConsensusAddresses(gRPCClient.Ctx, chainID, height, addresses); err != nil {
+		return fmt.Errorf("failed to write validator consensus addresses: %w", err)
+	}
+
+	slog.Debug("Validator consensus addresses written", "height", height, "validators", len(addresses))
+	return nil
+}
@@ -0,0 +1,154 @@
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+// The genesis states of the modules decoded from the genesis file; the states of the other modules are ignored
+const (
+	authGenesisState    = "cosmos.auth.v1beta1.GenesisState"
+	bankGenesisState    = "cosmos.bank.v1beta1.GenesisState"
+	stakingGenesisState = "cosmos.staking.v1beta1.GenesisState"
+	tx                  = "cosmos.tx.v1beta1.Tx"
+)
+
+// genesisFile is a genesis file, as written by the Cosmos SDK or CometBFT
+type genesisFile struct {
+	ChainID     string    `json:"chain_id"`
+	GenesisTime time.Time `json:"genesis_time"`
+	// InitialHeight is a string in the CometBFT genesis and a number in the Cosmos SDK genesis
+	InitialHeight json.RawMessage            `json:"initial_height"`
+	AppState      map[string]json.RawMessage `json:"app_state"`
+}
+
+// ReadFile reads and decodes a genesis file
+func ReadFile(path string, resolver *reflection.CustomResolver) (*models.Genesis, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read genesis file: %w", err)
+	}
+	return Decode(data, resolver)
+}
+
+// Decode decodes a genesis file. The states of the auth, bank and staking modules and the genesis transactions are
+// decoded with the resolver, which resolves their Any types, e.g., the accounts and the public keys, and normalized
+// from their protobuf JSON. The modules missing from the genesis have no state.
+func Decode(data []byte, resolver *reflection.CustomResolver) (*models.Genesis, error) {
+	var file genesisFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode genesis file: %w", err)
+	}
+	if file.ChainID == "" {
+		return nil, fmt.Errorf("genesis file has no chain ID")
+	}
+
+	initialHeight, err := parseInitialHeight(file.InitialHeight)
+	if err != nil {
+		return nil, err
+	}
+	genesis := &models.Genesis{ChainID: file.ChainID, GenesisTime: file.GenesisTime, InitialHeight: initialHeight}
+
+	authState, err := moduleState(resolver, file.AppState, "auth", authGenesisState)
+	if err != nil {
+		return nil, err
+	}
+	genesis.Accounts, genesis.VestingPeriods = normalize.GenesisAccounts(authState)
+
+	bankState, err := moduleState(resolver, file.AppState, "bank", bankGenesisState)
+	if err != nil {
+		return nil, err
+	}
+	genesis.Balances = normalize.GenesisBalances(bankState)
+
+	stakingState, err := moduleState(resolver, file.AppState, "staking", stakingGenesisState)
+	if err != nil {
+		return nil, err
+	}
+	genTxs, err := genesisTransactions(resolver, file.AppState["genutil"])
+	if err != nil {
+		return nil, err
+	}
+	genesis.Validators = normalize.GenesisValidators(stakingState, genTxs)
+
+	return genesis, nil
+}
+
+// parseInitialHeight parses the initial height of a genesis, which defaults to 1
+func parseInitialHeight(raw json.RawMessage) (int64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 1, nil
+	}
+	var heightStr string
+	if err := json.Unmarshal(raw, &heightStr); err != nil {
+		heightStr = string(raw)
+	}
+	if heightStr == "" {
+		return 1, nil
+	}
+	height, err := strconv.ParseInt(heightStr, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid initial height %s: %w", raw, err)
+	}
+	return height, nil
+}
+
+// moduleState returns the genesis state of a module in protobuf JSON, or nil if the genesis has no state for the module
+func moduleState(resolver *reflection.CustomResolver, appState map[string]json.RawMessage, module string, name protoreflect.FullName) (json.RawMessage, error) {
+	state, ok := appState[module]
+	if !ok {
+		return nil, nil
+	}
+	canonical, err := canonicalJSON(resolver, state, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s genesis state: %w", module, err)
+	}
+	return canonical, nil
+}
+
+// genesisTransactions returns the genesis transactions of the genutil state in protobuf JSON
+func genesisTransactions(resolver *reflection.CustomResolver, genutilState json.RawMessage) ([]json.RawMessage, error) {
+	if len(genutilState) == 0 {
+		return nil, nil
+	}
+	var state struct {
+		GenTxs []json.RawMessage `json:"gen_txs"`
+	}
+	if err := json.Unmarshal(genutilState, &state); err != nil {
+		return nil, fmt.Errorf("failed to decode genutil genesis state: %w", err)
+	}
+
+	genTxs := make([]json.RawMessage, 0, len(state.GenTxs))
+	for i, raw := range state.GenTxs {
+		genTx, err := canonicalJSON(resolver, raw, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode genesis transaction %d: %w", i, err)
+		}
+		genTxs = append(genTxs, genTx)
+	}
+	return genTxs, nil
+}
+
+// canonicalJSON decodes the protobuf JSON of a message, whose field names can be the original or the JSON names, and
+// encodes it again with the JSON names. The fields unknown to the resolver are ignored.
+func canonicalJSON(resolver *reflection.CustomResolver, data json.RawMessage, name protoreflect.FullName) (json.RawMessage, error) {
+	messageType, err := resolver.FindMessageByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("the descriptors have no %s: %w", name, err)
+	}
+	msg := messageType.New().Interface()
+	if err := (protojson.UnmarshalOptions{Resolver: resolver, DiscardUnknown: true}).Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return protojson.MarshalOptions{Resolver: resolver}.Marshal(msg)
+}
@@ -0,0 +1,102 @@
+package genesis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/manifest-network/yaci/internal/genesis"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+// bankResolver returns a resolver of a minimal bank genesis state
+func bankResolver(t *testing.T) *reflection.CustomResolver {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			JsonName: proto.String(name),
+			Number:   proto.Int32(number),
+			Type:     typ.Enum(),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if repeated {
+			f.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("cosmos/bank/v1beta1/genesis.proto"),
+		Package: proto.String("cosmos.bank.v1beta1"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("Coin"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("denom", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					field("amount", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+				},
+			},
+			{
+				Name: proto.String("Balance"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("address", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+					field("coins", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".cosmos.bank.v1beta1.Coin", true),
+				},
+			},
+			{
+				Name: proto.String("GenesisState"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("balances", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".cosmos.bank.v1beta1.Balance", true),
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	files := new(protoregistry.Files)
+	require.NoError(t, files.RegisterFile(file))
+	return reflection.NewCustomResolver(context.Background(), files, nil, 0)
+}
+
+func TestDecode(t *testing.T) {
+	resolver := bankResolver(t)
+
+	g, err := genesis.Decode([]byte(`{
+	  "genesis_time": "2024-01-02T03:04:05Z",
+	  "chain_id": "test-1",
+	  "initial_height": "1",
+	  "app_state": {
+	    "bank": {"params": {}, "balances": [{"address": "alice", "coins": [{"denom": "umfx", "amount": "42"}]}], "supply": []},
+	    "mint": {"minter": {}}
+	  }
+	}`), resolver)
+	require.NoError(t, err)
+	require.Equal(t, "test-1", g.ChainID)
+	require.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), g.GenesisTime)
+	require.Equal(t, int64(1), g.InitialHeight)
+	require.Equal(t, []models.GenesisBalance{{Address: "alice", Denom: "umfx", Amount: "42"}}, g.Balances)
+	require.Empty(t, g.Accounts)
+	require.Empty(t, g.Validators)
+
+	// The initial height is a number in the Cosmos SDK genesis
+	g, err = genesis.Decode([]byte(`{"chain_id": "test-1", "initial_height": 100, "app_state": {}}`), resolver)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), g.InitialHeight)
+
+	// The states of the modules are decoded with the descriptors
+	_, err = genesis.Decode([]byte(`{"chain_id": "test-1", "app_state": {"auth": {"accounts": []}}}`), resolver)
+	require.ErrorContains(t, err, "the descriptors have no cosmos.auth.v1beta1.GenesisState")
+
+	_, err = genesis.Decode([]byte(`{"app_state": {}}`), resolver)
+	require.ErrorContains(t, err, "genesis file has no chain ID")
+}
@@ -0,0 +1,183 @@
+package collectors
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// GasPriceQuery returns the distribution of the gas prices, i.e., the fee divided by the gas wanted,
+// of the transactions of the last $1 blocks of the chain $2, per fee denom.
+const GasPriceQuery = `
+ WITH latest AS (
+   SELECT MAX(id) AS height FROM blocks_raw WHERE chain_id = $2
+ ),
+ prices AS (
+   SELECT f.denom, (f.amount / t.gas_wanted)::float8 AS price
+   FROM latest
+   JOIN transactions_main t ON t.chain_id = $2 AND t.height > latest.height - $1
+   JOIN transaction_fees f ON f.id = t.id
+   WHERE t.gas_wanted > 0
+ )
+ SELECT
+   denom,
+   COUNT(*) AS count,
+   SUM(price) AS sum,
+   percentile_cont(0.1) WITHIN GROUP (ORDER BY price) AS p10,
+   percentile_cont(0.25) WITHIN GROUP (ORDER BY price) AS p25,
+   percentile_cont(0.5) WITHIN GROUP (ORDER BY price) AS p50,
+   percentile_cont(0.75) WITHIN GROUP (ORDER BY price) AS p75,
+   percentile_cont(0.9) WITHIN GROUP (ORDER BY price) AS p90
+ FROM prices
+ GROUP BY denom
+`
+
+// BlockGasQuery returns the distribution of the gas used by the last $1 blocks of the chain $2, including the empty blocks
+const BlockGasQuery = `
+ WITH latest AS (
+   SELECT MAX(id) AS height FROM blocks_raw WHERE chain_id = $2
+ ),
+ blocks AS (
+   SELECT b.id, COALESCE(SUM(t.gas_used), 0)::float8 AS gas_used
+   FROM latest
+   JOIN blocks_raw b ON b.chain_id = $2 AND b.id > latest.height - $1
+   LEFT JOIN transactions_main t ON t.chain_id = b.chain_id AND t.height = b.id
+   GROUP BY b.id
+ )
+ SELECT
+   COUNT(*) AS count,
+   COALESCE(SUM(gas_used), 0) AS sum,
+   COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY gas_used), 0) AS p50,
+   COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY gas_used), 0) AS p90,
+   COALESCE(percentile_cont(0.99) WITHIN GROUP (ORDER BY gas_used), 0) AS p99
+ FROM blocks
+`
+
+// DefaultGasMetricsWindow is the default number of blocks over which the gas metrics are computed
+const DefaultGasMetricsWindow int64 = 1000
+
+// GasOptions configures the gas price and block fullness metrics.
+type GasOptions struct {
+	// Window is the number of blocks, up to the latest indexed block, over which the metrics are computed
+	Window int64
+	// MaxBlockGas is the maximum gas of a block, from the consensus parameters.
+	// The block fullness is not reported when it is not positive, i.e., unknown or unlimited.
+	MaxBlockGas int64
+}
+
+// GasCollector collects the distribution of the gas prices per fee denom, and of the gas used and fullness of the blocks
+type GasCollector struct {
+	db            *sql.DB
+	chainID       string
+	opts          GasOptions
+	gasPrice      *prometheus.Desc
+	blockGasUsed  *prometheus.Desc
+	blockFullness *prometheus.Desc
+	maxBlockGas   *prometheus.Desc
+}
+
+func NewGasCollector(db *sql.DB, chainID string, opts GasOptions) *GasCollector {
+	return &GasCollector{
+		db:      db,
+		chainID: chainID,
+		opts:    opts,
+		gasPrice: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "fees", "gas_price"),
+			"Gas price paid by the transactions over the gas metrics window, per fee denom",
+			[]string{"denom"},
+			prometheus.Labels{"source": "postgres"},
+		),
+		blockGasUsed: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "blocks", "gas_used"),
+			"Gas used by the blocks over the gas metrics window",
+			nil,
+			prometheus.Labels{"source": "postgres"},
+		),
+		blockFullness: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "blocks", "fullness_ratio"),
+			"Gas used by the blocks over the gas metrics window, relative to the maximum block gas",
+			nil,
+			prometheus.Labels{"source": "postgres"},
+		),
+		maxBlockGas: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "blocks", "max_gas"),
+			"Maximum gas of a block, from the consensus parameters",
+			nil,
+			prometheus.Labels{"source": "grpc"},
+		),
+	}
+}
+
+func (c *GasCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.gasPrice
+	ch <- c.blockGasUsed
+	ch <- c.blockFullness
+	ch <- c.maxBlockGas
+}
+
+func (c *GasCollector) Collect(ch chan<- prometheus.Metric) {
+	c.collectGasPrices(ch)
+	c.collectBlockGas(ch)
+}
+
+func (c *GasCollector) collectGasPrices(ch chan<- prometheus.Metric) {
+	rows, err := c.db.Query(GasPriceQuery, c.opts.Window, c.chainID)
+	if err != nil {
+		slog.Error("Failed to query gas prices", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.gasPrice, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var denom string
+		var count uint64
+		var sum, p10, p25, p50, p75, p90 float64
+		if err := rows.Scan(&denom, &count, &sum, &p10, &p25, &p50, &p75, &p90); err != nil {
+			slog.Error("Failed to scan gas prices", "error", err)
+			ch <- prometheus.NewInvalidMetric(c.gasPrice, err)
+			return
+		}
+		quantiles := map[float64]float64{0.1: p10, 0.25: p25, 0.5: p50, 0.75: p75, 0.9: p90}
+		ch <- prometheus.MustNewConstSummary(c.gasPrice, count, sum, quantiles, denom)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Failed to process gas prices", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.gasPrice, err)
+	}
+}
+
+func (c *GasCollector) collectBlockGas(ch chan<- prometheus.Metric) {
+	var count uint64
+	var sum, p50, p90, p99 float64
+	if err := c.db.QueryRow(BlockGasQuery, c.opts.Window, c.chainID).Scan(&count, &sum, &p50, &p90, &p99); err != nil {
+		slog.Error("Failed to query block gas", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.blockGasUsed, err)
+		if c.opts.MaxBlockGas > 0 {
+			ch <- prometheus.NewInvalidMetric(c.blockFullness, err)
+		}
+		return
+	}
+
+	ch <- prometheus.MustNewConstSummary(c.blockGasUsed, count, sum, map[float64]float64{0.5: p50, 0.9: p90, 0.99: p99})
+	if c.opts.MaxBlockGas <= 0 {
+		return
+	}
+
+	maxGas := float64(c.opts.MaxBlockGas)
+	ch <- prometheus.MustNewConstMetric(c.maxBlockGas, prometheus.GaugeValue, maxGas)
+	ch <- prometheus.MustNewConstSummary(c.blockFullness, count, sum/maxGas, map[float64]float64{0.5: p50 / maxGas, 0.9: p90 / maxGas, 0.99: p99 / maxGas})
+}
+
+func init() {
+	RegisterCollectorFactory(func(db *sql.DB, extraParams ...interface{}) (prometheus.Collector, error) {
+		opts, ok := FindParam[GasOptions](extraParams)
+		if !ok {
+			opts = GasOptions{Window: DefaultGasMetricsWindow}
+		}
+		chainID, _ := FindParam[ChainID](extraParams)
+		return NewGasCollector(db, string(chainID), opts), nil
+	})
+}
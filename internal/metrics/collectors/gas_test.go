@@ -0,0 +1,60 @@
+package collectors_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/metrics/collectors"
+)
+
+func TestGasCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(collectors.GasPriceQuery)).
+		WithArgs(int64(100), "manifest-1").
+		WillReturnRows(sqlmock.NewRows([]string{"denom", "count", "sum", "p10", "p25", "p50", "p75", "p90"}).
+			AddRow("umfx", 4, 0.4, 0.01, 0.05, 0.1, 0.1, 0.2))
+	mock.ExpectQuery(regexp.QuoteMeta(collectors.BlockGasQuery)).
+		WithArgs(int64(100), "manifest-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count", "sum", "p50", "p90", "p99"}).
+			AddRow(100, 5000000, 25000, 500000, 1000000))
+
+	c := collectors.NewGasCollector(db, "manifest-1", collectors.GasOptions{Window: 100, MaxBlockGas: 2000000})
+	expected := `
+# HELP yaci_blocks_fullness_ratio Gas used by the blocks over the gas metrics window, relative to the maximum block gas
+# TYPE yaci_blocks_fullness_ratio summary
+yaci_blocks_fullness_ratio{source="postgres",quantile="0.5"} 0.0125
+yaci_blocks_fullness_ratio{source="postgres",quantile="0.9"} 0.25
+yaci_blocks_fullness_ratio{source="postgres",quantile="0.99"} 0.5
+yaci_blocks_fullness_ratio_sum{source="postgres"} 2.5
+yaci_blocks_fullness_ratio_count{source="postgres"} 100
+# HELP yaci_blocks_gas_used Gas used by the blocks over the gas metrics window
+# TYPE yaci_blocks_gas_used summary
+yaci_blocks_gas_used{source="postgres",quantile="0.5"} 25000
+yaci_blocks_gas_used{source="postgres",quantile="0.9"} 500000
+yaci_blocks_gas_used{source="postgres",quantile="0.99"} 1e+06
+yaci_blocks_gas_used_sum{source="postgres"} 5e+06
+yaci_blocks_gas_used_count{source="postgres"} 100
+# HELP yaci_blocks_max_gas Maximum gas of a block, from the consensus parameters
+# TYPE yaci_blocks_max_gas gauge
+yaci_blocks_max_gas{source="grpc"} 2e+06
+# HELP yaci_fees_gas_price Gas price paid by the transactions over the gas metrics window, per fee denom
+# TYPE yaci_fees_gas_price summary
+yaci_fees_gas_price{denom="umfx",source="postgres",quantile="0.1"} 0.01
+yaci_fees_gas_price{denom="umfx",source="postgres",quantile="0.25"} 0.05
+yaci_fees_gas_price{denom="umfx",source="postgres",quantile="0.5"} 0.1
+yaci_fees_gas_price{denom="umfx",source="postgres",quantile="0.75"} 0.1
+yaci_fees_gas_price{denom="umfx",source="postgres",quantile="0.9"} 0.2
+yaci_fees_gas_price_sum{denom="umfx",source="postgres"} 0.4
+yaci_fees_gas_price_count{denom="umfx",source="postgres"} 4
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
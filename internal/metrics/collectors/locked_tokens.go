@@ -2,191 +2,144 @@ package collectors
 
 import (
 	"database/sql"
-	"encoding/json"
-	"fmt"
 	"log/slog"
-	"math/big"
 	"strconv"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const AddressesQuery = `
- SELECT DISTINCT m.metadata->>'toAddress' as to_address
- FROM api.messages_main m
- JOIN api.transactions_main t ON m.id = t.id
- WHERE m.type = '/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount'
- AND t.error IS NULL
+// LockedTokensQuery sums the vesting periods that are not unlocked yet, of the vesting accounts created by transactions
+// and of the vesting accounts of the imported genesis.
+// The period of a genesis continuous vesting account is unlocked linearly between its start and end times, and the
+// period of a genesis permanent locked account, without unlock and end times, is never unlocked.
+// The optional address and end date columns are only grouped on when $2 and $3 are true, respectively.
+// $4 is the chain ID.
+const LockedTokensQuery = `
+ SELECT
+   CASE WHEN $2 THEN v.address END AS address,
+   CASE WHEN $3 THEN to_char(v.end_time AT TIME ZONE 'UTC', 'YYYY-MM-DD') END AS end_date,
+   SUM(v.amount)::text AS amount
+ FROM (
+   SELECT v.address, v.end_time, v.amount
+   FROM vesting_periods v
+   JOIN transactions_main t ON t.id = v.id AND t.chain_id = $4
+   WHERE v.denom = $1
+   AND v.unlock_time > now()
+   AND t.error IS NULL
+   UNION ALL
+   SELECT
+     g.address,
+     g.end_time,
+     CASE WHEN g.start_time IS NULL OR now() <= g.start_time THEN g.amount
+     ELSE g.amount - trunc(g.amount * EXTRACT(EPOCH FROM now() - g.start_time) / EXTRACT(EPOCH FROM g.end_time - g.start_time))
+     END
+   FROM genesis_vesting_periods g
+   WHERE g.chain_id = $4
+   AND g.denom = $1
+   AND (g.unlock_time IS NULL OR g.unlock_time > now())
+ ) v
+ GROUP BY 1, 2
 `
 
-const AddressMessagesQuery = `
- SELECT 
-   m.metadata->>'startTime' as start_time,
-   m.metadata->'vestingPeriods' as vesting_periods
- FROM api.get_messages_for_address($1) m
- WHERE m.type = '/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount'
- AND m.error IS NULL
-`
-
-type VestingAmount struct {
-	Denom  string `json:"denom"`
-	Amount string `json:"amount"`
-}
-
-type VestingPeriod struct {
-	Length string          `json:"length"`
-	Amount []VestingAmount `json:"amount"`
+// LockedTokensOptions configures the labels of the locked tokens metric.
+type LockedTokensOptions struct {
+	// ByAccount adds an `address` label with the vesting account address
+	ByAccount bool
+	// ByEndDate adds an `end_date` label with the date (YYYY-MM-DD, UTC) the vesting schedule ends, empty for the permanent
+	// locked accounts
+	ByEndDate bool
 }
 
+// LockedTokensCollector collects the amount of tokens still locked in the periodic vesting accounts created by
+// transactions and in the vesting accounts of the genesis, including the continuous vesting accounts, unlocked linearly,
+// and the permanent locked accounts
 type LockedTokensCollector struct {
-	db                *sql.DB
-	lockedTokensCount *prometheus.Desc
-	denom             string
+	db                 *sql.DB
+	chainID            string
+	lockedTokensAmount *prometheus.Desc
+	denom              string
+	opts               LockedTokensOptions
 }
 
-func NewLockedTokensCollector(db *sql.DB, denom string) *LockedTokensCollector {
+func NewLockedTokensCollector(db *sql.DB, chainID, denom string, opts LockedTokensOptions) *LockedTokensCollector {
+	labels := []string{"denom"}
+	if opts.ByAccount {
+		labels = append(labels, "address")
+	}
+	if opts.ByEndDate {
+		labels = append(labels, "end_date")
+	}
+
 	return &LockedTokensCollector{
-		db:    db,
-		denom: denom,
-		lockedTokensCount: prometheus.NewDesc(
-			prometheus.BuildFQName("yaci", "locked_tokens", "count"),
-			"Total umfx locked in vesting accounts",
-			[]string{"denom", "amount"},
+		db:      db,
+		chainID: chainID,
+		denom:   denom,
+		opts:    opts,
+		lockedTokensAmount: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "locked_tokens", "amount"),
+			"Amount of tokens locked in vesting accounts",
+			labels,
 			prometheus.Labels{"source": "postgres"},
 		),
 	}
 }
 
 func (c *LockedTokensCollector) Describe(ch chan<- *prometheus.Desc) {
-	ch <- c.lockedTokensCount
+	ch <- c.lockedTokensAmount
 }
 
 func (c *LockedTokensCollector) Collect(ch chan<- prometheus.Metric) {
-	// First, get all distinct toAddresses from vesting messages
-	addressRows, err := c.db.Query(AddressesQuery)
+	rows, err := c.db.Query(LockedTokensQuery, c.denom, c.opts.ByAccount, c.opts.ByEndDate, c.chainID)
 	if err != nil {
-		slog.Error("Failed to query addresses", "error", err)
-		ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
+		slog.Error("Failed to query locked tokens", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.lockedTokensAmount, err)
 		return
 	}
-	defer addressRows.Close()
-
-	var totalLocked = big.NewInt(0)
-	currentTime := time.Now().Unix()
-
-	// For each address
-	for addressRows.Next() {
-		var toAddress string
-		if err := addressRows.Scan(&toAddress); err != nil {
-			slog.Error("Failed to scan address", "error", err)
-			ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
+	defer rows.Close()
+
+	// Without any grouping the query returns no row when nothing is locked; report zero instead.
+	found := false
+	for rows.Next() {
+		var address, endDate sql.NullString
+		var amountStr string
+		if err := rows.Scan(&address, &endDate, &amountStr); err != nil {
+			slog.Error("Failed to scan locked tokens", "error", err)
+			ch <- prometheus.NewInvalidMetric(c.lockedTokensAmount, err)
 			return
 		}
 
-		// Get all vesting messages for this address
-		vestingRows, err := c.db.Query(AddressMessagesQuery, toAddress)
+		amount, err := strconv.ParseFloat(amountStr, 64)
 		if err != nil {
-			slog.Error("Failed to query vesting messages", "address", toAddress, "error", err)
-			ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
+			slog.Error("Failed to parse locked tokens amount", "amount", amountStr, "error", err)
+			ch <- prometheus.NewInvalidMetric(c.lockedTokensAmount, err)
 			return
 		}
-		defer vestingRows.Close()
-
-		// Process each vesting message
-		for vestingRows.Next() {
-			var startTimeStr string
-			var vestingPeriodsJSON []byte
-
-			if err := vestingRows.Scan(&startTimeStr, &vestingPeriodsJSON); err != nil {
-				slog.Error("Failed to scan vesting message", "error", err)
-				ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
-				return
-			}
-
-			startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
-			if err != nil {
-				slog.Error("Failed to parse start time", "error", err)
-				ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
-				return
-			}
-
-			var vestingPeriods []VestingPeriod
-			if err := json.Unmarshal(vestingPeriodsJSON, &vestingPeriods); err != nil {
-				slog.Error("Failed to unmarshal vesting periods", "error", err)
-				ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
-				return
-			}
-
-			accountLocked, err := calculateLockedTokens(startTime, currentTime, vestingPeriods, c.denom)
-			if err != nil {
-				slog.Error("Failed to calculate locked tokens", "error", err)
-				ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
-				return
-			}
-
-			totalLocked = totalLocked.Add(totalLocked, accountLocked)
-		}
 
-		if err := vestingRows.Err(); err != nil {
-			slog.Error("Failed to process vesting messages", "error", err)
-			ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
-			return
-		}
+		ch <- prometheus.MustNewConstMetric(c.lockedTokensAmount, prometheus.GaugeValue, amount, c.labelValues(address.String, endDate.String)...)
+		found = true
 	}
 
-	if err := addressRows.Err(); err != nil {
-		ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
+	if err := rows.Err(); err != nil {
+		slog.Error("Failed to process locked tokens", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.lockedTokensAmount, err)
 		return
 	}
 
-	metric, err := prometheus.NewConstMetric(c.lockedTokensCount, prometheus.GaugeValue, 1, c.denom, totalLocked.String())
-	if err != nil {
-		slog.Error("Failed to create locked tokens metric", "denom", c.denom, "error", err)
-		ch <- prometheus.NewInvalidMetric(c.lockedTokensCount, err)
-		return
+	if !found && !c.opts.ByAccount && !c.opts.ByEndDate {
+		ch <- prometheus.MustNewConstMetric(c.lockedTokensAmount, prometheus.GaugeValue, 0, c.denom)
 	}
-
-	ch <- metric
 }
 
-// calculateLockedTokens calculates the amount of tokens still locked
-func calculateLockedTokens(startTime, currentTime int64, periods []VestingPeriod, denom string) (*big.Int, error) {
-	if len(periods) == 0 {
-		return big.NewInt(0), nil
+// labelValues returns the label values matching the labels of the collector descriptor
+func (c *LockedTokensCollector) labelValues(address, endDate string) []string {
+	values := []string{c.denom}
+	if c.opts.ByAccount {
+		values = append(values, address)
 	}
-
-	var lockedAmount = new(big.Int)
-	unlockTime := startTime
-
-	for _, period := range periods {
-		lengthSeconds, err := strconv.ParseInt(period.Length, 10, 64)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse length: %s", period.Length)
-		}
-
-		unlockTime += lengthSeconds
-
-		// Process all amounts in this period
-		for _, amount := range period.Amount {
-			if amount.Denom != denom {
-				continue
-			}
-
-			var amountValue big.Int
-			_, ok := amountValue.SetString(amount.Amount, 10)
-			if !ok {
-				return nil, fmt.Errorf("failed to parse amount: %s", amount.Amount)
-			}
-
-			// If unlock time is in the future, these tokens are still locked
-			if unlockTime > currentTime {
-				lockedAmount = lockedAmount.Add(lockedAmount, &amountValue)
-			}
-		}
+	if c.opts.ByEndDate {
+		values = append(values, endDate)
 	}
-
-	return lockedAmount, nil
+	return values
 }
 
 // See `locked_umfx.go` for an example of how to register this collector
@@ -0,0 +1,126 @@
+package collectors_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/metrics/collectors"
+)
+
+func TestLockedTokensCollector(t *testing.T) {
+	t.Run("Total", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(collectors.LockedTokensQuery)).
+			WithArgs("umfx", false, false, "manifest-1").
+			WillReturnRows(sqlmock.NewRows([]string{"address", "end_date", "amount"}).AddRow(nil, nil, "2000000000"))
+
+		c := collectors.NewLockedTokensCollector(db, "manifest-1", "umfx", collectors.LockedTokensOptions{})
+		expected := `
+# HELP yaci_locked_tokens_amount Amount of tokens locked in vesting accounts
+# TYPE yaci_locked_tokens_amount gauge
+yaci_locked_tokens_amount{denom="umfx",source="postgres"} 2e+09
+`
+		require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("NothingLocked", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(collectors.LockedTokensQuery)).
+			WithArgs("umfx", false, false, "manifest-1").
+			WillReturnRows(sqlmock.NewRows([]string{"address", "end_date", "amount"}))
+
+		c := collectors.NewLockedTokensCollector(db, "manifest-1", "umfx", collectors.LockedTokensOptions{})
+		expected := `
+# HELP yaci_locked_tokens_amount Amount of tokens locked in vesting accounts
+# TYPE yaci_locked_tokens_amount gauge
+yaci_locked_tokens_amount{denom="umfx",source="postgres"} 0
+`
+		require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ByAccountAndEndDate", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(regexp.QuoteMeta(collectors.LockedTokensQuery)).
+			WithArgs("umfx", true, true, "manifest-1").
+			WillReturnRows(sqlmock.NewRows([]string{"address", "end_date", "amount"}).
+				AddRow("manifest1foo", "2030-01-01", "1500").
+				AddRow("manifest1bar", "2031-06-30", "42"))
+
+		c := collectors.NewLockedTokensCollector(db, "manifest-1", "umfx", collectors.LockedTokensOptions{ByAccount: true, ByEndDate: true})
+		expected := `
+# HELP yaci_locked_tokens_amount Amount of tokens locked in vesting accounts
+# TYPE yaci_locked_tokens_amount gauge
+yaci_locked_tokens_amount{address="manifest1bar",denom="umfx",end_date="2031-06-30",source="postgres"} 42
+yaci_locked_tokens_amount{address="manifest1foo",denom="umfx",end_date="2030-01-01",source="postgres"} 1500
+`
+		require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestLockedTokensQueryContinuousVesting(t *testing.T) {
+	// The original vesting of a continuous vesting account is unlocked linearly between its start and end times
+	query := strings.Join(strings.Fields(collectors.LockedTokensQuery), " ")
+	require.Contains(t, query, "CASE WHEN g.start_time IS NULL OR now() <= g.start_time THEN g.amount")
+	require.Contains(t, query, "g.amount - trunc(g.amount * EXTRACT(EPOCH FROM now() - g.start_time) / EXTRACT(EPOCH FROM g.end_time - g.start_time))")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// A quarter of the continuous vesting account is unlocked
+	mock.ExpectQuery(regexp.QuoteMeta(collectors.LockedTokensQuery)).
+		WithArgs("umfx", true, true, "manifest-1").
+		WillReturnRows(sqlmock.NewRows([]string{"address", "end_date", "amount"}).
+			AddRow("manifest1foo", "2030-01-01", "750"))
+
+	c := collectors.NewLockedTokensCollector(db, "manifest-1", "umfx", collectors.LockedTokensOptions{ByAccount: true, ByEndDate: true})
+	expected := `
+# HELP yaci_locked_tokens_amount Amount of tokens locked in vesting accounts
+# TYPE yaci_locked_tokens_amount gauge
+yaci_locked_tokens_amount{address="manifest1foo",denom="umfx",end_date="2030-01-01",source="postgres"} 750
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLockedTokensQueryPermanentLocked(t *testing.T) {
+	// The period of a permanent locked account has no unlock time and is always locked
+	query := strings.Join(strings.Fields(collectors.LockedTokensQuery), " ")
+	require.Contains(t, query, "(g.unlock_time IS NULL OR g.unlock_time > now())")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Nor has it an end date
+	mock.ExpectQuery(regexp.QuoteMeta(collectors.LockedTokensQuery)).
+		WithArgs("umfx", true, true, "manifest-1").
+		WillReturnRows(sqlmock.NewRows([]string{"address", "end_date", "amount"}).
+			AddRow("manifest1bar", nil, "1000"))
+
+	c := collectors.NewLockedTokensCollector(db, "manifest-1", "umfx", collectors.LockedTokensOptions{ByAccount: true, ByEndDate: true})
+	expected := `
+# HELP yaci_locked_tokens_amount Amount of tokens locked in vesting accounts
+# TYPE yaci_locked_tokens_amount gauge
+yaci_locked_tokens_amount{address="manifest1bar",denom="umfx",end_date="",source="postgres"} 1000
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
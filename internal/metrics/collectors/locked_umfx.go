@@ -10,6 +10,8 @@ import (
 
 func init() {
 	RegisterCollectorFactory(func(db *sql.DB, extraParams ...interface{}) (prometheus.Collector, error) {
-		return NewLockedTokensCollector(db, "umfx"), nil
+		opts, _ := FindParam[LockedTokensOptions](extraParams)
+		chainID, _ := FindParam[ChainID](extraParams)
+		return NewLockedTokensCollector(db, string(chainID), "umfx", opts), nil
 	})
 }
@@ -0,0 +1,143 @@
+package collectors
+
+import (
+	"database/sql"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MissedBlocksQuery counts the blocks missed by the validators of the latest indexed validator set over the
+// last $1 blocks of the chain $2, with their current and longest streaks of consecutive missed blocks.
+// A streak is current when it ends at the latest indexed height.
+const MissedBlocksQuery = `
+ WITH latest AS (
+   SELECT MAX(height) AS height FROM block_signatures WHERE chain_id = $2
+ ),
+ signatures AS (
+   SELECT
+     s.consensus_address,
+     s.height,
+     s.block_id_flag = 'BLOCK_ID_FLAG_ABSENT' AS missed,
+     ROW_NUMBER() OVER (PARTITION BY s.consensus_address ORDER BY s.height)
+       - ROW_NUMBER() OVER (PARTITION BY s.consensus_address, s.block_id_flag = 'BLOCK_ID_FLAG_ABSENT' ORDER BY s.height) AS streak
+   FROM block_signatures s, latest
+   WHERE s.chain_id = $2 AND s.height > latest.height - $1
+ ),
+ streaks AS (
+   SELECT consensus_address, COUNT(*) AS length, MAX(height) AS last_height
+   FROM signatures
+   WHERE missed
+   GROUP BY consensus_address, streak
+ )
+ SELECT
+   s.consensus_address,
+   COALESCE(a.operator_address, '') AS operator_address,
+   COALESCE(a.moniker, '') AS moniker,
+   COALESCE(SUM(st.length), 0) AS missed,
+   COALESCE(MAX(st.length) FILTER (WHERE st.last_height = latest.height), 0) AS current_streak,
+   COALESCE(MAX(st.length), 0) AS longest_streak
+ FROM latest
+ JOIN block_signatures s ON s.chain_id = $2 AND s.height = latest.height
+ LEFT JOIN streaks st ON st.consensus_address = s.consensus_address
+ LEFT JOIN validator_consensus_addresses a ON a.chain_id = s.chain_id AND a.consensus_address = s.consensus_address
+ GROUP BY s.consensus_address, a.operator_address, a.moniker, latest.height
+`
+
+// DefaultMissedBlocksWindow is the default number of blocks over which the missed blocks are counted
+const DefaultMissedBlocksWindow int64 = 10000
+
+// MissedBlocksOptions configures the missed blocks metrics.
+type MissedBlocksOptions struct {
+	// Window is the number of blocks, up to the latest indexed signatures, over which the missed blocks are counted
+	Window int64
+}
+
+// MissedBlocksCollector collects the blocks missed by the validators, from the indexed last commit signatures
+type MissedBlocksCollector struct {
+	db            *sql.DB
+	chainID       string
+	opts          MissedBlocksOptions
+	missedBlocks  *prometheus.Desc
+	currentStreak *prometheus.Desc
+	longestStreak *prometheus.Desc
+}
+
+func NewMissedBlocksCollector(db *sql.DB, chainID string, opts MissedBlocksOptions) *MissedBlocksCollector {
+	labels := []string{"consensus_address", "operator_address", "moniker"}
+	return &MissedBlocksCollector{
+		db:      db,
+		chainID: chainID,
+		opts:    opts,
+		missedBlocks: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "validator", "missed_blocks"),
+			"Number of blocks missed by the validator over the missed blocks window",
+			labels,
+			prometheus.Labels{"source": "postgres"},
+		),
+		currentStreak: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "validator", "missed_blocks_streak"),
+			"Number of consecutive blocks missed by the validator up to the latest indexed block",
+			labels,
+			prometheus.Labels{"source": "postgres"},
+		),
+		longestStreak: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "validator", "missed_blocks_longest_streak"),
+			"Longest streak of consecutive blocks missed by the validator over the missed blocks window",
+			labels,
+			prometheus.Labels{"source": "postgres"},
+		),
+	}
+}
+
+func (c *MissedBlocksCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.missedBlocks
+	ch <- c.currentStreak
+	ch <- c.longestStreak
+}
+
+func (c *MissedBlocksCollector) Collect(ch chan<- prometheus.Metric) {
+	rows, err := c.db.Query(MissedBlocksQuery, c.opts.Window, c.chainID)
+	if err != nil {
+		slog.Error("Failed to query missed blocks", "error", err)
+		c.invalidate(ch, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var consensusAddress, operatorAddress, moniker string
+		var missed, currentStreak, longestStreak float64
+		if err := rows.Scan(&consensusAddress, &operatorAddress, &moniker, &missed, &currentStreak, &longestStreak); err != nil {
+			slog.Error("Failed to scan missed blocks", "error", err)
+			c.invalidate(ch, err)
+			return
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.missedBlocks, prometheus.GaugeValue, missed, consensusAddress, operatorAddress, moniker)
+		ch <- prometheus.MustNewConstMetric(c.currentStreak, prometheus.GaugeValue, currentStreak, consensusAddress, operatorAddress, moniker)
+		ch <- prometheus.MustNewConstMetric(c.longestStreak, prometheus.GaugeValue, longestStreak, consensusAddress, operatorAddress, moniker)
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Failed to process missed blocks", "error", err)
+		c.invalidate(ch, err)
+	}
+}
+
+func (c *MissedBlocksCollector) invalidate(ch chan<- prometheus.Metric, err error) {
+	ch <- prometheus.NewInvalidMetric(c.missedBlocks, err)
+	ch <- prometheus.NewInvalidMetric(c.currentStreak, err)
+	ch <- prometheus.NewInvalidMetric(c.longestStreak, err)
+}
+
+func init() {
+	RegisterCollectorFactory(func(db *sql.DB, extraParams ...interface{}) (prometheus.Collector, error) {
+		opts, ok := FindParam[MissedBlocksOptions](extraParams)
+		if !ok {
+			opts = MissedBlocksOptions{Window: DefaultMissedBlocksWindow}
+		}
+		chainID, _ := FindParam[ChainID](extraParams)
+		return NewMissedBlocksCollector(db, string(chainID), opts), nil
+	})
+}
@@ -0,0 +1,43 @@
+package collectors_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/metrics/collectors"
+)
+
+func TestMissedBlocksCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(collectors.MissedBlocksQuery)).
+		WithArgs(int64(100), "manifest-1").
+		WillReturnRows(sqlmock.NewRows([]string{"consensus_address", "operator_address", "moniker", "missed", "current_streak", "longest_streak"}).
+			AddRow("72CD6E8422C407FB6D098690F1130B7DED7EC2F7", "manifestvaloper1a", "val1", 12, 3, 7).
+			AddRow("75877BB41D393B5FB8455CE60ECD8DDA001D0631", "", "", 0, 0, 0))
+
+	c := collectors.NewMissedBlocksCollector(db, "manifest-1", collectors.MissedBlocksOptions{Window: 100})
+	expected := `
+# HELP yaci_validator_missed_blocks Number of blocks missed by the validator over the missed blocks window
+# TYPE yaci_validator_missed_blocks gauge
+yaci_validator_missed_blocks{consensus_address="72CD6E8422C407FB6D098690F1130B7DED7EC2F7",moniker="val1",operator_address="manifestvaloper1a",source="postgres"} 12
+yaci_validator_missed_blocks{consensus_address="75877BB41D393B5FB8455CE60ECD8DDA001D0631",moniker="",operator_address="",source="postgres"} 0
+# HELP yaci_validator_missed_blocks_longest_streak Longest streak of consecutive blocks missed by the validator over the missed blocks window
+# TYPE yaci_validator_missed_blocks_longest_streak gauge
+yaci_validator_missed_blocks_longest_streak{consensus_address="72CD6E8422C407FB6D098690F1130B7DED7EC2F7",moniker="val1",operator_address="manifestvaloper1a",source="postgres"} 7
+yaci_validator_missed_blocks_longest_streak{consensus_address="75877BB41D393B5FB8455CE60ECD8DDA001D0631",moniker="",operator_address="",source="postgres"} 0
+# HELP yaci_validator_missed_blocks_streak Number of consecutive blocks missed by the validator up to the latest indexed block
+# TYPE yaci_validator_missed_blocks_streak gauge
+yaci_validator_missed_blocks_streak{consensus_address="72CD6E8422C407FB6D098690F1130B7DED7EC2F7",moniker="val1",operator_address="manifestvaloper1a",source="postgres"} 3
+yaci_validator_missed_blocks_streak{consensus_address="75877BB41D393B5FB8455CE60ECD8DDA001D0631",moniker="",operator_address="",source="postgres"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
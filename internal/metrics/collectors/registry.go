@@ -41,6 +41,20 @@ func (r *Registry) CreateCollectors(db *sql.DB, extraParams ...interface{}) ([]p
 	return collectors, nil
 }
 
+// ChainID is the extra parameter restricting the metrics to the data of a chain, in a database holding several chains
+type ChainID string
+
+// FindParam returns the first extra parameter of type T, if any
+func FindParam[T any](extraParams []interface{}) (T, bool) {
+	for _, param := range extraParams {
+		if v, ok := param.(T); ok {
+			return v, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
 var DefaultRegistry = NewRegistry()
 
 func RegisterCollectorFactory(factory CollectorFactory) {
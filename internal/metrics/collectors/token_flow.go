@@ -0,0 +1,176 @@
+package collectors
+
+import (
+	"database/sql"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TokenFlowQuery sums the coin amounts of the `amount` attribute of the given event types per event type and denom.
+// The coins are split into denom and amount at ingestion, in the coins table. The coins of the block events, e.g., the
+// x/mint `coinbase` of the begin blocker, have no transaction; those of the transactions are only counted if successful.
+// $1 is a comma-separated list of event types, $2 an optional comma-separated list of denoms, $3 the chain ID.
+const TokenFlowQuery = `
+ SELECT
+   c.type AS event_type,
+   c.denom,
+   SUM(c.amount)::text AS amount
+ FROM coins c
+ LEFT JOIN transactions_main t ON t.id = c.id
+ WHERE c.chain_id = $3
+ AND (c.id IS NULL OR t.error IS NULL)
+ AND c.source = 'event'
+ AND c.type = ANY(string_to_array($1, ','))
+ AND c.key = 'amount'
+ AND ($2 = '' OR c.denom = ANY(string_to_array($2, ',')))
+ GROUP BY 1, 2
+`
+
+// TokenFlowOptions configures the denoms and event types of the token flow metrics.
+type TokenFlowOptions struct {
+	// Denoms restricts the metrics to the given denoms. All denoms are reported when empty.
+	Denoms []string
+	// MintEventTypes are the event types whose `amount` attribute is counted as minted
+	MintEventTypes []string
+	// BurnEventTypes are the event types whose `amount` attribute is counted as burned
+	BurnEventTypes []string
+	// TransferEventTypes are the event types whose `amount` attribute is counted as transferred
+	TransferEventTypes []string
+}
+
+// DefaultTokenFlowOptions returns the options matching the Cosmos SDK x/bank events
+func DefaultTokenFlowOptions() TokenFlowOptions {
+	return TokenFlowOptions{
+		MintEventTypes:     []string{"coinbase"},
+		BurnEventTypes:     []string{"burn"},
+		TransferEventTypes: []string{"transfer"},
+	}
+}
+
+// TokenFlowCollector collects the total minted, burned and transferred amounts per denom
+type TokenFlowCollector struct {
+	db                     *sql.DB
+	chainID                string
+	opts                   TokenFlowOptions
+	eventTypes             string
+	denomsFilter           string
+	totalMintedAmount      *prometheus.Desc
+	totalBurnedAmount      *prometheus.Desc
+	totalTransferredAmount *prometheus.Desc
+}
+
+func NewTokenFlowCollector(db *sql.DB, chainID string, opts TokenFlowOptions) *TokenFlowCollector {
+	var eventTypes []string
+	for _, t := range slices.Concat(opts.MintEventTypes, opts.BurnEventTypes, opts.TransferEventTypes) {
+		if !slices.Contains(eventTypes, t) {
+			eventTypes = append(eventTypes, t)
+		}
+	}
+
+	return &TokenFlowCollector{
+		db:           db,
+		chainID:      chainID,
+		opts:         opts,
+		eventTypes:   strings.Join(eventTypes, ","),
+		denomsFilter: strings.Join(opts.Denoms, ","),
+		totalMintedAmount: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "tokenomics", "minted_amount"),
+			"Total amount minted per denom",
+			[]string{"denom"},
+			prometheus.Labels{"source": "postgres"},
+		),
+		totalBurnedAmount: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "tokenomics", "burned_amount"),
+			"Total amount burned per denom",
+			[]string{"denom"},
+			prometheus.Labels{"source": "postgres"},
+		),
+		totalTransferredAmount: prometheus.NewDesc(
+			prometheus.BuildFQName("yaci", "tokenomics", "transferred_amount"),
+			"Total amount transferred per denom",
+			[]string{"denom"},
+			prometheus.Labels{"source": "postgres"},
+		),
+	}
+}
+
+func (c *TokenFlowCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.totalMintedAmount
+	ch <- c.totalBurnedAmount
+	ch <- c.totalTransferredAmount
+}
+
+func (c *TokenFlowCollector) Collect(ch chan<- prometheus.Metric) {
+	rows, err := c.db.Query(TokenFlowQuery, c.eventTypes, c.denomsFilter, c.chainID)
+	if err != nil {
+		slog.Error("Failed to query token flows", "error", err)
+		c.invalidate(ch, err)
+		return
+	}
+	defer rows.Close()
+
+	// An event type can be configured for several flows, and several event types for the same flow
+	totals := map[*prometheus.Desc]map[string]float64{
+		c.totalMintedAmount:      {},
+		c.totalBurnedAmount:      {},
+		c.totalTransferredAmount: {},
+	}
+	for rows.Next() {
+		var eventType, denom, amountStr string
+		if err := rows.Scan(&eventType, &denom, &amountStr); err != nil {
+			slog.Error("Failed to scan token flow", "error", err)
+			c.invalidate(ch, err)
+			return
+		}
+
+		amount, err := strconv.ParseFloat(amountStr, 64)
+		if err != nil {
+			slog.Error("Failed to parse token flow amount", "amount", amountStr, "error", err)
+			c.invalidate(ch, err)
+			return
+		}
+
+		if slices.Contains(c.opts.MintEventTypes, eventType) {
+			totals[c.totalMintedAmount][denom] += amount
+		}
+		if slices.Contains(c.opts.BurnEventTypes, eventType) {
+			totals[c.totalBurnedAmount][denom] += amount
+		}
+		if slices.Contains(c.opts.TransferEventTypes, eventType) {
+			totals[c.totalTransferredAmount][denom] += amount
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		slog.Error("Failed to process token flows", "error", err)
+		c.invalidate(ch, err)
+		return
+	}
+
+	for desc, amounts := range totals {
+		for denom, amount := range amounts {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, amount, denom)
+		}
+	}
+}
+
+func (c *TokenFlowCollector) invalidate(ch chan<- prometheus.Metric, err error) {
+	ch <- prometheus.NewInvalidMetric(c.totalMintedAmount, err)
+	ch <- prometheus.NewInvalidMetric(c.totalBurnedAmount, err)
+	ch <- prometheus.NewInvalidMetric(c.totalTransferredAmount, err)
+}
+
+func init() {
+	RegisterCollectorFactory(func(db *sql.DB, extraParams ...interface{}) (prometheus.Collector, error) {
+		opts, ok := FindParam[TokenFlowOptions](extraParams)
+		if !ok {
+			opts = DefaultTokenFlowOptions()
+		}
+		chainID, _ := FindParam[ChainID](extraParams)
+		return NewTokenFlowCollector(db, string(chainID), opts), nil
+	})
+}
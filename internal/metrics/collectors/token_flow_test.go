@@ -0,0 +1,75 @@
+package collectors_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/metrics/collectors"
+)
+
+func TestTokenFlowCollector(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	opts := collectors.TokenFlowOptions{
+		Denoms:             []string{"umfx", "upwr"},
+		MintEventTypes:     []string{"coinbase", "tf_mint"},
+		BurnEventTypes:     []string{"burn"},
+		TransferEventTypes: []string{"transfer"},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(collectors.TokenFlowQuery)).
+		WithArgs("coinbase,tf_mint,burn,transfer", "umfx,upwr", "manifest-1").
+		WillReturnRows(sqlmock.NewRows([]string{"event_type", "denom", "amount"}).
+			AddRow("coinbase", "umfx", "100").
+			AddRow("tf_mint", "umfx", "23").
+			AddRow("burn", "upwr", "12").
+			AddRow("transfer", "umfx", "5000"))
+
+	c := collectors.NewTokenFlowCollector(db, "manifest-1", opts)
+	expected := `
+# HELP yaci_tokenomics_burned_amount Total amount burned per denom
+# TYPE yaci_tokenomics_burned_amount counter
+yaci_tokenomics_burned_amount{denom="upwr",source="postgres"} 12
+# HELP yaci_tokenomics_minted_amount Total amount minted per denom
+# TYPE yaci_tokenomics_minted_amount counter
+yaci_tokenomics_minted_amount{denom="umfx",source="postgres"} 123
+# HELP yaci_tokenomics_transferred_amount Total amount transferred per denom
+# TYPE yaci_tokenomics_transferred_amount counter
+yaci_tokenomics_transferred_amount{denom="umfx",source="postgres"} 5000
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenFlowQueryCountsBlockCoins(t *testing.T) {
+	// The coins of the block events, without transaction, are not dropped by the join with the transactions
+	query := strings.Join(strings.Fields(collectors.TokenFlowQuery), " ")
+	require.Contains(t, query, "LEFT JOIN transactions_main t ON t.id = c.id")
+	require.Contains(t, query, "(c.id IS NULL OR t.error IS NULL)")
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// The begin blocker mints the inflation with a block level `coinbase` event
+	mock.ExpectQuery(regexp.QuoteMeta(collectors.TokenFlowQuery)).
+		WithArgs("coinbase,burn,transfer", "", "manifest-1").
+		WillReturnRows(sqlmock.NewRows([]string{"event_type", "denom", "amount"}).
+			AddRow("coinbase", "umfx", "7000"))
+
+	c := collectors.NewTokenFlowCollector(db, "manifest-1", collectors.DefaultTokenFlowOptions())
+	expected := `
+# HELP yaci_tokenomics_minted_amount Total amount minted per denom
+# TYPE yaci_tokenomics_minted_amount counter
+yaci_tokenomics_minted_amount{denom="umfx",source="postgres"} 7000
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "yaci_tokenomics_minted_amount"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
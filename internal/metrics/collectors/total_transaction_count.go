@@ -6,18 +6,20 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-const TotalTransactionCountQuery = `SELECT COUNT(*) FROM api.transactions_main`
+const TotalTransactionCountQuery = `SELECT COUNT(*) FROM transactions_main WHERE chain_id = $1`
 
 // TotalTransactionCountCollector is a EnablePrometheus collector that collects the total number of transactions
 // Nested messages, which are messages that are sent within other messages, are not counted
 type TotalTransactionCountCollector struct {
 	db           *sql.DB
+	chainID      string
 	totalTxCount *prometheus.Desc
 }
 
-func NewTotalTransactionCountCollector(db *sql.DB) *TotalTransactionCountCollector {
+func NewTotalTransactionCountCollector(db *sql.DB, chainID string) *TotalTransactionCountCollector {
 	return &TotalTransactionCountCollector{
-		db: db,
+		db:      db,
+		chainID: chainID,
 		totalTxCount: prometheus.NewDesc(
 			prometheus.BuildFQName("yaci", "transactions", "total_count"),
 			"Total transaction count",
@@ -33,7 +35,7 @@ func (c *TotalTransactionCountCollector) Describe(ch chan<- *prometheus.Desc) {
 
 func (c *TotalTransactionCountCollector) Collect(ch chan<- prometheus.Metric) {
 	var count int64
-	err := c.db.QueryRow(TotalTransactionCountQuery).Scan(&count)
+	err := c.db.QueryRow(TotalTransactionCountQuery, c.chainID).Scan(&count)
 	if err != nil {
 		ch <- prometheus.NewInvalidMetric(c.totalTxCount, err)
 		return
@@ -44,6 +46,7 @@ func (c *TotalTransactionCountCollector) Collect(ch chan<- prometheus.Metric) {
 
 func init() {
 	RegisterCollectorFactory(func(db *sql.DB, extraParams ...interface{}) (prometheus.Collector, error) {
-		return NewTotalTransactionCountCollector(db), nil
+		chainID, _ := FindParam[ChainID](extraParams)
+		return NewTotalTransactionCountCollector(db, string(chainID)), nil
 	})
 }
@@ -10,13 +10,15 @@ import (
 const TotalUniqueAddressesQuery = `
 		WITH all_addresses AS (
 			SELECT sender AS address
-			FROM api.messages_main
+			FROM messages_main mm
+			JOIN transactions_main t ON t.id = mm.id AND t.chain_id = $3
 			WHERE sender LIKE $1
 			
 			UNION
 			
 			SELECT unnested_address AS address
-			FROM api.messages_main
+			FROM messages_main mm
+			JOIN transactions_main t ON t.id = mm.id AND t.chain_id = $3
 			CROSS JOIN LATERAL unnest(mentions) AS m(unnested_address)
 			WHERE unnested_address LIKE $1
 		),
@@ -35,9 +37,10 @@ type TotalUniqueAddressesCollector struct {
 	totalUniqueUserAddresses  *prometheus.Desc
 	totalUniqueGroupAddresses *prometheus.Desc
 	bech32Prefix              string
+	chainID                   string
 }
 
-func NewTotalUniqueAddressesCollector(db *sql.DB, bech32Prefix string) *TotalUniqueAddressesCollector {
+func NewTotalUniqueAddressesCollector(db *sql.DB, bech32Prefix, chainID string) *TotalUniqueAddressesCollector {
 	return &TotalUniqueAddressesCollector{
 		db: db,
 		totalUniqueUserAddresses: prometheus.NewDesc(
@@ -53,6 +56,7 @@ func NewTotalUniqueAddressesCollector(db *sql.DB, bech32Prefix string) *TotalUni
 			prometheus.Labels{"source": "postgres"},
 		),
 		bech32Prefix: bech32Prefix + "1",
+		chainID:      chainID,
 	}
 }
 
@@ -66,7 +70,7 @@ func (c *TotalUniqueAddressesCollector) Collect(ch chan<- prometheus.Metric) {
 	prefixLen := len(c.bech32Prefix)
 
 	// Single query to get both counts
-	err := c.db.QueryRow(TotalUniqueAddressesQuery, c.bech32Prefix+"%", prefixLen).Scan(&userCount, &groupCount)
+	err := c.db.QueryRow(TotalUniqueAddressesQuery, c.bech32Prefix+"%", prefixLen, c.chainID).Scan(&userCount, &groupCount)
 
 	if err != nil {
 		ch <- prometheus.NewInvalidMetric(c.totalUniqueUserAddresses, err)
@@ -87,6 +91,7 @@ func init() {
 		if !ok {
 			return nil, errors.New("invalid bech32 prefix type")
 		}
-		return NewTotalUniqueAddressesCollector(db, bech32Prefix), nil
+		chainID, _ := FindParam[ChainID](extraParams)
+		return NewTotalUniqueAddressesCollector(db, bech32Prefix, string(chainID)), nil
 	})
 }
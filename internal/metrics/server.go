@@ -14,7 +14,9 @@ import (
 	_ "github.com/manifest-network/yaci/internal/metrics/collectors" // Import all collectors
 )
 
-func CreateMetricsServer(db *sql.DB, bech32Prefix, addr string) (*http.Server, error) {
+// CreateMetricsServer creates the collectors and starts the metrics server.
+// The extra parameters are forwarded to the collector factories after the Bech32 prefix.
+func CreateMetricsServer(db *sql.DB, bech32Prefix, addr string, extraParams ...interface{}) (*http.Server, error) {
 	if db == nil {
 		return nil, errors.New("database connection is nil")
 	}
@@ -37,7 +39,7 @@ func CreateMetricsServer(db *sql.DB, bech32Prefix, addr string) (*http.Server, e
 		return nil, errors.New("invalid port number")
 	}
 
-	allCollectors, err := collectors.DefaultRegistry.CreateCollectors(db, bech32Prefix)
+	allCollectors, err := collectors.DefaultRegistry.CreateCollectors(db, append([]interface{}{bech32Prefix}, extraParams...)...)
 	if err != nil {
 		return nil, err
 	}
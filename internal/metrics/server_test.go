@@ -20,10 +20,20 @@ func TestCreateMetricsServer(t *testing.T) {
 		require.NoError(t, err)
 		defer db.Close()
 
+		// Collectors are gathered concurrently
+		mock.MatchExpectationsInOrder(false)
+		mock.ExpectQuery(regexp.QuoteMeta(collectors.TokenFlowQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"event_type", "denom", "amount"}).AddRow("coinbase", "umfx", "123"))
 		mock.ExpectQuery(regexp.QuoteMeta(collectors.TotalTransactionCountQuery)).
 			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(28))
 		mock.ExpectQuery(regexp.QuoteMeta(collectors.TotalUniqueAddressesQuery)).
 			WillReturnRows(sqlmock.NewRows([]string{"user_count", "group_count"}).AddRow(2, 2))
+		mock.ExpectQuery(regexp.QuoteMeta(collectors.MissedBlocksQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"consensus_address", "operator_address", "moniker", "missed", "current_streak", "longest_streak"}))
+		mock.ExpectQuery(regexp.QuoteMeta(collectors.GasPriceQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"denom", "count", "sum", "p10", "p25", "p50", "p75", "p90"}))
+		mock.ExpectQuery(regexp.QuoteMeta(collectors.BlockGasQuery)).
+			WillReturnRows(sqlmock.NewRows([]string{"count", "sum", "p50", "p90", "p99"}).AddRow(0, 0, 0, 0, 0))
 
 		server, err := metrics.CreateMetricsServer(db, "manifest", "127.0.0.1:2112")
 		require.NoError(t, err)
@@ -1,13 +1,471 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // Block represents a blockchain block.
 type Block struct {
-	ID   uint64
-	Data []byte
+	ID uint64
+	// ChainID is the identifier of the chain of the block, from the block header
+	ChainID string
+	Data    []byte
+	// Hash, LastBlockHash, Time and TxCount are the hash, previous block hash, header time and number of transactions of
+	// the block, set by the normalize package. They are stored apart from the raw data, which may not be stored.
+	Hash          string
+	LastBlockHash string
+	Time          string
+	TxCount       int
+	// Events are the events emitted outside of transactions, e.g., by the end blocker.
+	// They are only set when the block source provides the block results.
+	Events []Event
+	// HasEvents is set when the block source provides the block results, i.e., when Events holds all the block events.
+	HasEvents bool
+	// Governance holds the governance records derived from the block events, set by the normalize package.
+	Governance Governance
+	// BalanceChanges holds the balance changes derived from the block events, set by the normalize package.
+	BalanceChanges []BalanceChange
+	// Staking holds the staking records derived from the block events, set by the normalize package.
+	Staking Staking
+	// Coins holds the coins of the block events, set by the normalize package.
+	Coins []Coin
+	// ValidatorSet is the validator set of the previous block, in the order of the last commit signatures,
+	// as returned by `cosmos.base.tendermint.v1beta1.Service/GetValidatorSetByHeight`.
+	// It is only set when the signatures are indexed.
+	ValidatorSet []json.RawMessage
+	// Commit holds the proposer and the last commit signatures of the block, set by the normalize package.
+	Commit *BlockCommit
 }
 
 // Transaction represents a blockchain transaction.
 type Transaction struct {
 	Hash string
 	Data []byte
+	// Normalized is the parsed transaction, set by the normalize package before the transaction is written.
+	Normalized *NormalizedTransaction
+}
+
+// NormalizedTransaction is a transaction parsed into its messages, events and derived records.
+type NormalizedTransaction struct {
+	Code           uint32
+	Fee            json.RawMessage
+	FeeAmounts     []FeeAmount
+	FeePayer       *string
+	FeeGranter     *string
+	GasWanted      *int64
+	GasUsed        *int64
+	Memo           *string
+	Error          *string
+	Height         int64
+	Timestamp      time.Time
+	ProposalIDs    []string
+	Messages       []Message
+	Events         []Event
+	VestingPeriods []VestingPeriod
+	Governance     Governance
+	IBCPackets     []IBCPacketEvent
+	BalanceChanges []BalanceChange
+	Staking        Staking
+	Wasm           Wasm
+	Coins          []Coin
+}
+
+// FeeAmount is the fee paid by a transaction in a denomination.
+type FeeAmount struct {
+	Denom  string
+	Amount string
+}
+
+// Message is a transaction message. Nested messages, e.g., the messages of an authz MsgExec,
+// reference their parent message and have a path in the message tree.
+type Message struct {
+	Index       int64
+	ParentIndex *int64
+	Path        []int32
+	Data        json.RawMessage
+	Type        *string
+	Sender      *string
+	Mentions    []string
+	Metadata    json.RawMessage
+}
+
+// Event is a transaction event.
+type Event struct {
+	Index      int64
+	Data       json.RawMessage
+	Type       string
+	MsgIndex   *int64
+	Attributes []EventAttribute
+}
+
+// EventAttribute is a key/value attribute of an event.
+type EventAttribute struct {
+	Key   string
+	Value *string
+}
+
+// VestingPeriod is a period of a periodic vesting account, for a single denom.
+// The original vesting of a genesis continuous vesting account is a single period unlocked linearly from StartTime to
+// its end time, and that of a genesis permanent locked account a single Permanent period that is never unlocked.
+type VestingPeriod struct {
+	MessageIndex int64
+	PeriodIndex  int64
+	Address      string
+	Denom        string
+	Amount       string
+	StartTime    *time.Time
+	UnlockTime   time.Time
+	EndTime      time.Time
+	Permanent    bool
+}
+
+// Governance holds the x/gov and x/group proposal records derived from a transaction or from block events.
+type Governance struct {
+	Proposals     []Proposal
+	Votes         []ProposalVote
+	Deposits      []ProposalDeposit
+	StatusChanges []ProposalStatusChange
+	Tallies       []ProposalTally
+}
+
+// Proposal modules
+const (
+	ProposalModuleGov   = "gov"
+	ProposalModuleGroup = "group"
+)
+
+// Proposal is a proposal submitted by a message.
+type Proposal struct {
+	Module             string
+	ProposalID         uint64
+	MessageIndex       int64
+	Proposer           *string
+	GroupPolicyAddress *string
+	Title              *string
+	Summary            *string
+	Metadata           *string
+}
+
+// ProposalVote is a vote cast by a message. Weighted votes have several options.
+type ProposalVote struct {
+	Module       string
+	ProposalID   uint64
+	MessageIndex int64
+	Voter        string
+	Option       string
+	Options      json.RawMessage
+	Metadata     *string
+}
+
+// ProposalDeposit is a deposit on a x/gov proposal, including the initial deposit, for a single denom.
+type ProposalDeposit struct {
+	ProposalID   uint64
+	MessageIndex int64
+	Depositor    string
+	Denom        string
+	Amount       string
+}
+
+// ProposalStatusChange is a proposal status transition.
+// ExecutorResult is the result of the execution of a x/group proposal, if any.
+// VotingEndTime is the end of the voting period of a x/gov proposal entering it, only set when it is queried, i.e., when
+// the outcomes of the gov proposals are queried from a source without the end block events.
+type ProposalStatusChange struct {
+	Module         string
+	ProposalID     uint64
+	Status         string
+	ExecutorResult *string
+	VotingEndTime  *time.Time
+}
+
+// ProposalTally is a tally result of a proposal.
+type ProposalTally struct {
+	Module     string
+	ProposalID uint64
+	Yes        string
+	Abstain    string
+	No         string
+	NoWithVeto string
+}
+
+// IBC packet directions, relative to the indexed chain
+const (
+	IBCDirectionOutgoing = "outgoing"
+	IBCDirectionIncoming = "incoming"
+)
+
+// IBCPacketEvent is an IBC packet lifecycle event: send_packet, recv_packet, write_acknowledgement,
+// acknowledge_packet or timeout_packet. Port and Channel are the end of the channel on the indexed chain;
+// together with Direction and Sequence, they identify the packet.
+type IBCPacketEvent struct {
+	EventIndex       int64
+	MsgIndex         *int64
+	Type             string
+	Direction        string
+	Port             string
+	Channel          string
+	Sequence         uint64
+	SrcPort          string
+	SrcChannel       string
+	DstPort          string
+	DstChannel       string
+	ConnectionID     *string
+	TimeoutHeight    *string
+	TimeoutTimestamp *string
+	Data             json.RawMessage
+	Acknowledgement  json.RawMessage
+	AckSuccess       *bool
+	Transfer         *IBCTransfer
+}
+
+// IBCTransfer is the ICS-20 fungible token transfer carried by a packet.
+// Denom is the denomination in the packet; LocalDenom is the denomination on the indexed chain,
+// i.e., the base denomination or an `ibc/` hash, traced back to BaseDenom through DenomPath.
+type IBCTransfer struct {
+	Sender     string
+	Receiver   string
+	Denom      string
+	Amount     string
+	Memo       *string
+	BaseDenom  string
+	DenomPath  string
+	LocalDenom string
+}
+
+// BalanceChange is a change of the balance of an address in a denomination, from a coin_spent or coin_received event.
+// Amount is an integer, negative for spent coins.
+type BalanceChange struct {
+	EventIndex int64
+	Address    string
+	Denom      string
+	Amount     string
+}
+
+// Staking holds the x/staking and x/distribution records derived from a transaction or from block events.
+type Staking struct {
+	Delegations []DelegationChange
+	Rewards     []StakingReward
+}
+
+// Delegation change actions, named after their event
+const (
+	DelegationActionDelegate             = "delegate"
+	DelegationActionUnbond               = "unbond"
+	DelegationActionRedelegate           = "redelegate"
+	DelegationActionCancelUnbonding      = "cancel_unbonding_delegation"
+	DelegationActionCompleteUnbonding    = "complete_unbonding"
+	DelegationActionCompleteRedelegation = "complete_redelegation"
+)
+
+// DelegationChange is a change of a delegation. For redelegations, Validator is the destination validator.
+type DelegationChange struct {
+	EventIndex     int64
+	MsgIndex       *int64
+	Action         string
+	Delegator      string
+	Validator      string
+	SrcValidator   *string
+	Denom          string
+	Amount         string
+	CompletionTime *time.Time
+	CreationHeight *int64
+}
+
+// Staking reward kinds
+const (
+	StakingRewardDelegator  = "reward"
+	StakingRewardCommission = "commission"
+)
+
+// StakingReward is a withdrawal of delegator rewards or of validator commission, in a denomination.
+// Delegator is nil for commissions.
+type StakingReward struct {
+	EventIndex int64
+	MsgIndex   *int64
+	Kind       string
+	Delegator  *string
+	Validator  string
+	Denom      string
+	Amount     string
+}
+
+// ValidatorSnapshot is the validator set at a height, as returned by `cosmos.staking.v1beta1.Query/Validators`.
+type ValidatorSnapshot struct {
+	ChainID    string
+	Height     uint64
+	Time       time.Time
+	Validators []json.RawMessage
+}
+
+// The sources of the coins
+const (
+	CoinSourceEvent   = "event"
+	CoinSourceMessage = "message"
+)
+
+// Coin is an amount of a denomination found in an event attribute or a message field.
+// Type is the event or message type, and Key the attribute key or the dot-separated path of the message field.
+// MsgIndex is the index of the message, or the message index of the event.
+type Coin struct {
+	Source     string
+	EventIndex *int64
+	MsgIndex   *int64
+	Type       string
+	Key        string
+	Denom      string
+	Amount     string
+}
+
+// BlockIDFlagAbsent is the block ID flag of the validators that did not sign a block
+const BlockIDFlagAbsent = "BLOCK_ID_FLAG_ABSENT"
+
+// BlockCommit is the proposer of a block and the signatures of the previous block found in its last commit.
+// The addresses are the upper case hex consensus addresses.
+type BlockCommit struct {
+	ProposerAddress string
+	Time            time.Time
+	// Height is the height of the signed block, i.e., the height of the block minus one.
+	Height     int64
+	Signatures []CommitSignature
+}
+
+// CommitSignature is the vote of a validator in a commit.
+// The signatures are only set when the validator set of the signed block is known.
+type CommitSignature struct {
+	ValidatorIndex   int
+	ConsensusAddress string
+	BlockIDFlag      string
+	VotingPower      *int64
+	Timestamp        *time.Time
+}
+
+// ValidatorConsensusAddress maps the consensus address of a validator to its operator address.
+type ValidatorConsensusAddress struct {
+	ConsensusAddress string
+	OperatorAddress  string
+	Moniker          *string
+	ConsensusPubkey  json.RawMessage
+}
+
+// Wasm holds the CosmWasm records derived from a transaction.
+type Wasm struct {
+	Codes      []WasmCode
+	Contracts  []WasmContract
+	Executions []WasmExecution
+	Events     []WasmEvent
+}
+
+// WasmCode is a code stored by a MsgStoreCode message.
+type WasmCode struct {
+	MessageIndex int64
+	CodeID       uint64
+	Creator      *string
+	Checksum     *string
+}
+
+// WasmContract is a contract instantiated by a MsgInstantiateContract or MsgInstantiateContract2 message.
+// MessageIndex is nil for the contracts instantiated by other contracts. Msg is the decoded instantiation message.
+type WasmContract struct {
+	MessageIndex *int64
+	Address      string
+	CodeID       uint64
+	Creator      *string
+	Admin        *string
+	Label        *string
+	Msg          json.RawMessage
+	Funds        json.RawMessage
+}
+
+// Wasm execution kinds
+const (
+	WasmExecutionExecute = "execute"
+	WasmExecutionMigrate = "migrate"
+)
+
+// WasmExecution is a MsgExecuteContract or MsgMigrateContract message.
+// Msg is the decoded contract message and Action its top level key, e.g., `transfer` for `{"transfer": {...}}`.
+// CodeID is the new code of migrated contracts.
+type WasmExecution struct {
+	MessageIndex int64
+	Kind         string
+	Contract     string
+	Sender       *string
+	Action       *string
+	Msg          json.RawMessage
+	Funds        json.RawMessage
+	CodeID       *uint64
+}
+
+// WasmEvent is an event emitted by a contract, i.e., a `wasm` or `wasm-*` event.
+// Action is the `action` attribute, or the custom event type suffix. Attributes maps the attribute keys
+// to their values, without the contract address.
+type WasmEvent struct {
+	EventIndex int64
+	MsgIndex   *int64
+	Contract   string
+	Type       string
+	Action     *string
+	Attributes json.RawMessage
+}
+
+// WasmContractSnapshot is the information of the contracts at a height, as returned by `cosmwasm.wasm.v1.Query/ContractInfo`.
+type WasmContractSnapshot struct {
+	ChainID   string
+	Height    uint64
+	Time      time.Time
+	Contracts []json.RawMessage
+}
+
+// DefaultSchema is the default PostgreSQL schema of the indexed data
+const DefaultSchema = "api"
+
+// DefaultAnonRole is the default PostgreSQL role granted the read access of the anonymous web clients, e.g., PostgREST
+const DefaultAnonRole = "web_anon"
+
+// BlockNotificationChannel returns the PostgreSQL channel on which a BlockNotification is sent after a block is written to a schema.
+// The channels of the schemas other than the default one are suffixed with the schema name, as the channels are shared by the database.
+func BlockNotificationChannel(schema string) string {
+	if schema == DefaultSchema {
+		return "yaci_blocks"
+	}
+	return "yaci_blocks_" + schema
+}
+
+// BlockNotification is the payload of the notification sent after a block and its transactions are written.
+// The transaction hashes are omitted when they do not fit in a notification payload; Truncated is then true.
+type BlockNotification struct {
+	ChainID   string   `json:"chain_id"`
+	Height    uint64   `json:"height"`
+	TxHashes  []string `json:"tx_hashes"`
+	Truncated bool     `json:"truncated,omitempty"`
+}
+
+// Genesis is the genesis state of a chain, i.e., its state at height 0, before the first block.
+type Genesis struct {
+	ChainID       string
+	GenesisTime   time.Time
+	InitialHeight int64
+	Accounts      []GenesisAccount
+	Balances      []GenesisBalance
+	// VestingPeriods are the periods of the periodic and delayed vesting accounts; their message index is not set.
+	VestingPeriods []VestingPeriod
+	// Validators are the validators of the staking state and of the genesis transactions, as returned by
+	// `cosmos.staking.v1beta1.Query/Validators`.
+	Validators []json.RawMessage
+}
+
+// GenesisAccount is an account of the auth state of a genesis.
+type GenesisAccount struct {
+	Address       string
+	Type          string
+	AccountNumber *int64
+	Data          json.RawMessage
+}
+
+// GenesisBalance is the balance of an address in a denomination in the bank state of a genesis.
+type GenesisBalance struct {
+	Address string
+	Denom   string
+	Amount  string
 }
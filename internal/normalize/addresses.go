@@ -0,0 +1,75 @@
+package normalize
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// addressRegex is a rough bech32-like pattern: 2-83 chars of [a-z0-9], plus '1', plus 38+ chars of the bech32 charset.
+// Trailing chars are allowed because some addresses can be longer, e.g., valoper style addresses.
+var addressRegex = regexp.MustCompile(`^[a-z0-9]{2,83}1[qpzry9x8gf2tvdw0s3jn54khce6mua7l]{38,}$`)
+
+// ExtractAddresses returns the sorted, distinct bech32-like addresses found in a JSON value, or nil if there is none.
+// Addresses are searched in the keys and string values, delimited by quotes or whitespace.
+func ExtractAddresses(raw json.RawMessage) []string {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil
+	}
+
+	found := make(map[string]struct{})
+	collectAddresses(v, found)
+	if len(found) == 0 {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(found))
+	for addr := range found {
+		addresses = append(addresses, addr)
+	}
+	slices.Sort(addresses)
+	return addresses
+}
+
+func collectAddresses(v interface{}, found map[string]struct{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			collectStringAddresses(key, found)
+			collectAddresses(value, found)
+		}
+	case []interface{}:
+		for _, value := range v {
+			collectAddresses(value, found)
+		}
+	case string:
+		collectStringAddresses(v, found)
+	}
+}
+
+func collectStringAddresses(s string, found map[string]struct{}) {
+	tokens := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '"' || r == '\'' || unicode.IsSpace(r)
+	})
+	for _, token := range tokens {
+		if addressRegex.MatchString(token) {
+			found[token] = struct{}{}
+		}
+	}
+}
+
+// mergeAddresses returns the sorted, distinct union of address lists, or nil if there is none
+func mergeAddresses(lists ...[]string) []string {
+	var merged []string
+	for _, list := range lists {
+		merged = append(merged, list...)
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
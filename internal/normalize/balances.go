@@ -0,0 +1,77 @@
+package normalize
+
+import (
+	"strings"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// balanceChanges returns the balance changes recorded by the coin_spent and coin_received events.
+// The transfer events are only used when there is none, i.e., before Cosmos SDK v0.44, as they
+// duplicate the coin_spent and coin_received events otherwise.
+// The balance changes of the begin and end blockers are only recorded from the block events, so the ledger of the
+// blocks whose source does not provide them, i.e., a gRPC server, is incomplete; see models.Block.HasEvents.
+func balanceChanges(events []models.Event) []models.BalanceChange {
+	var changes []models.BalanceChange
+	for _, event := range events {
+		attrs := eventAttributes(event)
+		switch event.Type {
+		case "coin_spent":
+			changes = appendBalanceChanges(changes, event.Index, attrs["spender"], attrs["amount"], "-")
+		case "coin_received":
+			changes = appendBalanceChanges(changes, event.Index, attrs["receiver"], attrs["amount"], "")
+		}
+	}
+	if changes != nil {
+		return changes
+	}
+
+	for _, event := range events {
+		if event.Type != "transfer" {
+			continue
+		}
+		attrs := eventAttributes(event)
+		changes = appendBalanceChanges(changes, event.Index, attrs["sender"], attrs["amount"], "-")
+		changes = appendBalanceChanges(changes, event.Index, attrs["recipient"], attrs["amount"], "")
+	}
+	return changes
+}
+
+func appendBalanceChanges(changes []models.BalanceChange, eventIndex int64, address, amount, sign string) []models.BalanceChange {
+	if address == "" {
+		return changes
+	}
+	for _, coin := range parseCoins(amount) {
+		changes = append(changes, models.BalanceChange{
+			EventIndex: eventIndex,
+			Address:    address,
+			Denom:      coin.denom,
+			Amount:     sign + coin.amount,
+		})
+	}
+	return changes
+}
+
+type coin struct {
+	amount string
+	denom  string
+}
+
+// parseCoins parses a coins string, e.g., `10umfx,5ibc/27394FB0...`, skipping the invalid coins
+func parseCoins(s string) []coin {
+	var coins []coin
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		i := strings.IndexFunc(c, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			continue
+		}
+		amount := strings.TrimLeft(c[:i], "0")
+		if amount == "" {
+			// Zero amount
+			continue
+		}
+		coins = append(coins, coin{amount: amount, denom: c[i:]})
+	}
+	return coins
+}
@@ -0,0 +1,46 @@
+package normalize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+func TestBalanceChanges(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(`{
+	  "tx": {"body": {"messages": [{"@type": "/cosmos.bank.v1beta1.MsgSend", "fromAddress": "` + alice + `", "toAddress": "` + bob + `"}]}},
+	  "txResponse": {"height": "7", "timestamp": "2024-05-01T12:00:00Z", "code": 5, "events": [
+	    {"type": "coin_spent", "attributes": [{"key": "spender", "value": "` + alice + `"}, {"key": "amount", "value": "10umfx,0uatom,5ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2"}]},
+	    {"type": "coin_received", "attributes": [{"key": "receiver", "value": "` + bob + `"}, {"key": "amount", "value": "10umfx"}]},
+	    {"type": "transfer", "attributes": [{"key": "recipient", "value": "` + bob + `"}, {"key": "sender", "value": "` + alice + `"}, {"key": "amount", "value": "10umfx"}]}
+	  ]}
+	}`))
+	require.NoError(t, err)
+
+	// The fees are paid by failed transactions too; the transfer event duplicates the other events
+	require.Equal(t, []models.BalanceChange{
+		{EventIndex: 0, Address: alice, Denom: "umfx", Amount: "-10"},
+		{EventIndex: 0, Address: alice, Denom: "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2", Amount: "-5"},
+		{EventIndex: 1, Address: bob, Denom: "umfx", Amount: "10"},
+	}, tx.BalanceChanges)
+}
+
+func TestLegacyTransferBalanceChanges(t *testing.T) {
+	value := func(s string) *string { return &s }
+	block := &models.Block{Events: []models.Event{
+		{Index: 3, Type: "transfer", Attributes: []models.EventAttribute{
+			{Key: "recipient", Value: value(bob)},
+			{Key: "sender", Value: value(alice)},
+			{Key: "amount", Value: value("42umfx")},
+		}},
+	}}
+	normalize.Block(block)
+
+	require.Equal(t, []models.BalanceChange{
+		{EventIndex: 3, Address: alice, Denom: "umfx", Amount: "-42"},
+		{EventIndex: 3, Address: bob, Denom: "umfx", Amount: "42"},
+	}, block.BalanceChanges)
+}
@@ -0,0 +1,111 @@
+package normalize
+
+import (
+	"encoding/json"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// coinRegex matches a coin, i.e., an integer or decimal amount followed by a Cosmos SDK denomination
+var coinRegex = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([a-zA-Z][a-zA-Z0-9/:._-]{2,127})$`)
+
+// amountRegex matches the amount of a coin object
+var amountRegex = regexp.MustCompile(`^[0-9]+(?:\.[0-9]+)?$`)
+
+// nestedMessageKeys are the message keys holding the nested messages, which are indexed on their own
+var nestedMessageKeys = []string{"messages", "msgs", "content"}
+
+// coins returns the coins of the coin-valued event attributes and message fields.
+// An attribute is coin-valued when its whole value is a comma-separated list of coins, e.g., `10umfx,5uatom`;
+// a message field when it is a `{"denom", "amount"}` object or a list of them.
+func coins(messages []models.Message, events []models.Event) []models.Coin {
+	var result []models.Coin
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			if attr.Value == nil {
+				continue
+			}
+			for _, c := range parseCoinList(*attr.Value) {
+				result = append(result, models.Coin{
+					Source:     models.CoinSourceEvent,
+					EventIndex: ptr(event.Index),
+					MsgIndex:   event.MsgIndex,
+					Type:       event.Type,
+					Key:        attr.Key,
+					Denom:      c.denom,
+					Amount:     c.amount,
+				})
+			}
+		}
+	}
+
+	for _, m := range messages {
+		var data map[string]any
+		if err := json.Unmarshal(m.Data, &data); err != nil {
+			continue
+		}
+		msgType, _ := data["@type"].(string)
+		if len(NestedMessages(m.Data)) > 0 {
+			for _, key := range nestedMessageKeys {
+				delete(data, key)
+			}
+		}
+		walkCoins(data, "", func(key string, c coin) {
+			result = append(result, models.Coin{
+				Source:   models.CoinSourceMessage,
+				MsgIndex: ptr(m.Index),
+				Type:     msgType,
+				Key:      key,
+				Denom:    c.denom,
+				Amount:   c.amount,
+			})
+		})
+	}
+	return result
+}
+
+// BlockCoins returns the coins of the coin-valued attributes of the events emitted outside of transactions
+func BlockCoins(events []models.Event) []models.Coin {
+	return coins(nil, events)
+}
+
+// parseCoinList parses a comma-separated list of coins, returning nil unless every item is a coin.
+// Unlike parseCoins, zero amounts are kept.
+func parseCoinList(s string) []coin {
+	if s == "" {
+		return nil
+	}
+	var list []coin
+	for _, item := range strings.Split(s, ",") {
+		match := coinRegex.FindStringSubmatch(item)
+		if match == nil {
+			return nil
+		}
+		list = append(list, coin{amount: match[1], denom: match[2]})
+	}
+	return list
+}
+
+// walkCoins calls found for every coin object of a JSON value, with the dot-separated path of the field holding it
+func walkCoins(v any, path string, found func(key string, c coin)) {
+	switch v := v.(type) {
+	case map[string]any:
+		denom, denomOk := v["denom"].(string)
+		amount, amountOk := v["amount"].(string)
+		if denomOk && amountOk && denom != "" && amountRegex.MatchString(amount) {
+			found(path, coin{amount: amount, denom: denom})
+			return
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			walkCoins(v[key], strings.TrimPrefix(path+"."+key, "."), found)
+		}
+	case []any:
+		for _, item := range v {
+			walkCoins(item, path, found)
+		}
+	}
+}
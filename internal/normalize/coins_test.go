@@ -0,0 +1,44 @@
+package normalize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+const coinsTxJSON = `{
+  "tx": {"body": {"messages": [
+    {"@type": "/cosmos.bank.v1beta1.MsgSend", "fromAddress": "` + alice + `", "toAddress": "` + bob + `", "amount": [{"denom": "umfx", "amount": "10"}, {"denom": "factory/` + alice + `/upwr", "amount": "5"}]},
+    {"@type": "/cosmos.authz.v1beta1.MsgExec", "grantee": "` + bob + `", "msgs": [
+      {"@type": "/cosmos.staking.v1beta1.MsgDelegate", "delegatorAddress": "` + alice + `", "validatorAddress": "` + validator + `", "amount": {"denom": "umfx", "amount": "7"}}
+    ]}
+  ]}},
+  "txResponse": {
+    "height": "42",
+    "timestamp": "2024-05-01T12:00:00Z",
+    "events": [
+      {"type": "tx", "attributes": [{"key": "fee", "value": "2umfx"}, {"key": "fee_payer", "value": "` + alice + `"}]},
+      {"type": "transfer", "attributes": [{"key": "recipient", "value": "` + bob + `"}, {"key": "amount", "value": "10umfx,5factory/` + alice + `/upwr"}, {"key": "msg_index", "value": "0"}]},
+      {"type": "delegate", "attributes": [{"key": "amount", "value": "7"}, {"key": "new_shares", "value": "7.000000000000000000"}, {"key": "msg_index", "value": "1"}]}
+    ]
+  }
+}`
+
+func TestCoins(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(coinsTxJSON))
+	require.NoError(t, err)
+
+	zero, one, two := int64(0), int64(1), int64(2)
+	upwr := "factory/" + alice + "/upwr"
+	require.Equal(t, []models.Coin{
+		{Source: models.CoinSourceEvent, EventIndex: &zero, Type: "tx", Key: "fee", Denom: "umfx", Amount: "2"},
+		{Source: models.CoinSourceEvent, EventIndex: &one, MsgIndex: &zero, Type: "transfer", Key: "amount", Denom: "umfx", Amount: "10"},
+		{Source: models.CoinSourceEvent, EventIndex: &one, MsgIndex: &zero, Type: "transfer", Key: "amount", Denom: upwr, Amount: "5"},
+		{Source: models.CoinSourceMessage, MsgIndex: &zero, Type: "/cosmos.bank.v1beta1.MsgSend", Key: "amount", Denom: "umfx", Amount: "10"},
+		{Source: models.CoinSourceMessage, MsgIndex: &zero, Type: "/cosmos.bank.v1beta1.MsgSend", Key: "amount", Denom: upwr, Amount: "5"},
+		{Source: models.CoinSourceMessage, MsgIndex: &two, Type: "/cosmos.staking.v1beta1.MsgDelegate", Key: "amount", Denom: "umfx", Amount: "7"},
+	}, tx.Coins)
+}
@@ -0,0 +1,113 @@
+package normalize
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// blockCommit returns the proposer and the last commit signatures of a `cosmos.tx.v1beta1.Service.GetBlockWithTxs` response.
+// The consensus addresses of the validators that did not sign are taken from the validator set of the signed block,
+// which lists the validators in the order of the signatures. No signature is returned without the validator set.
+func blockCommit(data []byte, validatorSet []json.RawMessage) *models.BlockCommit {
+	block := parseObject(data).object("block")
+	header := block.object("header")
+	proposer := hexAddress(msgText(header, "proposerAddress"))
+	if proposer == "" {
+		return nil
+	}
+
+	commit := &models.BlockCommit{ProposerAddress: proposer}
+	commit.Time, _ = time.Parse(time.RFC3339Nano, msgText(header, "time"))
+
+	lastCommit := block.object("lastCommit")
+	commit.Height, _ = strconv.ParseInt(msgText(lastCommit, "height"), 10, 64)
+	if commit.Height == 0 || len(validatorSet) == 0 {
+		return commit
+	}
+
+	for i, raw := range lastCommit.array("signatures") {
+		sig := parseObject(raw)
+		signature := models.CommitSignature{
+			ValidatorIndex:   i,
+			ConsensusAddress: hexAddress(msgText(sig, "validatorAddress")),
+			BlockIDFlag:      msgText(sig, "blockIdFlag"),
+		}
+		if i < len(validatorSet) {
+			validator := parseObject(validatorSet[i])
+			if signature.ConsensusAddress == "" {
+				signature.ConsensusAddress = ConsensusAddress(validator["pubKey"])
+			}
+			if power, err := strconv.ParseInt(msgText(validator, "votingPower"), 10, 64); err == nil {
+				signature.VotingPower = &power
+			}
+		}
+		if signature.ConsensusAddress == "" {
+			continue
+		}
+		if signature.BlockIDFlag == "" {
+			signature.BlockIDFlag = models.BlockIDFlagAbsent
+		}
+		if t, err := time.Parse(time.RFC3339Nano, msgText(sig, "timestamp")); err == nil && signature.BlockIDFlag != models.BlockIDFlagAbsent {
+			signature.Timestamp = &t
+		}
+		commit.Signatures = append(commit.Signatures, signature)
+	}
+	return commit
+}
+
+// ValidatorConsensusAddresses returns the consensus addresses of validators,
+// as returned by `cosmos.staking.v1beta1.Query/Validators`
+func ValidatorConsensusAddresses(validators []json.RawMessage) []models.ValidatorConsensusAddress {
+	var addresses []models.ValidatorConsensusAddress
+	for _, raw := range validators {
+		validator := parseObject(raw)
+		operator := msgText(validator, "operatorAddress")
+		consensus := ConsensusAddress(validator["consensusPubkey"])
+		if operator == "" || consensus == "" {
+			continue
+		}
+		addresses = append(addresses, models.ValidatorConsensusAddress{
+			ConsensusAddress: consensus,
+			OperatorAddress:  operator,
+			Moniker:          validator.object("description").nonEmptyText("moniker"),
+			ConsensusPubkey:  validator["consensusPubkey"],
+		})
+	}
+	return addresses
+}
+
+// ConsensusAddress returns the upper case hex consensus address of a public key encoded as a JSON `Any`,
+// or an empty string if the key cannot be decoded
+func ConsensusAddress(pubKey json.RawMessage) string {
+	key := parseObject(pubKey)
+	keyBytes, err := base64.StdEncoding.DecodeString(msgText(key, "key"))
+	if err != nil || len(keyBytes) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256(keyBytes)
+	if msgText(key, "@type") != "/cosmos.crypto.secp256k1.PubKey" {
+		return strings.ToUpper(hex.EncodeToString(sum[:20]))
+	}
+	hasher := ripemd160.New()
+	hasher.Write(sum[:])
+	return strings.ToUpper(hex.EncodeToString(hasher.Sum(nil)))
+}
+
+// hexAddress converts a base64 address to upper case hex
+func hexAddress(address string) string {
+	decoded, err := base64.StdEncoding.DecodeString(address)
+	if err != nil || len(decoded) == 0 {
+		return ""
+	}
+	return strings.ToUpper(hex.EncodeToString(decoded))
+}
@@ -0,0 +1,77 @@
+package normalize_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+const (
+	consensusAddress1 = "72CD6E8422C407FB6D098690F1130B7DED7EC2F7"
+	consensusAddress2 = "75877BB41D393B5FB8455CE60ECD8DDA001D0631"
+	consensusAddress3 = "648AA5C579FB30F38AF744D97D6EC840C7A91277"
+)
+
+const commitBlockJSON = `{
+  "block": {
+    "header": {"height": "42", "time": "2024-05-01T12:00:00Z", "proposerAddress": "cs1uhCLEB/ttCYaQ8RMLfe1+wvc="},
+    "lastCommit": {"height": "41", "round": 1, "signatures": [
+      {"blockIdFlag": "BLOCK_ID_FLAG_COMMIT", "validatorAddress": "cs1uhCLEB/ttCYaQ8RMLfe1+wvc=", "timestamp": "2024-05-01T11:59:59Z", "signature": "c2ln"},
+      {"blockIdFlag": "BLOCK_ID_FLAG_ABSENT", "timestamp": "0001-01-01T00:00:00Z"},
+      {"blockIdFlag": "BLOCK_ID_FLAG_NIL", "validatorAddress": "ZIqlxXn7MPOK90TZfW7IQMepEnc=", "timestamp": "2024-05-01T11:59:58Z", "signature": "c2ln"}
+    ]}
+  }
+}`
+
+func validatorSet() []json.RawMessage {
+	return []json.RawMessage{
+		json.RawMessage(`{"address": "manifestvalcons1a", "pubKey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE="}, "votingPower": "30"}`),
+		json.RawMessage(`{"address": "manifestvalcons1b", "pubKey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI="}, "votingPower": "20"}`),
+		json.RawMessage(`{"address": "manifestvalcons1c", "pubKey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "AwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwM="}, "votingPower": "10"}`),
+	}
+}
+
+func TestBlockCommit(t *testing.T) {
+	block := &models.Block{ID: 42, Data: []byte(commitBlockJSON), ValidatorSet: validatorSet()}
+	normalize.Block(block)
+
+	signed1 := time.Date(2024, 5, 1, 11, 59, 59, 0, time.UTC)
+	signed3 := time.Date(2024, 5, 1, 11, 59, 58, 0, time.UTC)
+	power30, power20, power10 := int64(30), int64(20), int64(10)
+	require.Equal(t, &models.BlockCommit{
+		ProposerAddress: consensusAddress1,
+		Time:            time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Height:          41,
+		Signatures: []models.CommitSignature{
+			{ValidatorIndex: 0, ConsensusAddress: consensusAddress1, BlockIDFlag: "BLOCK_ID_FLAG_COMMIT", VotingPower: &power30, Timestamp: &signed1},
+			{ValidatorIndex: 1, ConsensusAddress: consensusAddress2, BlockIDFlag: models.BlockIDFlagAbsent, VotingPower: &power20},
+			{ValidatorIndex: 2, ConsensusAddress: consensusAddress3, BlockIDFlag: "BLOCK_ID_FLAG_NIL", VotingPower: &power10, Timestamp: &signed3},
+		},
+	}, block.Commit)
+
+	// Without the validator set, only the proposer is known
+	block = &models.Block{ID: 42, Data: []byte(commitBlockJSON)}
+	normalize.Block(block)
+	require.Equal(t, consensusAddress1, block.Commit.ProposerAddress)
+	require.Empty(t, block.Commit.Signatures)
+}
+
+func TestConsensusAddress(t *testing.T) {
+	require.Equal(t, consensusAddress2, normalize.ConsensusAddress(json.RawMessage(`{"@type": "/cosmos.crypto.ed25519.PubKey", "key": "AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI="}`)))
+	require.Equal(t, "B728E200DF4DF182A567E2802830A9F4CFB21CCA", normalize.ConsensusAddress(json.RawMessage(`{"@type": "/cosmos.crypto.secp256k1.PubKey", "key": "AgcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcH"}`)))
+	require.Empty(t, normalize.ConsensusAddress(json.RawMessage(`{"@type": "/cosmos.crypto.ed25519.PubKey"}`)))
+
+	addresses := normalize.ValidatorConsensusAddresses([]json.RawMessage{
+		json.RawMessage(`{"operatorAddress": "` + validator + `", "consensusPubkey": {"@type": "/cosmos.crypto.ed25519.PubKey", "key": "AwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwMDAwM="}, "description": {"moniker": "val"}}`),
+		json.RawMessage(`{"operatorAddress": "` + validator + `"}`),
+	})
+	require.Len(t, addresses, 1)
+	require.Equal(t, consensusAddress3, addresses[0].ConsensusAddress)
+	require.Equal(t, validator, addresses[0].OperatorAddress)
+	require.Equal(t, "val", *addresses[0].Moniker)
+}
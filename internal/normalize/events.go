@@ -0,0 +1,50 @@
+package normalize
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// normalizeEvents returns the events of a transaction with their attributes
+func normalizeEvents(rawEvents []json.RawMessage) ([]models.Event, error) {
+	events := make([]models.Event, 0, len(rawEvents))
+	for i, raw := range rawEvents {
+		ev := parseObject(raw)
+		if ev == nil {
+			return nil, fmt.Errorf("event %d is not a JSON object", i)
+		}
+
+		event := models.Event{Index: int64(i), Data: raw}
+		event.Type, _ = ev.text("type")
+
+		for _, rawAttr := range ev.array("attributes") {
+			attr := parseObject(rawAttr)
+			key, _ := attr.text("key")
+			var value *string
+			if v, ok := attr.text("value"); ok {
+				value = &v
+			}
+			event.Attributes = append(event.Attributes, models.EventAttribute{Key: key, Value: value})
+
+			// The message index is taken from the first `msg_index` attribute
+			if key == "msg_index" && event.MsgIndex == nil && value != nil && *value != "" {
+				msgIndex, err := strconv.ParseInt(*value, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid msg_index %q of event %d: %w", *value, i, err)
+				}
+				event.MsgIndex = &msgIndex
+			}
+		}
+
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// Events returns the events emitted outside of transactions with their attributes, e.g., those of the block results
+func Events(rawEvents []json.RawMessage) ([]models.Event, error) {
+	return normalizeEvents(rawEvents)
+}
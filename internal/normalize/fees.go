@@ -0,0 +1,45 @@
+package normalize
+
+import (
+	"strconv"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// transactionFees sets the fee amounts, fee payer, fee granter and gas of a transaction.
+// The fee payer is the payer set in the fee, the `fee_payer` attribute of the `tx` event (Cosmos SDK v0.47+),
+// or the sender of the first message, i.e., the first signer.
+func transactionFees(tx *models.NormalizedTransaction, fee, txResponse object) {
+	for _, raw := range fee.array("amount") {
+		c := parseObject(raw)
+		denom, amount := msgText(c, "denom"), msgText(c, "amount")
+		if denom == "" || amount == "" {
+			continue
+		}
+		tx.FeeAmounts = append(tx.FeeAmounts, models.FeeAmount{Denom: denom, Amount: amount})
+	}
+
+	tx.FeeGranter = fee.nonEmptyText("granter")
+	tx.FeePayer = fee.nonEmptyText("payer")
+	if tx.FeePayer == nil {
+		for _, event := range tx.Events {
+			if event.Type != "tx" {
+				continue
+			}
+			if payer := eventAttributes(event)["fee_payer"]; payer != "" {
+				tx.FeePayer = &payer
+				break
+			}
+		}
+	}
+	if tx.FeePayer == nil && len(tx.Messages) > 0 {
+		tx.FeePayer = tx.Messages[0].Sender
+	}
+
+	if gasWanted, err := strconv.ParseInt(msgText(txResponse, "gasWanted"), 10, 64); err == nil {
+		tx.GasWanted = &gasWanted
+	}
+	if gasUsed, err := strconv.ParseInt(msgText(txResponse, "gasUsed"), 10, 64); err == nil {
+		tx.GasUsed = &gasUsed
+	}
+}
@@ -0,0 +1,41 @@
+package normalize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+func feeTxJSON(fee, events string) string {
+	return `{
+  "tx": {
+    "body": {"messages": [{"@type": "/cosmos.bank.v1beta1.MsgSend", "fromAddress": "` + alice + `", "toAddress": "` + bob + `", "amount": [{"denom": "umfx", "amount": "1"}]}]},
+    "authInfo": {"fee": ` + fee + `}
+  },
+  "txResponse": {"height": "42", "timestamp": "2024-05-01T12:00:00Z", "code": 5, "gasWanted": "200000", "gasUsed": "81234", "events": ` + events + `}
+}`
+}
+
+func TestFees(t *testing.T) {
+	// Fee paid through a fee grant by the payer of the tx event
+	tx, err := normalize.Transaction([]byte(feeTxJSON(
+		`{"amount": [{"denom": "umfx", "amount": "5000"}, {"denom": "upwr", "amount": "3"}], "gasLimit": "200000", "granter": "`+carol+`"}`,
+		`[{"type": "tx", "attributes": [{"key": "fee", "value": "5000umfx,3upwr"}, {"key": "fee_payer", "value": "`+bob+`"}]}]`,
+	)))
+	require.NoError(t, err)
+	require.Equal(t, []models.FeeAmount{{Denom: "umfx", Amount: "5000"}, {Denom: "upwr", Amount: "3"}}, tx.FeeAmounts)
+	require.Equal(t, bob, *tx.FeePayer)
+	require.Equal(t, carol, *tx.FeeGranter)
+	require.Equal(t, int64(200000), *tx.GasWanted)
+	require.Equal(t, int64(81234), *tx.GasUsed)
+
+	// Without a payer, the fee is paid by the first signer
+	tx, err = normalize.Transaction([]byte(feeTxJSON(`{"amount": [], "gasLimit": "200000"}`, `[]`)))
+	require.NoError(t, err)
+	require.Empty(t, tx.FeeAmounts)
+	require.Equal(t, alice, *tx.FeePayer)
+	require.Nil(t, tx.FeeGranter)
+}
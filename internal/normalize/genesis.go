@@ -0,0 +1,158 @@
+package normalize
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+const (
+	periodicVestingAccount   = "/cosmos.vesting.v1beta1.PeriodicVestingAccount"
+	delayedVestingAccount    = "/cosmos.vesting.v1beta1.DelayedVestingAccount"
+	continuousVestingAccount = "/cosmos.vesting.v1beta1.ContinuousVestingAccount"
+	permanentLockedAccount   = "/cosmos.vesting.v1beta1.PermanentLockedAccount"
+	msgCreateValidator       = "/cosmos.staking.v1beta1.MsgCreateValidator"
+)
+
+// GenesisAccounts returns the accounts of the auth state of a genesis, and the vesting periods of its vesting accounts
+func GenesisAccounts(authState json.RawMessage) ([]models.GenesisAccount, []models.VestingPeriod) {
+	var accounts []models.GenesisAccount
+	var periods []models.VestingPeriod
+	for _, raw := range parseObject(authState).array("accounts") {
+		account := parseObject(raw)
+		// The base account is nested in the vesting and module accounts
+		base := account
+		if vesting := account.object("baseVestingAccount"); vesting != nil {
+			base = vesting.object("baseAccount")
+		} else if baseAccount := account.object("baseAccount"); baseAccount != nil {
+			base = baseAccount
+		}
+		address, ok := base.text("address")
+		if !ok {
+			continue
+		}
+
+		accountType, _ := account.text("@type")
+		genesisAccount := models.GenesisAccount{Address: address, Type: accountType, Data: raw}
+		if numberStr, ok := base.text("accountNumber"); ok {
+			if number, err := strconv.ParseInt(numberStr, 10, 64); err == nil {
+				genesisAccount.AccountNumber = &number
+			}
+		}
+		accounts = append(accounts, genesisAccount)
+		periods = append(periods, genesisVestingPeriods(accountType, address, account)...)
+	}
+	return accounts, periods
+}
+
+// genesisVestingPeriods returns the vesting periods of a vesting account, one per period and denom.
+// The original vesting of the other vesting accounts is a single period: unlocked at once at the end time of a delayed
+// vesting account, linearly from the start time to the end time of a continuous vesting account, and never for a
+// permanent locked account.
+func genesisVestingPeriods(accountType, address string, account object) []models.VestingPeriod {
+	vesting := account.object("baseVestingAccount")
+	var period models.VestingPeriod
+	switch accountType {
+	case periodicVestingAccount:
+		startTimeStr, _ := account.text("startTime")
+		startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
+		if err != nil {
+			return nil
+		}
+		return vestingSchedule(address, startTime, account.array("vestingPeriods"))
+	case delayedVestingAccount, continuousVestingAccount:
+		endTimeStr, _ := vesting.text("endTime")
+		endTime, err := strconv.ParseInt(endTimeStr, 10, 64)
+		if err != nil {
+			return nil
+		}
+		period.UnlockTime = time.Unix(endTime, 0).UTC()
+		period.EndTime = period.UnlockTime
+		if accountType == continuousVestingAccount {
+			startTimeStr, _ := account.text("startTime")
+			startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
+			if err != nil {
+				return nil
+			}
+			start := time.Unix(startTime, 0).UTC()
+			period.StartTime = &start
+		}
+	case permanentLockedAccount:
+		period.Permanent = true
+	default:
+		return nil
+	}
+
+	var periods []models.VestingPeriod
+	denoms, amounts := sumCoins(vesting.array("originalVesting"))
+	for _, denom := range denoms {
+		p := period
+		p.Address = address
+		p.Denom = denom
+		p.Amount = amounts[denom].String()
+		periods = append(periods, p)
+	}
+	return periods
+}
+
+// GenesisBalances returns the balances of the bank state of a genesis, one per address and denom
+func GenesisBalances(bankState json.RawMessage) []models.GenesisBalance {
+	var balances []models.GenesisBalance
+	for _, raw := range parseObject(bankState).array("balances") {
+		balance := parseObject(raw)
+		address, ok := balance.text("address")
+		if !ok {
+			continue
+		}
+		denoms, amounts := sumCoins(balance.array("coins"))
+		for _, denom := range denoms {
+			balances = append(balances, models.GenesisBalance{Address: address, Denom: denom, Amount: amounts[denom].String()})
+		}
+	}
+	return balances
+}
+
+// GenesisValidators returns the validators of the staking state of a genesis and those created by its genesis
+// transactions, as returned by `cosmos.staking.v1beta1.Query/Validators`. The validators of the genesis transactions
+// have no status, which is only set when the genesis is applied.
+func GenesisValidators(stakingState json.RawMessage, genTxs []json.RawMessage) []json.RawMessage {
+	validators := parseObject(stakingState).array("validators")
+	operators := make(map[string]bool, len(validators))
+	for _, raw := range validators {
+		operator, _ := parseObject(raw).text("operatorAddress")
+		operators[operator] = true
+	}
+
+	for _, rawTx := range genTxs {
+		for _, rawMsg := range parseObject(rawTx).object("body").array("messages") {
+			msg := parseObject(rawMsg)
+			if msgType, _ := msg.text("@type"); msgType != msgCreateValidator {
+				continue
+			}
+			operator, ok := msg.text("validatorAddress")
+			if !ok || operators[operator] {
+				continue
+			}
+			operators[operator] = true
+
+			tokens, _ := msg.object("value").text("amount")
+			validator, err := json.Marshal(map[string]any{
+				"operatorAddress":   operator,
+				"consensusPubkey":   msg["pubkey"],
+				"jailed":            false,
+				"tokens":            tokens,
+				"delegatorShares":   tokens,
+				"description":       msg["description"],
+				"commission":        map[string]any{"commissionRates": msg["commission"]},
+				"minSelfDelegation": msg["minSelfDelegation"],
+			})
+			if err != nil {
+				continue
+			}
+			validators = append(validators, validator)
+		}
+	}
+	return validators
+}
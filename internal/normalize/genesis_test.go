@@ -0,0 +1,94 @@
+package normalize_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+func TestGenesisAccounts(t *testing.T) {
+	accounts, periods := normalize.GenesisAccounts([]byte(`{"accounts": [
+	  {"@type": "/cosmos.auth.v1beta1.BaseAccount", "address": "` + alice + `", "accountNumber": "3"},
+	  {"@type": "/cosmos.vesting.v1beta1.PeriodicVestingAccount",
+	   "baseVestingAccount": {"baseAccount": {"address": "` + bob + `", "accountNumber": "4"}, "endTime": "1700000300"},
+	   "startTime": "1700000000",
+	   "vestingPeriods": [
+	     {"length": "100", "amount": [{"denom": "umfx", "amount": "10"}, {"denom": "umfx", "amount": "5"}]},
+	     {"length": "200", "amount": [{"denom": "umfx", "amount": "20"}]}
+	   ]},
+	  {"@type": "/cosmos.vesting.v1beta1.DelayedVestingAccount",
+	   "baseVestingAccount": {"baseAccount": {"address": "carol"}, "originalVesting": [{"denom": "umfx", "amount": "7"}], "endTime": "1800000000"}},
+	  {"@type": "/cosmos.vesting.v1beta1.ContinuousVestingAccount",
+	   "baseVestingAccount": {"baseAccount": {"address": "dave"}, "originalVesting": [{"denom": "umfx", "amount": "8"}], "endTime": "1900000000"},
+	   "startTime": "1850000000"},
+	  {"@type": "/cosmos.vesting.v1beta1.PermanentLockedAccount",
+	   "baseVestingAccount": {"baseAccount": {"address": "erin"}, "originalVesting": [{"denom": "umfx", "amount": "9"}], "endTime": "0"}},
+	  {"@type": "/cosmos.auth.v1beta1.ModuleAccount", "baseAccount": {"address": "module"}, "name": "bonded_tokens_pool"}
+	]}`))
+
+	require.Len(t, accounts, 6)
+	require.Equal(t, alice, accounts[0].Address)
+	require.Equal(t, "/cosmos.auth.v1beta1.BaseAccount", accounts[0].Type)
+	require.Equal(t, int64(3), *accounts[0].AccountNumber)
+	require.Equal(t, bob, accounts[1].Address)
+	require.Equal(t, "carol", accounts[2].Address)
+	require.Nil(t, accounts[2].AccountNumber)
+	require.Equal(t, "dave", accounts[3].Address)
+	require.Equal(t, "erin", accounts[4].Address)
+	require.Equal(t, "module", accounts[5].Address)
+	require.Equal(t, "/cosmos.auth.v1beta1.ModuleAccount", accounts[5].Type)
+
+	unix := func(sec int64) time.Time { return time.Unix(sec, 0).UTC() }
+	start := unix(1850000000)
+	require.Equal(t, []models.VestingPeriod{
+		{PeriodIndex: 0, Address: bob, Denom: "umfx", Amount: "15", UnlockTime: unix(1700000100), EndTime: unix(1700000300)},
+		{PeriodIndex: 1, Address: bob, Denom: "umfx", Amount: "20", UnlockTime: unix(1700000300), EndTime: unix(1700000300)},
+		{PeriodIndex: 0, Address: "carol", Denom: "umfx", Amount: "7", UnlockTime: unix(1800000000), EndTime: unix(1800000000)},
+		{PeriodIndex: 0, Address: "dave", Denom: "umfx", Amount: "8", StartTime: &start, UnlockTime: unix(1900000000), EndTime: unix(1900000000)},
+		{PeriodIndex: 0, Address: "erin", Denom: "umfx", Amount: "9", Permanent: true},
+	}, periods)
+}
+
+func TestGenesisBalances(t *testing.T) {
+	balances := normalize.GenesisBalances([]byte(`{"balances": [
+	  {"address": "` + alice + `", "coins": [{"denom": "uatom", "amount": "1"}, {"denom": "umfx", "amount": "42"}]},
+	  {"coins": [{"denom": "umfx", "amount": "1"}]}
+	]}`))
+	require.Equal(t, []models.GenesisBalance{
+		{Address: alice, Denom: "uatom", Amount: "1"},
+		{Address: alice, Denom: "umfx", Amount: "42"},
+	}, balances)
+}
+
+func TestGenesisValidators(t *testing.T) {
+	pubKey := `{"@type": "/cosmos.crypto.ed25519.PubKey", "key": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="}`
+	genTx := func(operator string) json.RawMessage {
+		return json.RawMessage(`{"body": {"messages": [{"@type": "/cosmos.staking.v1beta1.MsgCreateValidator",
+		  "description": {"moniker": "node"}, "validatorAddress": "` + operator + `", "pubkey": ` + pubKey + `,
+		  "value": {"denom": "umfx", "amount": "100"}}]}}`)
+	}
+
+	validators := normalize.GenesisValidators(
+		[]byte(`{"validators": [{"operatorAddress": "valoper1", "consensusPubkey": `+pubKey+`, "tokens": "5"}]}`),
+		[]json.RawMessage{genTx("valoper1"), genTx("valoper2")},
+	)
+
+	// The validators of the staking state take precedence over the genesis transactions
+	require.Len(t, validators, 2)
+	addresses := normalize.ValidatorConsensusAddresses(validators)
+	require.Len(t, addresses, 2)
+	require.Equal(t, "valoper1", addresses[0].OperatorAddress)
+	require.Equal(t, "valoper2", addresses[1].OperatorAddress)
+	require.Equal(t, "node", *addresses[1].Moniker)
+
+	var validator struct {
+		Tokens string `json:"tokens"`
+	}
+	require.NoError(t, json.Unmarshal(validators[1], &validator))
+	require.Equal(t, "100", validator.Tokens)
+}
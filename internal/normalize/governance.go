@@ -0,0 +1,424 @@
+package normalize
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// Proposal statuses not defined by the modules, for proposals removed from the state
+const (
+	proposalStatusDropped  = "PROPOSAL_STATUS_DROPPED"
+	proposalStatusCanceled = "PROPOSAL_STATUS_CANCELED"
+)
+
+// govStatusVotingPeriod is the status of the gov proposals in their voting period
+const govStatusVotingPeriod = "PROPOSAL_STATUS_VOTING_PERIOD"
+
+// govProposalResults maps the `proposal_result` attribute of the x/gov end block events to the proposal status
+var govProposalResults = map[string]string{
+	"proposal_passed":             "PROPOSAL_STATUS_PASSED",
+	"proposal_rejected":           "PROPOSAL_STATUS_REJECTED",
+	"proposal_failed":             "PROPOSAL_STATUS_FAILED",
+	"proposal_dropped":            proposalStatusDropped,
+	"proposal_canceled":           proposalStatusCanceled,
+	"expedited_proposal_rejected": govStatusVotingPeriod, // Converted to a regular proposal
+}
+
+// proposalSubmitTypes are the messages submitting a proposal; their nested messages are only executed if the proposal passes
+var proposalSubmitTypes = map[string]string{
+	"/cosmos.gov.v1.MsgSubmitProposal":      models.ProposalModuleGov,
+	"/cosmos.gov.v1beta1.MsgSubmitProposal": models.ProposalModuleGov,
+	"/cosmos.group.v1.MsgSubmitProposal":    models.ProposalModuleGroup,
+}
+
+// transactionGovernance returns the governance records of a successful transaction from its messages and events
+func transactionGovernance(messages []models.Message, events []models.Event) models.Governance {
+	var gov models.Governance
+
+	// The proposal IDs of the submitted proposals, by top level message index
+	submitted := map[string]map[int64][]uint64{}
+	for _, event := range events {
+		var module string
+		switch event.Type {
+		case "submit_proposal":
+			module = models.ProposalModuleGov
+		case "cosmos.group.v1.EventSubmitProposal":
+			module = models.ProposalModuleGroup
+		default:
+			continue
+		}
+		id, ok := eventProposalID(event)
+		if !ok || event.MsgIndex == nil {
+			continue
+		}
+		if submitted[module] == nil {
+			submitted[module] = map[int64][]uint64{}
+		}
+		submitted[module][*event.MsgIndex] = append(submitted[module][*event.MsgIndex], id)
+	}
+
+	for _, m := range executedMessages(messages) {
+		if m.Type == nil {
+			continue
+		}
+		msg := parseObject(m.Data)
+
+		if module, ok := proposalSubmitTypes[*m.Type]; ok {
+			ids := submitted[module][int64(m.Path[0])]
+			if len(ids) == 0 {
+				continue
+			}
+			submitted[module][int64(m.Path[0])] = ids[1:]
+			gov.Proposals = append(gov.Proposals, submittedProposal(module, ids[0], m, msg))
+			if module == models.ProposalModuleGov {
+				proposer, _ := msg.text("proposer")
+				gov.Deposits = append(gov.Deposits, proposalDeposits(ids[0], m.Index, proposer, msg.array("initialDeposit"))...)
+			}
+			continue
+		}
+
+		switch *m.Type {
+		case "/cosmos.gov.v1.MsgVote", "/cosmos.gov.v1beta1.MsgVote",
+			"/cosmos.gov.v1.MsgVoteWeighted", "/cosmos.gov.v1beta1.MsgVoteWeighted":
+			gov.Votes = appendVote(gov.Votes, models.ProposalModuleGov, m, msg)
+		case "/cosmos.group.v1.MsgVote":
+			gov.Votes = appendVote(gov.Votes, models.ProposalModuleGroup, m, msg)
+		case "/cosmos.gov.v1.MsgDeposit", "/cosmos.gov.v1beta1.MsgDeposit":
+			if id, ok := proposalID(msg); ok {
+				depositor, _ := msg.text("depositor")
+				gov.Deposits = append(gov.Deposits, proposalDeposits(id, m.Index, depositor, msg.array("amount"))...)
+			}
+		}
+	}
+
+	gov.StatusChanges, gov.Tallies = eventsGovernance(events)
+	return gov
+}
+
+// BlockGovernance returns the governance records of the events emitted outside of transactions, e.g., the end block
+// proposal outcomes and the group tallies of the pruned proposals
+func BlockGovernance(events []models.Event) models.Governance {
+	var gov models.Governance
+	gov.StatusChanges, gov.Tallies = eventsGovernance(events)
+	return gov
+}
+
+// EndedGovProposals returns the gov proposals whose voting period ended with a tally, i.e., that passed, were rejected
+// or failed. Their final tally is not in the end block events, see GovProposalTally.
+func EndedGovProposals(gov models.Governance) []uint64 {
+	var ids []uint64
+	for _, change := range gov.StatusChanges {
+		if change.Module == models.ProposalModuleGov && govTallied(change.Status) {
+			ids = append(ids, change.ProposalID)
+		}
+	}
+	return ids
+}
+
+// govTallied returns whether a gov proposal status is the outcome of a tally
+func govTallied(status string) bool {
+	switch status {
+	case "PROPOSAL_STATUS_PASSED", "PROPOSAL_STATUS_REJECTED", "PROPOSAL_STATUS_FAILED":
+		return true
+	}
+	return false
+}
+
+// VotingEndedGovProposals returns the gov proposals of a `cosmos.gov.v1.Query.Proposals` response whose voting period
+// ends at or before a block time, i.e., the proposals in their voting period before the block that its end blocker
+// tallies
+func VotingEndedGovProposals(proposals []json.RawMessage, blockTime string) []uint64 {
+	end, err := time.Parse(time.RFC3339Nano, blockTime)
+	if err != nil {
+		return nil
+	}
+
+	var ids []uint64
+	for _, raw := range proposals {
+		proposal := parseObject(raw)
+		id, _ := proposal.text("id")
+		proposalID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			continue
+		}
+		votingEndTime, _ := proposal.text("votingEndTime")
+		votingEnd, err := time.Parse(time.RFC3339Nano, votingEndTime)
+		if err != nil || votingEnd.After(end) {
+			continue
+		}
+		ids = append(ids, proposalID)
+	}
+	return ids
+}
+
+// GovProposalStatusChange returns the status of a `cosmos.gov.v1.Query.Proposal` response as a status change, if it is
+// the outcome of a tally, i.e., if the proposal passed, was rejected or failed
+func GovProposalStatusChange(response json.RawMessage) (models.ProposalStatusChange, bool) {
+	proposal := parseObject(response).object("proposal")
+	id, _ := proposal.text("id")
+	proposalID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return models.ProposalStatusChange{}, false
+	}
+	status, _ := proposal.text("status")
+	if !govTallied(status) {
+		return models.ProposalStatusChange{}, false
+	}
+	return models.ProposalStatusChange{Module: models.ProposalModuleGov, ProposalID: proposalID, Status: status}, true
+}
+
+// GovProposalVotingPeriod returns the status of a `cosmos.gov.v1.Query.Proposal` response as a status change with the
+// end of its voting period, if the proposal is in its voting period
+func GovProposalVotingPeriod(response json.RawMessage) (models.ProposalStatusChange, bool) {
+	proposal := parseObject(response).object("proposal")
+	id, _ := proposal.text("id")
+	proposalID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return models.ProposalStatusChange{}, false
+	}
+	status, _ := proposal.text("status")
+	votingEndTime, _ := proposal.text("votingEndTime")
+	votingEnd, err := time.Parse(time.RFC3339Nano, votingEndTime)
+	if status != govStatusVotingPeriod || err != nil {
+		return models.ProposalStatusChange{}, false
+	}
+	return models.ProposalStatusChange{Module: models.ProposalModuleGov, ProposalID: proposalID, Status: status, VotingEndTime: &votingEnd}, true
+}
+
+// GovProposalTally returns the final tally of a `cosmos.gov.v1.Query/Proposal` response
+func GovProposalTally(response json.RawMessage) (models.ProposalTally, bool) {
+	proposal := parseObject(response).object("proposal")
+	id, ok := proposal.text("id")
+	if !ok || proposal["finalTallyResult"] == nil {
+		return models.ProposalTally{}, false
+	}
+	proposalID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return models.ProposalTally{}, false
+	}
+
+	tally, ok := parseTally(string(proposal["finalTallyResult"]))
+	tally.Module, tally.ProposalID = models.ProposalModuleGov, proposalID
+	return tally, ok
+}
+
+// eventsGovernance returns the proposal status changes and tallies found in events
+func eventsGovernance(events []models.Event) ([]models.ProposalStatusChange, []models.ProposalTally) {
+	var changes []models.ProposalStatusChange
+	var tallies []models.ProposalTally
+
+	addChange := func(module string, id uint64, status string, executorResult *string) {
+		changes = append(changes, models.ProposalStatusChange{Module: module, ProposalID: id, Status: status, ExecutorResult: executorResult})
+	}
+
+	for _, event := range events {
+		id, ok := eventProposalID(event)
+		if !ok {
+			continue
+		}
+		attrs := eventAttributes(event)
+
+		switch event.Type {
+		case "submit_proposal":
+			if _, ok := attrs["voting_period_start"]; ok {
+				addChange(models.ProposalModuleGov, id, govStatusVotingPeriod, nil)
+			} else {
+				addChange(models.ProposalModuleGov, id, "PROPOSAL_STATUS_DEPOSIT_PERIOD", nil)
+			}
+		case "proposal_deposit":
+			if _, ok := attrs["voting_period_start"]; ok {
+				addChange(models.ProposalModuleGov, id, govStatusVotingPeriod, nil)
+			}
+		case "cancel_proposal":
+			addChange(models.ProposalModuleGov, id, proposalStatusCanceled, nil)
+		case "active_proposal", "inactive_proposal":
+			if status, ok := govProposalResults[attrs["proposal_result"]]; ok {
+				addChange(models.ProposalModuleGov, id, status, nil)
+			}
+		case "cosmos.group.v1.EventSubmitProposal":
+			addChange(models.ProposalModuleGroup, id, "PROPOSAL_STATUS_SUBMITTED", nil)
+		case "cosmos.group.v1.EventWithdrawProposal":
+			addChange(models.ProposalModuleGroup, id, "PROPOSAL_STATUS_WITHDRAWN", nil)
+		case "cosmos.group.v1.EventExec":
+			// Only accepted proposals are executed
+			if result := unquote(attrs["result"]); result != "" && result != "PROPOSAL_EXECUTOR_RESULT_NOT_RUN" {
+				addChange(models.ProposalModuleGroup, id, "PROPOSAL_STATUS_ACCEPTED", &result)
+			}
+		case "cosmos.group.v1.EventProposalPruned":
+			if status := unquote(attrs["status"]); status != "" {
+				addChange(models.ProposalModuleGroup, id, status, nil)
+			}
+			if tally, ok := parseTally(attrs["tally_result"]); ok {
+				tally.Module, tally.ProposalID = models.ProposalModuleGroup, id
+				tallies = append(tallies, tally)
+			}
+		}
+	}
+
+	return changes, tallies
+}
+
+// executedMessages returns the messages executed by the transaction, i.e., without the messages nested in proposals
+func executedMessages(messages []models.Message) []models.Message {
+	byIndex := make(map[int64]models.Message, len(messages))
+	for _, m := range messages {
+		byIndex[m.Index] = m
+	}
+
+	var executed []models.Message
+	for _, m := range messages {
+		inProposal := false
+		for parent := m.ParentIndex; parent != nil; parent = byIndex[*parent].ParentIndex {
+			if t := byIndex[*parent].Type; t != nil && proposalSubmitTypes[*t] != "" {
+				inProposal = true
+				break
+			}
+		}
+		if !inProposal {
+			executed = append(executed, m)
+		}
+	}
+	return executed
+}
+
+func submittedProposal(module string, id uint64, m models.Message, msg object) models.Proposal {
+	p := models.Proposal{
+		Module:       module,
+		ProposalID:   id,
+		MessageIndex: m.Index,
+		Title:        msg.nonEmptyText("title"),
+		Summary:      msg.nonEmptyText("summary"),
+		Metadata:     msg.nonEmptyText("metadata"),
+	}
+
+	switch module {
+	case models.ProposalModuleGov:
+		p.Proposer = msg.nonEmptyText("proposer")
+		// Legacy proposals carry their title and description in their content
+		if content := msg.object("content"); content != nil {
+			p.Title = content.nonEmptyText("title")
+			p.Summary = content.nonEmptyText("description")
+		}
+	case models.ProposalModuleGroup:
+		p.GroupPolicyAddress = msg.nonEmptyText("groupPolicyAddress")
+		if proposers := msg.array("proposers"); len(proposers) > 0 {
+			if proposer, ok := text(proposers[0]); ok {
+				p.Proposer = &proposer
+			}
+		}
+	}
+
+	return p
+}
+
+func appendVote(votes []models.ProposalVote, module string, m models.Message, msg object) []models.ProposalVote {
+	id, ok := proposalID(msg)
+	if !ok {
+		return votes
+	}
+
+	vote := models.ProposalVote{
+		Module:       module,
+		ProposalID:   id,
+		MessageIndex: m.Index,
+		Metadata:     msg.nonEmptyText("metadata"),
+	}
+	vote.Voter, _ = msg.text("voter")
+
+	if options := msg["options"]; len(options) > 0 {
+		// Weighted vote; the option is the one with the highest weight
+		vote.Options = options
+		best := new(big.Float)
+		for _, rawOption := range parseArray(options) {
+			option := parseObject(rawOption)
+			weightStr, _ := option.text("weight")
+			weight, ok := new(big.Float).SetString(weightStr)
+			if ok && weight.Cmp(best) > 0 {
+				best = weight
+				vote.Option, _ = option.text("option")
+			}
+		}
+	} else {
+		vote.Option, _ = msg.text("option")
+		options, _ := json.Marshal([]map[string]string{{"option": vote.Option, "weight": "1"}})
+		vote.Options = options
+	}
+
+	return append(votes, vote)
+}
+
+func proposalDeposits(id uint64, messageIndex int64, depositor string, coins []json.RawMessage) []models.ProposalDeposit {
+	var deposits []models.ProposalDeposit
+	for _, rawCoin := range coins {
+		coin := parseObject(rawCoin)
+		denom, _ := coin.text("denom")
+		amount, _ := coin.text("amount")
+		if denom == "" || amount == "" {
+			continue
+		}
+		deposits = append(deposits, models.ProposalDeposit{
+			ProposalID:   id,
+			MessageIndex: messageIndex,
+			Depositor:    depositor,
+			Denom:        denom,
+			Amount:       amount,
+		})
+	}
+	return deposits
+}
+
+// parseTally parses a JSON tally result, with either snake case or camel case keys
+func parseTally(raw string) (models.ProposalTally, bool) {
+	tally := parseObject(json.RawMessage(raw))
+	if tally == nil {
+		return models.ProposalTally{}, false
+	}
+
+	count := func(snake, camel string) string {
+		if v, ok := tally.text(snake); ok {
+			return v
+		}
+		if v, ok := tally.text(camel); ok {
+			return v
+		}
+		return "0"
+	}
+	return models.ProposalTally{
+		Yes:        count("yes_count", "yesCount"),
+		Abstain:    count("abstain_count", "abstainCount"),
+		No:         count("no_count", "noCount"),
+		NoWithVeto: count("no_with_veto_count", "noWithVetoCount"),
+	}, true
+}
+
+func proposalID(msg object) (uint64, bool) {
+	idStr, _ := msg.text("proposalId")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	return id, err == nil
+}
+
+func eventProposalID(event models.Event) (uint64, bool) {
+	id, err := strconv.ParseUint(unquote(eventAttributes(event)["proposal_id"]), 10, 64)
+	return id, err == nil
+}
+
+// eventAttributes returns the first value of each event attribute
+func eventAttributes(event models.Event) map[string]string {
+	attrs := make(map[string]string, len(event.Attributes))
+	for _, attr := range event.Attributes {
+		if _, exists := attrs[attr.Key]; !exists && attr.Value != nil {
+			attrs[attr.Key] = *attr.Value
+		}
+	}
+	return attrs
+}
+
+// unquote removes the quotes around the values of typed events
+func unquote(s string) string {
+	return strings.Trim(s, `"`)
+}
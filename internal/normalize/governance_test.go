@@ -0,0 +1,134 @@
+package normalize_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+const govTxJSON = `{
+  "tx": {
+    "body": {
+      "messages": [
+        {
+          "@type": "/cosmos.gov.v1.MsgSubmitProposal",
+          "proposer": "` + alice + `",
+          "title": "Upgrade",
+          "summary": "Upgrade the chain",
+          "initialDeposit": [{"denom": "umfx", "amount": "100"}],
+          "messages": [{"@type": "/cosmos.gov.v1.MsgVote", "proposalId": "99", "voter": "` + bob + `", "option": "VOTE_OPTION_NO"}]
+        },
+        {"@type": "/cosmos.gov.v1.MsgDeposit", "proposalId": "3", "depositor": "` + bob + `", "amount": [{"denom": "umfx", "amount": "50"}]},
+        {
+          "@type": "/cosmos.authz.v1beta1.MsgExec",
+          "grantee": "` + bob + `",
+          "msgs": [{
+            "@type": "/cosmos.gov.v1.MsgVoteWeighted",
+            "proposalId": "3",
+            "voter": "` + alice + `",
+            "options": [{"option": "VOTE_OPTION_YES", "weight": "0.300000000000000000"}, {"option": "VOTE_OPTION_ABSTAIN", "weight": "0.700000000000000000"}]
+          }]
+        },
+        {"@type": "/cosmos.group.v1.MsgVote", "proposalId": "7", "voter": "` + bob + `", "option": "VOTE_OPTION_YES", "exec": "EXEC_TRY"}
+      ]
+    }
+  },
+  "txResponse": {
+    "height": "42",
+    "timestamp": "2024-05-01T12:00:00Z",
+    "events": [
+      {"type": "submit_proposal", "attributes": [{"key": "proposal_id", "value": "4"}, {"key": "msg_index", "value": "0"}]},
+      {"type": "proposal_deposit", "attributes": [{"key": "proposal_id", "value": "3"}, {"key": "voting_period_start", "value": "3"}, {"key": "msg_index", "value": "1"}]},
+      {"type": "cosmos.group.v1.EventExec", "attributes": [{"key": "proposal_id", "value": "\"7\""}, {"key": "result", "value": "\"PROPOSAL_EXECUTOR_RESULT_SUCCESS\""}, {"key": "msg_index", "value": "3"}]},
+      {"type": "cosmos.group.v1.EventProposalPruned", "attributes": [{"key": "proposal_id", "value": "\"7\""}, {"key": "status", "value": "\"PROPOSAL_STATUS_ACCEPTED\""}, {"key": "tally_result", "value": "{\"yes_count\":\"2\",\"no_count\":\"1\",\"abstain_count\":\"0\",\"no_with_veto_count\":\"0\"}"}, {"key": "msg_index", "value": "3"}]}
+    ]
+  }
+}`
+
+func TestTransactionGovernance(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(govTxJSON))
+	require.NoError(t, err)
+	gov := tx.Governance
+
+	require.Len(t, gov.Proposals, 1)
+	require.Equal(t, models.ProposalModuleGov, gov.Proposals[0].Module)
+	require.Equal(t, uint64(4), gov.Proposals[0].ProposalID)
+	require.Equal(t, int64(0), gov.Proposals[0].MessageIndex)
+	require.Equal(t, alice, *gov.Proposals[0].Proposer)
+	require.Equal(t, "Upgrade", *gov.Proposals[0].Title)
+
+	require.Equal(t, []models.ProposalDeposit{
+		{ProposalID: 4, MessageIndex: 0, Depositor: alice, Denom: "umfx", Amount: "100"},
+		{ProposalID: 3, MessageIndex: 1, Depositor: bob, Denom: "umfx", Amount: "50"},
+	}, gov.Deposits)
+
+	// The vote nested in the proposal is not executed by the transaction
+	require.Len(t, gov.Votes, 2)
+	require.Equal(t, models.ProposalModuleGroup, gov.Votes[0].Module)
+	require.Equal(t, "VOTE_OPTION_YES", gov.Votes[0].Option)
+	require.JSONEq(t, `[{"option": "VOTE_OPTION_YES", "weight": "1"}]`, string(gov.Votes[0].Options))
+	require.Equal(t, models.ProposalModuleGov, gov.Votes[1].Module)
+	require.Equal(t, uint64(3), gov.Votes[1].ProposalID)
+	require.Equal(t, alice, gov.Votes[1].Voter)
+	require.Equal(t, "VOTE_OPTION_ABSTAIN", gov.Votes[1].Option)
+
+	success := "PROPOSAL_EXECUTOR_RESULT_SUCCESS"
+	require.Equal(t, []models.ProposalStatusChange{
+		{Module: models.ProposalModuleGov, ProposalID: 4, Status: "PROPOSAL_STATUS_DEPOSIT_PERIOD"},
+		{Module: models.ProposalModuleGov, ProposalID: 3, Status: "PROPOSAL_STATUS_VOTING_PERIOD"},
+		{Module: models.ProposalModuleGroup, ProposalID: 7, Status: "PROPOSAL_STATUS_ACCEPTED", ExecutorResult: &success},
+		{Module: models.ProposalModuleGroup, ProposalID: 7, Status: "PROPOSAL_STATUS_ACCEPTED"},
+	}, gov.StatusChanges)
+
+	require.Equal(t, []models.ProposalTally{
+		{Module: models.ProposalModuleGroup, ProposalID: 7, Yes: "2", Abstain: "0", No: "1", NoWithVeto: "0"},
+	}, gov.Tallies)
+}
+
+func TestBlockGovernance(t *testing.T) {
+	value := func(s string) *string { return &s }
+	gov := normalize.BlockGovernance([]models.Event{
+		{Type: "active_proposal", Attributes: []models.EventAttribute{
+			{Key: "proposal_id", Value: value("4")},
+			{Key: "proposal_result", Value: value("proposal_passed")},
+		}},
+		{Type: "inactive_proposal", Attributes: []models.EventAttribute{
+			{Key: "proposal_id", Value: value("5")},
+			{Key: "proposal_result", Value: value("proposal_dropped")},
+		}},
+		{Type: "coin_spent", Attributes: []models.EventAttribute{{Key: "amount", Value: value("1umfx")}}},
+	})
+
+	require.Equal(t, []models.ProposalStatusChange{
+		{Module: models.ProposalModuleGov, ProposalID: 4, Status: "PROPOSAL_STATUS_PASSED"},
+		{Module: models.ProposalModuleGov, ProposalID: 5, Status: "PROPOSAL_STATUS_DROPPED"},
+	}, gov.StatusChanges)
+	require.Empty(t, gov.Tallies)
+}
+
+func TestFailedTransactionGovernance(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(`{
+	  "tx": {"body": {"messages": [{"@type": "/cosmos.gov.v1.MsgVote", "proposalId": "1", "voter": "` + bob + `", "option": "VOTE_OPTION_YES"}]}},
+	  "txResponse": {"height": "1", "timestamp": "2024-05-01T12:00:00Z", "code": 5, "rawLog": "insufficient funds"}
+	}`))
+	require.NoError(t, err)
+	require.Equal(t, uint32(5), tx.Code)
+	require.Empty(t, tx.Governance.Votes)
+}
+
+func TestGovProposalVotingPeriod(t *testing.T) {
+	change, ok := normalize.GovProposalVotingPeriod([]byte(`{"proposal": {"id": "3", "status": "PROPOSAL_STATUS_VOTING_PERIOD", "votingEndTime": "2024-05-03T12:00:00.5Z"}}`))
+	require.True(t, ok)
+	votingEnd := time.Date(2024, 5, 3, 12, 0, 0, 500000000, time.UTC)
+	require.Equal(t, models.ProposalStatusChange{Module: models.ProposalModuleGov, ProposalID: 3, Status: "PROPOSAL_STATUS_VOTING_PERIOD", VotingEndTime: &votingEnd}, change)
+
+	// The ended proposals and the proposals in their deposit period have no voting end time
+	_, ok = normalize.GovProposalVotingPeriod([]byte(`{"proposal": {"id": "3", "status": "PROPOSAL_STATUS_PASSED", "votingEndTime": "2024-05-03T12:00:00Z"}}`))
+	require.False(t, ok)
+	_, ok = normalize.GovProposalVotingPeriod([]byte(`{"proposal": {"id": "4", "status": "PROPOSAL_STATUS_DEPOSIT_PERIOD"}}`))
+	require.False(t, ok)
+}
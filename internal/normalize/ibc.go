@@ -0,0 +1,196 @@
+package normalize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// ibcPacketDirections maps the IBC packet lifecycle events to the direction of their packet
+var ibcPacketDirections = map[string]string{
+	"send_packet":           models.IBCDirectionOutgoing,
+	"acknowledge_packet":    models.IBCDirectionOutgoing,
+	"timeout_packet":        models.IBCDirectionOutgoing,
+	"recv_packet":           models.IBCDirectionIncoming,
+	"write_acknowledgement": models.IBCDirectionIncoming,
+}
+
+// ibcPackets returns the IBC packet lifecycle events of a successful transaction
+func ibcPackets(events []models.Event) []models.IBCPacketEvent {
+	var packets []models.IBCPacketEvent
+	for i, event := range events {
+		direction, ok := ibcPacketDirections[event.Type]
+		if !ok {
+			continue
+		}
+		attrs := eventAttributes(event)
+		sequence, err := strconv.ParseUint(attrs["packet_sequence"], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		packet := models.IBCPacketEvent{
+			EventIndex:       event.Index,
+			MsgIndex:         event.MsgIndex,
+			Type:             event.Type,
+			Direction:        direction,
+			Sequence:         sequence,
+			SrcPort:          attrs["packet_src_port"],
+			SrcChannel:       attrs["packet_src_channel"],
+			DstPort:          attrs["packet_dst_port"],
+			DstChannel:       attrs["packet_dst_channel"],
+			ConnectionID:     nonEmpty(attrs["connection_id"]),
+			TimeoutHeight:    nonEmpty(attrs["packet_timeout_height"]),
+			TimeoutTimestamp: nonEmpty(attrs["packet_timeout_timestamp"]),
+		}
+		if packet.ConnectionID == nil {
+			// Attribute name before ibc-go v8
+			packet.ConnectionID = nonEmpty(attrs["packet_connection"])
+		}
+		if direction == models.IBCDirectionOutgoing {
+			packet.Port, packet.Channel = packet.SrcPort, packet.SrcChannel
+		} else {
+			packet.Port, packet.Channel = packet.DstPort, packet.DstChannel
+		}
+
+		switch event.Type {
+		case "send_packet", "recv_packet":
+			packet.Data = packetData(attrs["packet_data"], attrs["packet_data_hex"])
+			packet.Transfer = ibcTransfer(packet)
+		case "write_acknowledgement":
+			packet.Acknowledgement = packetData(attrs["packet_ack"], attrs["packet_ack_hex"])
+			packet.AckSuccess = acknowledgementSuccess(packet.Acknowledgement)
+		case "acknowledge_packet":
+			packet.AckSuccess = transferAckSuccess(events[i+1:], event.MsgIndex)
+		}
+
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+// packetData returns the packet data or acknowledgement of an event as JSON.
+// Data that is not JSON is returned as a JSON string; nil is returned if there is no data.
+func packetData(data, dataHex string) json.RawMessage {
+	if data == "" && dataHex != "" {
+		decoded, err := hex.DecodeString(dataHex)
+		if err != nil {
+			return nil
+		}
+		data = string(decoded)
+	}
+	if data == "" {
+		return nil
+	}
+	if json.Valid([]byte(data)) {
+		return json.RawMessage(data)
+	}
+	quoted, _ := json.Marshal(data)
+	return quoted
+}
+
+// acknowledgementSuccess returns whether a standard acknowledgement is a result or an error, or nil for other acknowledgements
+func acknowledgementSuccess(ack json.RawMessage) *bool {
+	obj := parseObject(ack)
+	if _, ok := obj["error"]; ok {
+		return ptr(false)
+	}
+	if _, ok := obj["result"]; ok {
+		return ptr(true)
+	}
+	return nil
+}
+
+// transferAckSuccess returns the outcome of an acknowledged transfer, from the `fungible_token_packet` events
+// emitted by the transfer module after the `acknowledge_packet` event of the same message
+func transferAckSuccess(events []models.Event, msgIndex *int64) *bool {
+	for _, event := range events {
+		if event.Type == "acknowledge_packet" || !sameMsgIndex(event.MsgIndex, msgIndex) {
+			break
+		}
+		if event.Type != "fungible_token_packet" {
+			continue
+		}
+		attrs := eventAttributes(event)
+		if _, ok := attrs["error"]; ok {
+			return ptr(false)
+		}
+		if _, ok := attrs["success"]; ok {
+			return ptr(true)
+		}
+	}
+	return nil
+}
+
+func sameMsgIndex(a, b *int64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// ibcTransfer returns the ICS-20 transfer of a sent or received packet, or nil if the packet is not a transfer
+func ibcTransfer(packet models.IBCPacketEvent) *models.IBCTransfer {
+	data := parseObject(packet.Data)
+	denom, _ := data.text("denom")
+	amount, _ := data.text("amount")
+	if denom == "" || amount == "" {
+		return nil
+	}
+
+	transfer := &models.IBCTransfer{Denom: denom, Amount: amount, Memo: data.nonEmptyText("memo")}
+	transfer.Sender, _ = data.text("sender")
+	transfer.Receiver, _ = data.text("receiver")
+
+	// The denomination on this chain, see the ICS-20 specification
+	fullDenom := denom
+	if packet.Direction == models.IBCDirectionIncoming {
+		if sourcePrefix := packet.SrcPort + "/" + packet.SrcChannel + "/"; strings.HasPrefix(denom, sourcePrefix) {
+			// The token returns to this chain, the counterparty prefix is removed
+			fullDenom = strings.TrimPrefix(denom, sourcePrefix)
+		} else {
+			fullDenom = packet.DstPort + "/" + packet.DstChannel + "/" + denom
+		}
+	}
+	transfer.DenomPath, transfer.BaseDenom = splitDenom(fullDenom)
+	transfer.LocalDenom = ibcDenom(transfer.DenomPath, transfer.BaseDenom)
+
+	return transfer
+}
+
+// splitDenom splits a full denomination, e.g., `transfer/channel-0/uatom`, into its trace path and base denomination.
+// The path is made of the leading port and channel identifier pairs.
+func splitDenom(fullDenom string) (path, base string) {
+	parts := strings.Split(fullDenom, "/")
+	i := 0
+	for i+2 < len(parts) && isChannelID(parts[i+1]) {
+		i += 2
+	}
+	return strings.Join(parts[:i], "/"), strings.Join(parts[i:], "/")
+}
+
+func isChannelID(s string) bool {
+	n, ok := strings.CutPrefix(s, "channel-")
+	if !ok {
+		return false
+	}
+	_, err := strconv.ParseUint(n, 10, 64)
+	return err == nil
+}
+
+// ibcDenom returns the denomination of a token on this chain, i.e., the hash of its trace for tokens from other chains
+func ibcDenom(path, base string) string {
+	if path == "" {
+		return base
+	}
+	hash := sha256.Sum256([]byte(path + "/" + base))
+	return "ibc/" + strings.ToUpper(hex.EncodeToString(hash[:]))
+}
+
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,123 @@
+package normalize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+const ibcTxJSON = `{
+  "tx": {"body": {"messages": [
+    {"@type": "/ibc.core.channel.v1.MsgRecvPacket", "signer": "` + bob + `"},
+    {"@type": "/ibc.core.channel.v1.MsgAcknowledgement", "signer": "` + bob + `"},
+    {"@type": "/ibc.core.channel.v1.MsgTimeout", "signer": "` + bob + `"}
+  ]}},
+  "txResponse": {
+    "height": "42",
+    "timestamp": "2024-05-01T12:00:00Z",
+    "events": [
+      {"type": "recv_packet", "attributes": [
+        {"key": "packet_data", "value": "{\"amount\":\"100\",\"denom\":\"uatom\",\"receiver\":\"` + alice + `\",\"sender\":\"` + carol + `\"}"},
+        {"key": "packet_sequence", "value": "12"},
+        {"key": "packet_src_port", "value": "transfer"},
+        {"key": "packet_src_channel", "value": "channel-141"},
+        {"key": "packet_dst_port", "value": "transfer"},
+        {"key": "packet_dst_channel", "value": "channel-0"},
+        {"key": "connection_id", "value": "connection-0"},
+        {"key": "msg_index", "value": "0"}
+      ]},
+      {"type": "write_acknowledgement", "attributes": [
+        {"key": "packet_ack", "value": "{\"result\":\"AQ==\"}"},
+        {"key": "packet_sequence", "value": "12"},
+        {"key": "packet_src_port", "value": "transfer"},
+        {"key": "packet_src_channel", "value": "channel-141"},
+        {"key": "packet_dst_port", "value": "transfer"},
+        {"key": "packet_dst_channel", "value": "channel-0"},
+        {"key": "msg_index", "value": "0"}
+      ]},
+      {"type": "acknowledge_packet", "attributes": [
+        {"key": "packet_sequence", "value": "5"},
+        {"key": "packet_src_port", "value": "transfer"},
+        {"key": "packet_src_channel", "value": "channel-0"},
+        {"key": "packet_dst_port", "value": "transfer"},
+        {"key": "packet_dst_channel", "value": "channel-141"},
+        {"key": "msg_index", "value": "1"}
+      ]},
+      {"type": "fungible_token_packet", "attributes": [{"key": "error", "value": "insufficient funds"}, {"key": "msg_index", "value": "1"}]},
+      {"type": "timeout_packet", "attributes": [
+        {"key": "packet_sequence", "value": "6"},
+        {"key": "packet_src_port", "value": "transfer"},
+        {"key": "packet_src_channel", "value": "channel-0"},
+        {"key": "packet_dst_port", "value": "transfer"},
+        {"key": "packet_dst_channel", "value": "channel-141"},
+        {"key": "msg_index", "value": "2"}
+      ]}
+    ]
+  }
+}`
+
+func TestIBCPackets(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(ibcTxJSON))
+	require.NoError(t, err)
+	require.Len(t, tx.IBCPackets, 4)
+
+	recv := tx.IBCPackets[0]
+	require.Equal(t, "recv_packet", recv.Type)
+	require.Equal(t, models.IBCDirectionIncoming, recv.Direction)
+	require.Equal(t, "transfer", recv.Port)
+	require.Equal(t, "channel-0", recv.Channel)
+	require.Equal(t, uint64(12), recv.Sequence)
+	require.Equal(t, "connection-0", *recv.ConnectionID)
+	require.Equal(t, &models.IBCTransfer{
+		Sender:     carol,
+		Receiver:   alice,
+		Denom:      "uatom",
+		Amount:     "100",
+		BaseDenom:  "uatom",
+		DenomPath:  "transfer/channel-0",
+		LocalDenom: "ibc/27394FB092D2ECCD56123C74F36E4C1F926001CEADA9CA97EA622B25F41E5EB2",
+	}, recv.Transfer)
+
+	ack := tx.IBCPackets[1]
+	require.Equal(t, "write_acknowledgement", ack.Type)
+	require.Equal(t, "channel-0", ack.Channel)
+	require.JSONEq(t, `{"result": "AQ=="}`, string(ack.Acknowledgement))
+	require.True(t, *ack.AckSuccess)
+
+	outgoingAck := tx.IBCPackets[2]
+	require.Equal(t, models.IBCDirectionOutgoing, outgoingAck.Direction)
+	require.Equal(t, "channel-0", outgoingAck.Channel)
+	require.Equal(t, uint64(5), outgoingAck.Sequence)
+	require.False(t, *outgoingAck.AckSuccess)
+
+	timeout := tx.IBCPackets[3]
+	require.Equal(t, "timeout_packet", timeout.Type)
+	require.Nil(t, timeout.AckSuccess)
+}
+
+func TestIBCReturningTransfer(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(`{
+	  "tx": {"body": {"messages": [{"@type": "/ibc.core.channel.v1.MsgRecvPacket", "signer": "` + bob + `"}]}},
+	  "txResponse": {"height": "1", "timestamp": "2024-05-01T12:00:00Z", "events": [
+	    {"type": "recv_packet", "attributes": [
+	      {"key": "packet_data_hex", "value": "7b22616d6f756e74223a2231222c2264656e6f6d223a227472616e736665722f6368616e6e656c2d3134312f756d6678227d"},
+	      {"key": "packet_sequence", "value": "1"},
+	      {"key": "packet_src_port", "value": "transfer"},
+	      {"key": "packet_src_channel", "value": "channel-141"},
+	      {"key": "packet_dst_port", "value": "transfer"},
+	      {"key": "packet_dst_channel", "value": "channel-0"}
+	    ]}
+	  ]}
+	}`))
+	require.NoError(t, err)
+	require.Len(t, tx.IBCPackets, 1)
+
+	// The token returns to its source chain, the counterparty prefix is removed
+	transfer := tx.IBCPackets[0].Transfer
+	require.Equal(t, "transfer/channel-141/umfx", transfer.Denom)
+	require.Equal(t, "umfx", transfer.LocalDenom)
+	require.Equal(t, "", transfer.DenomPath)
+}
@@ -0,0 +1,63 @@
+package normalize
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+type object map[string]json.RawMessage
+
+// parseObject parses a JSON object, returning nil if the value is not an object
+func parseObject(raw json.RawMessage) object {
+	var obj object
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil
+	}
+	return obj
+}
+
+// parseArray parses a JSON array, returning nil if the value is not an array
+func parseArray(raw json.RawMessage) []json.RawMessage {
+	var arr []json.RawMessage
+	if err := json.Unmarshal(raw, &arr); err != nil {
+		return nil
+	}
+	return arr
+}
+
+// text returns the value of a key as text, like the PostgreSQL `->>` operator:
+// strings are unquoted, other values are returned as JSON, null and missing values are not returned.
+func (o object) text(key string) (string, bool) {
+	return text(o[key])
+}
+
+// nonEmptyText returns the value of a key as text, or nil if it is missing or empty
+func (o object) nonEmptyText(key string) *string {
+	if v, ok := o.text(key); ok && v != "" {
+		return &v
+	}
+	return nil
+}
+
+func (o object) object(key string) object {
+	return parseObject(o[key])
+}
+
+func (o object) array(key string) []json.RawMessage {
+	return parseArray(o[key])
+}
+
+func text(raw json.RawMessage) (string, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", false
+	}
+	if raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return "", false
+		}
+		return s, true
+	}
+	return string(raw), true
+}
@@ -0,0 +1,203 @@
+package normalize
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// maxMessageDepth guards against pathological nesting of messages
+const maxMessageDepth = 16
+
+// metadataExcludedKeys are the message keys not copied to the message metadata, including the keys holding the nested
+// messages, e.g., the `msgs` of an authz MsgExec and the `packetData` of an ICA MsgSendTx, which are messages of their own
+var metadataExcludedKeys = []string{"@type", "sender", "executor", "admin", "voter", "messages", "msgs", "packetData", "proposalId", "proposers", "authority", "fromAddress"}
+
+// senderKeys are the message keys holding the sender address, by priority
+var senderKeys = []string{"sender", "fromAddress", "admin", "voter", "address", "executor", "authority", "granter"}
+
+// errDecodePacket is the transaction error set when the data of a received IBC packet cannot be decoded
+const errDecodePacket = "Error decoding base64 packet data"
+
+// messageNode is a message in the message tree of a transaction
+type messageNode struct {
+	path []int32
+	data json.RawMessage
+}
+
+// flattenMessages returns the top level messages of a transaction followed by their nested messages, depth first.
+// Top level messages keep their index in the transaction; nested messages are numbered after them.
+func flattenMessages(topLevel []json.RawMessage) []models.Message {
+	messages := make([]models.Message, 0, len(topLevel))
+	for i, data := range topLevel {
+		messages = append(messages, models.Message{Index: int64(i), Path: []int32{int32(i)}, Data: data})
+	}
+
+	var walk func(parent models.Message)
+	walk = func(parent models.Message) {
+		if len(parent.Path) >= maxMessageDepth {
+			return
+		}
+		for i, data := range NestedMessages(parent.Data) {
+			parentIndex := parent.Index
+			child := models.Message{
+				Index:       int64(len(messages)),
+				ParentIndex: &parentIndex,
+				Path:        append(append([]int32{}, parent.Path...), int32(i)),
+				Data:        data,
+			}
+			messages = append(messages, child)
+			walk(child)
+		}
+	}
+	for i := range topLevel {
+		walk(messages[i])
+	}
+
+	return messages
+}
+
+// NestedMessages returns the messages nested in a message, e.g., the messages of a proposal or of an authz MsgExec.
+// A group MsgExec does not carry the messages it executes; they are linked to the MsgSubmitProposal through the proposal ID.
+func NestedMessages(raw json.RawMessage) []json.RawMessage {
+	msg := parseObject(raw)
+	msgType, _ := msg.text("@type")
+
+	switch msgType {
+	case "/cosmos.group.v1.MsgSubmitProposal", "/cosmos.gov.v1.MsgSubmitProposal":
+		return msg.array("messages")
+	case "/cosmos.authz.v1beta1.MsgExec":
+		return msg.array("msgs")
+	case "/cosmos.gov.v1beta1.MsgSubmitProposal", "/cosmos.gov.v1.MsgExecLegacyContent":
+		if content := msg["content"]; parseObject(content) != nil {
+			return []json.RawMessage{content}
+		}
+	case "/ibc.applications.interchain_accounts.controller.v1.MsgSendTx":
+		return icaMessages(msg.object("packetData"))
+	case "/ibc.core.channel.v1.MsgRecvPacket":
+		if data, ok := msg.object("packet").text("data"); ok {
+			if packetData, err := decodeBase64JSON(data); err == nil {
+				return icaMessages(parseObject(packetData))
+			}
+		}
+	}
+
+	return nil
+}
+
+// icaMessages decodes the messages of an interchain account transaction, i.e., the base64 `data` of an ICA packet.
+// Only the `proto3json` encoding can be decoded.
+func icaMessages(packetData object) []json.RawMessage {
+	packetType, _ := packetData.text("type")
+	if packetType != "TYPE_EXECUTE_TX" && packetType != "1" {
+		return nil
+	}
+
+	data, ok := packetData.text("data")
+	if !ok {
+		return nil
+	}
+	cosmosTx, err := decodeBase64JSON(data)
+	if err != nil {
+		return nil
+	}
+	return parseObject(cosmosTx).array("messages")
+}
+
+// normalizeMessage sets the type, sender, mentions and metadata of a message.
+// It returns a transaction error if the message cannot be fully decoded.
+func normalizeMessage(m *models.Message) (txError *string, err error) {
+	msg := parseObject(m.Data)
+	if msg == nil {
+		return nil, fmt.Errorf("message %d is not a JSON object", m.Index)
+	}
+
+	if msgType, ok := msg.text("@type"); ok {
+		m.Type = &msgType
+	}
+	m.Sender = messageSender(msg)
+	m.Mentions = ExtractAddresses(m.Data)
+
+	metadata := make(object, len(msg))
+	for k, v := range msg {
+		metadata[k] = v
+	}
+	for _, k := range metadataExcludedKeys {
+		delete(metadata, k)
+	}
+
+	if m.Type != nil {
+		wasmMetadata(*m.Type, metadata)
+	}
+
+	// Extract the decoded data from the IBC packet
+	if m.Type != nil && *m.Type == "/ibc.core.channel.v1.MsgRecvPacket" {
+		packet := metadata.object("packet")
+		if _, ok := packet["data"]; ok {
+			data, _ := packet.text("data")
+			decoded, err := decodeBase64JSON(data)
+			if err != nil {
+				txError = ptr(errDecodePacket)
+			} else {
+				metadata["decodedData"] = decoded
+				if decodedObj := parseObject(decoded); decodedObj != nil {
+					if _, ok := decodedObj["sender"]; ok {
+						m.Sender = nil
+						if sender, ok := decodedObj.text("sender"); ok {
+							m.Sender = &sender
+						}
+					}
+				}
+				m.Mentions = mergeAddresses(m.Mentions, ExtractAddresses(decoded))
+			}
+		}
+	}
+
+	if m.Metadata, err = json.Marshal(metadata); err != nil {
+		return nil, fmt.Errorf("failed to marshal message %d metadata: %w", m.Index, err)
+	}
+
+	return txError, nil
+}
+
+// messageSender returns the sender of a message from the first known sender key that is set
+func messageSender(msg object) *string {
+	for _, key := range senderKeys {
+		if sender := msg.nonEmptyText(key); sender != nil {
+			return sender
+		}
+	}
+
+	if proposers := msg.array("proposers"); len(proposers) > 0 {
+		if proposer, ok := text(proposers[0]); ok {
+			return &proposer
+		}
+	}
+
+	if inputs := msg.array("inputs"); len(inputs) > 0 {
+		if address, ok := parseObject(inputs[0]).text("address"); ok {
+			return &address
+		}
+	}
+
+	return nil
+}
+
+// decodeBase64JSON decodes a base64 encoded JSON document
+func decodeBase64JSON(data string) (json.RawMessage, error) {
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+	if !utf8.Valid(decoded) || !json.Valid(decoded) {
+		return nil, fmt.Errorf("invalid JSON data")
+	}
+	return decoded, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
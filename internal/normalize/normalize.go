@@ -0,0 +1,162 @@
+// Package normalize parses the raw transaction JSON returned by the gRPC server into
+// the transaction, message and event records written by the output handlers.
+package normalize
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+const proposalFailureResult = `"PROPOSAL_EXECUTOR_RESULT_FAILURE"`
+
+// Transactions normalizes the transactions that are not normalized yet
+func Transactions(transactions []*models.Transaction) error {
+	for _, tx := range transactions {
+		if tx.Normalized != nil {
+			continue
+		}
+
+		normalized, err := Transaction(tx.Data)
+		if err != nil {
+			return fmt.Errorf("failed to normalize transaction %s: %w", tx.Hash, err)
+		}
+		tx.Normalized = normalized
+	}
+	return nil
+}
+
+// Block sets the summary of the block, the governance records, balance changes, staking records and coins derived from
+// the block events, and the proposer and last commit signatures of the block
+func Block(block *models.Block) {
+	root := parseObject(block.Data)
+	block.Hash, _ = root.object("blockId").text("hash")
+	block.LastBlockHash, _ = root.object("block").object("header").object("lastBlockId").text("hash")
+	block.Time, _ = root.object("block").object("header").text("time")
+	block.TxCount = len(root.object("block").object("data").array("txs"))
+	block.Commit = blockCommit(block.Data, block.ValidatorSet)
+	block.Governance = BlockGovernance(block.Events)
+	block.BalanceChanges = balanceChanges(block.Events)
+	block.Staking = BlockStaking(block.Events)
+	block.Coins = BlockCoins(block.Events)
+}
+
+// Transaction parses the JSON of a `cosmos.tx.v1beta1.Service.GetTx` response
+func Transaction(data []byte) (*models.NormalizedTransaction, error) {
+	root := parseObject(data)
+	if root == nil {
+		return nil, fmt.Errorf("transaction data is not a JSON object")
+	}
+	txData := root.object("tx")
+	txResponse := root.object("txResponse")
+
+	heightStr, _ := txResponse.text("height")
+	height, err := strconv.ParseInt(heightStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction height %q: %w", heightStr, err)
+	}
+
+	timestampStr, _ := txResponse.text("timestamp")
+	timestamp, err := time.Parse(time.RFC3339Nano, timestampStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction timestamp %q: %w", timestampStr, err)
+	}
+
+	tx := &models.NormalizedTransaction{
+		Height:    height,
+		Timestamp: timestamp,
+		Fee:       txData.object("authInfo")["fee"],
+	}
+	body := txData.object("body")
+	if memo, ok := body.text("memo"); ok {
+		tx.Memo = &memo
+	}
+
+	if codeStr, ok := txResponse.text("code"); ok {
+		code, err := strconv.ParseUint(codeStr, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction code %q: %w", codeStr, err)
+		}
+		tx.Code = uint32(code)
+	}
+
+	if tx.Events, err = normalizeEvents(txResponse.array("events")); err != nil {
+		return nil, err
+	}
+	tx.Error = txResponse.nonEmptyText("rawLog")
+	if tx.Error == nil {
+		tx.Error = proposalFailureLogs(tx.Events)
+	}
+	tx.ProposalIDs = proposalIDs(tx.Events)
+
+	tx.Messages = flattenMessages(body.array("messages"))
+	for i := range tx.Messages {
+		txError, err := normalizeMessage(&tx.Messages[i])
+		if err != nil {
+			return nil, err
+		}
+		if txError != nil {
+			tx.Error = txError
+		}
+		tx.VestingPeriods = append(tx.VestingPeriods, vestingPeriods(tx.Messages[i])...)
+	}
+
+	// The fees are paid by failed transactions as well
+	transactionFees(tx, txData.object("authInfo").object("fee"), txResponse)
+	tx.BalanceChanges = balanceChanges(tx.Events)
+	tx.Coins = coins(tx.Messages, tx.Events)
+
+	if tx.Code == 0 {
+		tx.Governance = transactionGovernance(tx.Messages, tx.Events)
+		tx.IBCPackets = ibcPackets(tx.Events)
+		tx.Staking = stakingRecords(tx.Messages, tx.Events)
+		tx.Wasm = transactionWasm(tx.Messages, tx.Events)
+	}
+
+	return tx, nil
+}
+
+// proposalFailureLogs returns the logs of a failed group proposal execution
+func proposalFailureLogs(events []models.Event) *string {
+	var logs *string
+	failed := false
+	for _, event := range events {
+		if event.Type != "cosmos.group.v1.EventExec" {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			switch {
+			case attr.Key == "logs" && logs == nil && attr.Value != nil:
+				logs = ptr(strings.Trim(*attr.Value, `"`))
+			case attr.Key == "result" && attr.Value != nil && *attr.Value == proposalFailureResult:
+				failed = true
+			}
+		}
+	}
+
+	if !failed {
+		return nil
+	}
+	return logs
+}
+
+// proposalIDs returns the sorted, distinct proposal IDs found in the event attributes, or nil if there is none
+func proposalIDs(events []models.Event) []string {
+	var ids []string
+	for _, event := range events {
+		for _, attr := range event.Attributes {
+			if attr.Key == "proposal_id" && attr.Value != nil {
+				ids = append(ids, strings.Trim(*attr.Value, `"`))
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
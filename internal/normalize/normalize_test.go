@@ -0,0 +1,196 @@
+package normalize_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+const (
+	alice = "manifest1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq"
+	bob   = "manifest1zzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzzz"
+	carol = "cosmos1pppppppppppppppppppppppppppppppppppppppppp"
+
+	// {"amount": "100", "denom": "uatom", "receiver": alice, "sender": carol}
+	ics20PacketData = "eyJhbW91bnQiOiAiMTAwIiwgImRlbm9tIjogInVhdG9tIiwgInJlY2VpdmVyIjogIm1hbmlmZXN0MXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxcXFxIiwgInNlbmRlciI6ICJjb3Ntb3MxcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwIn0="
+	// {"type": "TYPE_EXECUTE_TX", "data": base64({"messages": [MsgSend from bob]})}
+	icaPacketData = "eyJ0eXBlIjogIlRZUEVfRVhFQ1VURV9UWCIsICJkYXRhIjogImV5SnRaWE56WVdkbGN5STZJRnQ3SWtCMGVYQmxJam9nSWk5amIzTnRiM011WW1GdWF5NTJNV0psZEdFeExrMXpaMU5sYm1RaUxDQWlabkp2YlVGa1pISmxjM01pT2lBaWJXRnVhV1psYzNReGVucDZlbnA2ZW5wNmVucDZlbnA2ZW5wNmVucDZlbnA2ZW5wNmVucDZlbnA2ZW5wNmVucDZlbnA2ZW5vaWZWMTkifQ=="
+)
+
+const txJSON = `{
+  "tx": {
+    "body": {
+      "messages": [
+        {
+          "@type": "/cosmos.authz.v1beta1.MsgExec",
+          "grantee": "` + bob + `",
+          "msgs": [
+            {
+              "@type": "/cosmos.group.v1.MsgSubmitProposal",
+              "proposers": ["` + bob + `"],
+              "messages": [{"@type": "/cosmos.bank.v1beta1.MsgSend", "fromAddress": "` + alice + `", "toAddress": "` + bob + `"}]
+            },
+            {"@type": "/cosmos.bank.v1beta1.MsgSend", "fromAddress": "` + alice + `", "toAddress": "` + bob + `"}
+          ]
+        },
+        {
+          "@type": "/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount",
+          "fromAddress": "` + alice + `",
+          "toAddress": "` + bob + `",
+          "startTime": "1700000000",
+          "vestingPeriods": [
+            {"length": "100", "amount": [{"denom": "umfx", "amount": "10"}, {"denom": "umfx", "amount": "5"}]},
+            {"length": "200", "amount": [{"denom": "umfx", "amount": "20"}]}
+          ]
+        },
+        {"@type": "/ibc.core.channel.v1.MsgRecvPacket", "signer": "` + bob + `", "packet": {"data": "` + ics20PacketData + `"}},
+        {"@type": "/ibc.core.channel.v1.MsgRecvPacket", "signer": "` + bob + `", "packet": {"data": "` + icaPacketData + `"}}
+      ],
+      "memo": "hello ` + carol + `"
+    },
+    "authInfo": {"fee": {"amount": [{"denom": "umfx", "amount": "1000"}], "gasLimit": "200000"}}
+  },
+  "txResponse": {
+    "height": "42",
+    "timestamp": "2024-05-01T12:00:00Z",
+    "events": [
+      {"type": "tx", "attributes": [{"key": "fee", "value": "1000umfx"}]},
+      {"type": "cosmos.group.v1.EventSubmitProposal", "attributes": [{"key": "proposal_id", "value": "\"7\""}, {"key": "msg_index", "value": "0"}]},
+      {"type": "cosmos.group.v1.EventExec", "attributes": [{"key": "proposal_id", "value": "\"7\""}, {"key": "result", "value": "\"PROPOSAL_EXECUTOR_RESULT_FAILURE\""}, {"key": "logs", "value": "\"out of funds\""}]}
+    ]
+  }
+}`
+
+func TestTransaction(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(txJSON))
+	require.NoError(t, err)
+
+	require.Equal(t, int64(42), tx.Height)
+	require.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), tx.Timestamp)
+	require.Equal(t, "hello "+carol, *tx.Memo)
+	require.JSONEq(t, `{"amount": [{"denom": "umfx", "amount": "1000"}], "gasLimit": "200000"}`, string(tx.Fee))
+	require.Equal(t, "out of funds", *tx.Error)
+	require.Equal(t, []string{"7"}, tx.ProposalIDs)
+
+	t.Run("Messages", func(t *testing.T) {
+		type message struct {
+			index       int64
+			parentIndex *int64
+			path        []int32
+			msgType     string
+			sender      string
+		}
+		parent := func(i int64) *int64 { return &i }
+		expected := []message{
+			{0, nil, []int32{0}, "/cosmos.authz.v1beta1.MsgExec", ""},
+			{1, nil, []int32{1}, "/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount", alice},
+			{2, nil, []int32{2}, "/ibc.core.channel.v1.MsgRecvPacket", carol},
+			{3, nil, []int32{3}, "/ibc.core.channel.v1.MsgRecvPacket", ""},
+			{4, parent(0), []int32{0, 0}, "/cosmos.group.v1.MsgSubmitProposal", bob},
+			{5, parent(4), []int32{0, 0, 0}, "/cosmos.bank.v1beta1.MsgSend", alice},
+			{6, parent(0), []int32{0, 1}, "/cosmos.bank.v1beta1.MsgSend", alice},
+			{7, parent(3), []int32{3, 0}, "/cosmos.bank.v1beta1.MsgSend", bob},
+		}
+
+		require.Len(t, tx.Messages, len(expected))
+		for i, e := range expected {
+			m := tx.Messages[i]
+			require.Equal(t, e.index, m.Index)
+			require.Equal(t, e.parentIndex, m.ParentIndex)
+			require.Equal(t, e.path, m.Path)
+			require.Equal(t, e.msgType, *m.Type)
+			if e.sender == "" {
+				require.Nil(t, m.Sender, "message %d", i)
+			} else {
+				require.Equal(t, e.sender, *m.Sender, "message %d", i)
+			}
+		}
+
+		require.Equal(t, []string{alice, bob}, tx.Messages[0].Mentions)
+		require.Equal(t, []string{carol, alice, bob}, tx.Messages[2].Mentions)
+
+		var metadata map[string]interface{}
+		require.NoError(t, json.Unmarshal(tx.Messages[2].Metadata, &metadata))
+		require.NotContains(t, metadata, "@type")
+		require.Equal(t, "uatom", metadata["decodedData"].(map[string]interface{})["denom"])
+
+		// The nested messages are messages of their own, not copied to the metadata of their parent
+		metadata = nil
+		require.NoError(t, json.Unmarshal(tx.Messages[0].Metadata, &metadata))
+		require.Equal(t, map[string]interface{}{"grantee": bob}, metadata)
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		require.Len(t, tx.Events, 3)
+		require.Equal(t, "tx", tx.Events[0].Type)
+		require.Nil(t, tx.Events[0].MsgIndex)
+		require.Equal(t, int64(0), *tx.Events[1].MsgIndex)
+		require.Equal(t, "proposal_id", tx.Events[1].Attributes[0].Key)
+		require.Equal(t, `"7"`, *tx.Events[1].Attributes[0].Value)
+	})
+
+	t.Run("VestingPeriods", func(t *testing.T) {
+		end := time.Unix(1700000300, 0).UTC()
+		require.Equal(t, []models.VestingPeriod{
+			{MessageIndex: 1, PeriodIndex: 0, Address: bob, Denom: "umfx", Amount: "15", UnlockTime: time.Unix(1700000100, 0).UTC(), EndTime: end},
+			{MessageIndex: 1, PeriodIndex: 1, Address: bob, Denom: "umfx", Amount: "20", UnlockTime: end, EndTime: end},
+		}, tx.VestingPeriods)
+	})
+}
+
+func TestTransactionICA(t *testing.T) {
+	packetData, err := base64.StdEncoding.DecodeString(icaPacketData)
+	require.NoError(t, err)
+
+	tx, err := normalize.Transaction([]byte(`{
+	  "tx": {"body": {"messages": [{
+	    "@type": "/ibc.applications.interchain_accounts.controller.v1.MsgSendTx",
+	    "owner": "` + alice + `",
+	    "connectionId": "connection-0",
+	    "packetData": ` + string(packetData) + `
+	  }]}},
+	  "txResponse": {"height": "42", "timestamp": "2024-05-01T12:00:00Z"}
+	}`))
+	require.NoError(t, err)
+
+	require.Len(t, tx.Messages, 2)
+	require.Equal(t, "/cosmos.bank.v1beta1.MsgSend", *tx.Messages[1].Type)
+	require.Equal(t, int64(0), *tx.Messages[1].ParentIndex)
+
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal(tx.Messages[0].Metadata, &metadata))
+	require.Equal(t, map[string]interface{}{"owner": alice, "connectionId": "connection-0"}, metadata)
+}
+
+func TestTransactionInvalid(t *testing.T) {
+	_, err := normalize.Transaction([]byte(`not json`))
+	require.Error(t, err)
+
+	_, err = normalize.Transaction([]byte(`{"txResponse": {"height": "1"}}`))
+	require.ErrorContains(t, err, "invalid transaction timestamp")
+}
+
+func TestExtractAddresses(t *testing.T) {
+	raw := json.RawMessage(`{"to": "` + bob + `", "memo": "from '` + alice + `' with love", "nested": [{"x": "` + bob + `"}], "short": "manifest1abc", "upper": "MANIFEST1QQQQ"}`)
+	require.Equal(t, []string{alice, bob}, normalize.ExtractAddresses(raw))
+	require.Nil(t, normalize.ExtractAddresses(json.RawMessage(`{"amount": "1"}`)))
+}
+
+func TestBlockSummary(t *testing.T) {
+	block := &models.Block{ID: 42, Data: []byte(`{
+	  "blockId": {"hash": "q83vEjRWeJA="},
+	  "block": {"header": {"height": "42", "time": "2024-05-01T12:00:00.123456789Z", "lastBlockId": {"hash": "EjRWeJCrze8="}}, "data": {"txs": ["dHgx", "dHgy"]}}
+	}`)}
+	normalize.Block(block)
+
+	require.Equal(t, "q83vEjRWeJA=", block.Hash)
+	require.Equal(t, "EjRWeJCrze8=", block.LastBlockHash)
+	require.Equal(t, "2024-05-01T12:00:00.123456789Z", block.Time)
+	require.Equal(t, 2, block.TxCount)
+}
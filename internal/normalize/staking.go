@@ -0,0 +1,138 @@
+package normalize
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// delegationActions are the events changing a delegation
+var delegationActions = map[string]bool{
+	models.DelegationActionDelegate:             true,
+	models.DelegationActionUnbond:               true,
+	models.DelegationActionRedelegate:           true,
+	models.DelegationActionCancelUnbonding:      true,
+	models.DelegationActionCompleteUnbonding:    true,
+	models.DelegationActionCompleteRedelegation: true,
+}
+
+// stakingRecords returns the delegation changes and reward withdrawals found in events.
+// The attributes missing from the events of older Cosmos SDK versions, e.g., the delegator, are taken from the messages.
+func stakingRecords(messages []models.Message, events []models.Event) models.Staking {
+	var staking models.Staking
+	for _, event := range events {
+		attrs := eventAttributes(event)
+		msg := eventMessage(messages, event.MsgIndex)
+
+		switch {
+		case delegationActions[event.Type]:
+			change := models.DelegationChange{
+				EventIndex: event.Index,
+				MsgIndex:   event.MsgIndex,
+				Action:     event.Type,
+				Delegator:  firstNonEmpty(attrs["delegator"], msgText(msg, "delegatorAddress")),
+				Validator:  firstNonEmpty(attrs["validator"], attrs["destination_validator"], msgText(msg, "validatorAddress"), msgText(msg, "validatorDstAddress")),
+			}
+			if event.Type == models.DelegationActionRedelegate || event.Type == models.DelegationActionCompleteRedelegation {
+				change.SrcValidator = nonEmpty(firstNonEmpty(attrs["source_validator"], msgText(msg, "validatorSrcAddress")))
+			}
+
+			// The amount has no denomination before Cosmos SDK v0.47
+			coins := parseCoins(attrs["amount"])
+			if len(coins) > 0 {
+				change.Amount, change.Denom = coins[0].amount, coins[0].denom
+			} else if amount := msg.object("amount"); amount != nil {
+				change.Amount, _ = amount.text("amount")
+				change.Denom, _ = amount.text("denom")
+			}
+			if change.Delegator == "" || change.Validator == "" || change.Amount == "" {
+				continue
+			}
+
+			if t, err := time.Parse(time.RFC3339Nano, attrs["completion_time"]); err == nil {
+				change.CompletionTime = &t
+			}
+			if h, err := strconv.ParseInt(attrs["creation_height"], 10, 64); err == nil {
+				change.CreationHeight = &h
+			}
+			staking.Delegations = append(staking.Delegations, change)
+
+		case event.Type == "withdraw_rewards":
+			delegator := firstNonEmpty(attrs["delegator"], msgText(msg, "delegatorAddress"))
+			validator := firstNonEmpty(attrs["validator"], msgText(msg, "validatorAddress"))
+			if validator == "" {
+				continue
+			}
+			for _, c := range parseCoins(attrs["amount"]) {
+				staking.Rewards = append(staking.Rewards, models.StakingReward{
+					EventIndex: event.Index,
+					MsgIndex:   event.MsgIndex,
+					Kind:       models.StakingRewardDelegator,
+					Delegator:  nonEmpty(delegator),
+					Validator:  validator,
+					Denom:      c.denom,
+					Amount:     c.amount,
+				})
+			}
+
+		case event.Type == "withdraw_commission":
+			validator := firstNonEmpty(attrs["validator"], msgText(msg, "validatorAddress"))
+			if validator == "" {
+				continue
+			}
+			for _, c := range parseCoins(attrs["amount"]) {
+				staking.Rewards = append(staking.Rewards, models.StakingReward{
+					EventIndex: event.Index,
+					MsgIndex:   event.MsgIndex,
+					Kind:       models.StakingRewardCommission,
+					Validator:  validator,
+					Denom:      c.denom,
+					Amount:     c.amount,
+				})
+			}
+		}
+	}
+	return staking
+}
+
+// BlockStaking returns the staking records of the events emitted outside of transactions, e.g., the completed unbondings
+func BlockStaking(events []models.Event) models.Staking {
+	return stakingRecords(nil, events)
+}
+
+// eventMessage returns the message emitting an event: the top level message at the event message index,
+// or the single message nested in it, e.g., the message executed by an authz MsgExec.
+func eventMessage(messages []models.Message, msgIndex *int64) object {
+	if msgIndex == nil {
+		return nil
+	}
+	var found []models.Message
+	for _, m := range messages {
+		if len(m.Path) > 0 && int64(m.Path[0]) == *msgIndex {
+			found = append(found, m)
+		}
+	}
+	switch len(found) {
+	case 1:
+		return parseObject(found[0].Data)
+	case 2:
+		return parseObject(found[1].Data)
+	default:
+		return nil
+	}
+}
+
+func msgText(msg object, key string) string {
+	v, _ := msg.text(key)
+	return v
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
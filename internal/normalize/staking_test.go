@@ -0,0 +1,73 @@
+package normalize_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+const validator = "manifestvaloper1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq"
+
+const stakingTxJSON = `{
+  "tx": {"body": {"messages": [
+    {"@type": "/cosmos.staking.v1beta1.MsgDelegate", "delegatorAddress": "` + alice + `", "validatorAddress": "` + validator + `", "amount": {"denom": "umfx", "amount": "100"}},
+    {"@type": "/cosmos.staking.v1beta1.MsgUndelegate", "delegatorAddress": "` + bob + `", "validatorAddress": "` + validator + `", "amount": {"denom": "umfx", "amount": "40"}},
+    {"@type": "/cosmos.authz.v1beta1.MsgExec", "grantee": "` + bob + `", "msgs": [
+      {"@type": "/cosmos.distribution.v1beta1.MsgWithdrawValidatorCommission", "validatorAddress": "` + validator + `"}
+    ]}
+  ]}},
+  "txResponse": {
+    "height": "42",
+    "timestamp": "2024-05-01T12:00:00Z",
+    "events": [
+      {"type": "withdraw_rewards", "attributes": [{"key": "amount", "value": "3umfx"}, {"key": "validator", "value": "` + validator + `"}, {"key": "delegator", "value": "` + alice + `"}, {"key": "msg_index", "value": "0"}]},
+      {"type": "delegate", "attributes": [{"key": "validator", "value": "` + validator + `"}, {"key": "delegator", "value": "` + alice + `"}, {"key": "amount", "value": "100umfx"}, {"key": "new_shares", "value": "100.000000000000000000"}, {"key": "msg_index", "value": "0"}]},
+      {"type": "unbond", "attributes": [{"key": "validator", "value": "` + validator + `"}, {"key": "amount", "value": "40"}, {"key": "completion_time", "value": "2024-05-22T12:00:00Z"}, {"key": "msg_index", "value": "1"}]},
+      {"type": "withdraw_commission", "attributes": [{"key": "amount", "value": "7umfx,1uatom"}, {"key": "msg_index", "value": "2"}]}
+    ]
+  }
+}`
+
+func TestStaking(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(stakingTxJSON))
+	require.NoError(t, err)
+
+	zero, one := int64(0), int64(1)
+	completion := time.Date(2024, 5, 22, 12, 0, 0, 0, time.UTC)
+	// The delegator and denomination of the legacy unbond event are taken from the message
+	require.Equal(t, []models.DelegationChange{
+		{EventIndex: 1, MsgIndex: &zero, Action: models.DelegationActionDelegate, Delegator: alice, Validator: validator, Denom: "umfx", Amount: "100"},
+		{EventIndex: 2, MsgIndex: &one, Action: models.DelegationActionUnbond, Delegator: bob, Validator: validator, Denom: "umfx", Amount: "40", CompletionTime: &completion},
+	}, tx.Staking.Delegations)
+
+	require.Len(t, tx.Staking.Rewards, 3)
+	require.Equal(t, models.StakingRewardDelegator, tx.Staking.Rewards[0].Kind)
+	require.Equal(t, alice, *tx.Staking.Rewards[0].Delegator)
+	require.Equal(t, "3", tx.Staking.Rewards[0].Amount)
+	// The validator of the commission is taken from the message executed by MsgExec
+	require.Equal(t, models.StakingRewardCommission, tx.Staking.Rewards[1].Kind)
+	require.Nil(t, tx.Staking.Rewards[1].Delegator)
+	require.Equal(t, validator, tx.Staking.Rewards[1].Validator)
+	require.Equal(t, "uatom", tx.Staking.Rewards[2].Denom)
+}
+
+func TestBlockStaking(t *testing.T) {
+	value := func(s string) *string { return &s }
+	staking := normalize.BlockStaking([]models.Event{
+		{Index: 0, Type: "complete_redelegation", Attributes: []models.EventAttribute{
+			{Key: "amount", Value: value("5umfx")},
+			{Key: "delegator", Value: value(alice)},
+			{Key: "source_validator", Value: value("manifestvaloper1src")},
+			{Key: "destination_validator", Value: value(validator)},
+		}},
+	})
+
+	src := "manifestvaloper1src"
+	require.Equal(t, []models.DelegationChange{
+		{Action: models.DelegationActionCompleteRedelegation, Delegator: alice, Validator: validator, SrcValidator: &src, Denom: "umfx", Amount: "5"},
+	}, staking.Delegations)
+}
@@ -0,0 +1,88 @@
+package normalize
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+const msgCreatePeriodicVestingAccount = "/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount"
+
+// vestingPeriods returns the vesting periods of a MsgCreatePeriodicVestingAccount message, one per period and denom.
+func vestingPeriods(m models.Message) []models.VestingPeriod {
+	if m.Type == nil || *m.Type != msgCreatePeriodicVestingAccount {
+		return nil
+	}
+
+	msg := parseObject(m.Data)
+	startTimeStr, _ := msg.text("startTime")
+	startTime, err := strconv.ParseInt(startTimeStr, 10, 64)
+	if err != nil {
+		return nil
+	}
+	address, _ := msg.text("toAddress")
+
+	periods := vestingSchedule(address, startTime, msg.array("vestingPeriods"))
+	for i := range periods {
+		periods[i].MessageIndex = m.Index
+	}
+	return periods
+}
+
+// vestingSchedule returns the vesting periods of a schedule, one per period and denom.
+// The unlock time of a period is the start time plus the cumulative lengths of the periods up to it.
+func vestingSchedule(address string, startTime int64, rawPeriods []json.RawMessage) []models.VestingPeriod {
+	var periods []models.VestingPeriod
+	unlockEpoch := startTime
+	for i, rawPeriod := range rawPeriods {
+		period := parseObject(rawPeriod)
+		if lengthStr, ok := period.text("length"); ok {
+			length, _ := strconv.ParseInt(lengthStr, 10, 64)
+			unlockEpoch += length
+		}
+
+		denoms, amounts := sumCoins(period.array("amount"))
+		for _, denom := range denoms {
+			periods = append(periods, models.VestingPeriod{
+				PeriodIndex: int64(i),
+				Address:     address,
+				Denom:       denom,
+				Amount:      amounts[denom].String(),
+				UnlockTime:  time.Unix(unlockEpoch, 0).UTC(),
+			})
+		}
+	}
+
+	// The end time of every period is the unlock time of the last period
+	endTime := time.Unix(unlockEpoch, 0).UTC()
+	for i := range periods {
+		periods[i].EndTime = endTime
+	}
+
+	return periods
+}
+
+// sumCoins returns the denoms of coins, in order of appearance, and their amounts; amounts of the same denom are summed
+func sumCoins(rawCoins []json.RawMessage) ([]string, map[string]*big.Int) {
+	amounts := make(map[string]*big.Int)
+	var denoms []string
+	for _, rawCoin := range rawCoins {
+		coin := parseObject(rawCoin)
+		denom, _ := coin.text("denom")
+		amountStr, _ := coin.text("amount")
+		amount, ok := new(big.Int).SetString(amountStr, 10)
+		if !ok {
+			continue
+		}
+		if total, exists := amounts[denom]; exists {
+			total.Add(total, amount)
+			continue
+		}
+		amounts[denom] = amount
+		denoms = append(denoms, denom)
+	}
+	return denoms, amounts
+}
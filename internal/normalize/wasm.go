@@ -0,0 +1,219 @@
+package normalize
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// wasmContractAddressKey is the attribute holding the contract address in the CosmWasm events
+const wasmContractAddressKey = "_contract_address"
+
+// wasmMessageTypes are the CosmWasm messages whose `msg` payload is decoded
+var wasmMessageTypes = map[string]bool{
+	"/cosmwasm.wasm.v1.MsgInstantiateContract":  true,
+	"/cosmwasm.wasm.v1.MsgInstantiateContract2": true,
+	"/cosmwasm.wasm.v1.MsgExecuteContract":      true,
+	"/cosmwasm.wasm.v1.MsgMigrateContract":      true,
+	"/cosmwasm.wasm.v1.MsgSudoContract":         true,
+}
+
+// wasmMetadata adds the decoded contract message of CosmWasm messages to their metadata, and
+// removes the contract byte code, which is kept in the raw transaction only
+func wasmMetadata(msgType string, metadata object) {
+	if msgType == "/cosmwasm.wasm.v1.MsgStoreCode" {
+		delete(metadata, "wasmByteCode")
+		return
+	}
+	if !wasmMessageTypes[msgType] {
+		return
+	}
+	if decoded := decodeWasmMsg(metadata["msg"]); decoded != nil {
+		metadata["decodedMsg"] = decoded
+	}
+}
+
+// transactionWasm returns the CosmWasm records of a successful transaction from its messages and events
+func transactionWasm(messages []models.Message, events []models.Event) models.Wasm {
+	var wasm models.Wasm
+
+	// The store_code and instantiate events, by top level message index
+	codeEvents := map[int64][]map[string]string{}
+	instantiateEvents := map[int64][]map[string]string{}
+	for _, event := range events {
+		if event.MsgIndex == nil {
+			continue
+		}
+		switch event.Type {
+		case "store_code":
+			codeEvents[*event.MsgIndex] = append(codeEvents[*event.MsgIndex], eventAttributes(event))
+		case "instantiate":
+			instantiateEvents[*event.MsgIndex] = append(instantiateEvents[*event.MsgIndex], eventAttributes(event))
+		}
+	}
+
+	for _, m := range executedMessages(messages) {
+		if m.Type == nil {
+			continue
+		}
+		msg := parseObject(m.Data)
+		msgIndex := int64(m.Path[0])
+
+		switch *m.Type {
+		case "/cosmwasm.wasm.v1.MsgStoreCode":
+			if len(codeEvents[msgIndex]) == 0 {
+				continue
+			}
+			attrs := codeEvents[msgIndex][0]
+			codeEvents[msgIndex] = codeEvents[msgIndex][1:]
+			codeID, err := strconv.ParseUint(attrs["code_id"], 10, 64)
+			if err != nil {
+				continue
+			}
+			wasm.Codes = append(wasm.Codes, models.WasmCode{
+				MessageIndex: m.Index,
+				CodeID:       codeID,
+				Creator:      msg.nonEmptyText("sender"),
+				Checksum:     nonEmpty(attrs["code_checksum"]),
+			})
+
+		case "/cosmwasm.wasm.v1.MsgInstantiateContract", "/cosmwasm.wasm.v1.MsgInstantiateContract2":
+			// The first instantiate event of the message is the instantiation of the message contract,
+			// the next ones are the instantiations by the contract, handled below
+			if len(instantiateEvents[msgIndex]) == 0 {
+				continue
+			}
+			attrs := instantiateEvents[msgIndex][0]
+			instantiateEvents[msgIndex] = instantiateEvents[msgIndex][1:]
+			codeID, err := strconv.ParseUint(attrs["code_id"], 10, 64)
+			if err != nil || attrs[wasmContractAddressKey] == "" {
+				continue
+			}
+			wasm.Contracts = append(wasm.Contracts, models.WasmContract{
+				MessageIndex: ptr(m.Index),
+				Address:      attrs[wasmContractAddressKey],
+				CodeID:       codeID,
+				Creator:      msg.nonEmptyText("sender"),
+				Admin:        msg.nonEmptyText("admin"),
+				Label:        msg.nonEmptyText("label"),
+				Msg:          decodeWasmMsg(msg["msg"]),
+				Funds:        msg["funds"],
+			})
+
+		case "/cosmwasm.wasm.v1.MsgExecuteContract", "/cosmwasm.wasm.v1.MsgMigrateContract":
+			contract, _ := msg.text("contract")
+			if contract == "" {
+				continue
+			}
+			execution := models.WasmExecution{
+				MessageIndex: m.Index,
+				Kind:         models.WasmExecutionExecute,
+				Contract:     contract,
+				Sender:       msg.nonEmptyText("sender"),
+				Msg:          decodeWasmMsg(msg["msg"]),
+				Funds:        msg["funds"],
+			}
+			execution.Action = wasmAction(execution.Msg)
+			if *m.Type == "/cosmwasm.wasm.v1.MsgMigrateContract" {
+				execution.Kind = models.WasmExecutionMigrate
+				if codeID, err := strconv.ParseUint(msgText(msg, "codeId"), 10, 64); err == nil {
+					execution.CodeID = &codeID
+				}
+			}
+			wasm.Executions = append(wasm.Executions, execution)
+		}
+	}
+
+	// Contracts instantiated by other contracts
+	for _, msgIndex := range slices.Sorted(maps.Keys(instantiateEvents)) {
+		for _, attrs := range instantiateEvents[msgIndex] {
+			codeID, err := strconv.ParseUint(attrs["code_id"], 10, 64)
+			if err != nil || attrs[wasmContractAddressKey] == "" {
+				continue
+			}
+			wasm.Contracts = append(wasm.Contracts, models.WasmContract{
+				Address: attrs[wasmContractAddressKey],
+				CodeID:  codeID,
+			})
+		}
+	}
+
+	wasm.Events = wasmEvents(events)
+	return wasm
+}
+
+// wasmEvents returns the events emitted by contracts
+func wasmEvents(events []models.Event) []models.WasmEvent {
+	var wasmEvents []models.WasmEvent
+	for _, event := range events {
+		if event.Type != "wasm" && !strings.HasPrefix(event.Type, "wasm-") {
+			continue
+		}
+
+		attrs := make(map[string]string, len(event.Attributes))
+		var contract string
+		for _, attr := range event.Attributes {
+			if attr.Value == nil || attr.Key == "msg_index" {
+				continue
+			}
+			if attr.Key == wasmContractAddressKey {
+				if contract == "" {
+					contract = *attr.Value
+				}
+				continue
+			}
+			if _, exists := attrs[attr.Key]; !exists {
+				attrs[attr.Key] = *attr.Value
+			}
+		}
+		if contract == "" {
+			continue
+		}
+
+		wasmEvent := models.WasmEvent{
+			EventIndex: event.Index,
+			MsgIndex:   event.MsgIndex,
+			Contract:   contract,
+			Type:       event.Type,
+			Action:     nonEmpty(attrs["action"]),
+		}
+		if wasmEvent.Action == nil {
+			wasmEvent.Action = nonEmpty(strings.TrimPrefix(strings.TrimPrefix(event.Type, "wasm"), "-"))
+		}
+		wasmEvent.Attributes, _ = json.Marshal(attrs)
+		wasmEvents = append(wasmEvents, wasmEvent)
+	}
+	return wasmEvents
+}
+
+// decodeWasmMsg decodes a contract message, which is a base64 string in the JSON encoding of the messages
+func decodeWasmMsg(raw json.RawMessage) json.RawMessage {
+	if parseObject(raw) != nil {
+		return raw
+	}
+	data, ok := text(raw)
+	if !ok {
+		return nil
+	}
+	decoded, err := decodeBase64JSON(data)
+	if err != nil {
+		return nil
+	}
+	return decoded
+}
+
+// wasmAction returns the action of a contract message, i.e., the key of a single key JSON object
+func wasmAction(msg json.RawMessage) *string {
+	obj := parseObject(msg)
+	if len(obj) != 1 {
+		return nil
+	}
+	for action := range obj {
+		return &action
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package normalize_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+)
+
+const (
+	contract = "manifest14hj2tavq8fpesdwxxcu44rty3hh90vhujrvcmstl4zr3txmfvw9sq2r9g9"
+	child    = "manifest1nc5tatafv6eyq7llkr2gv50ff9e22mnf70qgjlv737ktmt4eswrqmqptas"
+)
+
+const wasmTxJSON = `{
+  "tx": {"body": {"messages": [
+    {"@type": "/cosmwasm.wasm.v1.MsgStoreCode", "sender": "` + alice + `", "wasmByteCode": "AGFzbQ=="},
+    {"@type": "/cosmwasm.wasm.v1.MsgInstantiateContract", "sender": "` + alice + `", "admin": "` + alice + `", "codeId": "3", "label": "token", "msg": "eyJuYW1lIjoiVG9rZW4ifQ==", "funds": []},
+    {"@type": "/cosmwasm.wasm.v1.MsgExecuteContract", "sender": "` + bob + `", "contract": "` + contract + `", "msg": "eyJ0cmFuc2ZlciI6eyJyZWNpcGllbnQiOiJ4IiwiYW1vdW50IjoiNSJ9fQ==", "funds": [{"denom": "umfx", "amount": "1"}]},
+    {"@type": "/cosmwasm.wasm.v1.MsgMigrateContract", "sender": "` + alice + `", "contract": "` + contract + `", "codeId": "4", "msg": {}}
+  ]}},
+  "txResponse": {
+    "height": "42",
+    "timestamp": "2024-05-01T12:00:00Z",
+    "events": [
+      {"type": "store_code", "attributes": [{"key": "code_checksum", "value": "abcd"}, {"key": "code_id", "value": "3"}, {"key": "msg_index", "value": "0"}]},
+      {"type": "instantiate", "attributes": [{"key": "_contract_address", "value": "` + contract + `"}, {"key": "code_id", "value": "3"}, {"key": "msg_index", "value": "1"}]},
+      {"type": "instantiate", "attributes": [{"key": "_contract_address", "value": "` + child + `"}, {"key": "code_id", "value": "2"}, {"key": "msg_index", "value": "1"}]},
+      {"type": "wasm", "attributes": [{"key": "_contract_address", "value": "` + contract + `"}, {"key": "action", "value": "transfer"}, {"key": "amount", "value": "5"}, {"key": "msg_index", "value": "2"}]},
+      {"type": "wasm-payout", "attributes": [{"key": "_contract_address", "value": "` + contract + `"}, {"key": "recipient", "value": "` + bob + `"}, {"key": "msg_index", "value": "2"}]},
+      {"type": "migrate", "attributes": [{"key": "_contract_address", "value": "` + contract + `"}, {"key": "code_id", "value": "4"}, {"key": "msg_index", "value": "3"}]}
+    ]
+  }
+}`
+
+func TestWasm(t *testing.T) {
+	tx, err := normalize.Transaction([]byte(wasmTxJSON))
+	require.NoError(t, err)
+	wasm := tx.Wasm
+
+	require.Len(t, wasm.Codes, 1)
+	require.Equal(t, uint64(3), wasm.Codes[0].CodeID)
+	require.Equal(t, "abcd", *wasm.Codes[0].Checksum)
+
+	require.Len(t, wasm.Contracts, 2)
+	require.Equal(t, contract, wasm.Contracts[0].Address)
+	require.Equal(t, int64(1), *wasm.Contracts[0].MessageIndex)
+	require.Equal(t, "token", *wasm.Contracts[0].Label)
+	require.JSONEq(t, `{"name": "Token"}`, string(wasm.Contracts[0].Msg))
+	// Instantiated by the contract
+	require.Equal(t, models.WasmContract{Address: child, CodeID: 2}, wasm.Contracts[1])
+
+	require.Len(t, wasm.Executions, 2)
+	require.Equal(t, models.WasmExecutionExecute, wasm.Executions[0].Kind)
+	require.Equal(t, "transfer", *wasm.Executions[0].Action)
+	require.JSONEq(t, `{"transfer": {"recipient": "x", "amount": "5"}}`, string(wasm.Executions[0].Msg))
+	require.Equal(t, models.WasmExecutionMigrate, wasm.Executions[1].Kind)
+	require.Equal(t, uint64(4), *wasm.Executions[1].CodeID)
+	require.Nil(t, wasm.Executions[1].Action)
+
+	require.Len(t, wasm.Events, 2)
+	require.Equal(t, contract, wasm.Events[0].Contract)
+	require.Equal(t, "transfer", *wasm.Events[0].Action)
+	require.JSONEq(t, `{"action": "transfer", "amount": "5"}`, string(wasm.Events[0].Attributes))
+	require.Equal(t, "payout", *wasm.Events[1].Action)
+
+	// The byte code is not copied to the metadata, the contract messages are decoded
+	require.NotContains(t, string(tx.Messages[0].Metadata), "wasmByteCode")
+	require.Contains(t, string(tx.Messages[2].Metadata), `"decodedMsg":{"transfer"`)
+}
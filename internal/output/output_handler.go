@@ -2,22 +2,66 @@ package output
 
 import (
 	"context"
+	"time"
 
 	"github.com/manifest-network/yaci/internal/models"
 )
 
+// RetentionPolicy is the data of a chain kept when the output is pruned.
+// The blocks older than the retained blocks or the retained duration are pruned.
+type RetentionPolicy struct {
+	// Blocks is the number of latest blocks kept, or zero
+	Blocks uint64
+	// Duration is the age of the oldest block kept, or zero
+	Duration time.Duration
+	// KeepNormalized keeps the normalized data of the pruned blocks; only their raw JSON is pruned
+	KeepNormalized bool
+	// DetachPartitions detaches the pruned partitions instead of dropping them, e.g., to archive them
+	DetachPartitions bool
+}
+
+// Enabled returns true if the policy prunes blocks
+func (p RetentionPolicy) Enabled() bool {
+	return p.Blocks > 0 || p.Duration > 0
+}
+
 type OutputHandler interface {
 	// WriteBlockWithTransactions writes a block and its transactions to the output.
 	WriteBlockWithTransactions(ctx context.Context, block *models.Block, transactions []*models.Transaction) error
 
-	// GetLatestBlock returns the latest block from the output.
-	GetLatestBlock(ctx context.Context) (*models.Block, error)
+	// WriteValidatorSnapshot writes a snapshot of the validator set to the output.
+	WriteValidatorSnapshot(ctx context.Context, snapshot *models.ValidatorSnapshot) error
+
+	// WriteWasmContractSnapshot writes a snapshot of the CosmWasm contract information to the output.
+	WriteWasmContractSnapshot(ctx context.Context, snapshot *models.WasmContractSnapshot) error
+
+	// WriteValidatorConsensusAddresses writes the consensus addresses of the validators of a chain at a height to the output.
+	WriteValidatorConsensusAddresses(ctx context.Context, chainID string, height uint64, addresses []models.ValidatorConsensusAddress) error
+
+	// WriteGenesis writes the genesis state of a chain to the output, replacing the genesis previously written for the chain.
+	WriteGenesis(ctx context.Context, genesis *models.Genesis) error
+
+	// GetLatestBlock returns the latest block of a chain from the output.
+	GetLatestBlock(ctx context.Context, chainID string) (*models.Block, error)
+
+	// GetEarliestBlock returns the earliest block of a chain from the output.
+	GetEarliestBlock(ctx context.Context, chainID string) (*models.Block, error)
+
+	// GetMissingBlockIds returns the missing block IDs of a chain from the output.
+	GetMissingBlockIds(ctx context.Context, chainID string) ([]uint64, error)
+
+	// Prune prunes the blocks of a chain outside the retention policy from the output, returning the pruning floor,
+	// i.e., the height of the earliest block kept, or zero if nothing is pruned.
+	// The blocks below the pruning floor are neither returned as the earliest block nor as missing blocks.
+	Prune(ctx context.Context, chainID string, policy RetentionPolicy) (uint64, error)
 
-	// GetEarliestBlock returns the earliest block from the output.
-	GetEarliestBlock(ctx context.Context) (*models.Block, error)
+	// GetPruningFloor returns the pruning floor of a chain from the output, or zero if the chain was never pruned.
+	GetPruningFloor(ctx context.Context, chainID string) (uint64, error)
 
-	// GetMissingBlockIds returns the missing block IDs from the output.
-	GetMissingBlockIds(ctx context.Context) ([]uint64, error)
+	// HasEndingGovProposals returns whether the voting period of a gov proposal of a chain, as recorded when it began,
+	// ends after the previous block of a height and at or before its block time, and no outcome of the proposal is
+	// recorded below that height.
+	HasEndingGovProposals(ctx context.Context, chainID string, height uint64, blockTime time.Time) (bool, error)
 
 	// Close closes the output handler.
 	Close() error
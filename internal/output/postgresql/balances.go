@@ -0,0 +1,24 @@
+package postgresql
+
+import (
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// queueBlockBalanceChanges queues the statements replacing the balance changes of the block events
+func queueBlockBalanceChanges(batch *pgx.Batch, block *models.Block) {
+	batch.Queue(`DELETE FROM balance_changes WHERE chain_id = $1 AND id IS NULL AND height = $2`, block.ChainID, block.ID)
+	queueBalanceChanges(batch, block.BalanceChanges, block.ChainID, nil, int64(block.ID))
+}
+
+// queueBalanceChanges queues the statements writing balance changes.
+// The transaction ID is nil for the changes of the block events.
+func queueBalanceChanges(batch *pgx.Batch, changes []models.BalanceChange, chainID string, id *string, height int64) {
+	for seq, c := range changes {
+		batch.Queue(`
+			INSERT INTO balance_changes (chain_id, height, seq, id, event_index, address, denom, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8::numeric)
+		`, chainID, height, seq, id, c.EventIndex, c.Address, c.Denom, c.Amount)
+	}
+}
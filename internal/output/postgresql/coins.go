@@ -0,0 +1,24 @@
+package postgresql
+
+import (
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// queueBlockCoins queues the statements replacing the coins of the block events
+func queueBlockCoins(batch *pgx.Batch, block *models.Block) {
+	batch.Queue(`DELETE FROM coins WHERE chain_id = $1 AND id IS NULL AND height = $2`, block.ChainID, block.ID)
+	queueCoins(batch, block.Coins, block.ChainID, nil, int64(block.ID))
+}
+
+// queueCoins queues the statements writing coins.
+// The transaction ID is nil for the coins of the block events.
+func queueCoins(batch *pgx.Batch, coins []models.Coin, chainID string, id *string, height int64) {
+	for seq, c := range coins {
+		batch.Queue(`
+			INSERT INTO coins (chain_id, height, seq, id, source, event_index, msg_index, type, key, denom, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11::numeric)
+		`, chainID, height, seq, id, c.Source, c.EventIndex, c.MsgIndex, c.Type, c.Key, c.Denom, c.Amount)
+	}
+}
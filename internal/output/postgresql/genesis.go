@@ -0,0 +1,88 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// genesisTables are the tables holding the genesis state of a chain
+var genesisTables = []string{"genesis_accounts", "genesis_balances", "genesis_vesting_periods", "genesis_validators", "genesis"}
+
+// WriteGenesis writes the genesis state of a chain, replacing the genesis previously imported for the chain
+func (h *PostgresOutputHandler) WriteGenesis(ctx context.Context, genesis *models.Genesis) error {
+	validators, err := json.Marshal(genesis.Validators)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validators: %w", err)
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // Ensure rollback if commit is not reached
+
+	batch := &pgx.Batch{}
+	for _, table := range genesisTables {
+		batch.Queue(`DELETE FROM `+table+` WHERE chain_id = $1`, genesis.ChainID)
+	}
+	batch.Queue(`
+		INSERT INTO genesis (chain_id, genesis_time, initial_height, imported_at) VALUES ($1, $2, $3, now())
+	`, genesis.ChainID, genesis.GenesisTime, genesis.InitialHeight)
+	for _, a := range genesis.Accounts {
+		batch.Queue(`
+			INSERT INTO genesis_accounts (chain_id, address, type, account_number, data) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (chain_id, address) DO NOTHING;
+		`, genesis.ChainID, a.Address, a.Type, a.AccountNumber, a.Data)
+	}
+	for _, b := range genesis.Balances {
+		batch.Queue(`
+			INSERT INTO genesis_balances (chain_id, address, denom, amount) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (chain_id, address, denom) DO UPDATE SET amount = genesis_balances.amount + EXCLUDED.amount;
+		`, genesis.ChainID, b.Address, b.Denom, b.Amount)
+	}
+	for _, p := range genesis.VestingPeriods {
+		// The period of a permanent locked account is never unlocked
+		var unlockTime, endTime *time.Time
+		if !p.Permanent {
+			unlockTime, endTime = &p.UnlockTime, &p.EndTime
+		}
+		batch.Queue(`
+			INSERT INTO genesis_vesting_periods (chain_id, address, period_index, denom, amount, start_time, unlock_time, end_time)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (chain_id, address, period_index, denom) DO UPDATE SET amount = genesis_vesting_periods.amount + EXCLUDED.amount;
+		`, genesis.ChainID, p.Address, p.PeriodIndex, p.Denom, p.Amount, p.StartTime, unlockTime, endTime)
+	}
+	batch.Queue(`
+		INSERT INTO genesis_validators (chain_id, operator_address, moniker, status, jailed, tokens, delegator_shares, commission_rate, consensus_pubkey, data)
+		SELECT
+			$1,
+			v->>'operatorAddress',
+			v->'description'->>'moniker',
+			v->>'status',
+			COALESCE((v->>'jailed')::boolean, false),
+			COALESCE((v->>'tokens')::numeric, 0),
+			COALESCE((v->>'delegatorShares')::numeric, 0),
+			(v->'commission'->'commissionRates'->>'rate')::numeric,
+			v->'consensusPubkey',
+			v
+		FROM jsonb_array_elements($2::jsonb) v
+		ON CONFLICT (chain_id, operator_address) DO NOTHING;
+	`, genesis.ChainID, validators)
+
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to write genesis: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	slog.Info("Genesis written", "chain_id", genesis.ChainID, "accounts", len(genesis.Accounts), "balances", len(genesis.Balances), "vesting_periods", len(genesis.VestingPeriods), "validators", len(genesis.Validators))
+	return nil
+}
@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// governanceTables are the tables holding the governance records of a transaction, in deletion order
+var governanceTables = []string{"proposal_tallies", "proposal_status_changes", "proposal_deposits", "proposal_votes", "proposals"}
+
+// queueBlockGovernance queues the statements replacing the governance records of the block events
+func queueBlockGovernance(batch *pgx.Batch, block *models.Block) {
+	batch.Queue(`DELETE FROM proposal_tallies WHERE chain_id = $1 AND id IS NULL AND height = $2`, block.ChainID, block.ID)
+	batch.Queue(`DELETE FROM proposal_status_changes WHERE chain_id = $1 AND id IS NULL AND height = $2`, block.ChainID, block.ID)
+	queueGovernance(batch, block.Governance, block.ChainID, nil, int64(block.ID), time.Time{})
+}
+
+// queueGovernance queues the statements writing governance records.
+// The transaction ID is nil for the records of the block events, which cannot submit proposals, vote or deposit.
+func queueGovernance(batch *pgx.Batch, gov models.Governance, chainID string, id *string, height int64, timestamp time.Time) {
+	for _, p := range gov.Proposals {
+		batch.Queue(`
+			INSERT INTO proposals (module, proposal_id, id, message_index, height, submit_time, proposer, group_policy_address, title, summary, metadata, chain_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (chain_id, module, proposal_id) DO UPDATE
+			SET id = EXCLUDED.id,
+			    message_index = EXCLUDED.message_index,
+			    height = EXCLUDED.height,
+			    submit_time = EXCLUDED.submit_time,
+			    proposer = EXCLUDED.proposer,
+			    group_policy_address = EXCLUDED.group_policy_address,
+			    title = EXCLUDED.title,
+			    summary = EXCLUDED.summary,
+			    metadata = EXCLUDED.metadata;
+		`, p.Module, p.ProposalID, id, p.MessageIndex, height, timestamp, p.Proposer, p.GroupPolicyAddress, p.Title, p.Summary, p.Metadata, chainID)
+	}
+
+	for _, v := range gov.Votes {
+		batch.Queue(`
+			INSERT INTO proposal_votes (id, message_index, module, proposal_id, voter, option, options, metadata, height)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, id, v.MessageIndex, v.Module, v.ProposalID, v.Voter, v.Option, v.Options, v.Metadata, height)
+	}
+
+	for _, d := range gov.Deposits {
+		batch.Queue(`
+			INSERT INTO proposal_deposits (id, message_index, denom, proposal_id, depositor, amount, height)
+			VALUES ($1, $2, $3, $4, $5, $6::numeric, $7)
+			ON CONFLICT (id, message_index, denom) DO UPDATE
+			SET amount = proposal_deposits.amount + EXCLUDED.amount;
+		`, id, d.MessageIndex, d.Denom, d.ProposalID, d.Depositor, d.Amount, height)
+	}
+
+	for seq, c := range gov.StatusChanges {
+		batch.Queue(`
+			INSERT INTO proposal_status_changes (module, proposal_id, height, seq, status, executor_result, id, chain_id, voting_end_time)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, c.Module, c.ProposalID, height, seq, c.Status, c.ExecutorResult, id, chainID, c.VotingEndTime)
+	}
+
+	for seq, t := range gov.Tallies {
+		batch.Queue(`
+			INSERT INTO proposal_tallies (module, proposal_id, height, seq, yes_count, abstain_count, no_count, no_with_veto_count, id, chain_id)
+			VALUES ($1, $2, $3, $4, $5::numeric, $6::numeric, $7::numeric, $8::numeric, $9, $10)
+		`, t.Module, t.ProposalID, height, seq, t.Yes, t.Abstain, t.No, t.NoWithVeto, id, chainID)
+	}
+}
+
+// HasEndingGovProposals returns whether the voting period of a gov proposal of a chain ends after the previous block of a
+// height and at or before its block time, without an outcome recorded below that height. The time of the previous block
+// is unknown until it is indexed, e.g., when the blocks are extracted concurrently; every recorded voting end until the
+// block time is then considered.
+func (h *PostgresOutputHandler) HasEndingGovProposals(ctx context.Context, chainID string, height uint64, blockTime time.Time) (bool, error) {
+	var ending bool
+	err := h.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+		  SELECT 1
+		  FROM proposal_status_changes s
+		  WHERE s.chain_id = $1
+		    AND s.module = 'gov'
+		    AND s.voting_end_time <= $3
+		    AND s.voting_end_time > COALESCE(
+		      (SELECT COALESCE(time, data->'block'->'header'->>'time')::timestamptz FROM blocks_raw WHERE chain_id = $1 AND id = $2 - 1),
+		      '-infinity'
+		    )
+		    AND NOT EXISTS (
+		      SELECT 1
+		      FROM proposal_status_changes o
+		      WHERE o.chain_id = s.chain_id
+		        AND o.module = s.module
+		        AND o.proposal_id = s.proposal_id
+		        AND o.height < $2
+		        AND o.status IN ('PROPOSAL_STATUS_PASSED', 'PROPOSAL_STATUS_REJECTED', 'PROPOSAL_STATUS_FAILED', 'PROPOSAL_STATUS_CANCELED')
+		    )
+		)
+	`, chainID, int64(height), blockTime).Scan(&ending)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the gov proposals ending at height %d: %w", height, err)
+	}
+	return ending, nil
+}
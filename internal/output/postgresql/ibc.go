@@ -0,0 +1,34 @@
+package postgresql
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// queueIBCPackets queues the statements writing the IBC packet lifecycle events of a transaction
+func queueIBCPackets(batch *pgx.Batch, packets []models.IBCPacketEvent, chainID, id string, height int64, timestamp time.Time) {
+	for _, p := range packets {
+		var sender, receiver, denom, amount, memo, baseDenom, denomPath, localDenom *string
+		if t := p.Transfer; t != nil {
+			sender, receiver, denom, amount, memo = &t.Sender, &t.Receiver, &t.Denom, &t.Amount, t.Memo
+			baseDenom, denomPath, localDenom = &t.BaseDenom, &t.DenomPath, &t.LocalDenom
+		}
+
+		batch.Queue(`
+			INSERT INTO ibc_packet_events (
+				id, event_index, msg_index, height, timestamp, event_type, direction, port, channel, sequence,
+				src_port, src_channel, dst_port, dst_channel, connection_id, timeout_height, timeout_timestamp,
+				data, acknowledgement, ack_success,
+				sender, receiver, denom, amount, memo, base_denom, denom_path, local_denom, chain_id
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			        $21, $22, $23, $24::numeric, $25, $26, $27, $28, $29)
+		`, id, p.EventIndex, p.MsgIndex, height, timestamp, p.Type, p.Direction, p.Port, p.Channel, p.Sequence,
+			p.SrcPort, p.SrcChannel, p.DstPort, p.DstChannel, p.ConnectionID, p.TimeoutHeight, p.TimeoutTimestamp,
+			jsonOrNil(p.Data), jsonOrNil(p.Acknowledgement), p.AckSuccess,
+			sender, receiver, denom, amount, memo, baseDenom, denomPath, localDenom, chainID)
+	}
+}
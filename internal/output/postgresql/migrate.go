@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Migrator applies the embedded migrations to a schema.
+// The migrations qualify the tables, views, functions and triggers they create, drop and call with the schema, except the
+// released migrations 002 to 005 whose trigger functions are recreated in the schema by 009; they keep the search path of
+// the connection string, which holds the migrations table.
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+	schema string
+}
+
+// MigrationStatus is the migration version of a schema
+type MigrationStatus struct {
+	// Version is the latest applied migration, or zero if none was applied
+	Version uint
+	// Dirty is true if the latest migration failed; the schema must be fixed and its version forced
+	Dirty bool
+	// Latest is the latest embedded migration
+	Latest uint
+	// Pending are the embedded migrations that are not applied
+	Pending []uint
+}
+
+// UpToDate returns true if every embedded migration was applied successfully
+func (s MigrationStatus) UpToDate() bool {
+	return !s.Dirty && s.Version >= s.Latest
+}
+
+// NewMigrator returns a migrator of a schema, whose migrations grant the read access to anonRole
+func NewMigrator(connString, schema, anonRole string) (*Migrator, error) {
+	config, err := pgx.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL connection string: %w", err)
+	}
+
+	d, err := iofs.New(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration source: %w", err)
+	}
+
+	driver, err := migratepgx.WithInstance(stdlib.OpenDB(*config), &migratepgx.Config{MigrationsTable: migrationsTable(schema)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration driver: %w", err)
+	}
+
+	src := newSchemaSource(d, schema, anonRole)
+	m, err := migrate.NewWithInstance("iofs", src, "postgres", driver)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration instance: %w", err)
+	}
+	m.Log = migrateLogger{}
+
+	return &Migrator{m: m, source: src, schema: schema}, nil
+}
+
+// Up applies the pending migrations
+func (m *Migrator) Up() error {
+	slog.Info("Running PostgreSQL migrations...", "schema", m.schema)
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return nil
+}
+
+// Down rolls back the given number of applied migrations
+func (m *Migrator) Down(steps uint) error {
+	slog.Info("Rolling back PostgreSQL migrations...", "schema", m.schema, "steps", steps)
+	if err := m.m.Steps(-int(steps)); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to roll back migrations: %w", err)
+	}
+	return nil
+}
+
+// Goto applies or rolls back the migrations up to a version
+func (m *Migrator) Goto(version uint) error {
+	slog.Info("Migrating PostgreSQL schema...", "schema", m.schema, "version", version)
+	if err := m.m.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Force sets the migration version without running any migration, and clears the dirty state.
+// A version of -1 records that no migration is applied.
+func (m *Migrator) Force(version int) error {
+	if err := m.m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	return nil
+}
+
+// Status returns the migration version of the schema
+func (m *Migrator) Status() (MigrationStatus, error) {
+	var status MigrationStatus
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return status, fmt.Errorf("failed to get the migration version: %w", err)
+	}
+	status.Version, status.Dirty = version, dirty
+
+	v, err := m.source.First()
+	for err == nil {
+		status.Latest = v
+		if v > status.Version {
+			status.Pending = append(status.Pending, v)
+		}
+		v, err = m.source.Next(v)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return status, fmt.Errorf("failed to list the migrations: %w", err)
+	}
+	return status, nil
+}
+
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// migrateLogger logs the applied migrations
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	slog.Info(strings.TrimSpace(fmt.Sprintf(format, v...)))
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
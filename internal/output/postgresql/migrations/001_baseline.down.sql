@@ -1,16 +1,23 @@
 BEGIN;
 
-DROP FUNCTION IF EXISTS api.get_address_filtered_transactions_and_successful_proposals(TEXT);
+DROP FUNCTION IF EXISTS {{schema}}.get_address_filtered_transactions_and_successful_proposals(TEXT);
 
-REVOKE SELECT ON api.transactions FROM web_anon;
-REVOKE SELECT ON api.blocks FROM web_anon;
-REVOKE USAGE ON SCHEMA api FROM web_anon;
+REVOKE SELECT ON {{schema}}.transactions FROM {{anon_role}};
+REVOKE SELECT ON {{schema}}.blocks FROM {{anon_role}};
+REVOKE USAGE ON SCHEMA {{schema}} FROM {{anon_role}};
 
-DROP TABLE IF EXISTS api.transactions;
-DROP TABLE IF EXISTS api.blocks;
+DROP TABLE IF EXISTS {{schema}}.transactions;
+DROP TABLE IF EXISTS {{schema}}.blocks;
 
-DROP SCHEMA IF EXISTS api CASCADE;
+DROP SCHEMA IF EXISTS {{schema}} CASCADE;
 
-DROP ROLE IF EXISTS web_anon;
+-- The role is shared by the schemas of the database; it is kept while it has access to another schema
+DO $$
+BEGIN
+  DROP ROLE IF EXISTS {{anon_role}};
+EXCEPTION WHEN dependent_objects_still_exist THEN
+  RAISE NOTICE 'role {{anon_role}} is still in use, not dropped';
+END
+$$;
 
 COMMIT;
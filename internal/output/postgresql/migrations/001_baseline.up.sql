@@ -1,14 +1,14 @@
 BEGIN;
 
 -- Create the schema if it doesn't exist
-CREATE SCHEMA IF NOT EXISTS api;
+CREATE SCHEMA IF NOT EXISTS {{schema}};
 
 -- Create the tables if they don't exist
-CREATE TABLE IF NOT EXISTS api.blocks (
+CREATE TABLE IF NOT EXISTS {{schema}}.blocks (
     id SERIAL PRIMARY KEY,
     data JSONB NOT NULL
 );
-CREATE TABLE IF NOT EXISTS api.transactions (
+CREATE TABLE IF NOT EXISTS {{schema}}.transactions (
     id VARCHAR(64) PRIMARY KEY,
     data JSONB NOT NULL
 );
@@ -16,18 +16,18 @@ CREATE TABLE IF NOT EXISTS api.transactions (
 -- Create a role for anonymous web access if it doesn't exist
 DO $$
 BEGIN
-  IF NOT EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = 'web_anon') THEN
-    CREATE ROLE web_anon NOLOGIN;
+  IF NOT EXISTS (SELECT FROM pg_catalog.pg_roles WHERE rolname = '{{anon_role}}') THEN
+    CREATE ROLE {{anon_role}} NOLOGIN;
   END IF;
 END
 $$;
 
--- Grant access to the web_anon role. Will succeed even if the role already has access.
-GRANT USAGE ON SCHEMA api TO web_anon;
-GRANT SELECT ON api.blocks TO web_anon;
-GRANT SELECT ON api.transactions TO web_anon;
+-- Grant access to the {{anon_role}} role. Will succeed even if the role already has access.
+GRANT USAGE ON SCHEMA {{schema}} TO {{anon_role}};
+GRANT SELECT ON {{schema}}.blocks TO {{anon_role}};
+GRANT SELECT ON {{schema}}.transactions TO {{anon_role}};
 
-CREATE OR REPLACE FUNCTION api.get_address_filtered_transactions_and_successful_proposals(address TEXT)
+CREATE OR REPLACE FUNCTION {{schema}}.get_address_filtered_transactions_and_successful_proposals(address TEXT)
 RETURNS TABLE (id VARCHAR(64), data JSONB)
 AS $$
 WITH base_messages AS (
@@ -36,7 +36,7 @@ WITH base_messages AS (
     t.data,
     msg.value AS message
   FROM
-    api.transactions t,
+    {{schema}}.transactions t,
     LATERAL jsonb_array_elements(t.data -> 'tx' -> 'body' -> 'messages') AS msg(value)
   WHERE
     -- Exclude messages that are MsgSubmitProposal
@@ -64,7 +64,7 @@ submit_proposals AS (
     t.data AS submit_data,
     proposal_attr.attr ->> 'value' AS proposal_id
   FROM
-    api.transactions t
+    {{schema}}.transactions t
     JOIN LATERAL jsonb_array_elements(t.data -> 'tx' -> 'body' -> 'messages') AS msg(value) ON TRUE
     JOIN LATERAL (
       SELECT attr
@@ -99,7 +99,7 @@ execs AS (
     attrs.attr_map ->> 'proposal_id' AS proposal_id,
     attrs.attr_map ->> 'result' AS result
   FROM
-    api.transactions t
+    {{schema}}.transactions t
     JOIN LATERAL (
       SELECT event
       FROM jsonb_array_elements(t.data -> 'txResponse' -> 'events') AS event
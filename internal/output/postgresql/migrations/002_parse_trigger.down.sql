@@ -1,19 +1,19 @@
 BEGIN;
 
-DROP INDEX IF EXISTS api.message_main_mentions_idx;
-DROP INDEX IF EXISTS api.message_main_sender_idx;
+DROP INDEX IF EXISTS {{schema}}.message_main_mentions_idx;
+DROP INDEX IF EXISTS {{schema}}.message_main_sender_idx;
 
-REVOKE EXECUTE ON FUNCTION api.get_messages_for_address(TEXT) FROM web_anon;
-REVOKE SELECT ON api.messages_main FROM web_anon;
-REVOKE SELECT ON api.messages_raw FROM web_anon;
-REVOKE SELECT ON api.transactions_raw FROM web_anon;
-REVOKE SELECT ON api.transactions_main FROM web_anon;
-REVOKE SELECT ON api.blocks_raw FROM web_anon;
+REVOKE EXECUTE ON FUNCTION {{schema}}.get_messages_for_address(TEXT) FROM {{anon_role}};
+REVOKE SELECT ON {{schema}}.messages_main FROM {{anon_role}};
+REVOKE SELECT ON {{schema}}.messages_raw FROM {{anon_role}};
+REVOKE SELECT ON {{schema}}.transactions_raw FROM {{anon_role}};
+REVOKE SELECT ON {{schema}}.transactions_main FROM {{anon_role}};
+REVOKE SELECT ON {{schema}}.blocks_raw FROM {{anon_role}};
 
-DROP FUNCTION IF EXISTS api.get_messages_for_address(TEXT);
+DROP FUNCTION IF EXISTS {{schema}}.get_messages_for_address(TEXT);
 
-DROP TRIGGER IF EXISTS new_message_update ON api.messages_raw;
-DROP TRIGGER IF EXISTS new_transaction_update ON api.transactions_raw;
+DROP TRIGGER IF EXISTS new_message_update ON {{schema}}.messages_raw;
+DROP TRIGGER IF EXISTS new_transaction_update ON {{schema}}.transactions_raw;
 
 DROP FUNCTION IF EXISTS update_message_main();
 DROP FUNCTION IF EXISTS update_transaction_main();
@@ -21,17 +21,17 @@ DROP FUNCTION IF EXISTS extract_proposal_ids(JSONB);
 DROP FUNCTION IF EXISTS extract_proposal_failure_logs(json_data JSONB);
 DROP FUNCTION IF EXISTS extract_metadata(JSONB);
 
-DROP TABLE api.messages_main;
-DROP TABLE api.messages_raw;
-DROP TABLE api.transactions_main;
+DROP TABLE {{schema}}.messages_main;
+DROP TABLE {{schema}}.messages_raw;
+DROP TABLE {{schema}}.transactions_main;
 
-ALTER TABLE api.transactions_raw RENAME TO transactions;
-ALTER TABLE api.blocks_raw RENAME TO blocks;
+ALTER TABLE {{schema}}.transactions_raw RENAME TO transactions;
+ALTER TABLE {{schema}}.blocks_raw RENAME TO blocks;
 
-GRANT SELECT ON api.blocks TO web_anon;
-GRANT SELECT ON api.transactions TO web_anon;
+GRANT SELECT ON {{schema}}.blocks TO {{anon_role}};
+GRANT SELECT ON {{schema}}.transactions TO {{anon_role}};
 
-CREATE OR REPLACE FUNCTION api.get_address_filtered_transactions_and_successful_proposals(address TEXT)
+CREATE OR REPLACE FUNCTION {{schema}}.get_address_filtered_transactions_and_successful_proposals(address TEXT)
 RETURNS TABLE (id VARCHAR(64), data JSONB)
 AS $$
 WITH base_messages AS (
@@ -40,7 +40,7 @@ WITH base_messages AS (
     t.data,
     msg.value AS message
   FROM
-    api.transactions t,
+    {{schema}}.transactions t,
     LATERAL jsonb_array_elements(t.data -> 'tx' -> 'body' -> 'messages') AS msg(value)
   WHERE
     -- Exclude messages that are MsgSubmitProposal
@@ -68,7 +68,7 @@ submit_proposals AS (
     t.data AS submit_data,
     proposal_attr.attr ->> 'value' AS proposal_id
   FROM
-    api.transactions t
+    {{schema}}.transactions t
     JOIN LATERAL jsonb_array_elements(t.data -> 'tx' -> 'body' -> 'messages') AS msg(value) ON TRUE
     JOIN LATERAL (
       SELECT attr
@@ -103,7 +103,7 @@ execs AS (
     attrs.attr_map ->> 'proposal_id' AS proposal_id,
     attrs.attr_map ->> 'result' AS result
   FROM
-    api.transactions t
+    {{schema}}.transactions t
     JOIN LATERAL (
       SELECT event
       FROM jsonb_array_elements(t.data -> 'txResponse' -> 'events') AS event
@@ -1,14 +1,14 @@
 BEGIN;
 
-REVOKE SELECT ON api.blocks FROM web_anon;
-REVOKE SELECT ON api.transactions FROM web_anon;
+REVOKE SELECT ON {{schema}}.blocks FROM {{anon_role}};
+REVOKE SELECT ON {{schema}}.transactions FROM {{anon_role}};
 
-ALTER TABLE api.blocks RENAME TO blocks_raw;
-ALTER TABLE api.transactions RENAME TO transactions_raw;
+ALTER TABLE {{schema}}.blocks RENAME TO blocks_raw;
+ALTER TABLE {{schema}}.transactions RENAME TO transactions_raw;
 
 -- This table stores the parsed transaction data
-CREATE TABLE IF NOT EXISTS api.transactions_main (
-    id VARCHAR(64) PRIMARY KEY REFERENCES api.transactions_raw(id),
+CREATE TABLE IF NOT EXISTS {{schema}}.transactions_main (
+    id VARCHAR(64) PRIMARY KEY REFERENCES {{schema}}.transactions_raw(id),
     fee JSONB,
     memo TEXT,
     error TEXT,
@@ -18,14 +18,14 @@ CREATE TABLE IF NOT EXISTS api.transactions_main (
 );
 
 -- This table stores the raw message data from the transaction
-CREATE TABLE IF NOT EXISTS api.messages_raw(
-    id VARCHAR(64) REFERENCES api.transactions_raw(id),
+CREATE TABLE IF NOT EXISTS {{schema}}.messages_raw(
+    id VARCHAR(64) REFERENCES {{schema}}.transactions_raw(id),
     message_index BIGINT,
     data JSONB,
     PRIMARY KEY (id, message_index)
 );
 
-CREATE TABLE IF NOT EXISTS api.messages_main(
+CREATE TABLE IF NOT EXISTS {{schema}}.messages_main(
     id VARCHAR(64),
     message_index BIGINT,
     type TEXT,
@@ -33,7 +33,7 @@ CREATE TABLE IF NOT EXISTS api.messages_main(
     mentions TEXT[],
     metadata JSONB,
     PRIMARY KEY (id, message_index),
-    FOREIGN KEY (id, message_index) REFERENCES api.messages_raw(id, message_index)
+    FOREIGN KEY (id, message_index) REFERENCES {{schema}}.messages_raw(id, message_index)
 );
 
 ---
@@ -150,7 +150,7 @@ BEGIN
 
   proposal_ids := extract_proposal_ids(NEW.data->'txResponse'->'events');
 
-  INSERT INTO api.transactions_main (id, fee, memo, error, height, timestamp, proposal_ids)
+  INSERT INTO {{schema}}.transactions_main (id, fee, memo, error, height, timestamp, proposal_ids)
   VALUES (
             NEW.id,
             NEW.data->'tx'->'authInfo'->'fee',
@@ -169,7 +169,7 @@ BEGIN
       proposal_ids = EXCLUDED.proposal_ids;
 
   -- Insert top level messages
-  INSERT INTO api.messages_raw (id, message_index, data)
+  INSERT INTO {{schema}}.messages_raw (id, message_index, data)
   SELECT
     NEW.id,
     message_index - 1,
@@ -179,7 +179,7 @@ BEGIN
   SET data = EXCLUDED.data;
 
   -- Insert nested messages, e.g., messages within a proposal
-  INSERT INTO api.messages_raw (id, message_index, data)
+  INSERT INTO {{schema}}.messages_raw (id, message_index, data)
   SELECT
     NEW.id,
     -- We make a derived index for nested messages so they don't collide with top level messages
@@ -239,7 +239,7 @@ BEGIN
   mentions := extract_addresses(NEW.data);
   metadata := extract_metadata(NEW.data);
 
-  INSERT INTO api.messages_main (id, message_index, type, sender, mentions, metadata)
+  INSERT INTO {{schema}}.messages_main (id, message_index, type, sender, mentions, metadata)
   VALUES (
            NEW.id,
            NEW.message_index,
@@ -263,20 +263,20 @@ $$;
 ---
 CREATE OR REPLACE TRIGGER new_transaction_update
 AFTER INSERT OR UPDATE
-ON api.transactions_raw
+ON {{schema}}.transactions_raw
 FOR EACH ROW
 EXECUTE FUNCTION update_transaction_main();
 
 CREATE OR REPLACE TRIGGER new_message_update
 AFTER INSERT OR UPDATE
-ON api.messages_raw
+ON {{schema}}.messages_raw
 FOR EACH ROW
 EXECUTE FUNCTION update_message_main();
 
 ---
 -- API function to get transactions and proposals by address
 ---
-CREATE OR REPLACE FUNCTION api.get_messages_for_address(_address TEXT)
+CREATE OR REPLACE FUNCTION {{schema}}.get_messages_for_address(_address TEXT)
   RETURNS TABLE (
     id VARCHAR(64),
     message_index BIGINT,
@@ -307,8 +307,8 @@ BEGIN
             t.timestamp,
             t.error,
             t.proposal_ids
-    FROM api.messages_main AS m
-    JOIN api.transactions_main AS t ON m.id = t.id
+    FROM {{schema}}.messages_main AS m
+    JOIN {{schema}}.transactions_main AS t ON m.id = t.id
     WHERE
         -- Always return top-level messages where address is the sender
         m.sender = _address AND m.message_index < 10000
@@ -322,8 +322,8 @@ BEGIN
         AND
         EXISTS (
           SELECT 1
-          FROM api.transactions_main tx2 -- The MsgExec transaction
-          JOIN api.messages_main     m2 ON tx2.id = m2.id -- The MsgExec message
+          FROM {{schema}}.transactions_main tx2 -- The MsgExec transaction
+          JOIN {{schema}}.messages_main     m2 ON tx2.id = m2.id -- The MsgExec message
           WHERE
             tx2.error IS NULL
             AND m2.type = '/cosmos.group.v1.MsgExec'
@@ -339,8 +339,8 @@ BEGIN
         AND
         EXISTS (
          SELECT 1
-         FROM api.transactions_main tx2 -- The MsgSubmitProposal transaction
-         JOIN api.messages_main m2 ON tx2.id = m2.id -- The MsgSubmitProposal message
+         FROM {{schema}}.transactions_main tx2 -- The MsgSubmitProposal transaction
+         JOIN {{schema}}.messages_main m2 ON tx2.id = m2.id -- The MsgSubmitProposal message
          WHERE
          tx2.error IS NULL
          AND tx2.proposal_ids && t.proposal_ids
@@ -352,45 +352,45 @@ END;
 $$;
 
 ---
--- Grant permissions to the web_anon role
+-- Grant permissions to the {{anon_role}} role
 ---
-GRANT SELECT ON api.blocks_raw TO web_anon;
-GRANT SELECT ON api.transactions_main TO web_anon;
-GRANT SELECT ON api.transactions_raw TO web_anon;
-GRANT SELECT ON api.messages_raw TO web_anon;
-GRANT SELECT ON api.messages_main TO web_anon;
-GRANT EXECUTE ON FUNCTION api.get_messages_for_address(TEXT) TO web_anon;
+GRANT SELECT ON {{schema}}.blocks_raw TO {{anon_role}};
+GRANT SELECT ON {{schema}}.transactions_main TO {{anon_role}};
+GRANT SELECT ON {{schema}}.transactions_raw TO {{anon_role}};
+GRANT SELECT ON {{schema}}.messages_raw TO {{anon_role}};
+GRANT SELECT ON {{schema}}.messages_main TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_messages_for_address(TEXT) TO {{anon_role}};
 
 ---
 -- Convert the existing data to the new schema using a staging table and our update triggers
 ---
-CREATE TABLE IF NOT EXISTS api.transactions_staging (
+CREATE TABLE IF NOT EXISTS {{schema}}.transactions_staging (
     id VARCHAR(64) PRIMARY KEY,
     data JSONB NOT NULL
 );
 
 CREATE OR REPLACE TRIGGER staging_transaction_update
 AFTER INSERT OR UPDATE
-ON api.transactions_staging
+ON {{schema}}.transactions_staging
 FOR EACH ROW
 EXECUTE FUNCTION update_transaction_main();
 
-INSERT INTO api.transactions_staging(id, data)
+INSERT INTO {{schema}}.transactions_staging(id, data)
 SELECT id, data
-FROM api.transactions_raw;
+FROM {{schema}}.transactions_raw;
 
-DROP TRIGGER staging_transaction_update ON api.transactions_staging;
-DROP TABLE api.transactions_staging;
+DROP TRIGGER staging_transaction_update ON {{schema}}.transactions_staging;
+DROP TABLE {{schema}}.transactions_staging;
 
 ---
 -- Create indexes on user addresses to speed up queries
 ---
-CREATE INDEX IF NOT EXISTS message_main_mentions_idx ON api.messages_main USING GIN (mentions);
-CREATE INDEX IF NOT EXISTS message_main_sender_idx ON api.messages_main(sender);
+CREATE INDEX IF NOT EXISTS message_main_mentions_idx ON {{schema}}.messages_main USING GIN (mentions);
+CREATE INDEX IF NOT EXISTS message_main_sender_idx ON {{schema}}.messages_main(sender);
 
 ---
 -- Drop the old function
 ---
-DROP FUNCTION IF EXISTS api.get_address_filtered_transactions_and_successful_proposals(TEXT);
+DROP FUNCTION IF EXISTS {{schema}}.get_address_filtered_transactions_and_successful_proposals(TEXT);
 
 COMMIT;
@@ -20,22 +20,22 @@ $$;
 ---
 -- Convert the existing data to the new schema using a staging table and our update triggers
 ---
-CREATE TABLE IF NOT EXISTS api.transactions_staging (
+CREATE TABLE IF NOT EXISTS {{schema}}.transactions_staging (
     id VARCHAR(64) PRIMARY KEY,
     data JSONB NOT NULL
 );
 
 CREATE OR REPLACE TRIGGER staging_transaction_update
 AFTER INSERT OR UPDATE
-ON api.transactions_staging
+ON {{schema}}.transactions_staging
 FOR EACH ROW
 EXECUTE FUNCTION update_transaction_main();
 
-INSERT INTO api.transactions_staging(id, data)
+INSERT INTO {{schema}}.transactions_staging(id, data)
 SELECT id, data
-FROM api.transactions_raw;
+FROM {{schema}}.transactions_raw;
 
-DROP TRIGGER staging_transaction_update ON api.transactions_staging;
-DROP TABLE api.transactions_staging;
+DROP TRIGGER staging_transaction_update ON {{schema}}.transactions_staging;
+DROP TABLE {{schema}}.transactions_staging;
 
 COMMIT;
@@ -54,14 +54,14 @@ BEGIN
         FROM unnest(mentions || new_addresses) AS addr;
       EXCEPTION WHEN OTHERS THEN
         -- On error, update the error field in the matching transaction
-        UPDATE api.transactions_main
+        UPDATE {{schema}}.transactions_main
         SET error = 'Error decoding base64 packet data'
         WHERE id = NEW.id;
       END;
     END IF;
   END IF;
 
-  INSERT INTO api.messages_main (id, message_index, type, sender, mentions, metadata)
+  INSERT INTO {{schema}}.messages_main (id, message_index, type, sender, mentions, metadata)
   VALUES (
            NEW.id,
            NEW.message_index,
@@ -83,22 +83,22 @@ $$;
 ---
 -- Convert the existing data to the new schema using a staging table and our update triggers
 ---
-CREATE TABLE IF NOT EXISTS api.transactions_staging (
+CREATE TABLE IF NOT EXISTS {{schema}}.transactions_staging (
     id VARCHAR(64) PRIMARY KEY,
     data JSONB NOT NULL
 );
 
 CREATE OR REPLACE TRIGGER staging_transaction_update
 AFTER INSERT OR UPDATE
-ON api.transactions_staging
+ON {{schema}}.transactions_staging
 FOR EACH ROW
 EXECUTE FUNCTION update_transaction_main();
 
-INSERT INTO api.transactions_staging(id, data)
+INSERT INTO {{schema}}.transactions_staging(id, data)
 SELECT id, data
-FROM api.transactions_raw;
+FROM {{schema}}.transactions_raw;
 
-DROP TRIGGER staging_transaction_update ON api.transactions_staging;
-DROP TABLE api.transactions_staging;
+DROP TRIGGER staging_transaction_update ON {{schema}}.transactions_staging;
+DROP TABLE {{schema}}.transactions_staging;
 
 COMMIT;
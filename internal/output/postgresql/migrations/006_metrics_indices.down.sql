@@ -1,5 +1,5 @@
 BEGIN;
 
-DROP INDEX IF EXISTS api.idx_messages_main_type;
+DROP INDEX IF EXISTS {{schema}}.idx_messages_main_type;
 
 COMMIT;
\ No newline at end of file
@@ -1,5 +1,5 @@
 BEGIN;
 
-CREATE INDEX IF NOT EXISTS idx_messages_main_type ON api.messages_main (type);
+CREATE INDEX IF NOT EXISTS idx_messages_main_type ON {{schema}}.messages_main (type);
 
 COMMIT;
\ No newline at end of file
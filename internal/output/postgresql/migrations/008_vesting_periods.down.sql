@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS new_message_vesting_periods ON {{schema}}.messages_main;
+DROP FUNCTION IF EXISTS {{schema}}.update_vesting_periods();
+
+-- Indexes are dropped automatically with the table
+DROP TABLE IF EXISTS {{schema}}.vesting_periods;
+
+COMMIT;
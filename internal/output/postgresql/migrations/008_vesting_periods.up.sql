@@ -0,0 +1,74 @@
+BEGIN;
+
+-- One row per vesting period and denom.
+-- Locked balances are aggregated from this table instead of re-parsing the vesting messages.
+CREATE TABLE IF NOT EXISTS {{schema}}.vesting_periods (
+  id            varchar(64) NOT NULL,  -- tx id
+  message_index bigint      NOT NULL,
+  period_index  bigint      NOT NULL,  -- 0-based within the message
+  address       text        NOT NULL,  -- vesting account address
+  denom         text        NOT NULL,
+  amount        numeric     NOT NULL,
+  unlock_time   timestamptz NOT NULL,  -- start time + cumulative period lengths
+  end_time      timestamptz NOT NULL,  -- unlock time of the last period of the schedule
+  PRIMARY KEY (id, message_index, period_index, denom),
+  FOREIGN KEY (id, message_index) REFERENCES {{schema}}.messages_main(id, message_index) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS vesting_periods_unlock_time_idx ON {{schema}}.vesting_periods (unlock_time);
+CREATE INDEX IF NOT EXISTS vesting_periods_address_idx     ON {{schema}}.vesting_periods (address);
+
+-- Rebuild the vesting periods of a message on insert or update
+CREATE OR REPLACE FUNCTION {{schema}}.update_vesting_periods()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  DELETE FROM {{schema}}.vesting_periods
+  WHERE id = NEW.id AND message_index = NEW.message_index;
+
+  IF NEW.type IS DISTINCT FROM '/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount' THEN
+    RETURN NEW;
+  END IF;
+
+  INSERT INTO {{schema}}.vesting_periods (id, message_index, period_index, address, denom, amount, unlock_time, end_time)
+  WITH periods AS (
+    SELECT
+      (p.ord - 1) AS period_index,
+      p.period,
+      (NEW.metadata->>'startTime')::bigint
+        + SUM(COALESCE((p.period->>'length')::bigint, 0)) OVER (ORDER BY p.ord) AS unlock_epoch
+    FROM jsonb_array_elements(NEW.metadata->'vestingPeriods') WITH ORDINALITY AS p(period, ord)
+  )
+  SELECT
+    NEW.id,
+    NEW.message_index,
+    periods.period_index,
+    NEW.metadata->>'toAddress',
+    c.coin->>'denom',
+    (c.coin->>'amount')::numeric,
+    to_timestamp(periods.unlock_epoch),
+    to_timestamp(MAX(periods.unlock_epoch) OVER ())
+  FROM periods
+  CROSS JOIN LATERAL jsonb_array_elements(periods.period->'amount') AS c(coin)
+  ON CONFLICT (id, message_index, period_index, denom) DO UPDATE
+  SET amount = {{schema}}.vesting_periods.amount + EXCLUDED.amount;
+
+  RETURN NEW;
+END $$;
+
+DROP TRIGGER IF EXISTS new_message_vesting_periods ON {{schema}}.messages_main;
+CREATE TRIGGER new_message_vesting_periods
+AFTER INSERT OR UPDATE
+ON {{schema}}.messages_main
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_vesting_periods();
+
+-- Backfill from the existing vesting messages (the trigger above will fire)
+UPDATE {{schema}}.messages_main
+SET metadata = metadata
+WHERE type = '/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount';
+
+GRANT SELECT ON {{schema}}.vesting_periods TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,264 @@
+BEGIN;
+
+-- Drop the nested messages, they are re-created with the `10000 + ...` index scheme below
+DELETE FROM {{schema}}.messages_main WHERE parent_index IS NOT NULL;
+DELETE FROM {{schema}}.messages_raw WHERE parent_index IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION {{schema}}.update_transaction_main()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  error_text TEXT;
+  proposal_ids TEXT[];
+BEGIN
+  error_text := NEW.data->'txResponse'->>'rawLog';
+
+  IF error_text IS NULL THEN
+    error_text := {{schema}}.extract_proposal_failure_logs(NEW.data);
+  END IF;
+
+  proposal_ids := {{schema}}.extract_proposal_ids(NEW.data->'txResponse'->'events');
+
+  INSERT INTO {{schema}}.transactions_main (id, fee, memo, error, height, timestamp, proposal_ids)
+  VALUES (
+            NEW.id,
+            NEW.data->'tx'->'authInfo'->'fee',
+            NEW.data->'tx'->'body'->>'memo',
+            error_text,
+            (NEW.data->'txResponse'->>'height')::BIGINT,
+            (NEW.data->'txResponse'->>'timestamp')::TIMESTAMPTZ,
+            proposal_ids
+         )
+  ON CONFLICT (id) DO UPDATE
+  SET fee = EXCLUDED.fee,
+      memo = EXCLUDED.memo,
+      error = EXCLUDED.error,
+      height = EXCLUDED.height,
+      timestamp = EXCLUDED.timestamp,
+      proposal_ids = EXCLUDED.proposal_ids;
+
+  -- Insert top level messages
+  INSERT INTO {{schema}}.messages_raw (id, message_index, data)
+  SELECT
+    NEW.id,
+    message_index - 1,
+    message
+  FROM jsonb_array_elements(NEW.data->'tx'->'body'->'messages') WITH ORDINALITY AS message(message, message_index)
+  ON CONFLICT (id, message_index) DO UPDATE
+  SET data = EXCLUDED.data;
+
+  -- Insert nested messages, e.g., messages within a proposal
+  INSERT INTO {{schema}}.messages_raw (id, message_index, data)
+  SELECT
+    NEW.id,
+    -- We make a derived index for nested messages so they don't collide with top level messages
+    10000 + ((top_level.msg_index - 1) * 1000) + sub_level.sub_index,
+    sub_level.sub_msg
+  FROM jsonb_array_elements(NEW.data->'tx'->'body'->'messages')
+       WITH ORDINALITY AS top_level(msg, msg_index)
+       CROSS JOIN LATERAL (
+         SELECT sub_msg, sub_index
+         FROM jsonb_array_elements(top_level.msg->'messages')
+              WITH ORDINALITY AS inner_msg(sub_msg, sub_index)
+       ) AS sub_level
+  -- TODO: Add x/gov support
+  WHERE top_level.msg->>'@type' = '/cosmos.group.v1.MsgSubmitProposal'
+    AND top_level.msg->'messages' IS NOT NULL
+  ON CONFLICT (id, message_index) DO UPDATE
+  SET data = EXCLUDED.data;
+
+  RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.update_message_main()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  sender TEXT;
+  mentions TEXT[];
+  metadata JSONB;
+  decoded_bytes BYTEA;
+  decoded_text TEXT;
+  decoded_json JSONB;
+  new_addresses TEXT[];
+BEGIN
+  sender := COALESCE(
+    NULLIF(NEW.data->>'sender', ''),
+    NULLIF(NEW.data->>'fromAddress', ''),
+    NULLIF(NEW.data->>'admin', ''),
+    NULLIF(NEW.data->>'voter', ''),
+    NULLIF(NEW.data->>'address', ''),
+    NULLIF(NEW.data->>'executor', ''),
+    NULLIF(NEW.data->>'authority', ''),
+    NULLIF(New.data->>'granter', ''),
+    (
+      SELECT jsonb_array_elements_text(NEW.data->'proposers')
+      LIMIT 1
+    ),
+    (
+      CASE
+        WHEN jsonb_typeof(NEW.data->'inputs') = 'array'
+             AND jsonb_array_length(NEW.data->'inputs') > 0
+        THEN NEW.data->'inputs'->0->>'address'
+        ELSE NULL
+      END
+    )
+  );
+
+  mentions := {{schema}}.extract_addresses(NEW.data);
+  metadata := {{schema}}.extract_metadata(NEW.data);
+
+  -- Extract the decoded data from the IBC packet
+  IF NEW.data->>'@type' = '/ibc.core.channel.v1.MsgRecvPacket' THEN
+    IF metadata->'packet' ? 'data' THEN
+      BEGIN
+        decoded_bytes := decode(metadata->'packet'->>'data', 'base64');
+        decoded_text := convert_from(decoded_bytes, 'UTF8');
+        decoded_json := decoded_text::jsonb;
+        metadata := metadata || jsonb_build_object('decodedData', decoded_json);
+        IF decoded_json ? 'sender' THEN
+          sender := decoded_json->>'sender';
+        END IF;
+        new_addresses := {{schema}}.extract_addresses(decoded_json);
+        SELECT array_agg(DISTINCT addr) INTO mentions
+        FROM unnest(mentions || new_addresses) AS addr;
+      EXCEPTION WHEN OTHERS THEN
+        -- On error, update the error field in the matching transaction
+        UPDATE {{schema}}.transactions_main
+        SET error = 'Error decoding base64 packet data'
+        WHERE id = NEW.id;
+      END;
+    END IF;
+  END IF;
+
+  INSERT INTO {{schema}}.messages_main (id, message_index, type, sender, mentions, metadata)
+  VALUES (
+           NEW.id,
+           NEW.message_index,
+           NEW.data->>'@type',
+           sender,
+           mentions,
+           metadata
+         )
+  ON CONFLICT (id, message_index) DO UPDATE
+  SET type = EXCLUDED.type,
+      sender = EXCLUDED.sender,
+      mentions = EXCLUDED.mentions,
+      metadata = EXCLUDED.metadata;
+
+  RETURN NEW;
+END;
+$$;
+
+DROP FUNCTION IF EXISTS {{schema}}.get_messages_for_address(TEXT);
+CREATE OR REPLACE FUNCTION {{schema}}.get_messages_for_address(_address TEXT)
+  RETURNS TABLE (
+    id VARCHAR(64),
+    message_index BIGINT,
+    type TEXT,
+    sender TEXT,
+    mentions TEXT[],
+    metadata JSONB,
+    fee JSONB,
+    memo TEXT,
+    height BIGINT,
+    "timestamp" TIMESTAMPTZ,
+    error TEXT,
+    proposal_ids TEXT[]
+  )
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  RETURN QUERY
+    SELECT  m.id,
+            m.message_index,
+            m.type,
+            m.sender,
+            m.mentions,
+            m.metadata,
+            t.fee,
+            t.memo,
+            t.height,
+            t.timestamp,
+            t.error,
+            t.proposal_ids
+    FROM {{schema}}.messages_main AS m
+    JOIN {{schema}}.transactions_main AS t ON m.id = t.id
+    WHERE
+        -- Always return top-level messages where address is the sender
+        m.sender = _address AND m.message_index < 10000
+      OR
+        -- Return top-level messages where address is mentioned and the transaction was successful
+        (t.error IS NULL AND m.message_index < 10000 AND m.mentions @> ARRAY[_address])
+      OR
+      -- Return nested messages where address is mentioned and the proposal was successfully executed
+      (
+        m.message_index >= 10000
+        AND
+        EXISTS (
+          SELECT 1
+          FROM {{schema}}.transactions_main tx2 -- The MsgExec transaction
+          JOIN {{schema}}.messages_main     m2 ON tx2.id = m2.id -- The MsgExec message
+          WHERE
+            tx2.error IS NULL
+            AND m2.type = '/cosmos.group.v1.MsgExec'
+            AND (tx2.proposal_ids && t.proposal_ids)
+        )
+        AND
+        m.mentions @> ARRAY[_address]
+      )
+      OR
+      -- Return MsgExec related to the group policy address
+      (
+        m.type = '/cosmos.group.v1.MsgExec'
+        AND
+        EXISTS (
+         SELECT 1
+         FROM {{schema}}.transactions_main tx2 -- The MsgSubmitProposal transaction
+         JOIN {{schema}}.messages_main m2 ON tx2.id = m2.id -- The MsgSubmitProposal message
+         WHERE
+         tx2.error IS NULL
+         AND tx2.proposal_ids && t.proposal_ids
+         AND m2.type = '/cosmos.group.v1.MsgSubmitProposal'
+         AND m2.metadata->>'groupPolicyAddress' = _address
+        )
+      );
+END;
+$$;
+
+GRANT EXECUTE ON FUNCTION {{schema}}.get_messages_for_address(TEXT) TO {{anon_role}};
+
+DROP FUNCTION IF EXISTS {{schema}}.extract_nested_messages(JSONB);
+DROP FUNCTION IF EXISTS {{schema}}.decode_ica_messages(JSONB);
+
+DROP INDEX IF EXISTS {{schema}}.messages_main_parent_idx;
+ALTER TABLE {{schema}}.messages_main DROP COLUMN IF EXISTS path;
+ALTER TABLE {{schema}}.messages_main DROP COLUMN IF EXISTS parent_index;
+ALTER TABLE {{schema}}.messages_raw DROP COLUMN IF EXISTS path;
+ALTER TABLE {{schema}}.messages_raw DROP COLUMN IF EXISTS parent_index;
+
+---
+-- Convert the existing data to the previous schema using a staging table and our update triggers
+---
+CREATE TABLE IF NOT EXISTS {{schema}}.transactions_staging (
+    id VARCHAR(64) PRIMARY KEY,
+    data JSONB NOT NULL
+);
+
+CREATE OR REPLACE TRIGGER staging_transaction_update
+AFTER INSERT OR UPDATE
+ON {{schema}}.transactions_staging
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_transaction_main();
+
+INSERT INTO {{schema}}.transactions_staging(id, data)
+SELECT id, data
+FROM {{schema}}.transactions_raw;
+
+DROP TRIGGER staging_transaction_update ON {{schema}}.transactions_staging;
+DROP TABLE {{schema}}.transactions_staging;
+
+COMMIT;
@@ -0,0 +1,466 @@
+BEGIN;
+
+---
+-- Nested messages are stored with their parent message index and their path in the message tree,
+-- e.g., the path of the second message of an authz MsgExec that is the first message of the transaction is {0,1}.
+-- Top level messages keep their index in the transaction; nested messages are numbered after them, depth first.
+---
+ALTER TABLE {{schema}}.messages_raw ADD COLUMN IF NOT EXISTS parent_index BIGINT;
+ALTER TABLE {{schema}}.messages_raw ADD COLUMN IF NOT EXISTS path INT[];
+ALTER TABLE {{schema}}.messages_main ADD COLUMN IF NOT EXISTS parent_index BIGINT;
+ALTER TABLE {{schema}}.messages_main ADD COLUMN IF NOT EXISTS path INT[];
+
+CREATE INDEX IF NOT EXISTS messages_main_parent_idx ON {{schema}}.messages_main (id, parent_index);
+
+---
+-- The helper and trigger functions of 002 to 005 were created unqualified, i.e., in the first schema of the search
+-- path; they are recreated in the schema so that the triggers of several schemas do not share them.
+---
+CREATE OR REPLACE FUNCTION {{schema}}.extract_addresses(msg JSONB)
+RETURNS TEXT[]
+LANGUAGE SQL STABLE
+AS $$
+WITH addresses AS (
+  SELECT unnest(
+    regexp_matches(
+      -- Convert the JSONB to text, then do a pattern match
+      msg::text,
+      -- Very rough bech32-like pattern:
+      --   - 2-83 chars of [a-z0-9], plus '1', plus 38+ chars of the set [qpzry9x8gf2tvdw0s3jn54khce6mua7l]
+      --   We allow trailing chars because some addresses can be longer if they contain e.g. valoper style, etc.
+      E'(?<=[\\"\'\\\\s]|^)([a-z0-9]{2,83}1[qpzry9x8gf2tvdw0s3jn54khce6mua7l]{38,})(?=[\\"\'\\\\s]|$)',
+      'g'
+    )
+  ) AS addr
+)
+SELECT array_agg(DISTINCT addr)
+FROM addresses;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_metadata(msg JSONB)
+RETURNS JSONB
+LANGUAGE SQL STABLE
+AS $$
+  WITH keys_to_remove AS (
+      SELECT ARRAY['@type', 'sender', 'executor', 'admin', 'voter', 'messages', 'proposalId', 'proposers', 'authority', 'fromAddress']::text[] AS keys
+  )
+  SELECT msg - (SELECT keys FROM keys_to_remove)
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_proposal_failure_logs(json_data JSONB)
+RETURNS TEXT
+LANGUAGE sql
+AS $$
+WITH
+  events AS (
+    SELECT jsonb_array_elements(json_data->'txResponse'->'events') AS event
+  ),
+
+  typed_attributes AS (
+    SELECT
+      event->>'type' AS event_type,
+      jsonb_array_elements(event->'attributes') AS attribute
+    FROM events
+  )
+
+  SELECT
+    TRIM(BOTH '"' FROM typed_attributes.attribute->>'value') AS logs
+  FROM typed_attributes
+  WHERE
+    typed_attributes.event_type = 'cosmos.group.v1.EventExec'
+    AND typed_attributes.attribute->>'key' = 'logs'
+    AND EXISTS (
+      SELECT 1
+      FROM typed_attributes t2
+      WHERE t2.event_type = typed_attributes.event_type
+        AND t2.attribute->>'key' = 'result'
+        AND t2.attribute->>'value' = '"PROPOSAL_EXECUTOR_RESULT_FAILURE"'
+    )
+  LIMIT 1;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_proposal_ids(events JSONB)
+RETURNS TEXT[]
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  proposal_ids TEXT[];
+BEGIN
+   SELECT
+     ARRAY_AGG(DISTINCT TRIM(BOTH '"' FROM attr->>'value'))
+   INTO proposal_ids
+   FROM jsonb_array_elements(events) AS ev(event)
+   CROSS JOIN LATERAL jsonb_array_elements(ev.event->'attributes') AS attr
+   WHERE attr->>'key' = 'proposal_id';
+
+  RETURN proposal_ids;
+END;
+$$;
+
+-- Decode the messages of an interchain account transaction, i.e., the base64 `data` of an ICA packet.
+-- Only the `proto3json` encoding can be decoded; an empty array is returned otherwise.
+CREATE OR REPLACE FUNCTION {{schema}}.decode_ica_messages(packet_data JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql
+IMMUTABLE
+AS $$
+DECLARE
+  cosmos_tx JSONB;
+BEGIN
+  IF packet_data->>'type' IS NULL
+     OR packet_data->>'type' NOT IN ('TYPE_EXECUTE_TX', '1')
+     OR packet_data->>'data' IS NULL THEN
+    RETURN '[]'::jsonb;
+  END IF;
+
+  cosmos_tx := convert_from(decode(packet_data->>'data', 'base64'), 'UTF8')::jsonb;
+  RETURN COALESCE(cosmos_tx->'messages', '[]'::jsonb);
+EXCEPTION WHEN OTHERS THEN
+  RETURN '[]'::jsonb;
+END;
+$$;
+
+-- Return the messages nested in a message as a JSONB array.
+-- A group MsgExec does not carry the messages it executes; they are linked to the MsgSubmitProposal through the proposal ID.
+CREATE OR REPLACE FUNCTION {{schema}}.extract_nested_messages(msg JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql
+IMMUTABLE
+AS $$
+DECLARE
+  packet_data JSONB;
+BEGIN
+  CASE msg->>'@type'
+    WHEN '/cosmos.group.v1.MsgSubmitProposal', '/cosmos.gov.v1.MsgSubmitProposal' THEN
+      RETURN COALESCE(msg->'messages', '[]'::jsonb);
+    WHEN '/cosmos.authz.v1beta1.MsgExec' THEN
+      RETURN COALESCE(msg->'msgs', '[]'::jsonb);
+    WHEN '/cosmos.gov.v1beta1.MsgSubmitProposal', '/cosmos.gov.v1.MsgExecLegacyContent' THEN
+      IF jsonb_typeof(msg->'content') = 'object' THEN
+        RETURN jsonb_build_array(msg->'content');
+      END IF;
+    WHEN '/ibc.applications.interchain_accounts.controller.v1.MsgSendTx' THEN
+      RETURN {{schema}}.decode_ica_messages(msg->'packetData');
+    WHEN '/ibc.core.channel.v1.MsgRecvPacket' THEN
+      BEGIN
+        packet_data := convert_from(decode(msg->'packet'->>'data', 'base64'), 'UTF8')::jsonb;
+      EXCEPTION WHEN OTHERS THEN
+        RETURN '[]'::jsonb;
+      END;
+      IF jsonb_typeof(packet_data) = 'object' THEN
+        RETURN {{schema}}.decode_ica_messages(packet_data);
+      END IF;
+    ELSE
+      NULL;
+  END CASE;
+
+  RETURN '[]'::jsonb;
+END;
+$$;
+
+---
+-- Function to parse a raw transaction into the transactions_main table
+---
+CREATE OR REPLACE FUNCTION {{schema}}.update_transaction_main()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  error_text TEXT;
+  proposal_ids TEXT[];
+BEGIN
+  error_text := NEW.data->'txResponse'->>'rawLog';
+
+  IF error_text IS NULL THEN
+    error_text := {{schema}}.extract_proposal_failure_logs(NEW.data);
+  END IF;
+
+  proposal_ids := {{schema}}.extract_proposal_ids(NEW.data->'txResponse'->'events');
+
+  INSERT INTO {{schema}}.transactions_main (id, fee, memo, error, height, timestamp, proposal_ids)
+  VALUES (
+            NEW.id,
+            NEW.data->'tx'->'authInfo'->'fee',
+            NEW.data->'tx'->'body'->>'memo',
+            error_text,
+            (NEW.data->'txResponse'->>'height')::BIGINT,
+            (NEW.data->'txResponse'->>'timestamp')::TIMESTAMPTZ,
+            proposal_ids
+         )
+  ON CONFLICT (id) DO UPDATE
+  SET fee = EXCLUDED.fee,
+      memo = EXCLUDED.memo,
+      error = EXCLUDED.error,
+      height = EXCLUDED.height,
+      timestamp = EXCLUDED.timestamp,
+      proposal_ids = EXCLUDED.proposal_ids;
+
+  -- Insert the top level and nested messages
+  INSERT INTO {{schema}}.messages_raw (id, message_index, parent_index, path, data)
+  WITH RECURSIVE tree(path, data) AS (
+    SELECT ARRAY[(ord - 1)::int], msg
+    FROM jsonb_array_elements(NEW.data->'tx'->'body'->'messages') WITH ORDINALITY AS top_level(msg, ord)
+    UNION ALL
+    SELECT tree.path || (nested.ord - 1)::int, nested.msg
+    FROM tree
+    CROSS JOIN LATERAL jsonb_array_elements({{schema}}.extract_nested_messages(tree.data)) WITH ORDINALITY AS nested(msg, ord)
+    -- Guard against pathological nesting
+    WHERE cardinality(tree.path) < 16
+  ),
+  numbered AS (
+    SELECT
+      path,
+      data,
+      CASE
+        WHEN cardinality(path) = 1 THEN path[1]::bigint
+        ELSE jsonb_array_length(NEW.data->'tx'->'body'->'messages')
+             + row_number() OVER (PARTITION BY cardinality(path) = 1 ORDER BY path) - 1
+      END AS message_index
+    FROM tree
+  )
+  SELECT
+    NEW.id,
+    child.message_index,
+    parent.message_index,
+    child.path,
+    child.data
+  FROM numbered child
+  LEFT JOIN numbered parent
+    ON cardinality(child.path) > 1
+   AND parent.path = child.path[1:cardinality(child.path) - 1]
+  ON CONFLICT (id, message_index) DO UPDATE
+  SET parent_index = EXCLUDED.parent_index,
+      path = EXCLUDED.path,
+      data = EXCLUDED.data;
+
+  RETURN NEW;
+END;
+$$;
+
+---
+-- Function to parse a raw message into the messages_main table
+---
+CREATE OR REPLACE FUNCTION {{schema}}.update_message_main()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  sender TEXT;
+  mentions TEXT[];
+  metadata JSONB;
+  decoded_bytes BYTEA;
+  decoded_text TEXT;
+  decoded_json JSONB;
+  new_addresses TEXT[];
+BEGIN
+  sender := COALESCE(
+    NULLIF(NEW.data->>'sender', ''),
+    NULLIF(NEW.data->>'fromAddress', ''),
+    NULLIF(NEW.data->>'admin', ''),
+    NULLIF(NEW.data->>'voter', ''),
+    NULLIF(NEW.data->>'address', ''),
+    NULLIF(NEW.data->>'executor', ''),
+    NULLIF(NEW.data->>'authority', ''),
+    NULLIF(NEW.data->>'granter', ''),
+    NULLIF(NEW.data->>'grantee', ''),
+    (
+      SELECT jsonb_array_elements_text(NEW.data->'proposers')
+      LIMIT 1
+    ),
+    (
+      CASE
+        WHEN jsonb_typeof(NEW.data->'inputs') = 'array'
+             AND jsonb_array_length(NEW.data->'inputs') > 0
+        THEN NEW.data->'inputs'->0->>'address'
+        ELSE NULL
+      END
+    )
+  );
+
+  mentions := {{schema}}.extract_addresses(NEW.data);
+  metadata := {{schema}}.extract_metadata(NEW.data);
+
+  -- Extract the decoded data from the IBC packet
+  IF NEW.data->>'@type' = '/ibc.core.channel.v1.MsgRecvPacket' THEN
+    IF metadata->'packet' ? 'data' THEN
+      BEGIN
+        decoded_bytes := decode(metadata->'packet'->>'data', 'base64');
+        decoded_text := convert_from(decoded_bytes, 'UTF8');
+        decoded_json := decoded_text::jsonb;
+        metadata := metadata || jsonb_build_object('decodedData', decoded_json);
+        IF decoded_json ? 'sender' THEN
+          sender := decoded_json->>'sender';
+        END IF;
+        new_addresses := {{schema}}.extract_addresses(decoded_json);
+        SELECT array_agg(DISTINCT addr) INTO mentions
+        FROM unnest(mentions || new_addresses) AS addr;
+      EXCEPTION WHEN OTHERS THEN
+        -- On error, update the error field in the matching transaction
+        UPDATE {{schema}}.transactions_main
+        SET error = 'Error decoding base64 packet data'
+        WHERE id = NEW.id;
+      END;
+    END IF;
+  END IF;
+
+  INSERT INTO {{schema}}.messages_main (id, message_index, parent_index, path, type, sender, mentions, metadata)
+  VALUES (
+           NEW.id,
+           NEW.message_index,
+           NEW.parent_index,
+           NEW.path,
+           NEW.data->>'@type',
+           sender,
+           mentions,
+           metadata
+         )
+  ON CONFLICT (id, message_index) DO UPDATE
+  SET parent_index = EXCLUDED.parent_index,
+      path = EXCLUDED.path,
+      type = EXCLUDED.type,
+      sender = EXCLUDED.sender,
+      mentions = EXCLUDED.mentions,
+      metadata = EXCLUDED.metadata;
+
+  RETURN NEW;
+END;
+$$;
+
+---
+-- API function to get transactions and proposals by address
+---
+DROP FUNCTION IF EXISTS {{schema}}.get_messages_for_address(TEXT);
+CREATE OR REPLACE FUNCTION {{schema}}.get_messages_for_address(_address TEXT)
+  RETURNS TABLE (
+    id VARCHAR(64),
+    message_index BIGINT,
+    parent_index BIGINT,
+    path INT[],
+    type TEXT,
+    sender TEXT,
+    mentions TEXT[],
+    metadata JSONB,
+    fee JSONB,
+    memo TEXT,
+    height BIGINT,
+    "timestamp" TIMESTAMPTZ,
+    error TEXT,
+    proposal_ids TEXT[]
+  )
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  RETURN QUERY
+    SELECT  m.id,
+            m.message_index,
+            m.parent_index,
+            m.path,
+            m.type,
+            m.sender,
+            m.mentions,
+            m.metadata,
+            t.fee,
+            t.memo,
+            t.height,
+            t.timestamp,
+            t.error,
+            t.proposal_ids
+    FROM {{schema}}.messages_main AS m
+    JOIN {{schema}}.transactions_main AS t ON m.id = t.id
+    LEFT JOIN {{schema}}.messages_main AS root ON root.id = m.id AND root.message_index = m.path[1]
+    WHERE
+        -- Always return top-level messages where address is the sender
+        m.sender = _address AND m.parent_index IS NULL
+      OR
+        -- Return top-level messages where address is mentioned and the transaction was successful
+        (t.error IS NULL AND m.parent_index IS NULL AND m.mentions @> ARRAY[_address])
+      OR
+      -- Return nested messages executed by the transaction, e.g., authz or ICA, where address is mentioned
+      (
+        m.parent_index IS NOT NULL
+        AND t.error IS NULL
+        AND root.type NOT IN (
+          '/cosmos.group.v1.MsgSubmitProposal',
+          '/cosmos.gov.v1.MsgSubmitProposal',
+          '/cosmos.gov.v1beta1.MsgSubmitProposal'
+        )
+        AND m.mentions @> ARRAY[_address]
+      )
+      OR
+      -- Return nested messages where address is mentioned and the group proposal was successfully executed
+      (
+        m.parent_index IS NOT NULL
+        AND root.type = '/cosmos.group.v1.MsgSubmitProposal'
+        AND
+        EXISTS (
+          SELECT 1
+          FROM {{schema}}.transactions_main tx2 -- The MsgExec transaction
+          JOIN {{schema}}.messages_main     m2 ON tx2.id = m2.id -- The MsgExec message
+          WHERE
+            tx2.error IS NULL
+            AND m2.type = '/cosmos.group.v1.MsgExec'
+            AND (tx2.proposal_ids && t.proposal_ids)
+        )
+        AND
+        m.mentions @> ARRAY[_address]
+      )
+      OR
+      -- Return MsgExec related to the group policy address
+      (
+        m.type = '/cosmos.group.v1.MsgExec'
+        AND
+        EXISTS (
+         SELECT 1
+         FROM {{schema}}.transactions_main tx2 -- The MsgSubmitProposal transaction
+         JOIN {{schema}}.messages_main m2 ON tx2.id = m2.id -- The MsgSubmitProposal message
+         WHERE
+         tx2.error IS NULL
+         AND tx2.proposal_ids && t.proposal_ids
+         AND m2.type = '/cosmos.group.v1.MsgSubmitProposal'
+         AND m2.metadata->>'groupPolicyAddress' = _address
+        )
+      );
+END;
+$$;
+
+GRANT EXECUTE ON FUNCTION {{schema}}.get_messages_for_address(TEXT) TO {{anon_role}};
+
+---
+-- Drop the nested messages stored with the `10000 + ...` index scheme, then
+-- convert the existing data to the new schema using a staging table and our update triggers
+---
+DELETE FROM {{schema}}.messages_main WHERE message_index >= 10000;
+DELETE FROM {{schema}}.messages_raw WHERE message_index >= 10000;
+
+CREATE TABLE IF NOT EXISTS {{schema}}.transactions_staging (
+    id VARCHAR(64) PRIMARY KEY,
+    data JSONB NOT NULL
+);
+
+CREATE OR REPLACE TRIGGER staging_transaction_update
+AFTER INSERT OR UPDATE
+ON {{schema}}.transactions_staging
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_transaction_main();
+
+-- Re-bind the triggers to the functions of the schema; the legacy functions do not extract the nested messages
+CREATE OR REPLACE TRIGGER new_transaction_update
+AFTER INSERT OR UPDATE
+ON {{schema}}.transactions_raw
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_transaction_main();
+
+CREATE OR REPLACE TRIGGER new_message_update
+AFTER INSERT OR UPDATE
+ON {{schema}}.messages_raw
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_message_main();
+
+INSERT INTO {{schema}}.transactions_staging(id, data)
+SELECT id, data
+FROM {{schema}}.transactions_raw;
+
+DROP TRIGGER staging_transaction_update ON {{schema}}.transactions_staging;
+DROP TABLE {{schema}}.transactions_staging;
+
+COMMIT;
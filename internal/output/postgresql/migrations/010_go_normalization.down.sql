@@ -0,0 +1,445 @@
+BEGIN;
+
+---
+-- Restore the triggers normalizing the raw transactions
+---
+CREATE OR REPLACE FUNCTION {{schema}}.extract_addresses(msg JSONB)
+RETURNS TEXT[]
+LANGUAGE SQL STABLE
+AS $$
+WITH addresses AS (
+  SELECT unnest(
+    regexp_matches(
+      -- Convert the JSONB to text, then do a pattern match
+      msg::text,
+      -- Very rough bech32-like pattern:
+      --   - 2-83 chars of [a-z0-9], plus '1', plus 38+ chars of the set [qpzry9x8gf2tvdw0s3jn54khce6mua7l]
+      --   We allow trailing chars because some addresses can be longer if they contain e.g. valoper style, etc.
+      E'(?<=[\\"\'\\\\s]|^)([a-z0-9]{2,83}1[qpzry9x8gf2tvdw0s3jn54khce6mua7l]{38,})(?=[\\"\'\\\\s]|$)',
+      'g'
+    )
+  ) AS addr
+)
+SELECT array_agg(DISTINCT addr)
+FROM addresses;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_metadata(msg JSONB)
+RETURNS JSONB
+LANGUAGE SQL STABLE
+AS $$
+  WITH keys_to_remove AS (
+      SELECT ARRAY['@type', 'sender', 'executor', 'admin', 'voter', 'messages', 'proposalId', 'proposers', 'authority', 'fromAddress']::text[] AS keys
+  )
+  SELECT msg - (SELECT keys FROM keys_to_remove)
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_proposal_failure_logs(json_data JSONB)
+RETURNS TEXT
+LANGUAGE sql
+AS $$
+WITH
+  events AS (
+    SELECT jsonb_array_elements(json_data->'txResponse'->'events') AS event
+  ),
+
+  typed_attributes AS (
+    SELECT
+      event->>'type' AS event_type,
+      jsonb_array_elements(event->'attributes') AS attribute
+    FROM events
+  )
+
+  SELECT
+    TRIM(BOTH '"' FROM typed_attributes.attribute->>'value') AS logs
+  FROM typed_attributes
+  WHERE
+    typed_attributes.event_type = 'cosmos.group.v1.EventExec'
+    AND typed_attributes.attribute->>'key' = 'logs'
+    AND EXISTS (
+      SELECT 1
+      FROM typed_attributes t2
+      WHERE t2.event_type = typed_attributes.event_type
+        AND t2.attribute->>'key' = 'result'
+        AND t2.attribute->>'value' = '"PROPOSAL_EXECUTOR_RESULT_FAILURE"'
+    )
+  LIMIT 1;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_proposal_ids(events JSONB)
+RETURNS TEXT[]
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  proposal_ids TEXT[];
+BEGIN
+   SELECT
+     ARRAY_AGG(DISTINCT TRIM(BOTH '"' FROM attr->>'value'))
+   INTO proposal_ids
+   FROM jsonb_array_elements(events) AS ev(event)
+   CROSS JOIN LATERAL jsonb_array_elements(ev.event->'attributes') AS attr
+   WHERE attr->>'key' = 'proposal_id';
+
+  RETURN proposal_ids;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.decode_ica_messages(packet_data JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql
+IMMUTABLE
+AS $$
+DECLARE
+  cosmos_tx JSONB;
+BEGIN
+  IF packet_data->>'type' IS NULL
+     OR packet_data->>'type' NOT IN ('TYPE_EXECUTE_TX', '1')
+     OR packet_data->>'data' IS NULL THEN
+    RETURN '[]'::jsonb;
+  END IF;
+
+  cosmos_tx := convert_from(decode(packet_data->>'data', 'base64'), 'UTF8')::jsonb;
+  RETURN COALESCE(cosmos_tx->'messages', '[]'::jsonb);
+EXCEPTION WHEN OTHERS THEN
+  RETURN '[]'::jsonb;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_nested_messages(msg JSONB)
+RETURNS JSONB
+LANGUAGE plpgsql
+IMMUTABLE
+AS $$
+DECLARE
+  packet_data JSONB;
+BEGIN
+  CASE msg->>'@type'
+    WHEN '/cosmos.group.v1.MsgSubmitProposal', '/cosmos.gov.v1.MsgSubmitProposal' THEN
+      RETURN COALESCE(msg->'messages', '[]'::jsonb);
+    WHEN '/cosmos.authz.v1beta1.MsgExec' THEN
+      RETURN COALESCE(msg->'msgs', '[]'::jsonb);
+    WHEN '/cosmos.gov.v1beta1.MsgSubmitProposal', '/cosmos.gov.v1.MsgExecLegacyContent' THEN
+      IF jsonb_typeof(msg->'content') = 'object' THEN
+        RETURN jsonb_build_array(msg->'content');
+      END IF;
+    WHEN '/ibc.applications.interchain_accounts.controller.v1.MsgSendTx' THEN
+      RETURN {{schema}}.decode_ica_messages(msg->'packetData');
+    WHEN '/ibc.core.channel.v1.MsgRecvPacket' THEN
+      BEGIN
+        packet_data := convert_from(decode(msg->'packet'->>'data', 'base64'), 'UTF8')::jsonb;
+      EXCEPTION WHEN OTHERS THEN
+        RETURN '[]'::jsonb;
+      END;
+      IF jsonb_typeof(packet_data) = 'object' THEN
+        RETURN {{schema}}.decode_ica_messages(packet_data);
+      END IF;
+    ELSE
+      NULL;
+  END CASE;
+
+  RETURN '[]'::jsonb;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.update_transaction_main()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  error_text TEXT;
+  proposal_ids TEXT[];
+BEGIN
+  error_text := NEW.data->'txResponse'->>'rawLog';
+
+  IF error_text IS NULL THEN
+    error_text := {{schema}}.extract_proposal_failure_logs(NEW.data);
+  END IF;
+
+  proposal_ids := {{schema}}.extract_proposal_ids(NEW.data->'txResponse'->'events');
+
+  INSERT INTO {{schema}}.transactions_main (id, fee, memo, error, height, timestamp, proposal_ids)
+  VALUES (
+            NEW.id,
+            NEW.data->'tx'->'authInfo'->'fee',
+            NEW.data->'tx'->'body'->>'memo',
+            error_text,
+            (NEW.data->'txResponse'->>'height')::BIGINT,
+            (NEW.data->'txResponse'->>'timestamp')::TIMESTAMPTZ,
+            proposal_ids
+         )
+  ON CONFLICT (id) DO UPDATE
+  SET fee = EXCLUDED.fee,
+      memo = EXCLUDED.memo,
+      error = EXCLUDED.error,
+      height = EXCLUDED.height,
+      timestamp = EXCLUDED.timestamp,
+      proposal_ids = EXCLUDED.proposal_ids;
+
+  -- Insert the top level and nested messages
+  INSERT INTO {{schema}}.messages_raw (id, message_index, parent_index, path, data)
+  WITH RECURSIVE tree(path, data) AS (
+    SELECT ARRAY[(ord - 1)::int], msg
+    FROM jsonb_array_elements(NEW.data->'tx'->'body'->'messages') WITH ORDINALITY AS top_level(msg, ord)
+    UNION ALL
+    SELECT tree.path || (nested.ord - 1)::int, nested.msg
+    FROM tree
+    CROSS JOIN LATERAL jsonb_array_elements({{schema}}.extract_nested_messages(tree.data)) WITH ORDINALITY AS nested(msg, ord)
+    -- Guard against pathological nesting
+    WHERE cardinality(tree.path) < 16
+  ),
+  numbered AS (
+    SELECT
+      path,
+      data,
+      CASE
+        WHEN cardinality(path) = 1 THEN path[1]::bigint
+        ELSE jsonb_array_length(NEW.data->'tx'->'body'->'messages')
+             + row_number() OVER (PARTITION BY cardinality(path) = 1 ORDER BY path) - 1
+      END AS message_index
+    FROM tree
+  )
+  SELECT
+    NEW.id,
+    child.message_index,
+    parent.message_index,
+    child.path,
+    child.data
+  FROM numbered child
+  LEFT JOIN numbered parent
+    ON cardinality(child.path) > 1
+   AND parent.path = child.path[1:cardinality(child.path) - 1]
+  ON CONFLICT (id, message_index) DO UPDATE
+  SET parent_index = EXCLUDED.parent_index,
+      path = EXCLUDED.path,
+      data = EXCLUDED.data;
+
+  RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.update_message_main()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  sender TEXT;
+  mentions TEXT[];
+  metadata JSONB;
+  decoded_bytes BYTEA;
+  decoded_text TEXT;
+  decoded_json JSONB;
+  new_addresses TEXT[];
+BEGIN
+  sender := COALESCE(
+    NULLIF(NEW.data->>'sender', ''),
+    NULLIF(NEW.data->>'fromAddress', ''),
+    NULLIF(NEW.data->>'admin', ''),
+    NULLIF(NEW.data->>'voter', ''),
+    NULLIF(NEW.data->>'address', ''),
+    NULLIF(NEW.data->>'executor', ''),
+    NULLIF(NEW.data->>'authority', ''),
+    NULLIF(NEW.data->>'granter', ''),
+    NULLIF(NEW.data->>'grantee', ''),
+    (
+      SELECT jsonb_array_elements_text(NEW.data->'proposers')
+      LIMIT 1
+    ),
+    (
+      CASE
+        WHEN jsonb_typeof(NEW.data->'inputs') = 'array'
+             AND jsonb_array_length(NEW.data->'inputs') > 0
+        THEN NEW.data->'inputs'->0->>'address'
+        ELSE NULL
+      END
+    )
+  );
+
+  mentions := {{schema}}.extract_addresses(NEW.data);
+  metadata := {{schema}}.extract_metadata(NEW.data);
+
+  -- Extract the decoded data from the IBC packet
+  IF NEW.data->>'@type' = '/ibc.core.channel.v1.MsgRecvPacket' THEN
+    IF metadata->'packet' ? 'data' THEN
+      BEGIN
+        decoded_bytes := decode(metadata->'packet'->>'data', 'base64');
+        decoded_text := convert_from(decoded_bytes, 'UTF8');
+        decoded_json := decoded_text::jsonb;
+        metadata := metadata || jsonb_build_object('decodedData', decoded_json);
+        IF decoded_json ? 'sender' THEN
+          sender := decoded_json->>'sender';
+        END IF;
+        new_addresses := {{schema}}.extract_addresses(decoded_json);
+        SELECT array_agg(DISTINCT addr) INTO mentions
+        FROM unnest(mentions || new_addresses) AS addr;
+      EXCEPTION WHEN OTHERS THEN
+        -- On error, update the error field in the matching transaction
+        UPDATE {{schema}}.transactions_main
+        SET error = 'Error decoding base64 packet data'
+        WHERE id = NEW.id;
+      END;
+    END IF;
+  END IF;
+
+  INSERT INTO {{schema}}.messages_main (id, message_index, parent_index, path, type, sender, mentions, metadata)
+  VALUES (
+           NEW.id,
+           NEW.message_index,
+           NEW.parent_index,
+           NEW.path,
+           NEW.data->>'@type',
+           sender,
+           mentions,
+           metadata
+         )
+  ON CONFLICT (id, message_index) DO UPDATE
+  SET parent_index = EXCLUDED.parent_index,
+      path = EXCLUDED.path,
+      type = EXCLUDED.type,
+      sender = EXCLUDED.sender,
+      mentions = EXCLUDED.mentions,
+      metadata = EXCLUDED.metadata;
+
+  RETURN NEW;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.extract_event_msg_index(ev jsonb)
+RETURNS bigint
+LANGUAGE sql
+STABLE
+AS $$
+  SELECT NULLIF(a->>'value','')::bigint
+  FROM jsonb_array_elements(ev->'attributes') a
+  WHERE a->>'key' = 'msg_index'
+  LIMIT 1
+$$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.update_events_raw()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  ev jsonb;
+  ev_ord int;
+BEGIN
+  -- Rebuild all events for this tx id (safe for INSERT and UPDATE)
+  DELETE FROM {{schema}}.events_raw WHERE id = NEW.id;
+
+  FOR ev, ev_ord IN
+    SELECT e, (ord::int - 1)
+    FROM jsonb_array_elements(NEW.data->'txResponse'->'events') WITH ORDINALITY AS t(e, ord)
+  LOOP
+    INSERT INTO {{schema}}.events_raw (id, event_index, data)
+    VALUES (NEW.id, ev_ord, ev);
+  END LOOP;
+
+  RETURN NEW;
+END $$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.update_event_main()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+  a jsonb;
+  a_ord int;
+  msg_idx bigint;
+  ev_type text;
+BEGIN
+  -- Get msg_index once per event
+  msg_idx := {{schema}}.extract_event_msg_index(NEW.data);
+  ev_type := NEW.data->>'type';
+
+  -- Rebuild attributes for this (id, event_index)
+  DELETE FROM {{schema}}.events_main
+  WHERE id = NEW.id AND event_index = NEW.event_index;
+
+  FOR a, a_ord IN
+    SELECT attr, (ord::int - 1)
+    FROM jsonb_array_elements(NEW.data->'attributes') WITH ORDINALITY AS t(attr, ord)
+  LOOP
+    INSERT INTO {{schema}}.events_main (
+      id, event_index, attr_index, event_type, attr_key, attr_value, msg_index
+    ) VALUES (
+      NEW.id,
+      NEW.event_index,
+      a_ord,
+      ev_type,
+      a->>'key',
+      a->>'value',
+      msg_idx
+    );
+  END LOOP;
+
+  RETURN NEW;
+END $$;
+
+CREATE OR REPLACE FUNCTION {{schema}}.update_vesting_periods()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+  DELETE FROM {{schema}}.vesting_periods
+  WHERE id = NEW.id AND message_index = NEW.message_index;
+
+  IF NEW.type IS DISTINCT FROM '/cosmos.vesting.v1beta1.MsgCreatePeriodicVestingAccount' THEN
+    RETURN NEW;
+  END IF;
+
+  INSERT INTO {{schema}}.vesting_periods (id, message_index, period_index, address, denom, amount, unlock_time, end_time)
+  WITH periods AS (
+    SELECT
+      (p.ord - 1) AS period_index,
+      p.period,
+      (NEW.metadata->>'startTime')::bigint
+        + SUM(COALESCE((p.period->>'length')::bigint, 0)) OVER (ORDER BY p.ord) AS unlock_epoch
+    FROM jsonb_array_elements(NEW.metadata->'vestingPeriods') WITH ORDINALITY AS p(period, ord)
+  )
+  SELECT
+    NEW.id,
+    NEW.message_index,
+    periods.period_index,
+    NEW.metadata->>'toAddress',
+    c.coin->>'denom',
+    (c.coin->>'amount')::numeric,
+    to_timestamp(periods.unlock_epoch),
+    to_timestamp(MAX(periods.unlock_epoch) OVER ())
+  FROM periods
+  CROSS JOIN LATERAL jsonb_array_elements(periods.period->'amount') AS c(coin)
+  ON CONFLICT (id, message_index, period_index, denom) DO UPDATE
+  SET amount = {{schema}}.vesting_periods.amount + EXCLUDED.amount;
+
+  RETURN NEW;
+END $$;
+
+CREATE OR REPLACE TRIGGER new_transaction_update
+AFTER INSERT OR UPDATE
+ON {{schema}}.transactions_raw
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_transaction_main();
+
+CREATE OR REPLACE TRIGGER new_message_update
+AFTER INSERT OR UPDATE
+ON {{schema}}.messages_raw
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_message_main();
+
+CREATE OR REPLACE TRIGGER new_transaction_events_raw
+AFTER INSERT OR UPDATE OF data
+ON {{schema}}.transactions_raw
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_events_raw();
+
+CREATE OR REPLACE TRIGGER new_event_update
+AFTER INSERT OR UPDATE OF data
+ON {{schema}}.events_raw
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_event_main();
+
+CREATE OR REPLACE TRIGGER new_message_vesting_periods
+AFTER INSERT OR UPDATE
+ON {{schema}}.messages_main
+FOR EACH ROW
+EXECUTE FUNCTION {{schema}}.update_vesting_periods();
+
+COMMIT;
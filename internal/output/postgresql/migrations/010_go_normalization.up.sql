@@ -0,0 +1,35 @@
+BEGIN;
+
+---
+-- Transactions, messages, events and vesting periods are normalized by the indexer before they are written.
+-- Drop the triggers and functions that used to normalize them.
+---
+DROP TRIGGER IF EXISTS new_transaction_update ON {{schema}}.transactions_raw;
+DROP TRIGGER IF EXISTS new_message_update ON {{schema}}.messages_raw;
+DROP TRIGGER IF EXISTS new_transaction_events_raw ON {{schema}}.transactions_raw;
+DROP TRIGGER IF EXISTS new_event_update ON {{schema}}.events_raw;
+DROP TRIGGER IF EXISTS new_message_vesting_periods ON {{schema}}.messages_main;
+
+DROP FUNCTION IF EXISTS {{schema}}.update_transaction_main();
+DROP FUNCTION IF EXISTS {{schema}}.update_message_main();
+DROP FUNCTION IF EXISTS {{schema}}.update_events_raw();
+DROP FUNCTION IF EXISTS {{schema}}.update_event_main();
+DROP FUNCTION IF EXISTS {{schema}}.update_vesting_periods();
+
+DROP FUNCTION IF EXISTS {{schema}}.extract_nested_messages(JSONB);
+DROP FUNCTION IF EXISTS {{schema}}.decode_ica_messages(JSONB);
+DROP FUNCTION IF EXISTS {{schema}}.extract_addresses(JSONB);
+DROP FUNCTION IF EXISTS {{schema}}.extract_metadata(JSONB);
+DROP FUNCTION IF EXISTS {{schema}}.extract_proposal_failure_logs(JSONB);
+DROP FUNCTION IF EXISTS {{schema}}.extract_proposal_ids(JSONB);
+DROP FUNCTION IF EXISTS {{schema}}.extract_event_msg_index(JSONB);
+
+-- The functions of 002 to 005 that were created unqualified, before 009 recreated them in the schema
+DROP FUNCTION IF EXISTS public.update_transaction_main();
+DROP FUNCTION IF EXISTS public.update_message_main();
+DROP FUNCTION IF EXISTS public.extract_addresses(JSONB);
+DROP FUNCTION IF EXISTS public.extract_metadata(JSONB);
+DROP FUNCTION IF EXISTS public.extract_proposal_failure_logs(JSONB);
+DROP FUNCTION IF EXISTS public.extract_proposal_ids(JSONB);
+
+COMMIT;
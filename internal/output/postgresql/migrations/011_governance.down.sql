@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP VIEW IF EXISTS {{schema}}.proposal_summaries;
+DROP VIEW IF EXISTS {{schema}}.proposal_messages;
+
+-- Indexes are dropped automatically with the tables
+DROP TABLE IF EXISTS {{schema}}.proposal_tallies;
+DROP TABLE IF EXISTS {{schema}}.proposal_status_changes;
+DROP TABLE IF EXISTS {{schema}}.proposal_deposits;
+DROP TABLE IF EXISTS {{schema}}.proposal_votes;
+DROP TABLE IF EXISTS {{schema}}.proposals;
+
+COMMIT;
@@ -0,0 +1,143 @@
+BEGIN;
+
+-- x/gov and x/group proposals, one row per submitted proposal
+CREATE TABLE IF NOT EXISTS {{schema}}.proposals (
+  module               text        NOT NULL,  -- 'gov' or 'group'
+  proposal_id          bigint      NOT NULL,
+  id                   varchar(64) NOT NULL,  -- submit tx id
+  message_index        bigint      NOT NULL,  -- submit message index
+  height               bigint      NOT NULL,
+  submit_time          timestamptz NOT NULL,
+  proposer             text,
+  group_policy_address text,                  -- group proposals only
+  title                text,
+  summary              text,
+  metadata             text,
+  PRIMARY KEY (module, proposal_id),
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS proposals_id_idx       ON {{schema}}.proposals (id);
+CREATE INDEX IF NOT EXISTS proposals_proposer_idx ON {{schema}}.proposals (proposer);
+CREATE INDEX IF NOT EXISTS proposals_policy_idx   ON {{schema}}.proposals (group_policy_address);
+
+-- Votes, one row per vote message. A voter can vote several times on a x/gov proposal; the latest vote counts.
+CREATE TABLE IF NOT EXISTS {{schema}}.proposal_votes (
+  id            varchar(64) NOT NULL,  -- tx id
+  message_index bigint      NOT NULL,
+  module        text        NOT NULL,
+  proposal_id   bigint      NOT NULL,
+  voter         text        NOT NULL,
+  option        text        NOT NULL,  -- option with the highest weight
+  options       jsonb       NOT NULL,  -- weighted options
+  metadata      text,
+  height        bigint      NOT NULL,
+  PRIMARY KEY (id, message_index),
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS proposal_votes_proposal_idx ON {{schema}}.proposal_votes (module, proposal_id, voter);
+CREATE INDEX IF NOT EXISTS proposal_votes_voter_idx    ON {{schema}}.proposal_votes (voter);
+
+-- x/gov deposits, including the initial deposit, one row per message and denom
+CREATE TABLE IF NOT EXISTS {{schema}}.proposal_deposits (
+  id            varchar(64) NOT NULL,  -- tx id
+  message_index bigint      NOT NULL,
+  denom         text        NOT NULL,
+  proposal_id   bigint      NOT NULL,
+  depositor     text        NOT NULL,
+  amount        numeric     NOT NULL,
+  height        bigint      NOT NULL,
+  PRIMARY KEY (id, message_index, denom),
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS proposal_deposits_proposal_idx  ON {{schema}}.proposal_deposits (proposal_id);
+CREATE INDEX IF NOT EXISTS proposal_deposits_depositor_idx ON {{schema}}.proposal_deposits (depositor);
+
+-- Status transitions, from the transaction events or from the end block events (id is then NULL)
+CREATE TABLE IF NOT EXISTS {{schema}}.proposal_status_changes (
+  module          text        NOT NULL,
+  proposal_id     bigint      NOT NULL,
+  height          bigint      NOT NULL,
+  seq             int         NOT NULL,  -- 0-based within the transaction or the block events
+  status          text        NOT NULL,
+  executor_result text,                  -- group proposals only
+  id              varchar(64),           -- tx id, NULL for end block events
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS proposal_status_changes_uniq_idx
+  ON {{schema}}.proposal_status_changes (module, proposal_id, height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS proposal_status_changes_id_idx     ON {{schema}}.proposal_status_changes (id);
+CREATE INDEX IF NOT EXISTS proposal_status_changes_height_idx ON {{schema}}.proposal_status_changes (height);
+
+-- Tally results, from the transaction events or from the end block events (id is then NULL)
+CREATE TABLE IF NOT EXISTS {{schema}}.proposal_tallies (
+  module             text        NOT NULL,
+  proposal_id        bigint      NOT NULL,
+  height             bigint      NOT NULL,
+  seq                int         NOT NULL,  -- 0-based within the transaction or the block events
+  yes_count          numeric     NOT NULL,
+  abstain_count      numeric     NOT NULL,
+  no_count           numeric     NOT NULL,
+  no_with_veto_count numeric     NOT NULL,
+  id                 varchar(64),           -- tx id, NULL for end block events
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS proposal_tallies_uniq_idx
+  ON {{schema}}.proposal_tallies (module, proposal_id, height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS proposal_tallies_id_idx     ON {{schema}}.proposal_tallies (id);
+CREATE INDEX IF NOT EXISTS proposal_tallies_height_idx ON {{schema}}.proposal_tallies (height);
+
+-- The messages executed by a proposal when it passes, i.e., the messages nested in the submit message
+CREATE OR REPLACE VIEW {{schema}}.proposal_messages AS
+SELECT
+  p.module,
+  p.proposal_id,
+  m.id,
+  m.message_index,
+  m.type,
+  m.sender,
+  m.mentions,
+  m.metadata
+FROM {{schema}}.proposals p
+JOIN {{schema}}.messages_main m ON m.id = p.id AND m.parent_index = p.message_index;
+
+-- The proposals with their latest status and tally
+CREATE OR REPLACE VIEW {{schema}}.proposal_summaries AS
+SELECT
+  p.*,
+  s.status,
+  s.executor_result,
+  s.height AS status_height,
+  t.yes_count,
+  t.abstain_count,
+  t.no_count,
+  t.no_with_veto_count
+FROM {{schema}}.proposals p
+LEFT JOIN LATERAL (
+  SELECT status, executor_result, height
+  FROM {{schema}}.proposal_status_changes sc
+  WHERE sc.module = p.module AND sc.proposal_id = p.proposal_id
+  ORDER BY sc.height DESC, sc.id IS NULL DESC, sc.seq DESC
+  LIMIT 1
+) s ON TRUE
+LEFT JOIN LATERAL (
+  SELECT yes_count, abstain_count, no_count, no_with_veto_count
+  FROM {{schema}}.proposal_tallies pt
+  WHERE pt.module = p.module AND pt.proposal_id = p.proposal_id
+  ORDER BY pt.height DESC, pt.id IS NULL DESC, pt.seq DESC
+  LIMIT 1
+) t ON TRUE;
+
+GRANT SELECT ON {{schema}}.proposals TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_votes TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_deposits TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_status_changes TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_tallies TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_messages TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_summaries TO {{anon_role}};
+
+COMMIT;
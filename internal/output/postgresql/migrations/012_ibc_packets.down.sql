@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP VIEW IF EXISTS {{schema}}.ibc_denom_traces;
+DROP VIEW IF EXISTS {{schema}}.ibc_packets;
+
+-- Indexes are dropped automatically with the table
+DROP TABLE IF EXISTS {{schema}}.ibc_packet_events;
+
+COMMIT;
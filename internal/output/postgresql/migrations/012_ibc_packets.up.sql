@@ -0,0 +1,115 @@
+BEGIN;
+
+-- IBC packet lifecycle events, one row per send_packet, recv_packet, write_acknowledgement, acknowledge_packet
+-- and timeout_packet event. A packet is identified by its direction and sequence on a local port and channel.
+CREATE TABLE IF NOT EXISTS {{schema}}.ibc_packet_events (
+  id                varchar(64) NOT NULL,  -- tx id
+  event_index       bigint      NOT NULL,
+  msg_index         bigint,
+  height            bigint      NOT NULL,
+  timestamp         timestamptz NOT NULL,
+  event_type        text        NOT NULL,
+  direction         text        NOT NULL,  -- 'outgoing' or 'incoming'
+  port              text        NOT NULL,  -- local end of the channel
+  channel           text        NOT NULL,
+  sequence          bigint      NOT NULL,
+  src_port          text        NOT NULL,
+  src_channel       text        NOT NULL,
+  dst_port          text        NOT NULL,
+  dst_channel       text        NOT NULL,
+  connection_id     text,
+  timeout_height    text,
+  timeout_timestamp text,
+  data              jsonb,                 -- send_packet and recv_packet only
+  acknowledgement   jsonb,                 -- write_acknowledgement only
+  ack_success       boolean,
+  -- ICS-20 transfers
+  sender            text,
+  receiver          text,
+  denom             text,                  -- denomination in the packet
+  amount            numeric,
+  memo              text,
+  base_denom        text,
+  denom_path        text,
+  local_denom       text,                  -- denomination on this chain
+  PRIMARY KEY (id, event_index),
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS ibc_packet_events_packet_idx   ON {{schema}}.ibc_packet_events (direction, port, channel, sequence);
+CREATE INDEX IF NOT EXISTS ibc_packet_events_sender_idx   ON {{schema}}.ibc_packet_events (sender);
+CREATE INDEX IF NOT EXISTS ibc_packet_events_receiver_idx ON {{schema}}.ibc_packet_events (receiver);
+CREATE INDEX IF NOT EXISTS ibc_packet_events_height_idx   ON {{schema}}.ibc_packet_events (height);
+
+-- One row per sent or received packet, with its acknowledgement or timeout.
+-- The latency of an outgoing packet is the time between its sending and its acknowledgement or timeout on this chain.
+CREATE OR REPLACE VIEW {{schema}}.ibc_packets AS
+SELECT
+  p.direction,
+  p.port,
+  p.channel,
+  p.sequence,
+  p.src_port,
+  p.src_channel,
+  p.dst_port,
+  p.dst_channel,
+  p.connection_id,
+  p.id AS tx_id,
+  p.height,
+  p.timestamp,
+  p.timeout_height,
+  p.timeout_timestamp,
+  p.data,
+  p.sender,
+  p.receiver,
+  p.denom,
+  p.amount,
+  p.memo,
+  p.base_denom,
+  p.denom_path,
+  p.local_denom,
+  ack.id AS ack_tx_id,
+  ack.height AS ack_height,
+  ack.timestamp AS ack_timestamp,
+  ack.acknowledgement,
+  ack.ack_success,
+  t.id AS timeout_tx_id,
+  t.height AS timeout_tx_height,
+  t.timestamp AS timeout_tx_timestamp,
+  CASE
+    WHEN t.id IS NOT NULL THEN 'timed_out'
+    WHEN ack.id IS NULL THEN 'pending'
+    WHEN ack.ack_success IS FALSE THEN 'failed'
+    ELSE 'acknowledged'
+  END AS status,
+  COALESCE(ack.timestamp, t.timestamp) - p.timestamp AS latency
+FROM {{schema}}.ibc_packet_events p
+LEFT JOIN LATERAL (
+  SELECT a.id, a.height, a.timestamp, a.acknowledgement, a.ack_success
+  FROM {{schema}}.ibc_packet_events a
+  WHERE a.direction = p.direction AND a.port = p.port AND a.channel = p.channel AND a.sequence = p.sequence
+    AND a.event_type IN ('acknowledge_packet', 'write_acknowledgement')
+  ORDER BY a.height, a.event_index
+  LIMIT 1
+) ack ON true
+LEFT JOIN LATERAL (
+  SELECT a.id, a.height, a.timestamp
+  FROM {{schema}}.ibc_packet_events a
+  WHERE a.direction = p.direction AND a.port = p.port AND a.channel = p.channel AND a.sequence = p.sequence
+    AND a.event_type = 'timeout_packet'
+  ORDER BY a.height, a.event_index
+  LIMIT 1
+) t ON true
+WHERE p.event_type IN ('send_packet', 'recv_packet');
+
+-- The IBC denominations seen in transfers, with their trace
+CREATE OR REPLACE VIEW {{schema}}.ibc_denom_traces AS
+SELECT DISTINCT local_denom, denom_path, base_denom
+FROM {{schema}}.ibc_packet_events
+WHERE denom_path <> '';
+
+GRANT SELECT ON {{schema}}.ibc_packet_events TO {{anon_role}};
+GRANT SELECT ON {{schema}}.ibc_packets TO {{anon_role}};
+GRANT SELECT ON {{schema}}.ibc_denom_traces TO {{anon_role}};
+
+COMMIT;
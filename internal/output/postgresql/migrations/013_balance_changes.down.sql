@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS {{schema}}.get_balance_at(text, bigint);
+DROP VIEW IF EXISTS {{schema}}.balance_deltas;
+
+-- Indexes are dropped automatically with the table
+DROP TABLE IF EXISTS {{schema}}.balance_changes;
+
+COMMIT;
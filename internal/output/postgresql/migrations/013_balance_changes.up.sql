@@ -0,0 +1,45 @@
+BEGIN;
+
+-- Balance changes, one row per address and denomination of a coin_spent or coin_received event,
+-- from the transaction events or from the block events (id is then NULL)
+CREATE TABLE IF NOT EXISTS {{schema}}.balance_changes (
+  height      bigint      NOT NULL,
+  seq         int         NOT NULL,  -- position in the transaction or block changes
+  id          varchar(64),           -- tx id
+  event_index bigint      NOT NULL,
+  address     text        NOT NULL,
+  denom       text        NOT NULL,
+  amount      numeric     NOT NULL,  -- negative for spent coins
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS balance_changes_seq_idx
+  ON {{schema}}.balance_changes (height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS balance_changes_address_idx ON {{schema}}.balance_changes (address, denom, height);
+CREATE INDEX IF NOT EXISTS balance_changes_id_idx      ON {{schema}}.balance_changes (id);
+
+-- Net balance change per address, denomination and height
+CREATE OR REPLACE VIEW {{schema}}.balance_deltas AS
+SELECT address, denom, height, SUM(amount) AS amount
+FROM {{schema}}.balance_changes
+GROUP BY address, denom, height;
+
+-- Balances of an address at a height, from the indexed balance changes.
+-- The balances are only accurate if the chain is indexed from its genesis.
+CREATE OR REPLACE FUNCTION {{schema}}.get_balance_at(_address text, _height bigint)
+RETURNS TABLE (denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT bc.denom, SUM(bc.amount) AS amount
+  FROM {{schema}}.balance_changes bc
+  WHERE bc.address = _address AND bc.height <= _height
+  GROUP BY bc.denom
+  HAVING SUM(bc.amount) <> 0
+  ORDER BY bc.denom;
+$$;
+
+GRANT SELECT ON {{schema}}.balance_changes TO {{anon_role}};
+GRANT SELECT ON {{schema}}.balance_deltas TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_balance_at(text, bigint) TO {{anon_role}};
+
+COMMIT;
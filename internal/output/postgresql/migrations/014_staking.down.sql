@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS {{schema}}.get_delegations_at(text, bigint);
+DROP VIEW IF EXISTS {{schema}}.delegation_deltas;
+
+-- Indexes are dropped automatically with the tables
+DROP TABLE IF EXISTS {{schema}}.validator_snapshots;
+DROP TABLE IF EXISTS {{schema}}.staking_rewards;
+DROP TABLE IF EXISTS {{schema}}.delegation_changes;
+
+COMMIT;
@@ -0,0 +1,105 @@
+BEGIN;
+
+-- Delegation changes, from the transaction events or from the block events (id is then NULL), e.g., the completed unbondings.
+-- For redelegations, validator is the destination validator.
+CREATE TABLE IF NOT EXISTS {{schema}}.delegation_changes (
+  height          bigint      NOT NULL,
+  seq             int         NOT NULL,  -- position in the transaction or block changes
+  id              varchar(64),           -- tx id
+  event_index     bigint      NOT NULL,
+  msg_index       bigint,
+  timestamp       timestamptz,           -- tx timestamp
+  action          text        NOT NULL,  -- delegate, unbond, redelegate, cancel_unbonding_delegation, complete_unbonding, complete_redelegation
+  delegator       text        NOT NULL,
+  validator       text        NOT NULL,
+  src_validator   text,
+  denom           text        NOT NULL,
+  amount          numeric     NOT NULL,
+  completion_time timestamptz,
+  creation_height bigint,
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS delegation_changes_seq_idx
+  ON {{schema}}.delegation_changes (height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS delegation_changes_delegator_idx ON {{schema}}.delegation_changes (delegator, height);
+CREATE INDEX IF NOT EXISTS delegation_changes_validator_idx ON {{schema}}.delegation_changes (validator, height);
+CREATE INDEX IF NOT EXISTS delegation_changes_id_idx        ON {{schema}}.delegation_changes (id);
+
+-- Withdrawn delegator rewards and validator commissions, one row per denomination
+CREATE TABLE IF NOT EXISTS {{schema}}.staking_rewards (
+  height      bigint      NOT NULL,
+  seq         int         NOT NULL,
+  id          varchar(64),
+  event_index bigint      NOT NULL,
+  msg_index   bigint,
+  timestamp   timestamptz,
+  kind        text        NOT NULL,  -- 'reward' or 'commission'
+  delegator   text,                  -- NULL for commissions
+  validator   text        NOT NULL,
+  denom       text        NOT NULL,
+  amount      numeric     NOT NULL,
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS staking_rewards_seq_idx
+  ON {{schema}}.staking_rewards (height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS staking_rewards_delegator_idx ON {{schema}}.staking_rewards (delegator, height);
+CREATE INDEX IF NOT EXISTS staking_rewards_validator_idx ON {{schema}}.staking_rewards (validator, height);
+CREATE INDEX IF NOT EXISTS staking_rewards_id_idx        ON {{schema}}.staking_rewards (id);
+
+-- Periodic snapshots of the validator set
+CREATE TABLE IF NOT EXISTS {{schema}}.validator_snapshots (
+  height           bigint      NOT NULL,
+  operator_address text        NOT NULL,
+  snapshot_time    timestamptz NOT NULL,
+  moniker          text,
+  status           text,
+  jailed           boolean     NOT NULL,
+  tokens           numeric     NOT NULL,
+  delegator_shares numeric     NOT NULL,
+  commission_rate  numeric,
+  consensus_pubkey jsonb,
+  data             jsonb       NOT NULL,
+  PRIMARY KEY (height, operator_address)
+);
+
+CREATE INDEX IF NOT EXISTS validator_snapshots_operator_idx ON {{schema}}.validator_snapshots (operator_address, height);
+
+-- Signed change of the delegated amount per delegator, validator and height.
+-- Unbonding and redelegated tokens leave the delegation when the unbonding or redelegation starts.
+-- Slashing is not accounted for.
+CREATE OR REPLACE VIEW {{schema}}.delegation_deltas AS
+SELECT delegator, validator, denom, height, amount
+FROM {{schema}}.delegation_changes
+WHERE action IN ('delegate', 'cancel_unbonding_delegation', 'redelegate')
+UNION ALL
+SELECT delegator, src_validator, denom, height, -amount
+FROM {{schema}}.delegation_changes
+WHERE action = 'redelegate'
+UNION ALL
+SELECT delegator, validator, denom, height, -amount
+FROM {{schema}}.delegation_changes
+WHERE action = 'unbond';
+
+-- Delegations of a delegator at a height, from the indexed delegation changes.
+-- The delegations are only accurate if the chain is indexed from its genesis.
+CREATE OR REPLACE FUNCTION {{schema}}.get_delegations_at(_delegator text, _height bigint)
+RETURNS TABLE (validator text, denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT d.validator, d.denom, SUM(d.amount) AS amount
+  FROM {{schema}}.delegation_deltas d
+  WHERE d.delegator = _delegator AND d.height <= _height
+  GROUP BY d.validator, d.denom
+  HAVING SUM(d.amount) <> 0
+  ORDER BY d.validator, d.denom;
+$$;
+
+GRANT SELECT ON {{schema}}.delegation_changes TO {{anon_role}};
+GRANT SELECT ON {{schema}}.staking_rewards TO {{anon_role}};
+GRANT SELECT ON {{schema}}.validator_snapshots TO {{anon_role}};
+GRANT SELECT ON {{schema}}.delegation_deltas TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_delegations_at(text, bigint) TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- Indexes are dropped automatically with the tables
+DROP TABLE IF EXISTS {{schema}}.wasm_contract_snapshots;
+DROP TABLE IF EXISTS {{schema}}.wasm_events;
+DROP TABLE IF EXISTS {{schema}}.wasm_executions;
+DROP TABLE IF EXISTS {{schema}}.wasm_contracts;
+DROP TABLE IF EXISTS {{schema}}.wasm_codes;
+
+COMMIT;
@@ -0,0 +1,98 @@
+BEGIN;
+
+-- Codes stored by MsgStoreCode
+CREATE TABLE IF NOT EXISTS {{schema}}.wasm_codes (
+  code_id       bigint      PRIMARY KEY,
+  id            varchar(64) NOT NULL,  -- tx id
+  message_index bigint      NOT NULL,
+  height        bigint      NOT NULL,
+  timestamp     timestamptz NOT NULL,
+  creator       text,
+  checksum      text,
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS wasm_codes_id_idx      ON {{schema}}.wasm_codes (id);
+CREATE INDEX IF NOT EXISTS wasm_codes_creator_idx ON {{schema}}.wasm_codes (creator);
+
+-- Contracts instantiated by MsgInstantiateContract(2), or by other contracts (message_index is then NULL)
+CREATE TABLE IF NOT EXISTS {{schema}}.wasm_contracts (
+  address       text        PRIMARY KEY,
+  code_id       bigint      NOT NULL,  -- instantiated code
+  id            varchar(64) NOT NULL,  -- tx id
+  message_index bigint,
+  height        bigint      NOT NULL,
+  timestamp     timestamptz NOT NULL,
+  creator       text,
+  admin         text,
+  label         text,
+  init_msg      jsonb,                 -- decoded instantiation message
+  funds         jsonb,
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS wasm_contracts_id_idx      ON {{schema}}.wasm_contracts (id);
+CREATE INDEX IF NOT EXISTS wasm_contracts_code_idx    ON {{schema}}.wasm_contracts (code_id);
+CREATE INDEX IF NOT EXISTS wasm_contracts_creator_idx ON {{schema}}.wasm_contracts (creator);
+
+-- MsgExecuteContract and MsgMigrateContract messages
+CREATE TABLE IF NOT EXISTS {{schema}}.wasm_executions (
+  id            varchar(64) NOT NULL,  -- tx id
+  message_index bigint      NOT NULL,
+  height        bigint      NOT NULL,
+  timestamp     timestamptz NOT NULL,
+  kind          text        NOT NULL,  -- 'execute' or 'migrate'
+  contract      text        NOT NULL,
+  sender        text,
+  action        text,                  -- top level key of the contract message
+  msg           jsonb,                 -- decoded contract message
+  funds         jsonb,
+  code_id       bigint,                -- new code of migrated contracts
+  PRIMARY KEY (id, message_index),
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS wasm_executions_contract_idx ON {{schema}}.wasm_executions (contract, action, height);
+CREATE INDEX IF NOT EXISTS wasm_executions_sender_idx   ON {{schema}}.wasm_executions (sender, height);
+
+-- Events emitted by the contracts (wasm and wasm-* events), with their attributes as a JSON object
+CREATE TABLE IF NOT EXISTS {{schema}}.wasm_events (
+  id          varchar(64) NOT NULL,  -- tx id
+  event_index bigint      NOT NULL,
+  msg_index   bigint,
+  height      bigint      NOT NULL,
+  timestamp   timestamptz NOT NULL,
+  contract    text        NOT NULL,
+  event_type  text        NOT NULL,
+  action      text,                  -- `action` attribute, or custom event type suffix
+  attributes  jsonb       NOT NULL,
+  PRIMARY KEY (id, event_index),
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS wasm_events_contract_idx   ON {{schema}}.wasm_events (contract, action, height);
+CREATE INDEX IF NOT EXISTS wasm_events_attributes_idx ON {{schema}}.wasm_events USING gin (attributes jsonb_path_ops);
+
+-- Periodic snapshots of the contract information
+CREATE TABLE IF NOT EXISTS {{schema}}.wasm_contract_snapshots (
+  height        bigint      NOT NULL,
+  address       text        NOT NULL,
+  snapshot_time timestamptz NOT NULL,
+  code_id       bigint,
+  creator       text,
+  admin         text,
+  label         text,
+  ibc_port_id   text,
+  data          jsonb       NOT NULL,
+  PRIMARY KEY (height, address)
+);
+
+CREATE INDEX IF NOT EXISTS wasm_contract_snapshots_address_idx ON {{schema}}.wasm_contract_snapshots (address, height);
+
+GRANT SELECT ON {{schema}}.wasm_codes TO {{anon_role}};
+GRANT SELECT ON {{schema}}.wasm_contracts TO {{anon_role}};
+GRANT SELECT ON {{schema}}.wasm_executions TO {{anon_role}};
+GRANT SELECT ON {{schema}}.wasm_events TO {{anon_role}};
+GRANT SELECT ON {{schema}}.wasm_contract_snapshots TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP VIEW IF EXISTS {{schema}}.proposed_blocks;
+DROP VIEW IF EXISTS {{schema}}.validator_signatures;
+
+-- Indexes are dropped automatically with the tables
+DROP TABLE IF EXISTS {{schema}}.validator_consensus_addresses;
+DROP TABLE IF EXISTS {{schema}}.block_signatures;
+DROP TABLE IF EXISTS {{schema}}.block_proposers;
+
+COMMIT;
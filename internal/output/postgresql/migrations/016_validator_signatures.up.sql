@@ -0,0 +1,68 @@
+BEGIN;
+
+-- Proposer of each block. The addresses are upper case hex consensus addresses.
+CREATE TABLE IF NOT EXISTS {{schema}}.block_proposers (
+  height           bigint      PRIMARY KEY,
+  proposer_address text        NOT NULL,
+  time             timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS block_proposers_proposer_idx ON {{schema}}.block_proposers (proposer_address, height);
+
+-- Validator votes of the last commit of each block, indexed at the height of the signed block
+CREATE TABLE IF NOT EXISTS {{schema}}.block_signatures (
+  height            bigint NOT NULL,
+  consensus_address text   NOT NULL,
+  validator_index   int    NOT NULL,  -- position in the validator set
+  block_id_flag     text   NOT NULL,  -- BLOCK_ID_FLAG_COMMIT, BLOCK_ID_FLAG_NIL or BLOCK_ID_FLAG_ABSENT
+  voting_power      bigint,
+  timestamp         timestamptz,
+  PRIMARY KEY (height, consensus_address)
+);
+
+CREATE INDEX IF NOT EXISTS block_signatures_consensus_address_idx ON {{schema}}.block_signatures (consensus_address, height);
+CREATE INDEX IF NOT EXISTS block_signatures_absent_idx
+  ON {{schema}}.block_signatures (height) WHERE block_id_flag = 'BLOCK_ID_FLAG_ABSENT';
+
+-- Consensus address of the validators, from the staking module
+CREATE TABLE IF NOT EXISTS {{schema}}.validator_consensus_addresses (
+  consensus_address text   PRIMARY KEY,
+  operator_address  text   NOT NULL,
+  moniker           text,
+  consensus_pubkey  jsonb,
+  height            bigint NOT NULL  -- height of the last update
+);
+
+CREATE INDEX IF NOT EXISTS validator_consensus_addresses_operator_idx ON {{schema}}.validator_consensus_addresses (operator_address);
+
+CREATE OR REPLACE VIEW {{schema}}.validator_signatures AS
+SELECT
+  s.height,
+  s.consensus_address,
+  a.operator_address,
+  a.moniker,
+  s.validator_index,
+  s.block_id_flag,
+  s.block_id_flag <> 'BLOCK_ID_FLAG_ABSENT' AS signed,
+  s.voting_power,
+  s.timestamp
+FROM {{schema}}.block_signatures s
+LEFT JOIN {{schema}}.validator_consensus_addresses a ON a.consensus_address = s.consensus_address;
+
+CREATE OR REPLACE VIEW {{schema}}.proposed_blocks AS
+SELECT
+  p.height,
+  p.proposer_address,
+  a.operator_address,
+  a.moniker,
+  p.time
+FROM {{schema}}.block_proposers p
+LEFT JOIN {{schema}}.validator_consensus_addresses a ON a.consensus_address = p.proposer_address;
+
+GRANT SELECT ON {{schema}}.block_proposers TO {{anon_role}};
+GRANT SELECT ON {{schema}}.block_signatures TO {{anon_role}};
+GRANT SELECT ON {{schema}}.validator_consensus_addresses TO {{anon_role}};
+GRANT SELECT ON {{schema}}.validator_signatures TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposed_blocks TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Indexes are dropped automatically with the table
+DROP TABLE IF EXISTS {{schema}}.coins;
+
+COMMIT;
@@ -0,0 +1,47 @@
+BEGIN;
+
+-- Coins of the coin-valued event attributes and message fields, one row per denomination.
+-- The coins of the block events have a NULL id.
+CREATE TABLE IF NOT EXISTS {{schema}}.coins (
+  height      bigint      NOT NULL,
+  seq         int         NOT NULL,  -- position in the transaction or block coins
+  id          varchar(64),           -- tx id
+  source      text        NOT NULL,  -- 'event' or 'message'
+  event_index bigint,                -- NULL for the message coins
+  msg_index   bigint,                -- message index, or message index of the event
+  type        text        NOT NULL,  -- event or message type
+  key         text        NOT NULL,  -- attribute key, or dot-separated path of the message field
+  denom       text        NOT NULL,
+  amount      numeric     NOT NULL,
+  FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS coins_seq_idx
+  ON {{schema}}.coins (height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS coins_type_key_denom_idx ON {{schema}}.coins (source, type, key, denom);
+CREATE INDEX IF NOT EXISTS coins_denom_idx          ON {{schema}}.coins (denom, height);
+CREATE INDEX IF NOT EXISTS coins_id_idx             ON {{schema}}.coins (id);
+
+-- Backfill the event coins of the existing transactions. The message coins are only written at ingestion.
+INSERT INTO {{schema}}.coins (height, seq, id, source, event_index, msg_index, type, key, denom, amount)
+SELECT
+  t.height,
+  (ROW_NUMBER() OVER (PARTITION BY e.id ORDER BY e.event_index, e.attr_index, c.ord) - 1)::int,
+  e.id,
+  'event',
+  e.event_index,
+  e.msg_index,
+  e.event_type,
+  e.attr_key,
+  (m.captures)[2],
+  (m.captures)[1]::numeric
+FROM {{schema}}.events_main e
+JOIN {{schema}}.transactions_main t ON t.id = e.id
+CROSS JOIN LATERAL unnest(string_to_array(e.attr_value, ',')) WITH ORDINALITY AS c(coin, ord)
+CROSS JOIN LATERAL regexp_matches(c.coin, '^([0-9]+(?:\.[0-9]+)?)([a-zA-Z][a-zA-Z0-9/:._-]{2,127})$') AS m(captures)
+WHERE e.attr_value ~ '^[0-9]+(\.[0-9]+)?[a-zA-Z][a-zA-Z0-9/:._-]{2,127}(,[0-9]+(\.[0-9]+)?[a-zA-Z][a-zA-Z0-9/:._-]{2,127})*$'
+ON CONFLICT DO NOTHING;
+
+GRANT SELECT ON {{schema}}.coins TO {{anon_role}};
+
+COMMIT;
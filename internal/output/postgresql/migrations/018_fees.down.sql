@@ -0,0 +1,19 @@
+BEGIN;
+
+DROP VIEW IF EXISTS {{schema}}.fee_grant_usage;
+DROP VIEW IF EXISTS {{schema}}.transaction_gas_prices;
+
+-- Indexes are dropped automatically with the table(s)
+DROP TABLE IF EXISTS {{schema}}.transaction_fees;
+
+DROP INDEX IF EXISTS {{schema}}.transactions_main_height_idx;
+DROP INDEX IF EXISTS {{schema}}.transactions_main_fee_granter_idx;
+DROP INDEX IF EXISTS {{schema}}.transactions_main_fee_payer_idx;
+
+ALTER TABLE {{schema}}.transactions_main
+  DROP COLUMN IF EXISTS fee_granter,
+  DROP COLUMN IF EXISTS fee_payer,
+  DROP COLUMN IF EXISTS gas_used,
+  DROP COLUMN IF EXISTS gas_wanted;
+
+COMMIT;
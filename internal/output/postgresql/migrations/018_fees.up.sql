@@ -0,0 +1,82 @@
+BEGIN;
+
+ALTER TABLE {{schema}}.transactions_main
+  ADD COLUMN IF NOT EXISTS gas_wanted  bigint,
+  ADD COLUMN IF NOT EXISTS gas_used    bigint,
+  ADD COLUMN IF NOT EXISTS fee_payer   text,
+  ADD COLUMN IF NOT EXISTS fee_granter text;
+
+CREATE INDEX IF NOT EXISTS transactions_main_fee_payer_idx   ON {{schema}}.transactions_main (fee_payer);
+CREATE INDEX IF NOT EXISTS transactions_main_fee_granter_idx ON {{schema}}.transactions_main (fee_granter) WHERE fee_granter IS NOT NULL;
+CREATE INDEX IF NOT EXISTS transactions_main_height_idx      ON {{schema}}.transactions_main (height);
+
+-- Fee paid by each transaction, per denomination
+CREATE TABLE IF NOT EXISTS {{schema}}.transaction_fees (
+  id     varchar(64) NOT NULL REFERENCES {{schema}}.transactions_raw(id) ON DELETE CASCADE,
+  denom  text        NOT NULL,
+  amount numeric     NOT NULL,
+  PRIMARY KEY (id, denom)
+);
+
+CREATE INDEX IF NOT EXISTS transaction_fees_denom_idx ON {{schema}}.transaction_fees (denom);
+
+-- Backfill the existing transactions.
+-- Without an explicit payer, the fee payer is the `fee_payer` attribute of the `tx` event, or the sender of the first message.
+UPDATE {{schema}}.transactions_main t
+SET gas_wanted = NULLIF(r.data->'txResponse'->>'gasWanted', '')::bigint,
+    gas_used = NULLIF(r.data->'txResponse'->>'gasUsed', '')::bigint,
+    fee_granter = NULLIF(t.fee->>'granter', ''),
+    fee_payer = COALESCE(
+      NULLIF(t.fee->>'payer', ''),
+      (SELECT e.attr_value FROM {{schema}}.events_main e
+       WHERE e.id = t.id AND e.event_type = 'tx' AND e.attr_key = 'fee_payer' AND e.attr_value <> ''
+       ORDER BY e.event_index, e.attr_index LIMIT 1),
+      (SELECT m.sender FROM {{schema}}.messages_main m WHERE m.id = t.id AND m.message_index = 0)
+    )
+FROM {{schema}}.transactions_raw r
+WHERE r.id = t.id;
+
+INSERT INTO {{schema}}.transaction_fees (id, denom, amount)
+SELECT t.id, c->>'denom', SUM((c->>'amount')::numeric)
+FROM {{schema}}.transactions_main t
+CROSS JOIN LATERAL jsonb_array_elements(COALESCE(t.fee->'amount', '[]'::jsonb)) c
+WHERE COALESCE(c->>'denom', '') <> '' AND COALESCE(c->>'amount', '') <> ''
+GROUP BY t.id, c->>'denom'
+ON CONFLICT DO NOTHING;
+
+-- Gas price paid by each transaction, per fee denomination
+CREATE OR REPLACE VIEW {{schema}}.transaction_gas_prices AS
+SELECT
+  t.id,
+  t.height,
+  t.timestamp,
+  f.denom,
+  f.amount AS fee,
+  t.gas_wanted,
+  t.gas_used,
+  f.amount / NULLIF(t.gas_wanted, 0) AS gas_price
+FROM {{schema}}.transactions_main t
+JOIN {{schema}}.transaction_fees f ON f.id = t.id;
+
+-- Fees paid through fee grants, per granter, grantee and denomination
+CREATE OR REPLACE VIEW {{schema}}.fee_grant_usage AS
+SELECT
+  t.fee_granter AS granter,
+  t.fee_payer AS grantee,
+  f.denom,
+  COUNT(*) AS tx_count,
+  COUNT(*) FILTER (WHERE t.error IS NOT NULL) AS failed_tx_count,
+  SUM(f.amount) AS total_fee,
+  SUM(t.gas_used) AS total_gas_used,
+  MIN(t.height) AS first_height,
+  MAX(t.height) AS last_height
+FROM {{schema}}.transactions_main t
+JOIN {{schema}}.transaction_fees f ON f.id = t.id
+WHERE t.fee_granter IS NOT NULL
+GROUP BY t.fee_granter, t.fee_payer, f.denom;
+
+GRANT SELECT ON {{schema}}.transaction_fees TO {{anon_role}};
+GRANT SELECT ON {{schema}}.transaction_gas_prices TO {{anon_role}};
+GRANT SELECT ON {{schema}}.fee_grant_usage TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,296 @@
+BEGIN;
+
+-- Only a database holding a single chain can be migrated down
+DO $$
+BEGIN
+  IF (SELECT COUNT(DISTINCT chain_id) FROM {{schema}}.blocks_raw) > 1 THEN
+    RAISE EXCEPTION 'the database holds several chains';
+  END IF;
+END $$;
+
+DROP VIEW IF EXISTS {{schema}}.chains;
+
+DROP VIEW IF EXISTS {{schema}}.fee_grant_usage;
+CREATE VIEW {{schema}}.fee_grant_usage AS
+SELECT
+  t.fee_granter AS granter,
+  t.fee_payer AS grantee,
+  f.denom,
+  COUNT(*) AS tx_count,
+  COUNT(*) FILTER (WHERE t.error IS NOT NULL) AS failed_tx_count,
+  SUM(f.amount) AS total_fee,
+  SUM(t.gas_used) AS total_gas_used,
+  MIN(t.height) AS first_height,
+  MAX(t.height) AS last_height
+FROM {{schema}}.transactions_main t
+JOIN {{schema}}.transaction_fees f ON f.id = t.id
+WHERE t.fee_granter IS NOT NULL
+GROUP BY t.fee_granter, t.fee_payer, f.denom;
+
+DROP VIEW IF EXISTS {{schema}}.transaction_gas_prices;
+CREATE VIEW {{schema}}.transaction_gas_prices AS
+SELECT
+  t.id,
+  t.height,
+  t.timestamp,
+  f.denom,
+  f.amount AS fee,
+  t.gas_wanted,
+  t.gas_used,
+  f.amount / NULLIF(t.gas_wanted, 0) AS gas_price
+FROM {{schema}}.transactions_main t
+JOIN {{schema}}.transaction_fees f ON f.id = t.id;
+
+DROP VIEW IF EXISTS {{schema}}.proposed_blocks;
+CREATE VIEW {{schema}}.proposed_blocks AS
+SELECT
+  p.height,
+  p.proposer_address,
+  a.operator_address,
+  a.moniker,
+  p.time
+FROM {{schema}}.block_proposers p
+LEFT JOIN {{schema}}.validator_consensus_addresses a ON a.consensus_address = p.proposer_address;
+
+DROP VIEW IF EXISTS {{schema}}.validator_signatures;
+CREATE VIEW {{schema}}.validator_signatures AS
+SELECT
+  s.height,
+  s.consensus_address,
+  a.operator_address,
+  a.moniker,
+  s.validator_index,
+  s.block_id_flag,
+  s.block_id_flag <> 'BLOCK_ID_FLAG_ABSENT' AS signed,
+  s.voting_power,
+  s.timestamp
+FROM {{schema}}.block_signatures s
+LEFT JOIN {{schema}}.validator_consensus_addresses a ON a.consensus_address = s.consensus_address;
+
+DROP FUNCTION IF EXISTS {{schema}}.get_delegations_at(text, bigint, text);
+CREATE OR REPLACE FUNCTION {{schema}}.get_delegations_at(_delegator text, _height bigint)
+RETURNS TABLE (validator text, denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT d.validator, d.denom, SUM(d.amount) AS amount
+  FROM {{schema}}.delegation_deltas d
+  WHERE d.delegator = _delegator AND d.height <= _height
+  GROUP BY d.validator, d.denom
+  HAVING SUM(d.amount) <> 0
+  ORDER BY d.validator, d.denom;
+$$;
+
+DROP VIEW IF EXISTS {{schema}}.delegation_deltas;
+CREATE VIEW {{schema}}.delegation_deltas AS
+SELECT delegator, validator, denom, height, amount
+FROM {{schema}}.delegation_changes
+WHERE action IN ('delegate', 'cancel_unbonding_delegation', 'redelegate')
+UNION ALL
+SELECT delegator, src_validator, denom, height, -amount
+FROM {{schema}}.delegation_changes
+WHERE action = 'redelegate'
+UNION ALL
+SELECT delegator, validator, denom, height, -amount
+FROM {{schema}}.delegation_changes
+WHERE action = 'unbond';
+
+DROP FUNCTION IF EXISTS {{schema}}.get_balance_at(text, bigint, text);
+CREATE OR REPLACE FUNCTION {{schema}}.get_balance_at(_address text, _height bigint)
+RETURNS TABLE (denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT bc.denom, SUM(bc.amount) AS amount
+  FROM {{schema}}.balance_changes bc
+  WHERE bc.address = _address AND bc.height <= _height
+  GROUP BY bc.denom
+  HAVING SUM(bc.amount) <> 0
+  ORDER BY bc.denom;
+$$;
+
+DROP VIEW IF EXISTS {{schema}}.balance_deltas;
+CREATE VIEW {{schema}}.balance_deltas AS
+SELECT address, denom, height, SUM(amount) AS amount
+FROM {{schema}}.balance_changes
+GROUP BY address, denom, height;
+
+DROP VIEW IF EXISTS {{schema}}.ibc_denom_traces;
+CREATE VIEW {{schema}}.ibc_denom_traces AS
+SELECT DISTINCT local_denom, denom_path, base_denom
+FROM {{schema}}.ibc_packet_events
+WHERE denom_path <> '';
+
+DROP VIEW IF EXISTS {{schema}}.ibc_packets;
+CREATE VIEW {{schema}}.ibc_packets AS
+SELECT
+  p.direction,
+  p.port,
+  p.channel,
+  p.sequence,
+  p.src_port,
+  p.src_channel,
+  p.dst_port,
+  p.dst_channel,
+  p.connection_id,
+  p.id AS tx_id,
+  p.height,
+  p.timestamp,
+  p.timeout_height,
+  p.timeout_timestamp,
+  p.data,
+  p.sender,
+  p.receiver,
+  p.denom,
+  p.amount,
+  p.memo,
+  p.base_denom,
+  p.denom_path,
+  p.local_denom,
+  ack.id AS ack_tx_id,
+  ack.height AS ack_height,
+  ack.timestamp AS ack_timestamp,
+  ack.acknowledgement,
+  ack.ack_success,
+  t.id AS timeout_tx_id,
+  t.height AS timeout_tx_height,
+  t.timestamp AS timeout_tx_timestamp,
+  CASE
+    WHEN t.id IS NOT NULL THEN 'timed_out'
+    WHEN ack.id IS NULL THEN 'pending'
+    WHEN ack.ack_success IS FALSE THEN 'failed'
+    ELSE 'acknowledged'
+  END AS status,
+  COALESCE(ack.timestamp, t.timestamp) - p.timestamp AS latency
+FROM {{schema}}.ibc_packet_events p
+LEFT JOIN LATERAL (
+  SELECT a.id, a.height, a.timestamp, a.acknowledgement, a.ack_success
+  FROM {{schema}}.ibc_packet_events a
+  WHERE a.direction = p.direction AND a.port = p.port AND a.channel = p.channel AND a.sequence = p.sequence
+    AND a.event_type IN ('acknowledge_packet', 'write_acknowledgement')
+  ORDER BY a.height, a.event_index
+  LIMIT 1
+) ack ON true
+LEFT JOIN LATERAL (
+  SELECT a.id, a.height, a.timestamp
+  FROM {{schema}}.ibc_packet_events a
+  WHERE a.direction = p.direction AND a.port = p.port AND a.channel = p.channel AND a.sequence = p.sequence
+    AND a.event_type = 'timeout_packet'
+  ORDER BY a.height, a.event_index
+  LIMIT 1
+) t ON true
+WHERE p.event_type IN ('send_packet', 'recv_packet');
+
+DROP VIEW IF EXISTS {{schema}}.proposal_messages;
+CREATE VIEW {{schema}}.proposal_messages AS
+SELECT
+  p.module,
+  p.proposal_id,
+  m.id,
+  m.message_index,
+  m.type,
+  m.sender,
+  m.mentions,
+  m.metadata
+FROM {{schema}}.proposals p
+JOIN {{schema}}.messages_main m ON m.id = p.id AND m.parent_index = p.message_index;
+
+DROP VIEW IF EXISTS {{schema}}.proposal_summaries;
+
+DROP INDEX IF EXISTS {{schema}}.transactions_main_height_idx;
+DROP INDEX IF EXISTS {{schema}}.block_signatures_absent_idx;
+DROP INDEX IF EXISTS {{schema}}.ibc_packet_events_packet_idx;
+DROP INDEX IF EXISTS {{schema}}.staking_rewards_seq_idx;
+DROP INDEX IF EXISTS {{schema}}.delegation_changes_seq_idx;
+DROP INDEX IF EXISTS {{schema}}.coins_seq_idx;
+DROP INDEX IF EXISTS {{schema}}.balance_changes_seq_idx;
+DROP INDEX IF EXISTS {{schema}}.proposal_tallies_height_idx;
+DROP INDEX IF EXISTS {{schema}}.proposal_tallies_uniq_idx;
+DROP INDEX IF EXISTS {{schema}}.proposal_status_changes_height_idx;
+DROP INDEX IF EXISTS {{schema}}.proposal_status_changes_uniq_idx;
+
+ALTER TABLE {{schema}}.validator_consensus_addresses DROP CONSTRAINT validator_consensus_addresses_pkey, ADD PRIMARY KEY (consensus_address);
+ALTER TABLE {{schema}}.block_signatures DROP CONSTRAINT block_signatures_pkey, ADD PRIMARY KEY (height, consensus_address);
+ALTER TABLE {{schema}}.block_proposers DROP CONSTRAINT block_proposers_pkey, ADD PRIMARY KEY (height);
+ALTER TABLE {{schema}}.wasm_contract_snapshots DROP CONSTRAINT wasm_contract_snapshots_pkey, ADD PRIMARY KEY (height, address);
+ALTER TABLE {{schema}}.wasm_contracts DROP CONSTRAINT wasm_contracts_pkey, ADD PRIMARY KEY (address);
+ALTER TABLE {{schema}}.wasm_codes DROP CONSTRAINT wasm_codes_pkey, ADD PRIMARY KEY (code_id);
+ALTER TABLE {{schema}}.validator_snapshots DROP CONSTRAINT validator_snapshots_pkey, ADD PRIMARY KEY (height, operator_address);
+ALTER TABLE {{schema}}.proposals DROP CONSTRAINT proposals_pkey, ADD PRIMARY KEY (module, proposal_id);
+ALTER TABLE {{schema}}.blocks_raw DROP CONSTRAINT blocks_raw_pkey, ADD CONSTRAINT blocks_pkey PRIMARY KEY (id);
+
+ALTER TABLE {{schema}}.validator_consensus_addresses DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.block_signatures DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.block_proposers DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.wasm_contract_snapshots DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.wasm_contracts DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.wasm_codes DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.validator_snapshots DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.staking_rewards DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.delegation_changes DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.coins DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.balance_changes DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.ibc_packet_events DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.proposal_tallies DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.proposal_status_changes DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.proposals DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.transactions_main DROP COLUMN IF EXISTS chain_id;
+ALTER TABLE {{schema}}.blocks_raw DROP COLUMN IF EXISTS chain_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS proposal_status_changes_uniq_idx
+  ON {{schema}}.proposal_status_changes (module, proposal_id, height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS proposal_status_changes_height_idx ON {{schema}}.proposal_status_changes (height);
+CREATE UNIQUE INDEX IF NOT EXISTS proposal_tallies_uniq_idx
+  ON {{schema}}.proposal_tallies (module, proposal_id, height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS proposal_tallies_height_idx ON {{schema}}.proposal_tallies (height);
+CREATE UNIQUE INDEX IF NOT EXISTS balance_changes_seq_idx
+  ON {{schema}}.balance_changes (height, COALESCE(id, ''), seq);
+CREATE UNIQUE INDEX IF NOT EXISTS coins_seq_idx
+  ON {{schema}}.coins (height, COALESCE(id, ''), seq);
+CREATE UNIQUE INDEX IF NOT EXISTS delegation_changes_seq_idx
+  ON {{schema}}.delegation_changes (height, COALESCE(id, ''), seq);
+CREATE UNIQUE INDEX IF NOT EXISTS staking_rewards_seq_idx
+  ON {{schema}}.staking_rewards (height, COALESCE(id, ''), seq);
+CREATE INDEX IF NOT EXISTS ibc_packet_events_packet_idx ON {{schema}}.ibc_packet_events (direction, port, channel, sequence);
+CREATE INDEX IF NOT EXISTS block_signatures_absent_idx
+  ON {{schema}}.block_signatures (height) WHERE block_id_flag = 'BLOCK_ID_FLAG_ABSENT';
+CREATE INDEX IF NOT EXISTS transactions_main_height_idx ON {{schema}}.transactions_main (height);
+
+CREATE VIEW {{schema}}.proposal_summaries AS
+SELECT
+  p.*,
+  s.status,
+  s.executor_result,
+  s.height AS status_height,
+  t.yes_count,
+  t.abstain_count,
+  t.no_count,
+  t.no_with_veto_count
+FROM {{schema}}.proposals p
+LEFT JOIN LATERAL (
+  SELECT status, executor_result, height
+  FROM {{schema}}.proposal_status_changes sc
+  WHERE sc.module = p.module AND sc.proposal_id = p.proposal_id
+  ORDER BY sc.height DESC, sc.id IS NULL DESC, sc.seq DESC
+  LIMIT 1
+) s ON TRUE
+LEFT JOIN LATERAL (
+  SELECT yes_count, abstain_count, no_count, no_with_veto_count
+  FROM {{schema}}.proposal_tallies pt
+  WHERE pt.module = p.module AND pt.proposal_id = p.proposal_id
+  ORDER BY pt.height DESC, pt.id IS NULL DESC, pt.seq DESC
+  LIMIT 1
+) t ON TRUE;
+
+GRANT SELECT ON {{schema}}.proposal_messages TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_summaries TO {{anon_role}};
+GRANT SELECT ON {{schema}}.ibc_packets TO {{anon_role}};
+GRANT SELECT ON {{schema}}.ibc_denom_traces TO {{anon_role}};
+GRANT SELECT ON {{schema}}.balance_deltas TO {{anon_role}};
+GRANT SELECT ON {{schema}}.delegation_deltas TO {{anon_role}};
+GRANT SELECT ON {{schema}}.validator_signatures TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposed_blocks TO {{anon_role}};
+GRANT SELECT ON {{schema}}.transaction_gas_prices TO {{anon_role}};
+GRANT SELECT ON {{schema}}.fee_grant_usage TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_balance_at(text, bigint) TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_delegations_at(text, bigint) TO {{anon_role}};
+
+COMMIT;
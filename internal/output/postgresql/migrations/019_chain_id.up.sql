@@ -0,0 +1,304 @@
+BEGIN;
+
+---
+-- Several chains can be indexed into the same database. The tables keyed by height, or by identifiers that are
+-- only unique within a chain, hold the chain ID. The transaction hashes are unique across chains.
+--
+-- The existing rows belong to the chain of the indexed blocks, as a database used to hold a single chain.
+---
+DO $$
+DECLARE
+  _chain_id text;
+  _table    text;
+BEGIN
+  SELECT data->'block'->'header'->>'chainId' INTO _chain_id
+  FROM {{schema}}.blocks_raw
+  ORDER BY id DESC
+  LIMIT 1;
+
+  FOREACH _table IN ARRAY ARRAY[
+    'blocks_raw', 'transactions_main',
+    'proposals', 'proposal_status_changes', 'proposal_tallies',
+    'ibc_packet_events', 'balance_changes', 'coins',
+    'delegation_changes', 'staking_rewards', 'validator_snapshots',
+    'wasm_codes', 'wasm_contracts', 'wasm_contract_snapshots',
+    'block_proposers', 'block_signatures', 'validator_consensus_addresses'
+  ] LOOP
+    -- Adding a column with a constant default does not rewrite the table
+    EXECUTE format('ALTER TABLE {{schema}}.%I ADD COLUMN IF NOT EXISTS chain_id text NOT NULL DEFAULT %L', _table, COALESCE(_chain_id, ''));
+    EXECUTE format('ALTER TABLE {{schema}}.%I ALTER COLUMN chain_id DROP DEFAULT', _table);
+  END LOOP;
+END $$;
+
+-- blocks_raw was created as {{schema}}.blocks
+ALTER TABLE {{schema}}.blocks_raw DROP CONSTRAINT blocks_pkey, ADD PRIMARY KEY (chain_id, id);
+ALTER TABLE {{schema}}.proposals DROP CONSTRAINT proposals_pkey, ADD PRIMARY KEY (chain_id, module, proposal_id);
+ALTER TABLE {{schema}}.validator_snapshots DROP CONSTRAINT validator_snapshots_pkey, ADD PRIMARY KEY (chain_id, height, operator_address);
+ALTER TABLE {{schema}}.wasm_codes DROP CONSTRAINT wasm_codes_pkey, ADD PRIMARY KEY (chain_id, code_id);
+ALTER TABLE {{schema}}.wasm_contracts DROP CONSTRAINT wasm_contracts_pkey, ADD PRIMARY KEY (chain_id, address);
+ALTER TABLE {{schema}}.wasm_contract_snapshots DROP CONSTRAINT wasm_contract_snapshots_pkey, ADD PRIMARY KEY (chain_id, height, address);
+ALTER TABLE {{schema}}.block_proposers DROP CONSTRAINT block_proposers_pkey, ADD PRIMARY KEY (chain_id, height);
+ALTER TABLE {{schema}}.block_signatures DROP CONSTRAINT block_signatures_pkey, ADD PRIMARY KEY (chain_id, height, consensus_address);
+ALTER TABLE {{schema}}.validator_consensus_addresses DROP CONSTRAINT validator_consensus_addresses_pkey, ADD PRIMARY KEY (chain_id, consensus_address);
+
+DROP INDEX IF EXISTS {{schema}}.proposal_status_changes_uniq_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS proposal_status_changes_uniq_idx
+  ON {{schema}}.proposal_status_changes (chain_id, module, proposal_id, height, COALESCE(id, ''), seq);
+DROP INDEX IF EXISTS {{schema}}.proposal_status_changes_height_idx;
+CREATE INDEX IF NOT EXISTS proposal_status_changes_height_idx ON {{schema}}.proposal_status_changes (chain_id, height);
+
+DROP INDEX IF EXISTS {{schema}}.proposal_tallies_uniq_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS proposal_tallies_uniq_idx
+  ON {{schema}}.proposal_tallies (chain_id, module, proposal_id, height, COALESCE(id, ''), seq);
+DROP INDEX IF EXISTS {{schema}}.proposal_tallies_height_idx;
+CREATE INDEX IF NOT EXISTS proposal_tallies_height_idx ON {{schema}}.proposal_tallies (chain_id, height);
+
+DROP INDEX IF EXISTS {{schema}}.balance_changes_seq_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS balance_changes_seq_idx
+  ON {{schema}}.balance_changes (chain_id, height, COALESCE(id, ''), seq);
+
+DROP INDEX IF EXISTS {{schema}}.coins_seq_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS coins_seq_idx
+  ON {{schema}}.coins (chain_id, height, COALESCE(id, ''), seq);
+
+DROP INDEX IF EXISTS {{schema}}.delegation_changes_seq_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS delegation_changes_seq_idx
+  ON {{schema}}.delegation_changes (chain_id, height, COALESCE(id, ''), seq);
+
+DROP INDEX IF EXISTS {{schema}}.staking_rewards_seq_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS staking_rewards_seq_idx
+  ON {{schema}}.staking_rewards (chain_id, height, COALESCE(id, ''), seq);
+
+DROP INDEX IF EXISTS {{schema}}.ibc_packet_events_packet_idx;
+CREATE INDEX IF NOT EXISTS ibc_packet_events_packet_idx ON {{schema}}.ibc_packet_events (chain_id, direction, port, channel, sequence);
+
+DROP INDEX IF EXISTS {{schema}}.block_signatures_absent_idx;
+CREATE INDEX IF NOT EXISTS block_signatures_absent_idx
+  ON {{schema}}.block_signatures (chain_id, height) WHERE block_id_flag = 'BLOCK_ID_FLAG_ABSENT';
+
+DROP INDEX IF EXISTS {{schema}}.transactions_main_height_idx;
+CREATE INDEX IF NOT EXISTS transactions_main_height_idx ON {{schema}}.transactions_main (chain_id, height);
+
+---
+-- The views and functions join and aggregate the rows of the same chain
+---
+DROP VIEW IF EXISTS {{schema}}.proposal_summaries;
+CREATE VIEW {{schema}}.proposal_summaries AS
+SELECT
+  p.*,
+  s.status,
+  s.executor_result,
+  s.height AS status_height,
+  t.yes_count,
+  t.abstain_count,
+  t.no_count,
+  t.no_with_veto_count
+FROM {{schema}}.proposals p
+LEFT JOIN LATERAL (
+  SELECT status, executor_result, height
+  FROM {{schema}}.proposal_status_changes sc
+  WHERE sc.chain_id = p.chain_id AND sc.module = p.module AND sc.proposal_id = p.proposal_id
+  ORDER BY sc.height DESC, sc.id IS NULL DESC, sc.seq DESC
+  LIMIT 1
+) s ON TRUE
+LEFT JOIN LATERAL (
+  SELECT yes_count, abstain_count, no_count, no_with_veto_count
+  FROM {{schema}}.proposal_tallies pt
+  WHERE pt.chain_id = p.chain_id AND pt.module = p.module AND pt.proposal_id = p.proposal_id
+  ORDER BY pt.height DESC, pt.id IS NULL DESC, pt.seq DESC
+  LIMIT 1
+) t ON TRUE;
+
+CREATE OR REPLACE VIEW {{schema}}.proposal_messages AS
+SELECT
+  p.module,
+  p.proposal_id,
+  m.id,
+  m.message_index,
+  m.type,
+  m.sender,
+  m.mentions,
+  m.metadata,
+  p.chain_id
+FROM {{schema}}.proposals p
+JOIN {{schema}}.messages_main m ON m.id = p.id AND m.parent_index = p.message_index;
+
+CREATE OR REPLACE VIEW {{schema}}.ibc_packets AS
+SELECT
+  p.direction,
+  p.port,
+  p.channel,
+  p.sequence,
+  p.src_port,
+  p.src_channel,
+  p.dst_port,
+  p.dst_channel,
+  p.connection_id,
+  p.id AS tx_id,
+  p.height,
+  p.timestamp,
+  p.timeout_height,
+  p.timeout_timestamp,
+  p.data,
+  p.sender,
+  p.receiver,
+  p.denom,
+  p.amount,
+  p.memo,
+  p.base_denom,
+  p.denom_path,
+  p.local_denom,
+  ack.id AS ack_tx_id,
+  ack.height AS ack_height,
+  ack.timestamp AS ack_timestamp,
+  ack.acknowledgement,
+  ack.ack_success,
+  t.id AS timeout_tx_id,
+  t.height AS timeout_tx_height,
+  t.timestamp AS timeout_tx_timestamp,
+  CASE
+    WHEN t.id IS NOT NULL THEN 'timed_out'
+    WHEN ack.id IS NULL THEN 'pending'
+    WHEN ack.ack_success IS FALSE THEN 'failed'
+    ELSE 'acknowledged'
+  END AS status,
+  COALESCE(ack.timestamp, t.timestamp) - p.timestamp AS latency,
+  p.chain_id
+FROM {{schema}}.ibc_packet_events p
+LEFT JOIN LATERAL (
+  SELECT a.id, a.height, a.timestamp, a.acknowledgement, a.ack_success
+  FROM {{schema}}.ibc_packet_events a
+  WHERE a.chain_id = p.chain_id AND a.direction = p.direction AND a.port = p.port AND a.channel = p.channel AND a.sequence = p.sequence
+    AND a.event_type IN ('acknowledge_packet', 'write_acknowledgement')
+  ORDER BY a.height, a.event_index
+  LIMIT 1
+) ack ON true
+LEFT JOIN LATERAL (
+  SELECT a.id, a.height, a.timestamp
+  FROM {{schema}}.ibc_packet_events a
+  WHERE a.chain_id = p.chain_id AND a.direction = p.direction AND a.port = p.port AND a.channel = p.channel AND a.sequence = p.sequence
+    AND a.event_type = 'timeout_packet'
+  ORDER BY a.height, a.event_index
+  LIMIT 1
+) t ON true
+WHERE p.event_type IN ('send_packet', 'recv_packet');
+
+CREATE OR REPLACE VIEW {{schema}}.ibc_denom_traces AS
+SELECT DISTINCT local_denom, denom_path, base_denom, chain_id
+FROM {{schema}}.ibc_packet_events
+WHERE denom_path <> '';
+
+CREATE OR REPLACE VIEW {{schema}}.balance_deltas AS
+SELECT address, denom, height, SUM(amount) AS amount, chain_id
+FROM {{schema}}.balance_changes
+GROUP BY chain_id, address, denom, height;
+
+-- The chain ID can be omitted when the database holds a single chain
+DROP FUNCTION IF EXISTS {{schema}}.get_balance_at(text, bigint);
+CREATE OR REPLACE FUNCTION {{schema}}.get_balance_at(_address text, _height bigint, _chain_id text DEFAULT NULL)
+RETURNS TABLE (denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT bc.denom, SUM(bc.amount) AS amount
+  FROM {{schema}}.balance_changes bc
+  WHERE bc.address = _address AND bc.height <= _height AND (_chain_id IS NULL OR bc.chain_id = _chain_id)
+  GROUP BY bc.denom
+  HAVING SUM(bc.amount) <> 0
+  ORDER BY bc.denom;
+$$;
+
+CREATE OR REPLACE VIEW {{schema}}.delegation_deltas AS
+SELECT delegator, validator, denom, height, amount, chain_id
+FROM {{schema}}.delegation_changes
+WHERE action IN ('delegate', 'cancel_unbonding_delegation', 'redelegate')
+UNION ALL
+SELECT delegator, src_validator, denom, height, -amount, chain_id
+FROM {{schema}}.delegation_changes
+WHERE action = 'redelegate'
+UNION ALL
+SELECT delegator, validator, denom, height, -amount, chain_id
+FROM {{schema}}.delegation_changes
+WHERE action = 'unbond';
+
+DROP FUNCTION IF EXISTS {{schema}}.get_delegations_at(text, bigint);
+CREATE OR REPLACE FUNCTION {{schema}}.get_delegations_at(_delegator text, _height bigint, _chain_id text DEFAULT NULL)
+RETURNS TABLE (validator text, denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT d.validator, d.denom, SUM(d.amount) AS amount
+  FROM {{schema}}.delegation_deltas d
+  WHERE d.delegator = _delegator AND d.height <= _height AND (_chain_id IS NULL OR d.chain_id = _chain_id)
+  GROUP BY d.validator, d.denom
+  HAVING SUM(d.amount) <> 0
+  ORDER BY d.validator, d.denom;
+$$;
+
+CREATE OR REPLACE VIEW {{schema}}.validator_signatures AS
+SELECT
+  s.height,
+  s.consensus_address,
+  a.operator_address,
+  a.moniker,
+  s.validator_index,
+  s.block_id_flag,
+  s.block_id_flag <> 'BLOCK_ID_FLAG_ABSENT' AS signed,
+  s.voting_power,
+  s.timestamp,
+  s.chain_id
+FROM {{schema}}.block_signatures s
+LEFT JOIN {{schema}}.validator_consensus_addresses a ON a.chain_id = s.chain_id AND a.consensus_address = s.consensus_address;
+
+CREATE OR REPLACE VIEW {{schema}}.proposed_blocks AS
+SELECT
+  p.height,
+  p.proposer_address,
+  a.operator_address,
+  a.moniker,
+  p.time,
+  p.chain_id
+FROM {{schema}}.block_proposers p
+LEFT JOIN {{schema}}.validator_consensus_addresses a ON a.chain_id = p.chain_id AND a.consensus_address = p.proposer_address;
+
+CREATE OR REPLACE VIEW {{schema}}.transaction_gas_prices AS
+SELECT
+  t.id,
+  t.height,
+  t.timestamp,
+  f.denom,
+  f.amount AS fee,
+  t.gas_wanted,
+  t.gas_used,
+  f.amount / NULLIF(t.gas_wanted, 0) AS gas_price,
+  t.chain_id
+FROM {{schema}}.transactions_main t
+JOIN {{schema}}.transaction_fees f ON f.id = t.id;
+
+CREATE OR REPLACE VIEW {{schema}}.fee_grant_usage AS
+SELECT
+  t.fee_granter AS granter,
+  t.fee_payer AS grantee,
+  f.denom,
+  COUNT(*) AS tx_count,
+  COUNT(*) FILTER (WHERE t.error IS NOT NULL) AS failed_tx_count,
+  SUM(f.amount) AS total_fee,
+  SUM(t.gas_used) AS total_gas_used,
+  MIN(t.height) AS first_height,
+  MAX(t.height) AS last_height,
+  t.chain_id
+FROM {{schema}}.transactions_main t
+JOIN {{schema}}.transaction_fees f ON f.id = t.id
+WHERE t.fee_granter IS NOT NULL
+GROUP BY t.chain_id, t.fee_granter, t.fee_payer, f.denom;
+
+-- The indexed chains with their indexed height range
+CREATE OR REPLACE VIEW {{schema}}.chains AS
+SELECT chain_id, MIN(id) AS earliest_height, MAX(id) AS latest_height, COUNT(*) AS block_count
+FROM {{schema}}.blocks_raw
+GROUP BY chain_id;
+
+GRANT SELECT ON {{schema}}.proposal_summaries TO {{anon_role}};
+GRANT SELECT ON {{schema}}.chains TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_balance_at(text, bigint, text) TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_delegations_at(text, bigint, text) TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,174 @@
+BEGIN;
+
+---
+-- Rebuild the partitioned tables as plain tables, without the height columns
+---
+DROP VIEW IF EXISTS {{schema}}.proposal_messages;
+DROP VIEW IF EXISTS {{schema}}.chains;
+
+ALTER TABLE {{schema}}.transactions_main DROP CONSTRAINT IF EXISTS transactions_main_id_height_fkey;
+ALTER TABLE {{schema}}.vesting_periods   DROP CONSTRAINT IF EXISTS vesting_periods_id_fkey;
+
+DO $$
+DECLARE
+  _table text;
+BEGIN
+  FOREACH _table IN ARRAY ARRAY[
+    'proposals', 'proposal_votes', 'proposal_deposits', 'proposal_status_changes', 'proposal_tallies',
+    'ibc_packet_events', 'balance_changes', 'coins', 'transaction_fees', 'delegation_changes', 'staking_rewards',
+    'wasm_codes', 'wasm_contracts', 'wasm_executions', 'wasm_events'
+  ] LOOP
+    EXECUTE format('ALTER TABLE {{schema}}.%I DROP CONSTRAINT IF EXISTS %I', _table, _table || '_id_fkey');
+  END LOOP;
+END
+$$;
+
+ALTER TABLE {{schema}}.blocks_raw       RENAME TO blocks_raw_partitioned;
+ALTER TABLE {{schema}}.transactions_raw RENAME TO transactions_raw_partitioned;
+ALTER TABLE {{schema}}.messages_raw     RENAME TO messages_raw_partitioned;
+ALTER TABLE {{schema}}.messages_main    RENAME TO messages_main_partitioned;
+ALTER TABLE {{schema}}.events_raw       RENAME TO events_raw_partitioned;
+ALTER TABLE {{schema}}.events_main      RENAME TO events_main_partitioned;
+
+CREATE TABLE {{schema}}.blocks_raw (
+  id       bigint NOT NULL,
+  data     jsonb  NOT NULL,
+  chain_id text   NOT NULL
+);
+
+CREATE TABLE {{schema}}.transactions_raw (
+  id   varchar(64) NOT NULL,
+  data jsonb       NOT NULL
+);
+
+CREATE TABLE {{schema}}.messages_raw (
+  id            varchar(64) NOT NULL,
+  message_index bigint      NOT NULL,
+  data          jsonb,
+  parent_index  bigint,
+  path          int[]
+);
+
+CREATE TABLE {{schema}}.messages_main (
+  id            varchar(64) NOT NULL,
+  message_index bigint      NOT NULL,
+  type          text,
+  sender        text,
+  mentions      text[],
+  metadata      jsonb,
+  parent_index  bigint,
+  path          int[]
+);
+
+CREATE TABLE {{schema}}.events_raw (
+  id          varchar(64) NOT NULL,
+  event_index bigint      NOT NULL,
+  data        jsonb       NOT NULL
+);
+
+CREATE TABLE {{schema}}.events_main (
+  id          varchar(64) NOT NULL,
+  event_index bigint      NOT NULL,
+  attr_index  bigint      NOT NULL,
+  event_type  text        NOT NULL,
+  attr_key    text        NOT NULL,
+  attr_value  text,
+  msg_index   bigint
+);
+
+INSERT INTO {{schema}}.blocks_raw (id, data, chain_id)
+SELECT id, data, chain_id FROM {{schema}}.blocks_raw_partitioned;
+
+INSERT INTO {{schema}}.transactions_raw (id, data)
+SELECT id, data FROM {{schema}}.transactions_raw_partitioned;
+
+INSERT INTO {{schema}}.messages_raw (id, message_index, data, parent_index, path)
+SELECT id, message_index, data, parent_index, path FROM {{schema}}.messages_raw_partitioned;
+
+INSERT INTO {{schema}}.messages_main (id, message_index, type, sender, mentions, metadata, parent_index, path)
+SELECT id, message_index, type, sender, mentions, metadata, parent_index, path FROM {{schema}}.messages_main_partitioned;
+
+INSERT INTO {{schema}}.events_raw (id, event_index, data)
+SELECT id, event_index, data FROM {{schema}}.events_raw_partitioned;
+
+INSERT INTO {{schema}}.events_main (id, event_index, attr_index, event_type, attr_key, attr_value, msg_index)
+SELECT id, event_index, attr_index, event_type, attr_key, attr_value, msg_index FROM {{schema}}.events_main_partitioned;
+
+-- The partitions are dropped with their tables
+DROP TABLE {{schema}}.events_main_partitioned;
+DROP TABLE {{schema}}.events_raw_partitioned;
+DROP TABLE {{schema}}.messages_main_partitioned;
+DROP TABLE {{schema}}.messages_raw_partitioned;
+DROP TABLE {{schema}}.transactions_raw_partitioned;
+DROP TABLE {{schema}}.blocks_raw_partitioned;
+
+DROP FUNCTION IF EXISTS {{schema}}.create_partitions(bigint);
+DROP TABLE IF EXISTS {{schema}}.partitioning;
+
+ALTER TABLE {{schema}}.blocks_raw       ADD PRIMARY KEY (chain_id, id);
+ALTER TABLE {{schema}}.transactions_raw ADD CONSTRAINT transactions_pkey PRIMARY KEY (id);
+ALTER TABLE {{schema}}.messages_raw     ADD PRIMARY KEY (id, message_index);
+ALTER TABLE {{schema}}.messages_main    ADD PRIMARY KEY (id, message_index);
+ALTER TABLE {{schema}}.events_raw       ADD PRIMARY KEY (id, event_index);
+ALTER TABLE {{schema}}.events_main      ADD PRIMARY KEY (id, event_index, attr_index);
+
+ALTER TABLE {{schema}}.transactions_main ADD FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw (id);
+ALTER TABLE {{schema}}.messages_raw      ADD FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw (id);
+ALTER TABLE {{schema}}.messages_main     ADD FOREIGN KEY (id, message_index) REFERENCES {{schema}}.messages_raw (id, message_index);
+ALTER TABLE {{schema}}.events_raw        ADD FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw (id) ON DELETE CASCADE;
+ALTER TABLE {{schema}}.events_main       ADD FOREIGN KEY (id, event_index) REFERENCES {{schema}}.events_raw (id, event_index) ON DELETE CASCADE;
+ALTER TABLE {{schema}}.vesting_periods   ADD FOREIGN KEY (id, message_index) REFERENCES {{schema}}.messages_main (id, message_index) ON DELETE CASCADE;
+
+DO $$
+DECLARE
+  _table text;
+BEGIN
+  FOREACH _table IN ARRAY ARRAY[
+    'proposals', 'proposal_votes', 'proposal_deposits', 'proposal_status_changes', 'proposal_tallies',
+    'ibc_packet_events', 'balance_changes', 'coins', 'transaction_fees', 'delegation_changes', 'staking_rewards',
+    'wasm_codes', 'wasm_contracts', 'wasm_executions', 'wasm_events'
+  ] LOOP
+    EXECUTE format('ALTER TABLE {{schema}}.%I ADD FOREIGN KEY (id) REFERENCES {{schema}}.transactions_raw (id) ON DELETE CASCADE', _table);
+  END LOOP;
+END
+$$;
+
+CREATE INDEX IF NOT EXISTS message_main_mentions_idx ON {{schema}}.messages_main USING GIN (mentions);
+CREATE INDEX IF NOT EXISTS message_main_sender_idx   ON {{schema}}.messages_main (sender);
+CREATE INDEX IF NOT EXISTS idx_messages_main_type    ON {{schema}}.messages_main (type);
+CREATE INDEX IF NOT EXISTS messages_main_parent_idx  ON {{schema}}.messages_main (id, parent_index);
+
+CREATE INDEX IF NOT EXISTS events_main_type_idx                ON {{schema}}.events_main (event_type);
+CREATE INDEX IF NOT EXISTS events_main_msg_idx                 ON {{schema}}.events_main (msg_index);
+CREATE INDEX IF NOT EXISTS events_main_attr_key_val_sha256_idx ON {{schema}}.events_main (attr_key, digest(COALESCE(attr_value, ''), 'sha256'));
+CREATE INDEX IF NOT EXISTS events_main_id_idx                  ON {{schema}}.events_main (id);
+
+CREATE VIEW {{schema}}.proposal_messages AS
+SELECT
+  p.module,
+  p.proposal_id,
+  m.id,
+  m.message_index,
+  m.type,
+  m.sender,
+  m.mentions,
+  m.metadata,
+  p.chain_id
+FROM {{schema}}.proposals p
+JOIN {{schema}}.messages_main m ON m.id = p.id AND m.parent_index = p.message_index;
+
+CREATE VIEW {{schema}}.chains AS
+SELECT chain_id, MIN(id) AS earliest_height, MAX(id) AS latest_height, COUNT(*) AS block_count
+FROM {{schema}}.blocks_raw
+GROUP BY chain_id;
+
+GRANT SELECT ON {{schema}}.blocks_raw        TO {{anon_role}};
+GRANT SELECT ON {{schema}}.transactions_raw  TO {{anon_role}};
+GRANT SELECT ON {{schema}}.messages_raw      TO {{anon_role}};
+GRANT SELECT ON {{schema}}.messages_main     TO {{anon_role}};
+GRANT SELECT ON {{schema}}.events_raw        TO {{anon_role}};
+GRANT SELECT ON {{schema}}.events_main       TO {{anon_role}};
+GRANT SELECT ON {{schema}}.proposal_messages TO {{anon_role}};
+GRANT SELECT ON {{schema}}.chains            TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,267 @@
+---
+-- The raw and normalized blocks, transactions, messages and events are partitioned by height range. The partitions
+-- are created by the indexer before it writes the blocks of a range, with `create_partitions`.
+--
+-- The existing tables are renamed `<table>_unpartitioned` and their rows are copied into the partitioned tables, one
+-- partition range per transaction, so that the locks and the WAL of the copy are bounded by a range. The height of the
+-- transactions, messages and events is taken from `transactions_main`. The copied ranges are recorded in
+-- `partitioning_copied`: if the migration fails, e.g., because the disk is full, it resumes from the first range not
+-- copied once the version is forced back to 19 and the migrations are applied again. The old tables are only dropped
+-- once every range is copied, so the copy requires the disk space of the copied tables; see the README for the offline
+-- migration of the databases that cannot hold two copies.
+--
+-- The keys of the partitioned tables include the height, and every lookup by transaction hash is meant to be
+-- restricted by height. The tables that referenced the transactions by hash now reference `transactions_main`. The
+-- partitioned tables are not referenced by foreign keys, so that their partitions can be dropped independently.
+--
+-- The migration is a single statement, which commits between the ranges: it must not be wrapped in a transaction.
+---
+DO $do$
+DECLARE
+  _table text;
+  _size  bigint;
+  _from  bigint;
+BEGIN
+  -- Size, in blocks, of the partitions
+  CREATE TABLE IF NOT EXISTS {{schema}}.partitioning (
+    singleton boolean PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+    size      bigint  NOT NULL CHECK (size > 0)
+  );
+  INSERT INTO {{schema}}.partitioning (size) VALUES (1000000) ON CONFLICT DO NOTHING;
+
+  -- Create the partitions holding a height, if they don't exist. They are named after their first height.
+  CREATE OR REPLACE FUNCTION {{schema}}.create_partitions(_height bigint)
+  RETURNS void
+  LANGUAGE plpgsql
+  AS $fn$
+  DECLARE
+    _size  bigint;
+    _from  bigint;
+    _table text;
+  BEGIN
+    SELECT size INTO _size FROM {{schema}}.partitioning;
+    _from := _height - _height % _size;
+
+    -- Serialize the creation of the partitions by the indexers sharing the schema
+    PERFORM pg_advisory_xact_lock(hashtext('{{schema}}.create_partitions'));
+
+    FOREACH _table IN ARRAY ARRAY['blocks_raw', 'transactions_raw', 'messages_raw', 'messages_main', 'events_raw', 'events_main'] LOOP
+      EXECUTE format('CREATE TABLE IF NOT EXISTS {{schema}}.%I PARTITION OF {{schema}}.%I FOR VALUES FROM (%s) TO (%s)',
+                     _table || '_p' || _from, _table, _from, _from + _size);
+    END LOOP;
+  END
+  $fn$;
+
+  -- The tables are only renamed by the first run of the migration
+  IF to_regclass('{{schema}}.blocks_raw_unpartitioned') IS NULL THEN
+    ---
+    -- Detach the tables from the views and foreign keys
+    ---
+    DROP VIEW IF EXISTS {{schema}}.proposal_messages;
+    DROP VIEW IF EXISTS {{schema}}.chains;
+
+    ALTER TABLE {{schema}}.transactions_main DROP CONSTRAINT IF EXISTS transactions_main_id_fkey;
+    ALTER TABLE {{schema}}.messages_raw      DROP CONSTRAINT IF EXISTS messages_raw_id_fkey;
+    ALTER TABLE {{schema}}.messages_main     DROP CONSTRAINT IF EXISTS messages_main_id_message_index_fkey;
+    ALTER TABLE {{schema}}.events_raw        DROP CONSTRAINT IF EXISTS events_raw_id_fkey;
+    ALTER TABLE {{schema}}.events_main       DROP CONSTRAINT IF EXISTS events_main_id_event_index_fkey;
+    ALTER TABLE {{schema}}.vesting_periods   DROP CONSTRAINT IF EXISTS vesting_periods_id_message_index_fkey;
+
+    FOREACH _table IN ARRAY ARRAY[
+      'proposals', 'proposal_votes', 'proposal_deposits', 'proposal_status_changes', 'proposal_tallies',
+      'ibc_packet_events', 'balance_changes', 'coins', 'transaction_fees', 'delegation_changes', 'staking_rewards',
+      'wasm_codes', 'wasm_contracts', 'wasm_executions', 'wasm_events'
+    ] LOOP
+      EXECUTE format('ALTER TABLE {{schema}}.%I DROP CONSTRAINT IF EXISTS %I', _table, _table || '_id_fkey');
+    END LOOP;
+
+    ALTER TABLE {{schema}}.blocks_raw       RENAME TO blocks_raw_unpartitioned;
+    ALTER TABLE {{schema}}.transactions_raw RENAME TO transactions_raw_unpartitioned;
+    ALTER TABLE {{schema}}.messages_raw     RENAME TO messages_raw_unpartitioned;
+    ALTER TABLE {{schema}}.messages_main    RENAME TO messages_main_unpartitioned;
+    ALTER TABLE {{schema}}.events_raw       RENAME TO events_raw_unpartitioned;
+    ALTER TABLE {{schema}}.events_main      RENAME TO events_main_unpartitioned;
+
+    ---
+    -- Partitioned tables
+    ---
+    CREATE TABLE {{schema}}.blocks_raw (
+      id       bigint NOT NULL,
+      data     jsonb  NOT NULL,
+      chain_id text   NOT NULL
+    ) PARTITION BY RANGE (id);
+
+    CREATE TABLE {{schema}}.transactions_raw (
+      id     varchar(64) NOT NULL,
+      data   jsonb       NOT NULL,
+      height bigint      NOT NULL
+    ) PARTITION BY RANGE (height);
+
+    CREATE TABLE {{schema}}.messages_raw (
+      id            varchar(64) NOT NULL,
+      message_index bigint      NOT NULL,
+      data          jsonb,
+      parent_index  bigint,
+      path          int[],
+      height        bigint      NOT NULL
+    ) PARTITION BY RANGE (height);
+
+    CREATE TABLE {{schema}}.messages_main (
+      id            varchar(64) NOT NULL,
+      message_index bigint      NOT NULL,
+      type          text,
+      sender        text,
+      mentions      text[],
+      metadata      jsonb,
+      parent_index  bigint,
+      path          int[],
+      height        bigint      NOT NULL
+    ) PARTITION BY RANGE (height);
+
+    CREATE TABLE {{schema}}.events_raw (
+      id          varchar(64) NOT NULL,
+      event_index bigint      NOT NULL,
+      data        jsonb       NOT NULL,
+      height      bigint      NOT NULL
+    ) PARTITION BY RANGE (height);
+
+    CREATE TABLE {{schema}}.events_main (
+      id          varchar(64) NOT NULL,
+      event_index bigint      NOT NULL,
+      attr_index  bigint      NOT NULL,
+      event_type  text        NOT NULL,
+      attr_key    text        NOT NULL,
+      attr_value  text,
+      msg_index   bigint,
+      height      bigint      NOT NULL
+    ) PARTITION BY RANGE (height);
+
+    CREATE TABLE {{schema}}.partitioning_copied (
+      from_height bigint PRIMARY KEY
+    );
+  END IF;
+  COMMIT;
+
+  ---
+  -- Copy the existing rows, one partition range per transaction
+  ---
+  SELECT size INTO _size FROM {{schema}}.partitioning;
+  FOR _from IN
+    SELECT id - id % _size FROM {{schema}}.blocks_raw_unpartitioned
+    UNION
+    SELECT height - height % _size FROM {{schema}}.transactions_main
+    EXCEPT
+    SELECT from_height FROM {{schema}}.partitioning_copied
+    ORDER BY 1
+  LOOP
+    PERFORM {{schema}}.create_partitions(_from);
+
+    INSERT INTO {{schema}}.blocks_raw (id, data, chain_id)
+    SELECT id, data, chain_id
+    FROM {{schema}}.blocks_raw_unpartitioned
+    WHERE id >= _from AND id < _from + _size;
+
+    INSERT INTO {{schema}}.transactions_raw (id, data, height)
+    SELECT r.id, r.data, t.height
+    FROM {{schema}}.transactions_main t
+    JOIN {{schema}}.transactions_raw_unpartitioned r ON r.id = t.id
+    WHERE t.height >= _from AND t.height < _from + _size;
+
+    INSERT INTO {{schema}}.messages_raw (id, message_index, data, parent_index, path, height)
+    SELECT m.id, m.message_index, m.data, m.parent_index, m.path, t.height
+    FROM {{schema}}.transactions_main t
+    JOIN {{schema}}.messages_raw_unpartitioned m ON m.id = t.id
+    WHERE t.height >= _from AND t.height < _from + _size;
+
+    INSERT INTO {{schema}}.messages_main (id, message_index, type, sender, mentions, metadata, parent_index, path, height)
+    SELECT m.id, m.message_index, m.type, m.sender, m.mentions, m.metadata, m.parent_index, m.path, t.height
+    FROM {{schema}}.transactions_main t
+    JOIN {{schema}}.messages_main_unpartitioned m ON m.id = t.id
+    WHERE t.height >= _from AND t.height < _from + _size;
+
+    INSERT INTO {{schema}}.events_raw (id, event_index, data, height)
+    SELECT e.id, e.event_index, e.data, t.height
+    FROM {{schema}}.transactions_main t
+    JOIN {{schema}}.events_raw_unpartitioned e ON e.id = t.id
+    WHERE t.height >= _from AND t.height < _from + _size;
+
+    INSERT INTO {{schema}}.events_main (id, event_index, attr_index, event_type, attr_key, attr_value, msg_index, height)
+    SELECT e.id, e.event_index, e.attr_index, e.event_type, e.attr_key, e.attr_value, e.msg_index, t.height
+    FROM {{schema}}.transactions_main t
+    JOIN {{schema}}.events_main_unpartitioned e ON e.id = t.id
+    WHERE t.height >= _from AND t.height < _from + _size;
+
+    INSERT INTO {{schema}}.partitioning_copied (from_height) VALUES (_from);
+    COMMIT;
+  END LOOP;
+
+  DROP TABLE {{schema}}.events_main_unpartitioned;
+  DROP TABLE {{schema}}.events_raw_unpartitioned;
+  DROP TABLE {{schema}}.messages_main_unpartitioned;
+  DROP TABLE {{schema}}.messages_raw_unpartitioned;
+  DROP TABLE {{schema}}.transactions_raw_unpartitioned;
+  DROP TABLE {{schema}}.blocks_raw_unpartitioned;
+  DROP TABLE {{schema}}.partitioning_copied;
+
+  ---
+  -- Keys and indexes, created after the copy. The primary keys lead with the transaction hash, so the lookups by hash
+  -- and height need no other index.
+  ---
+  ALTER TABLE {{schema}}.blocks_raw       ADD PRIMARY KEY (chain_id, id);
+  ALTER TABLE {{schema}}.transactions_raw ADD PRIMARY KEY (id, height);
+  ALTER TABLE {{schema}}.messages_raw     ADD PRIMARY KEY (id, message_index, height);
+  ALTER TABLE {{schema}}.messages_main    ADD PRIMARY KEY (id, message_index, height);
+  ALTER TABLE {{schema}}.events_raw       ADD PRIMARY KEY (id, event_index, height);
+  ALTER TABLE {{schema}}.events_main      ADD PRIMARY KEY (id, event_index, attr_index, height);
+
+  ALTER TABLE {{schema}}.vesting_periods ADD FOREIGN KEY (id) REFERENCES {{schema}}.transactions_main (id) ON DELETE CASCADE;
+
+  FOREACH _table IN ARRAY ARRAY[
+    'proposals', 'proposal_votes', 'proposal_deposits', 'proposal_status_changes', 'proposal_tallies',
+    'ibc_packet_events', 'balance_changes', 'coins', 'transaction_fees', 'delegation_changes', 'staking_rewards',
+    'wasm_codes', 'wasm_contracts', 'wasm_executions', 'wasm_events'
+  ] LOOP
+    EXECUTE format('ALTER TABLE {{schema}}.%I ADD FOREIGN KEY (id) REFERENCES {{schema}}.transactions_main (id) ON DELETE CASCADE', _table);
+  END LOOP;
+
+  CREATE INDEX IF NOT EXISTS message_main_mentions_idx ON {{schema}}.messages_main USING GIN (mentions);
+  CREATE INDEX IF NOT EXISTS message_main_sender_idx   ON {{schema}}.messages_main (sender);
+  CREATE INDEX IF NOT EXISTS idx_messages_main_type    ON {{schema}}.messages_main (type);
+  CREATE INDEX IF NOT EXISTS messages_main_parent_idx  ON {{schema}}.messages_main (id, parent_index);
+
+  CREATE INDEX IF NOT EXISTS events_main_type_idx                ON {{schema}}.events_main (event_type);
+  CREATE INDEX IF NOT EXISTS events_main_msg_idx                 ON {{schema}}.events_main (msg_index);
+  CREATE INDEX IF NOT EXISTS events_main_attr_key_val_sha256_idx ON {{schema}}.events_main (attr_key, digest(COALESCE(attr_value, ''), 'sha256'));
+
+  ---
+  -- Views
+  ---
+  CREATE VIEW {{schema}}.proposal_messages AS
+  SELECT
+    p.module,
+    p.proposal_id,
+    m.id,
+    m.message_index,
+    m.type,
+    m.sender,
+    m.mentions,
+    m.metadata,
+    p.chain_id
+  FROM {{schema}}.proposals p
+  JOIN {{schema}}.messages_main m ON m.id = p.id AND m.height = p.height AND m.parent_index = p.message_index;
+
+  CREATE VIEW {{schema}}.chains AS
+  SELECT chain_id, MIN(id) AS earliest_height, MAX(id) AS latest_height, COUNT(*) AS block_count
+  FROM {{schema}}.blocks_raw
+  GROUP BY chain_id;
+
+  GRANT SELECT ON {{schema}}.blocks_raw        TO {{anon_role}};
+  GRANT SELECT ON {{schema}}.transactions_raw  TO {{anon_role}};
+  GRANT SELECT ON {{schema}}.messages_raw      TO {{anon_role}};
+  GRANT SELECT ON {{schema}}.messages_main     TO {{anon_role}};
+  GRANT SELECT ON {{schema}}.events_raw        TO {{anon_role}};
+  GRANT SELECT ON {{schema}}.events_main       TO {{anon_role}};
+  GRANT SELECT ON {{schema}}.proposal_messages TO {{anon_role}};
+  GRANT SELECT ON {{schema}}.chains            TO {{anon_role}};
+END
+$do$;
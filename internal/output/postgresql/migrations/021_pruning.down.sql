@@ -0,0 +1,6 @@
+BEGIN;
+
+REVOKE SELECT ON {{schema}}.pruning_floors FROM {{anon_role}};
+DROP TABLE IF EXISTS {{schema}}.pruning_floors;
+
+COMMIT;
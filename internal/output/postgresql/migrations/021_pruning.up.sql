@@ -0,0 +1,15 @@
+BEGIN;
+
+---
+-- The blocks of a chain below its pruning floor were pruned by `yaci prune` or the retention policy of the indexer.
+-- They are not indexed again when the missing blocks are filled.
+---
+CREATE TABLE IF NOT EXISTS {{schema}}.pruning_floors (
+  chain_id  text        PRIMARY KEY,
+  height    bigint      NOT NULL,
+  pruned_at timestamptz NOT NULL
+);
+
+GRANT SELECT ON {{schema}}.pruning_floors TO {{anon_role}};
+
+COMMIT;
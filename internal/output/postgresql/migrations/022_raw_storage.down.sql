@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Fails when raw JSON was compressed or dropped
+
+ALTER TABLE {{schema}}.events_raw
+  ALTER COLUMN data SET NOT NULL,
+  DROP COLUMN IF EXISTS data_zstd;
+
+ALTER TABLE {{schema}}.messages_raw
+  DROP COLUMN IF EXISTS data_zstd;
+
+ALTER TABLE {{schema}}.transactions_raw
+  ALTER COLUMN data SET NOT NULL,
+  DROP COLUMN IF EXISTS data_zstd;
+
+ALTER TABLE {{schema}}.blocks_raw
+  ALTER COLUMN data SET NOT NULL,
+  DROP COLUMN IF EXISTS data_zstd,
+  DROP COLUMN IF EXISTS tx_count,
+  DROP COLUMN IF EXISTS time,
+  DROP COLUMN IF EXISTS hash;
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+---
+-- The raw JSON can be stored compressed with zstd in the `data_zstd` columns, or not stored, instead of the `data`
+-- columns. At most one of `data` and `data_zstd` is set; the raw JSON of the rows where none is set was dropped and
+-- can be fetched from a node.
+--
+-- The summary of the blocks is stored apart from their raw JSON. It is not set on the blocks written before this
+-- migration, whose summary is read from the raw JSON.
+---
+ALTER TABLE {{schema}}.blocks_raw
+  ADD COLUMN IF NOT EXISTS hash      text,
+  ADD COLUMN IF NOT EXISTS time      text,
+  ADD COLUMN IF NOT EXISTS tx_count  integer,
+  ADD COLUMN IF NOT EXISTS data_zstd bytea,
+  ALTER COLUMN data DROP NOT NULL;
+
+ALTER TABLE {{schema}}.transactions_raw
+  ADD COLUMN IF NOT EXISTS data_zstd bytea,
+  ALTER COLUMN data DROP NOT NULL;
+
+ALTER TABLE {{schema}}.messages_raw
+  ADD COLUMN IF NOT EXISTS data_zstd bytea;
+
+ALTER TABLE {{schema}}.events_raw
+  ADD COLUMN IF NOT EXISTS data_zstd bytea,
+  ALTER COLUMN data DROP NOT NULL;
+
+COMMIT;
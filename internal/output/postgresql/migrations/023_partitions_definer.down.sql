@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER FUNCTION {{schema}}.create_partitions(bigint) SECURITY INVOKER RESET search_path;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+---
+-- The partitions are created by the indexer as it writes the blocks. The function creating them runs with the
+-- privileges of its owner, i.e., the user running the migrations, so that the indexer does not need the privilege
+-- to create tables when the migrations are run separately.
+---
+ALTER FUNCTION {{schema}}.create_partitions(bigint) SECURITY DEFINER SET search_path = pg_catalog, pg_temp;
+
+COMMIT;
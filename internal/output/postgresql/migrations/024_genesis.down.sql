@@ -0,0 +1,24 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS {{schema}}.get_balance_at(text, bigint, text);
+CREATE OR REPLACE FUNCTION {{schema}}.get_balance_at(_address text, _height bigint, _chain_id text DEFAULT NULL)
+RETURNS TABLE (denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT bc.denom, SUM(bc.amount) AS amount
+  FROM {{schema}}.balance_changes bc
+  WHERE bc.address = _address AND bc.height <= _height AND (_chain_id IS NULL OR bc.chain_id = _chain_id)
+  GROUP BY bc.denom
+  HAVING SUM(bc.amount) <> 0
+  ORDER BY bc.denom;
+$$;
+
+GRANT EXECUTE ON FUNCTION {{schema}}.get_balance_at(text, bigint, text) TO {{anon_role}};
+
+DROP TABLE IF EXISTS {{schema}}.genesis_validators;
+DROP TABLE IF EXISTS {{schema}}.genesis_vesting_periods;
+DROP TABLE IF EXISTS {{schema}}.genesis_balances;
+DROP TABLE IF EXISTS {{schema}}.genesis_accounts;
+DROP TABLE IF EXISTS {{schema}}.genesis;
+
+COMMIT;
@@ -0,0 +1,93 @@
+BEGIN;
+
+---
+-- The genesis state of a chain, i.e., its state at height 0, imported from its genesis file by `yaci import-genesis`.
+-- The genesis of a chain is replaced when it is imported again.
+---
+CREATE TABLE IF NOT EXISTS {{schema}}.genesis (
+  chain_id       text        PRIMARY KEY,
+  genesis_time   timestamptz NOT NULL,
+  initial_height bigint      NOT NULL,
+  imported_at    timestamptz NOT NULL
+);
+
+-- The accounts of the auth state, including the vesting and module accounts
+CREATE TABLE IF NOT EXISTS {{schema}}.genesis_accounts (
+  chain_id       text   NOT NULL,
+  address        text   NOT NULL,
+  type           text   NOT NULL,  -- e.g., /cosmos.vesting.v1beta1.PeriodicVestingAccount
+  account_number bigint,
+  data           jsonb  NOT NULL,
+  PRIMARY KEY (chain_id, address)
+);
+
+CREATE INDEX IF NOT EXISTS genesis_accounts_type_idx ON {{schema}}.genesis_accounts (chain_id, type);
+
+-- The balances of the bank state, one row per address and denom
+CREATE TABLE IF NOT EXISTS {{schema}}.genesis_balances (
+  chain_id text    NOT NULL,
+  address  text    NOT NULL,
+  denom    text    NOT NULL,
+  amount   numeric NOT NULL,
+  PRIMARY KEY (chain_id, address, denom)
+);
+
+-- The vesting periods of the periodic and delayed vesting accounts, one row per period and denom.
+-- The original vesting of a delayed vesting account is a single period unlocked at its end time.
+CREATE TABLE IF NOT EXISTS {{schema}}.genesis_vesting_periods (
+  chain_id     text        NOT NULL,
+  address      text        NOT NULL,
+  period_index bigint      NOT NULL,
+  denom        text        NOT NULL,
+  amount       numeric     NOT NULL,
+  unlock_time  timestamptz NOT NULL,
+  end_time     timestamptz NOT NULL,
+  PRIMARY KEY (chain_id, address, period_index, denom)
+);
+
+CREATE INDEX IF NOT EXISTS genesis_vesting_periods_unlock_time_idx ON {{schema}}.genesis_vesting_periods (chain_id, unlock_time);
+
+-- The validators of the staking state and of the genesis transactions
+CREATE TABLE IF NOT EXISTS {{schema}}.genesis_validators (
+  chain_id         text    NOT NULL,
+  operator_address text    NOT NULL,
+  moniker          text,
+  status           text,             -- NULL for the validators of the genesis transactions
+  jailed           boolean NOT NULL,
+  tokens           numeric NOT NULL,
+  delegator_shares numeric NOT NULL,
+  commission_rate  numeric,
+  consensus_pubkey jsonb,
+  data             jsonb   NOT NULL,
+  PRIMARY KEY (chain_id, operator_address)
+);
+
+-- The genesis balances are the balances at height 0
+DROP FUNCTION IF EXISTS {{schema}}.get_balance_at(text, bigint, text);
+CREATE OR REPLACE FUNCTION {{schema}}.get_balance_at(_address text, _height bigint, _chain_id text DEFAULT NULL)
+RETURNS TABLE (denom text, amount numeric)
+LANGUAGE sql STABLE
+AS $$
+  SELECT b.denom, SUM(b.amount) AS amount
+  FROM (
+    SELECT bc.denom, bc.amount
+    FROM {{schema}}.balance_changes bc
+    WHERE bc.address = _address AND bc.height <= _height AND (_chain_id IS NULL OR bc.chain_id = _chain_id)
+    UNION ALL
+    SELECT gb.denom, gb.amount
+    FROM {{schema}}.genesis_balances gb
+    WHERE gb.address = _address AND _height >= 0 AND (_chain_id IS NULL OR gb.chain_id = _chain_id)
+  ) b
+  GROUP BY b.denom
+  HAVING SUM(b.amount) <> 0
+  ORDER BY b.denom;
+$$;
+
+GRANT SELECT ON {{schema}}.genesis TO {{anon_role}};
+GRANT SELECT ON {{schema}}.genesis_accounts TO {{anon_role}};
+GRANT SELECT ON {{schema}}.genesis_balances TO {{anon_role}};
+GRANT SELECT ON {{schema}}.genesis_vesting_periods TO {{anon_role}};
+GRANT SELECT ON {{schema}}.genesis_validators TO {{anon_role}};
+GRANT EXECUTE ON FUNCTION {{schema}}.get_balance_at(text, bigint, text) TO {{anon_role}};
+
+COMMIT;
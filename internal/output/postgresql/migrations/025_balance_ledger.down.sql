@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS {{schema}}.balance_ledger_complete(bigint, text);
+DROP INDEX IF EXISTS {{schema}}.blocks_raw_missing_events_idx;
+ALTER TABLE {{schema}}.blocks_raw DROP COLUMN IF EXISTS has_events;
+
+COMMIT;
@@ -0,0 +1,40 @@
+BEGIN;
+
+---
+-- The balance ledger, i.e., the balance changes and the genesis balances of get_balance_at, misses the balance changes
+-- emitted outside of transactions, e.g., the minted coins and the distributed rewards, for the blocks indexed from a
+-- source without the block events, i.e., a gRPC server. has_events records whether the block events were indexed; it
+-- is unknown for the blocks indexed before this migration.
+---
+ALTER TABLE {{schema}}.blocks_raw ADD COLUMN IF NOT EXISTS has_events boolean;
+CREATE INDEX IF NOT EXISTS blocks_raw_missing_events_idx ON {{schema}}.blocks_raw (chain_id, id) WHERE has_events IS NOT TRUE;
+
+---
+-- Returns whether the balances of get_balance_at are complete at a height: the genesis of the chain is imported, the
+-- chain is indexed from its initial height and has not been pruned, and every block up to the height is indexed with
+-- its block events. Without a chain ID, every chain with an imported genesis or a pruning floor is checked.
+-- The blocks missing from the index are not checked.
+---
+CREATE OR REPLACE FUNCTION {{schema}}.balance_ledger_complete(_height bigint, _chain_id text DEFAULT NULL)
+RETURNS boolean
+LANGUAGE sql STABLE
+AS $$
+  SELECT EXISTS (
+    SELECT 1 FROM {{schema}}.genesis g WHERE _chain_id IS NULL OR g.chain_id = _chain_id
+  ) AND NOT EXISTS (
+    SELECT 1
+    FROM {{schema}}.genesis g
+    WHERE (_chain_id IS NULL OR g.chain_id = _chain_id)
+      AND (SELECT MIN(b.id) FROM {{schema}}.blocks_raw b WHERE b.chain_id = g.chain_id) > g.initial_height
+  ) AND NOT EXISTS (
+    SELECT 1 FROM {{schema}}.pruning_floors f WHERE _chain_id IS NULL OR f.chain_id = _chain_id
+  ) AND NOT EXISTS (
+    SELECT 1
+    FROM {{schema}}.blocks_raw b
+    WHERE (_chain_id IS NULL OR b.chain_id = _chain_id) AND b.id <= _height AND b.has_events IS NOT TRUE
+  );
+$$;
+
+GRANT EXECUTE ON FUNCTION {{schema}}.balance_ledger_complete(bigint, text) TO {{anon_role}};
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE {{schema}}.blocks_raw DROP COLUMN IF EXISTS last_block_hash;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+---
+-- The hash of the previous block referenced by the header of a block is stored apart from its raw JSON, which may not
+-- be stored, so that the links between the indexed blocks can be verified. It is not set on the blocks written before
+-- this migration, whose previous block hash is read from the raw JSON.
+---
+ALTER TABLE {{schema}}.blocks_raw ADD COLUMN IF NOT EXISTS last_block_hash text;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS {{schema}}.proposal_status_changes_voting_end_idx;
+ALTER TABLE {{schema}}.proposal_status_changes DROP COLUMN IF EXISTS voting_end_time;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+---
+-- The end of the voting period of the gov proposals entering it, queried when the outcomes of the gov proposals are
+-- queried from a source without the end block events, i.e., a gRPC server. The blocks ending a voting period are found
+-- with it, so that the proposals are only queried for these blocks.
+---
+ALTER TABLE {{schema}}.proposal_status_changes ADD COLUMN IF NOT EXISTS voting_end_time timestamptz;
+
+CREATE INDEX IF NOT EXISTS proposal_status_changes_voting_end_idx
+  ON {{schema}}.proposal_status_changes (chain_id, voting_end_time) WHERE voting_end_time IS NOT NULL;
+
+COMMIT;
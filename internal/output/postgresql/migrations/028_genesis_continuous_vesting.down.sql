@@ -0,0 +1,8 @@
+BEGIN;
+
+DELETE FROM {{schema}}.genesis_vesting_periods WHERE start_time IS NOT NULL OR unlock_time IS NULL OR end_time IS NULL;
+ALTER TABLE {{schema}}.genesis_vesting_periods ALTER COLUMN end_time SET NOT NULL;
+ALTER TABLE {{schema}}.genesis_vesting_periods ALTER COLUMN unlock_time SET NOT NULL;
+ALTER TABLE {{schema}}.genesis_vesting_periods DROP COLUMN IF EXISTS start_time;
+
+COMMIT;
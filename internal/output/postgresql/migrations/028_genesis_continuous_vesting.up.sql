@@ -0,0 +1,12 @@
+BEGIN;
+
+---
+-- The continuous and permanent locked vesting accounts of the genesis. The original vesting of a continuous vesting
+-- account is unlocked linearly from its start time to its end time, and that of a permanent locked account is never
+-- unlocked, i.e., its unlock and end times are NULL.
+---
+ALTER TABLE {{schema}}.genesis_vesting_periods ADD COLUMN IF NOT EXISTS start_time timestamptz;
+ALTER TABLE {{schema}}.genesis_vesting_periods ALTER COLUMN unlock_time DROP NOT NULL;
+ALTER TABLE {{schema}}.genesis_vesting_periods ALTER COLUMN end_time DROP NOT NULL;
+
+COMMIT;
@@ -0,0 +1,53 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// partitions creates the partitions of the tables partitioned by height before the blocks are written to them.
+// The partitions are created one partition ahead of the written blocks, so that they are rarely created on the write path.
+type partitions struct {
+	mu      sync.Mutex
+	size    uint64
+	created map[uint64]bool
+}
+
+func newPartitions(ctx context.Context, pool *pgxpool.Pool) (*partitions, error) {
+	var size int64
+	if err := pool.QueryRow(ctx, `SELECT size FROM partitioning`).Scan(&size); err != nil {
+		return nil, fmt.Errorf("failed to get the partition size: %w", err)
+	}
+	return &partitions{size: uint64(size), created: make(map[uint64]bool)}, nil
+}
+
+// ensure creates the partitions holding a height and the following height range, unless they were already created
+func (p *partitions) ensure(ctx context.Context, pool *pgxpool.Pool, height uint64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	from := height - height%p.size
+	for _, start := range []uint64{from, from + p.size} {
+		if p.created[start] {
+			continue
+		}
+		if _, err := pool.Exec(ctx, `SELECT create_partitions($1)`, int64(start)); err != nil {
+			return fmt.Errorf("failed to create the partitions of height %d: %w", start, err)
+		}
+		p.created[start] = true
+	}
+	return nil
+}
+
+// forget removes dropped or detached partitions, so that they are created again if blocks are written to their range
+func (p *partitions) forget(removed []partition) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, r := range removed {
+		delete(p.created, r.From)
+	}
+}
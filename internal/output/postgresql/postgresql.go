@@ -4,35 +4,46 @@ import (
 	"context"
 	"embed"
 	_ "embed"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 
-	"github.com/golang-migrate/migrate/v4"
-	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx"
-	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/output"
 )
 
 //go:embed migrations/*
 var migrationsFS embed.FS
 
+// maxNotificationPayload is the maximum size of a PostgreSQL notification payload, minus some margin
+const maxNotificationPayload = 7900
+
 type PostgresOutputHandler struct {
-	pool *pgxpool.Pool
+	pool       *pgxpool.Pool
+	schema     string
+	rawStorage output.RawStorage
+	partitions *partitions
 }
 
 func (h *PostgresOutputHandler) GetPool() *pgxpool.Pool {
 	return h.pool
 }
 
-func NewPostgresOutputHandler(connString string) (*PostgresOutputHandler, error) {
-	config, err := pgxpool.ParseConfig(connString)
+// NewPostgresOutputHandler returns a handler writing the indexed data to a schema, migrated to the latest version, which
+// grants the read access to anonRole. The raw JSON is written in the given storage mode. When the migrations are skipped, e.g., as they are run by a
+// separate job with the DDL privileges, the schema must already be at the latest version.
+func NewPostgresOutputHandler(connString, schema, anonRole string, rawStorage output.RawStorage, skipMigrations bool) (*PostgresOutputHandler, error) {
+	config, err := ParsePoolConfig(connString, schema)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse PostgreSQL connection string: %w", err)
+		return nil, err
+	}
+
+	if err = migrateOrCheck(connString, schema, anonRole, skipMigrations); err != nil {
+		return nil, err
 	}
 
 	pool, err := pgxpool.NewWithConfig(context.Background(), config)
@@ -40,26 +51,29 @@ func NewPostgresOutputHandler(connString string) (*PostgresOutputHandler, error)
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
 
-	handler := &PostgresOutputHandler{
-		pool: pool,
-	}
-
-	// Run migrations. This is idempotent.
-	if err = handler.runMigrations(); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	partitions, err := newPartitions(context.Background(), pool)
+	if err != nil {
+		pool.Close()
+		return nil, err
 	}
 
-	return handler, nil
+	return &PostgresOutputHandler{
+		pool:       pool,
+		schema:     schema,
+		rawStorage: rawStorage,
+		partitions: partitions,
+	}, nil
 }
 
-func (h *PostgresOutputHandler) GetLatestBlock(ctx context.Context) (*models.Block, error) {
-	var block models.Block
+func (h *PostgresOutputHandler) GetLatestBlock(ctx context.Context, chainID string) (*models.Block, error) {
+	block := models.Block{ChainID: chainID}
 	err := h.pool.QueryRow(ctx, `
 		SELECT id
-		FROM api.blocks_raw
+		FROM blocks_raw
+		WHERE chain_id = $1
 		ORDER BY id DESC
 		LIMIT 1
-	`).Scan(&block.ID)
+	`, chainID).Scan(&block.ID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil // No rows found
@@ -69,14 +83,15 @@ func (h *PostgresOutputHandler) GetLatestBlock(ctx context.Context) (*models.Blo
 	return &block, nil
 }
 
-func (h *PostgresOutputHandler) GetEarliestBlock(ctx context.Context) (*models.Block, error) {
-	var block models.Block
+func (h *PostgresOutputHandler) GetEarliestBlock(ctx context.Context, chainID string) (*models.Block, error) {
+	block := models.Block{ChainID: chainID}
 	err := h.pool.QueryRow(ctx, `
 		SELECT id
-		FROM api.blocks_raw
+		FROM blocks_raw
+		WHERE chain_id = $1 AND id >= COALESCE((SELECT height FROM pruning_floors WHERE chain_id = $1), 0)
 		ORDER BY id ASC
 		LIMIT 1
-	`).Scan(&block.ID)
+	`, chainID).Scan(&block.ID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil // No rows found
@@ -86,16 +101,19 @@ func (h *PostgresOutputHandler) GetEarliestBlock(ctx context.Context) (*models.B
 	return &block, nil
 }
 
-func (h *PostgresOutputHandler) GetMissingBlockIds(ctx context.Context) ([]uint64, error) {
+func (h *PostgresOutputHandler) GetMissingBlockIds(ctx context.Context, chainID string) ([]uint64, error) {
 	rows, err := h.pool.Query(ctx, `
 		SELECT s.id
 		FROM generate_series(
-				 (SELECT MIN(id) FROM api.blocks_raw),
-				 (SELECT MAX(id) FROM api.blocks_raw)
+				 GREATEST(
+					 (SELECT MIN(id) FROM blocks_raw WHERE chain_id = $1),
+					 (SELECT height FROM pruning_floors WHERE chain_id = $1)
+				 ),
+				 (SELECT MAX(id) FROM blocks_raw WHERE chain_id = $1)
 			 ) AS s(id)
-		LEFT JOIN api.blocks_raw t ON t.id = s.id
+		LEFT JOIN blocks_raw t ON t.chain_id = $1 AND t.id = s.id
 		WHERE t.id IS NULL;
-	`)
+	`, chainID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get missing block IDs: %w", err)
 	}
@@ -114,6 +132,10 @@ func (h *PostgresOutputHandler) GetMissingBlockIds(ctx context.Context) ([]uint6
 }
 
 func (h *PostgresOutputHandler) WriteBlockWithTransactions(ctx context.Context, block *models.Block, transactions []*models.Transaction) error {
+	if err := h.partitions.ensure(ctx, h.pool, block.ID); err != nil {
+		return err
+	}
+
 	tx, err := h.pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -121,23 +143,48 @@ func (h *PostgresOutputHandler) WriteBlockWithTransactions(ctx context.Context,
 	defer tx.Rollback(ctx) // Ensure rollback if commit is not reached
 
 	// Write block
+	data, compressed := h.rawStorage.Encode(block.Data)
 	_, err = tx.Exec(ctx, `
-		INSERT INTO api.blocks_raw (id, data) VALUES ($1, $2)
-		ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data;
-	`, block.ID, block.Data)
+		INSERT INTO blocks_raw (chain_id, id, data, data_zstd, hash, last_block_hash, time, tx_count, has_events)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+		ON CONFLICT (chain_id, id) DO UPDATE
+		SET data = EXCLUDED.data,
+		    data_zstd = EXCLUDED.data_zstd,
+		    hash = EXCLUDED.hash,
+		    last_block_hash = EXCLUDED.last_block_hash,
+		    time = EXCLUDED.time,
+		    tx_count = EXCLUDED.tx_count,
+		    has_events = EXCLUDED.has_events;
+	`, block.ChainID, block.ID, data, compressed, block.Hash, block.LastBlockHash, block.Time, block.TxCount, block.HasEvents)
 	if err != nil {
 		return fmt.Errorf("failed to write blockchain block: %w", err)
 	}
 
-	// Write transactions
+	// Write transactions and their normalized records
+	batch := &pgx.Batch{}
+	queueBlockGovernance(batch, block)
+	queueBlockBalanceChanges(batch, block)
+	queueBlockStaking(batch, block)
+	queueBlockCommit(batch, block)
+	queueBlockCoins(batch, block)
+	queueStaleTransactions(batch, block, transactions)
 	for _, txData := range transactions {
-		_, err = tx.Exec(ctx, `
-			INSERT INTO api.transactions_raw (id, data) VALUES ($1, $2)
-			ON CONFLICT (id) DO UPDATE SET data = EXCLUDED.data;
-		`, txData.Hash, txData.Data)
-		if err != nil {
-			return fmt.Errorf("failed to write blockchain transaction: %w", err)
+		if txData.Normalized == nil {
+			return fmt.Errorf("transaction %s is not normalized", txData.Hash)
 		}
+		queueTransaction(batch, block.ChainID, txData, h.rawStorage)
+	}
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to write blockchain transactions: %w", err)
+	}
+
+	// Notify the listeners. The notification is only delivered once the transaction commits.
+	payload, err := notificationPayload(block, transactions)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `SELECT pg_notify($1, $2)`, models.BlockNotificationChannel(h.schema), payload); err != nil {
+		return fmt.Errorf("failed to notify block: %w", err)
 	}
 
 	// Commit transaction
@@ -148,31 +195,172 @@ func (h *PostgresOutputHandler) WriteBlockWithTransactions(ctx context.Context,
 	return nil
 }
 
-func (h *PostgresOutputHandler) runMigrations() error {
-	// Create tables if they don't exist
-	slog.Info("Running PostgreSQL migrations...")
+// queueStaleTransactions queues the statements deleting the transactions previously written at the height of a block
+// that are not in the block, e.g., when a block is repaired. The records derived from them are deleted in cascade,
+// except those of the partitioned tables.
+func queueStaleTransactions(batch *pgx.Batch, block *models.Block, transactions []*models.Transaction) {
+	hashes := make([]string, 0, len(transactions))
+	for _, txData := range transactions {
+		hashes = append(hashes, txData.Hash)
+	}
 
-	d, err := iofs.New(migrationsFS, "migrations")
-	if err != nil {
-		return fmt.Errorf("failed to create migration source: %w", err)
+	for _, table := range []string{"events_main", "events_raw", "messages_main", "messages_raw", "transactions_raw"} {
+		batch.Queue(`
+			DELETE FROM `+table+`
+			WHERE height = $2 AND id <> ALL($3)
+			  AND id IN (SELECT id FROM transactions_main WHERE chain_id = $1 AND height = $2)
+		`, block.ChainID, block.ID, hashes)
+	}
+	batch.Queue(`DELETE FROM transactions_main WHERE chain_id = $1 AND height = $2 AND id <> ALL($3)`, block.ChainID, block.ID, hashes)
+}
+
+// queueTransaction queues the statements writing a transaction and its normalized records, with the raw JSON in a storage mode.
+// The records of a previously written transaction are replaced.
+func queueTransaction(batch *pgx.Batch, chainID string, txData *models.Transaction, rawStorage output.RawStorage) {
+	id := txData.Hash
+	n := txData.Normalized
+
+	data, compressed := rawStorage.Encode(txData.Data)
+	batch.Queue(`
+		INSERT INTO transactions_raw (id, data, data_zstd, height) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id, height) DO UPDATE SET data = EXCLUDED.data, data_zstd = EXCLUDED.data_zstd;
+	`, id, data, compressed, n.Height)
+
+	batch.Queue(`DELETE FROM vesting_periods WHERE id = $1`, id)
+	batch.Queue(`DELETE FROM events_main WHERE id = $1 AND height = $2`, id, n.Height)
+	batch.Queue(`DELETE FROM messages_main WHERE id = $1 AND height = $2`, id, n.Height)
+	batch.Queue(`DELETE FROM messages_raw WHERE id = $1 AND height = $2`, id, n.Height)
+	batch.Queue(`DELETE FROM events_raw WHERE id = $1 AND height = $2`, id, n.Height)
+	for _, table := range governanceTables {
+		batch.Queue(`DELETE FROM `+table+` WHERE id = $1`, id)
+	}
+	batch.Queue(`DELETE FROM ibc_packet_events WHERE id = $1`, id)
+	batch.Queue(`DELETE FROM balance_changes WHERE id = $1`, id)
+	batch.Queue(`DELETE FROM coins WHERE id = $1`, id)
+	batch.Queue(`DELETE FROM transaction_fees WHERE id = $1`, id)
+	batch.Queue(`DELETE FROM delegation_changes WHERE id = $1`, id)
+	batch.Queue(`DELETE FROM staking_rewards WHERE id = $1`, id)
+	for _, table := range wasmTables {
+		batch.Queue(`DELETE FROM `+table+` WHERE id = $1`, id)
+	}
+
+	batch.Queue(`
+		INSERT INTO transactions_main (id, fee, memo, error, height, timestamp, proposal_ids, gas_wanted, gas_used, fee_payer, fee_granter, chain_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (id) DO UPDATE
+		SET fee = EXCLUDED.fee,
+		    memo = EXCLUDED.memo,
+		    error = EXCLUDED.error,
+		    height = EXCLUDED.height,
+		    timestamp = EXCLUDED.timestamp,
+		    proposal_ids = EXCLUDED.proposal_ids,
+		    gas_wanted = EXCLUDED.gas_wanted,
+		    gas_used = EXCLUDED.gas_used,
+		    fee_payer = EXCLUDED.fee_payer,
+		    fee_granter = EXCLUDED.fee_granter,
+		    chain_id = EXCLUDED.chain_id;
+	`, id, jsonOrNil(n.Fee), n.Memo, n.Error, n.Height, n.Timestamp, n.ProposalIDs, n.GasWanted, n.GasUsed, n.FeePayer, n.FeeGranter, chainID)
+
+	for _, f := range n.FeeAmounts {
+		batch.Queue(`
+			INSERT INTO transaction_fees (id, denom, amount) VALUES ($1, $2, $3::numeric)
+			ON CONFLICT (id, denom) DO UPDATE SET amount = transaction_fees.amount + EXCLUDED.amount;
+		`, id, f.Denom, f.Amount)
+	}
+
+	for _, m := range n.Messages {
+		data, compressed := rawStorage.Encode(m.Data)
+		batch.Queue(`
+			INSERT INTO messages_raw (id, message_index, parent_index, path, data, data_zstd, height)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, id, m.Index, m.ParentIndex, m.Path, data, compressed, n.Height)
+		batch.Queue(`
+			INSERT INTO messages_main (id, message_index, parent_index, path, type, sender, mentions, metadata, height)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, id, m.Index, m.ParentIndex, m.Path, m.Type, m.Sender, m.Mentions, m.Metadata, n.Height)
+	}
+
+	for _, e := range n.Events {
+		data, compressed := rawStorage.Encode(e.Data)
+		batch.Queue(`
+			INSERT INTO events_raw (id, event_index, data, data_zstd, height) VALUES ($1, $2, $3, $4, $5)
+		`, id, e.Index, data, compressed, n.Height)
+		for i, attr := range e.Attributes {
+			batch.Queue(`
+				INSERT INTO events_main (id, event_index, attr_index, event_type, attr_key, attr_value, msg_index, height)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			`, id, e.Index, i, e.Type, attr.Key, attr.Value, e.MsgIndex, n.Height)
+		}
+	}
+
+	for _, p := range n.VestingPeriods {
+		batch.Queue(`
+			INSERT INTO vesting_periods (id, message_index, period_index, address, denom, amount, unlock_time, end_time)
+			VALUES ($1, $2, $3, $4, $5, $6::numeric, $7, $8)
+		`, id, p.MessageIndex, p.PeriodIndex, p.Address, p.Denom, p.Amount, p.UnlockTime, p.EndTime)
+	}
+
+	queueGovernance(batch, n.Governance, chainID, &id, n.Height, n.Timestamp)
+	queueIBCPackets(batch, n.IBCPackets, chainID, id, n.Height, n.Timestamp)
+	queueBalanceChanges(batch, n.BalanceChanges, chainID, &id, n.Height)
+	queueCoins(batch, n.Coins, chainID, &id, n.Height)
+	queueStaking(batch, n.Staking, chainID, &id, n.Height, &n.Timestamp)
+	queueWasm(batch, n.Wasm, chainID, id, n.Height, n.Timestamp)
+}
+
+// jsonOrNil returns nil for an empty JSON value, so it is stored as NULL
+func jsonOrNil(raw []byte) []byte {
+	if len(raw) == 0 {
+		return nil
+	}
+	return raw
+}
+
+// notificationPayload returns the JSON notification of the block, without the transaction hashes if they do not fit
+func notificationPayload(block *models.Block, transactions []*models.Transaction) (string, error) {
+	notification := models.BlockNotification{
+		ChainID:  block.ChainID,
+		Height:   block.ID,
+		TxHashes: make([]string, 0, len(transactions)),
+	}
+	for _, txData := range transactions {
+		notification.TxHashes = append(notification.TxHashes, txData.Hash)
 	}
 
-	driver, err := migratepgx.WithInstance(stdlib.OpenDBFromPool(h.pool), &migratepgx.Config{})
+	payload, err := json.Marshal(notification)
 	if err != nil {
-		return fmt.Errorf("failed to create migration driver: %w", err)
+		return "", fmt.Errorf("failed to marshal block notification: %w", err)
+	}
+	if len(payload) > maxNotificationPayload {
+		notification.TxHashes = []string{}
+		notification.Truncated = true
+		if payload, err = json.Marshal(notification); err != nil {
+			return "", fmt.Errorf("failed to marshal block notification: %w", err)
+		}
 	}
 
-	m, err := migrate.NewWithInstance("iofs", d, "postgres", driver)
+	return string(payload), nil
+}
+
+// migrateOrCheck runs the migrations of a schema, which is idempotent, or checks that they were run
+func migrateOrCheck(connString, schema, anonRole string, skipMigrations bool) error {
+	migrator, err := NewMigrator(connString, schema, anonRole)
 	if err != nil {
-		return fmt.Errorf("failed to create migration instance: %w", err)
+		return err
 	}
-	defer m.Close()
+	defer migrator.Close()
 
-	// Run migrations
-	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	if !skipMigrations {
+		return migrator.Up()
 	}
 
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	if !status.UpToDate() {
+		return fmt.Errorf("schema %s is at migration version %d (dirty: %t) instead of %d, run `yaci migrate up`", schema, status.Version, status.Dirty, status.Latest)
+	}
 	return nil
 }
 
@@ -0,0 +1,235 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+// txRawTables are the partitioned tables holding the raw JSON of the transactions, their messages and events
+var txRawTables = []string{"events_raw", "messages_raw", "transactions_raw"}
+
+// rawTables are the partitioned tables holding the raw JSON, the transactions first
+var rawTables = slices.Concat(txRawTables, []string{"blocks_raw"})
+
+// normalizedTables are the partitioned tables holding the normalized messages and events
+var normalizedTables = []string{"events_main", "messages_main"}
+
+// heightTables are the normalized tables whose rows are not all tied to a transaction, e.g., those of the block events.
+// The rows tied to a transaction are deleted in cascade with the transaction.
+var heightTables = []string{
+	"proposal_status_changes", "proposal_tallies", "ibc_packet_events", "balance_changes", "coins", "delegation_changes",
+	"staking_rewards", "validator_snapshots", "wasm_contract_snapshots", "block_proposers", "block_signatures",
+}
+
+// partition is a partition of a table partitioned by height, holding the heights from From to To excluded
+type partition struct {
+	Name     string
+	From, To uint64
+}
+
+// Prune prunes the blocks of a chain below the pruning floor of a retention policy.
+// The partitions below the floor are dropped or detached when they only hold pruned blocks; the rows of the pruned
+// blocks in the other partitions are deleted.
+func (h *PostgresOutputHandler) Prune(ctx context.Context, chainID string, policy output.RetentionPolicy) (uint64, error) {
+	floor, err := h.pruningFloor(ctx, chainID, policy)
+	if err != nil || floor == 0 {
+		return 0, err
+	}
+
+	tx, err := h.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) // Ensure rollback if commit is not reached
+
+	// The floor never decreases, so that pruned blocks are not indexed again
+	err = tx.QueryRow(ctx, `
+		INSERT INTO pruning_floors (chain_id, height, pruned_at) VALUES ($1, $2, now())
+		ON CONFLICT (chain_id) DO UPDATE SET height = GREATEST(pruning_floors.height, EXCLUDED.height), pruned_at = EXCLUDED.pruned_at
+		RETURNING height
+	`, chainID, int64(floor)).Scan(&floor)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write the pruning floor: %w", err)
+	}
+
+	tables := rawTables
+	if !policy.KeepNormalized {
+		tables = slices.Concat(normalizedTables, rawTables)
+	}
+	// The detached partitions are renamed with the time of the pruning, so that a partition created again by another
+	// chain and detached later does not collide with them
+	detachedSuffix := fmt.Sprintf("_detached_%d", time.Now().Unix())
+	var removed []partition
+	for _, table := range tables {
+		partitions, err := removablePartitions(ctx, tx, table, chainID, floor, !slices.Contains(rawTables, table))
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range partitions {
+			statements := []string{`DROP TABLE ` + p.Name}
+			if policy.DetachPartitions {
+				// The detached partition is renamed so that the partition can be created again by another chain
+				statements = []string{
+					`ALTER TABLE ` + table + ` DETACH PARTITION ` + p.Name,
+					`ALTER TABLE ` + p.Name + ` RENAME TO ` + p.Name + detachedSuffix,
+				}
+			}
+			for _, statement := range statements {
+				if _, err := tx.Exec(ctx, statement); err != nil {
+					return 0, fmt.Errorf("failed to remove partition %s: %w", p.Name, err)
+				}
+			}
+			removed = append(removed, p)
+		}
+	}
+
+	// The rows of the pruned blocks in the remaining partitions
+	batch := &pgx.Batch{}
+	prunedTables := txRawTables
+	if !policy.KeepNormalized {
+		prunedTables = slices.Concat(normalizedTables, prunedTables)
+	}
+	for _, table := range prunedTables {
+		batch.Queue(`
+			DELETE FROM `+table+` r
+			USING transactions_main t
+			WHERE t.chain_id = $1 AND t.height < $2 AND r.id = t.id AND r.height = t.height AND r.height < $2
+		`, chainID, int64(floor))
+	}
+	batch.Queue(`DELETE FROM blocks_raw WHERE chain_id = $1 AND id < $2`, chainID, int64(floor))
+	if !policy.KeepNormalized {
+		for _, table := range heightTables {
+			batch.Queue(`DELETE FROM `+table+` WHERE chain_id = $1 AND height < $2`, chainID, int64(floor))
+		}
+		batch.Queue(`DELETE FROM transactions_main WHERE chain_id = $1 AND height < $2`, chainID, int64(floor))
+	}
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, fmt.Errorf("failed to delete the pruned blocks: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	h.partitions.forget(removed)
+
+	slog.Info("Blocks pruned", "chain_id", chainID, "floor", floor, "removed_partitions", len(removed), "detached", policy.DetachPartitions)
+	return floor, nil
+}
+
+// GetPruningFloor returns the pruning floor of a chain, or zero if the chain was never pruned
+func (h *PostgresOutputHandler) GetPruningFloor(ctx context.Context, chainID string) (uint64, error) {
+	var floor int64
+	err := h.pool.QueryRow(ctx, `SELECT height FROM pruning_floors WHERE chain_id = $1`, chainID).Scan(&floor)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the pruning floor: %w", err)
+	}
+	return uint64(floor), nil
+}
+
+// pruningFloor returns the height of the earliest block of a chain kept by a retention policy, or zero if the
+// chain has no indexed block. The latest indexed block is always kept.
+func (h *PostgresOutputHandler) pruningFloor(ctx context.Context, chainID string, policy output.RetentionPolicy) (uint64, error) {
+	var earliest, latest *int64
+	err := h.pool.QueryRow(ctx, `SELECT MIN(id), MAX(id) FROM blocks_raw WHERE chain_id = $1`, chainID).Scan(&earliest, &latest)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get the indexed block range: %w", err)
+	}
+	if latest == nil {
+		return 0, nil
+	}
+
+	floor := uint64(*earliest)
+	if policy.Blocks > 0 && uint64(*latest) >= policy.Blocks {
+		floor = max(floor, uint64(*latest)-policy.Blocks+1)
+	}
+	if policy.Duration > 0 {
+		height, err := h.firstBlockSince(ctx, chainID, uint64(*earliest), uint64(*latest), time.Now().Add(-policy.Duration))
+		if err != nil {
+			return 0, err
+		}
+		floor = max(floor, height)
+	}
+	return min(floor, uint64(*latest)), nil
+}
+
+// firstBlockSince returns the lowest height from which the indexed blocks of a chain were produced at or after a time,
+// or the height following the latest block. The block times increase with the heights, so the height is found by a
+// binary search over the indexed blocks.
+func (h *PostgresOutputHandler) firstBlockSince(ctx context.Context, chainID string, earliest, latest uint64, since time.Time) (uint64, error) {
+	lo, hi := earliest, latest+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		var blockTime time.Time
+		err := h.pool.QueryRow(ctx, `
+			SELECT COALESCE(time, data->'block'->'header'->>'time')::timestamptz
+			FROM blocks_raw
+			WHERE chain_id = $1 AND id >= $2
+			ORDER BY id
+			LIMIT 1
+		`, chainID, int64(mid)).Scan(&blockTime)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("failed to get the time of block %d: %w", mid, err)
+		}
+		if errors.Is(err, pgx.ErrNoRows) || !blockTime.Before(since) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// removablePartitions returns the partitions of a table below the pruning floor of a chain that only hold pruned blocks,
+// i.e., blocks below the pruning floors of their chains. The partitions of the normalized tables must moreover only
+// hold blocks of the pruned chain, as the normalized data of the other chains may be kept.
+func removablePartitions(ctx context.Context, tx pgx.Tx, table, chainID string, floor uint64, normalized bool) ([]partition, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT c.relname, b[1]::bigint, b[2]::bigint
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		CROSS JOIN LATERAL regexp_match(pg_get_expr(c.relpartbound, c.oid), 'FROM \(''?(\d+)''?\) TO \(''?(\d+)''?\)') AS b
+		WHERE i.inhparent = to_regclass($1) AND b[2]::bigint <= $2
+		ORDER BY 2
+	`, table, int64(floor))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the partitions of %s: %w", table, err)
+	}
+	partitions, err := pgx.CollectRows(rows, pgx.RowToStructByPos[partition])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the partitions of %s: %w", table, err)
+	}
+
+	var removable []partition
+	for _, p := range partitions {
+		var kept bool
+		err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+			  SELECT 1 FROM blocks_raw b
+			  LEFT JOIN pruning_floors f ON f.chain_id = b.chain_id
+			  WHERE b.id >= $1 AND b.id < $2 AND b.id >= COALESCE(f.height, 0)
+			) OR EXISTS (
+			  SELECT 1 FROM transactions_main t
+			  LEFT JOIN pruning_floors f ON f.chain_id = t.chain_id
+			  WHERE t.height >= $1 AND t.height < $2 AND (t.height >= COALESCE(f.height, 0) OR ($3 AND t.chain_id <> $4))
+			)
+		`, int64(p.From), int64(p.To), normalized, chainID).Scan(&kept)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check partition %s: %w", p.Name, err)
+		}
+		if !kept {
+			removable = append(removable, p)
+		}
+	}
+	return removable, nil
+}
@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	migratepgx "github.com/golang-migrate/migrate/v4/database/pgx"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+const (
+	// schemaPlaceholder is replaced by the schema of the indexed data in the migrations
+	schemaPlaceholder = "{{schema}}"
+	// anonRolePlaceholder is replaced by the role of the anonymous web access in the migrations
+	anonRolePlaceholder = "{{anon_role}}"
+)
+
+// ParsePoolConfig parses a PostgreSQL connection string into the configuration of a pool resolving the unqualified
+// table and function names in the schema of the indexed data. The `public` schema is kept in the search path for the extensions.
+func ParsePoolConfig(connString, schema string) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL connection string: %w", err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema + ", public"
+	return config, nil
+}
+
+// migrationsTable returns the table recording the migrations applied to a schema.
+// The table of the default schema keeps the name used before the schema was configurable.
+func migrationsTable(schema string) string {
+	if schema == models.DefaultSchema {
+		return migratepgx.DefaultMigrationsTable
+	}
+	return migratepgx.DefaultMigrationsTable + "_" + schema
+}
+
+// schemaSource is a migration source replacing the placeholders of the migrations by the schema and the anonymous role
+type schemaSource struct {
+	source.Driver
+	replacer *strings.Replacer
+}
+
+func newSchemaSource(driver source.Driver, schema, anonRole string) *schemaSource {
+	return &schemaSource{
+		Driver:   driver,
+		replacer: strings.NewReplacer(schemaPlaceholder, schema, anonRolePlaceholder, anonRole),
+	}
+}
+
+func (s *schemaSource) ReadUp(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadUp(version)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.rewrite(r, identifier)
+}
+
+func (s *schemaSource) ReadDown(version uint) (io.ReadCloser, string, error) {
+	r, identifier, err := s.Driver.ReadDown(version)
+	if err != nil {
+		return nil, "", err
+	}
+	return s.rewrite(r, identifier)
+}
+
+func (s *schemaSource) rewrite(r io.ReadCloser, identifier string) (io.ReadCloser, string, error) {
+	defer r.Close()
+	migration, err := io.ReadAll(r)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read migration %s: %w", identifier, err)
+	}
+	rewritten := s.replacer.Replace(string(migration))
+	return io.NopCloser(strings.NewReader(rewritten)), identifier, nil
+}
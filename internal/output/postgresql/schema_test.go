@@ -0,0 +1,58 @@
+package postgresql
+
+import (
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// unqualifiedFunctionRegex matches the function definitions and drops whose name is not qualified with the schema
+var unqualifiedFunctionRegex = regexp.MustCompile(`(?i)FUNCTION\s+(IF\s+EXISTS\s+)?[a-z_0-9]+\s*\(`)
+
+// lastReleasedMigration is the latest migration applied by released versions, which is never rewritten
+const lastReleasedMigration = "007"
+
+func TestMigrationsQualifyFunctions(t *testing.T) {
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		if path.Base(file)[:3] <= lastReleasedMigration {
+			continue
+		}
+		migration, err := fs.ReadFile(migrationsFS, file)
+		require.NoError(t, err)
+		require.Empty(t, unqualifiedFunctionRegex.FindAllString(string(migration), -1), file)
+	}
+}
+
+func TestSchemaSource(t *testing.T) {
+	src := newSchemaSource(nil, "chain_b", "chain_b_anon")
+	require.Equal(t,
+		"GRANT SELECT ON chain_b.blocks TO chain_b_anon; -- the api schema",
+		src.replacer.Replace("GRANT SELECT ON {{schema}}.blocks TO {{anon_role}}; -- the api schema"),
+	)
+}
+
+func TestNestedMessagesMigrationRebindsTriggers(t *testing.T) {
+	migration, err := fs.ReadFile(migrationsFS, "migrations/009_nested_messages.up.sql")
+	require.NoError(t, err)
+	up := string(migration)
+
+	// The triggers created by 002 execute the unqualified functions until they are re-bound, before the backfill
+	backfill := strings.Index(up, "INSERT INTO {{schema}}.transactions_staging")
+	require.Positive(t, backfill)
+	for _, trigger := range []string{
+		"CREATE OR REPLACE TRIGGER new_transaction_update\nAFTER INSERT OR UPDATE\nON {{schema}}.transactions_raw\nFOR EACH ROW\nEXECUTE FUNCTION {{schema}}.update_transaction_main();",
+		"CREATE OR REPLACE TRIGGER new_message_update\nAFTER INSERT OR UPDATE\nON {{schema}}.messages_raw\nFOR EACH ROW\nEXECUTE FUNCTION {{schema}}.update_message_main();",
+	} {
+		i := strings.Index(up, trigger)
+		require.Positive(t, i, trigger)
+		require.Less(t, i, backfill, trigger)
+	}
+}
@@ -0,0 +1,58 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// queueBlockCommit queues the statements writing the proposer of the block and the signatures of its last commit.
+// The signatures of the signed block are replaced, and kept when the block has none, i.e., when they are not indexed.
+func queueBlockCommit(batch *pgx.Batch, block *models.Block) {
+	commit := block.Commit
+	if commit == nil {
+		return
+	}
+
+	batch.Queue(`
+		INSERT INTO block_proposers (chain_id, height, proposer_address, time) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (chain_id, height) DO UPDATE
+		SET proposer_address = EXCLUDED.proposer_address,
+		    time = EXCLUDED.time;
+	`, block.ChainID, block.ID, commit.ProposerAddress, commit.Time)
+
+	if len(commit.Signatures) == 0 {
+		return
+	}
+	batch.Queue(`DELETE FROM block_signatures WHERE chain_id = $1 AND height = $2`, block.ChainID, commit.Height)
+	for _, s := range commit.Signatures {
+		batch.Queue(`
+			INSERT INTO block_signatures (chain_id, height, consensus_address, validator_index, block_id_flag, voting_power, timestamp)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (chain_id, height, consensus_address) DO NOTHING;
+		`, block.ChainID, commit.Height, s.ConsensusAddress, s.ValidatorIndex, s.BlockIDFlag, s.VotingPower, s.Timestamp)
+	}
+}
+
+func (h *PostgresOutputHandler) WriteValidatorConsensusAddresses(ctx context.Context, chainID string, height uint64, addresses []models.ValidatorConsensusAddress) error {
+	batch := &pgx.Batch{}
+	for _, a := range addresses {
+		batch.Queue(`
+			INSERT INTO validator_consensus_addresses (chain_id, consensus_address, operator_address, moniker, consensus_pubkey, height)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (chain_id, consensus_address) DO UPDATE
+			SET operator_address = EXCLUDED.operator_address,
+			    moniker = EXCLUDED.moniker,
+			    consensus_pubkey = EXCLUDED.consensus_pubkey,
+			    height = EXCLUDED.height
+			WHERE validator_consensus_addresses.height <= EXCLUDED.height;
+		`, chainID, a.ConsensusAddress, a.OperatorAddress, a.Moniker, a.ConsensusPubkey, height)
+	}
+	if err := h.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to write validator consensus addresses: %w", err)
+	}
+	return nil
+}
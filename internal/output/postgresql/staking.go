@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// queueBlockStaking queues the statements replacing the staking records of the block events
+func queueBlockStaking(batch *pgx.Batch, block *models.Block) {
+	batch.Queue(`DELETE FROM delegation_changes WHERE chain_id = $1 AND id IS NULL AND height = $2`, block.ChainID, block.ID)
+	batch.Queue(`DELETE FROM staking_rewards WHERE chain_id = $1 AND id IS NULL AND height = $2`, block.ChainID, block.ID)
+	queueStaking(batch, block.Staking, block.ChainID, nil, int64(block.ID), nil)
+}
+
+// queueStaking queues the statements writing staking records.
+// The transaction ID and timestamp are nil for the records of the block events.
+func queueStaking(batch *pgx.Batch, staking models.Staking, chainID string, id *string, height int64, timestamp *time.Time) {
+	for seq, d := range staking.Delegations {
+		batch.Queue(`
+			INSERT INTO delegation_changes (chain_id, height, seq, id, event_index, msg_index, timestamp, action, delegator, validator, src_validator, denom, amount, completion_time, creation_height)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::numeric, $14, $15)
+		`, chainID, height, seq, id, d.EventIndex, d.MsgIndex, timestamp, d.Action, d.Delegator, d.Validator, d.SrcValidator, d.Denom, d.Amount, d.CompletionTime, d.CreationHeight)
+	}
+
+	for seq, r := range staking.Rewards {
+		batch.Queue(`
+			INSERT INTO staking_rewards (chain_id, height, seq, id, event_index, msg_index, timestamp, kind, delegator, validator, denom, amount)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12::numeric)
+		`, chainID, height, seq, id, r.EventIndex, r.MsgIndex, timestamp, r.Kind, r.Delegator, r.Validator, r.Denom, r.Amount)
+	}
+}
+
+func (h *PostgresOutputHandler) WriteValidatorSnapshot(ctx context.Context, snapshot *models.ValidatorSnapshot) error {
+	validators, err := json.Marshal(snapshot.Validators)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validators: %w", err)
+	}
+
+	_, err = h.pool.Exec(ctx, `
+		INSERT INTO validator_snapshots (chain_id, height, operator_address, snapshot_time, moniker, status, jailed, tokens, delegator_shares, commission_rate, consensus_pubkey, data)
+		SELECT
+			$4,
+			$1,
+			v->>'operatorAddress',
+			$2,
+			v->'description'->>'moniker',
+			v->>'status',
+			COALESCE((v->>'jailed')::boolean, false),
+			COALESCE((v->>'tokens')::numeric, 0),
+			COALESCE((v->>'delegatorShares')::numeric, 0),
+			(v->'commission'->'commissionRates'->>'rate')::numeric,
+			v->'consensusPubkey',
+			v
+		FROM jsonb_array_elements($3::jsonb) v
+		ON CONFLICT (chain_id, height, operator_address) DO UPDATE
+		SET snapshot_time = EXCLUDED.snapshot_time,
+		    moniker = EXCLUDED.moniker,
+		    status = EXCLUDED.status,
+		    jailed = EXCLUDED.jailed,
+		    tokens = EXCLUDED.tokens,
+		    delegator_shares = EXCLUDED.delegator_shares,
+		    commission_rate = EXCLUDED.commission_rate,
+		    consensus_pubkey = EXCLUDED.consensus_pubkey,
+		    data = EXCLUDED.data;
+	`, snapshot.Height, snapshot.Time, validators, snapshot.ChainID)
+	if err != nil {
+		return fmt.Errorf("failed to write validator snapshot: %w", err)
+	}
+	return nil
+}
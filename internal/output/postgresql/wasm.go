@@ -0,0 +1,99 @@
+package postgresql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// wasmTables are the tables holding the CosmWasm records of a transaction
+var wasmTables = []string{"wasm_events", "wasm_executions", "wasm_contracts", "wasm_codes"}
+
+// queueWasm queues the statements writing the CosmWasm records of a transaction
+func queueWasm(batch *pgx.Batch, wasm models.Wasm, chainID, id string, height int64, timestamp time.Time) {
+	for _, c := range wasm.Codes {
+		batch.Queue(`
+			INSERT INTO wasm_codes (code_id, id, message_index, height, timestamp, creator, checksum, chain_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (chain_id, code_id) DO UPDATE
+			SET id = EXCLUDED.id,
+			    message_index = EXCLUDED.message_index,
+			    height = EXCLUDED.height,
+			    timestamp = EXCLUDED.timestamp,
+			    creator = EXCLUDED.creator,
+			    checksum = EXCLUDED.checksum;
+		`, c.CodeID, id, c.MessageIndex, height, timestamp, c.Creator, c.Checksum, chainID)
+	}
+
+	for _, c := range wasm.Contracts {
+		batch.Queue(`
+			INSERT INTO wasm_contracts (address, code_id, id, message_index, height, timestamp, creator, admin, label, init_msg, funds, chain_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (chain_id, address) DO UPDATE
+			SET code_id = EXCLUDED.code_id,
+			    id = EXCLUDED.id,
+			    message_index = EXCLUDED.message_index,
+			    height = EXCLUDED.height,
+			    timestamp = EXCLUDED.timestamp,
+			    creator = EXCLUDED.creator,
+			    admin = EXCLUDED.admin,
+			    label = EXCLUDED.label,
+			    init_msg = EXCLUDED.init_msg,
+			    funds = EXCLUDED.funds;
+		`, c.Address, c.CodeID, id, c.MessageIndex, height, timestamp, c.Creator, c.Admin, c.Label, jsonOrNil(c.Msg), jsonOrNil(c.Funds), chainID)
+	}
+
+	for _, e := range wasm.Executions {
+		batch.Queue(`
+			INSERT INTO wasm_executions (id, message_index, height, timestamp, kind, contract, sender, action, msg, funds, code_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, id, e.MessageIndex, height, timestamp, e.Kind, e.Contract, e.Sender, e.Action, jsonOrNil(e.Msg), jsonOrNil(e.Funds), e.CodeID)
+	}
+
+	for _, e := range wasm.Events {
+		batch.Queue(`
+			INSERT INTO wasm_events (id, event_index, msg_index, height, timestamp, contract, event_type, action, attributes)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, id, e.EventIndex, e.MsgIndex, height, timestamp, e.Contract, e.Type, e.Action, e.Attributes)
+	}
+}
+
+func (h *PostgresOutputHandler) WriteWasmContractSnapshot(ctx context.Context, snapshot *models.WasmContractSnapshot) error {
+	contracts, err := json.Marshal(snapshot.Contracts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal contracts: %w", err)
+	}
+
+	_, err = h.pool.Exec(ctx, `
+		INSERT INTO wasm_contract_snapshots (chain_id, height, address, snapshot_time, code_id, creator, admin, label, ibc_port_id, data)
+		SELECT
+			$4,
+			$1,
+			c->>'address',
+			$2,
+			(c->'contractInfo'->>'codeId')::bigint,
+			c->'contractInfo'->>'creator',
+			NULLIF(c->'contractInfo'->>'admin', ''),
+			c->'contractInfo'->>'label',
+			NULLIF(c->'contractInfo'->>'ibcPortId', ''),
+			c
+		FROM jsonb_array_elements($3::jsonb) c
+		ON CONFLICT (chain_id, height, address) DO UPDATE
+		SET snapshot_time = EXCLUDED.snapshot_time,
+		    code_id = EXCLUDED.code_id,
+		    creator = EXCLUDED.creator,
+		    admin = EXCLUDED.admin,
+		    label = EXCLUDED.label,
+		    ibc_port_id = EXCLUDED.ibc_port_id,
+		    data = EXCLUDED.data;
+	`, snapshot.Height, snapshot.Time, contracts, snapshot.ChainID)
+	if err != nil {
+		return fmt.Errorf("failed to write contract snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package output
+
+import (
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// RawStorage is the storage mode of the raw JSON of the blocks, transactions, messages and events
+type RawStorage string
+
+const (
+	// RawStorageJSONB stores the raw JSON as is, queryable with the JSON operators
+	RawStorageJSONB RawStorage = "jsonb"
+	// RawStorageZstd stores the raw JSON compressed with zstd
+	RawStorageZstd RawStorage = "zstd"
+	// RawStorageDrop does not store the raw JSON once it is normalized; it must be fetched from a node when needed
+	RawStorageDrop RawStorage = "drop"
+)
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func (s RawStorage) Validate() error {
+	switch s {
+	case RawStorageJSONB, RawStorageZstd, RawStorageDrop:
+		return nil
+	default:
+		return fmt.Errorf("invalid raw storage %q, expected %s, %s or %s", s, RawStorageJSONB, RawStorageZstd, RawStorageDrop)
+	}
+}
+
+// Encode returns the values stored for raw JSON: the JSON itself, or the JSON compressed with zstd.
+// At most one of them is set.
+func (s RawStorage) Encode(data []byte) (jsonData, compressed []byte) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	switch s {
+	case RawStorageZstd:
+		return nil, zstdEncoder.EncodeAll(data, nil)
+	case RawStorageDrop:
+		return nil, nil
+	default:
+		return data, nil
+	}
+}
+
+// DecodeRaw returns the raw JSON from its stored values, or nil if it was dropped
+func DecodeRaw(jsonData, compressed []byte) ([]byte, error) {
+	if jsonData != nil || compressed == nil {
+		return jsonData, nil
+	}
+	data, err := zstdDecoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress raw JSON: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,30 @@
+package output_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+func TestRawStorage(t *testing.T) {
+	data := []byte(`{"block": {"header": {"height": "42"}}}`)
+
+	jsonData, compressed := output.RawStorageJSONB.Encode(data)
+	require.Equal(t, data, jsonData)
+	require.Nil(t, compressed)
+
+	jsonData, compressed = output.RawStorageZstd.Encode(data)
+	require.Nil(t, jsonData)
+	decoded, err := output.DecodeRaw(jsonData, compressed)
+	require.NoError(t, err)
+	require.Equal(t, data, decoded)
+
+	jsonData, compressed = output.RawStorageDrop.Encode(data)
+	decoded, err = output.DecodeRaw(jsonData, compressed)
+	require.NoError(t, err)
+	require.Nil(t, decoded)
+
+	require.Error(t, output.RawStorage("gzip").Validate())
+}
@@ -0,0 +1,36 @@
+package reflection
+
+import (
+	"fmt"
+	"os"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// WriteFileDescriptorSet writes the descriptors to a file, as a serialized `google.protobuf.FileDescriptorSet`
+func WriteFileDescriptorSet(path string, descriptors []*descriptorpb.FileDescriptorProto) error {
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: descriptors})
+	if err != nil {
+		return fmt.Errorf("failed to marshal descriptor set: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write descriptor set: %w", err)
+	}
+	return nil
+}
+
+// ReadFileDescriptorSet builds the file descriptor set from a file holding a serialized `google.protobuf.FileDescriptorSet`,
+// e.g., written by WriteFileDescriptorSet or `buf build`
+func ReadFileDescriptorSet(path string) (*protoregistry.Files, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal descriptor set: %w", err)
+	}
+	return BuildFileDescriptorSet(set.GetFile())
+}
@@ -0,0 +1,38 @@
+package reflection_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+func TestFileDescriptorSet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "descriptors.binpb")
+	descriptors := []*descriptorpb.FileDescriptorProto{
+		{
+			Name:        proto.String("file1.proto"),
+			Package:     proto.String("test"),
+			Dependency:  []string{"file2.proto"},
+			MessageType: []*descriptorpb.DescriptorProto{{Name: proto.String("Foo")}},
+		},
+		{
+			Name:    proto.String("file2.proto"),
+			Package: proto.String("test"),
+		},
+	}
+	require.NoError(t, reflection.WriteFileDescriptorSet(path, descriptors))
+
+	files, err := reflection.ReadFileDescriptorSet(path)
+	require.NoError(t, err)
+	require.Equal(t, 2, files.NumFiles())
+	_, err = files.FindDescriptorByName("test.Foo")
+	require.NoError(t, err)
+
+	_, err = reflection.ReadFileDescriptorSet(filepath.Join(t.TempDir(), "missing.binpb"))
+	require.Error(t, err)
+}
@@ -27,6 +27,7 @@ type CustomResolver struct {
 }
 
 // NewCustomResolver creates a new instance of CustomResolver.
+// The gRPC connection is nil when the files are not fetched from a gRPC server; the missing descriptors are not fetched then.
 func NewCustomResolver(ctx context.Context, files *protoregistry.Files, grpcConn *grpc.ClientConn, maxRetries uint) *CustomResolver {
 	return &CustomResolver{
 		files:       files, // Note: The protoregistry.Files type is safe for concurrent use by multiple goroutines, but it is not safe to concurrently mutate the registry while also being used.
@@ -79,6 +80,11 @@ func (r *CustomResolver) FindMessageByName(name protoreflect.FullName) (protoref
 		return createMessageType(desc, name)
 	}
 
+	// Without a gRPC connection, e.g., with a descriptor set read from a file, the registry is complete
+	if r.grpcConn == nil {
+		return nil, fmt.Errorf("message %s not found: %w", name, protoregistry.NotFound)
+	}
+
 	// If not found, attempt to fetch the descriptor via reflection
 	if err := r.fetchDescriptorBySymbol(string(name)); err != nil {
 		return nil, fmt.Errorf("failed to fetch descriptor for symbol %s: %w", name, err)
@@ -0,0 +1,240 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+// Keys of the CometBFT databases, see `store/store.go` and `state/store.go` of CometBFT
+const (
+	blockStoreStateKey = "blockStore"
+	blockMetaKeyFormat = "H:%d"
+	blockPartKeyFormat = "P:%d:%d"
+	abciResponsesKey   = "abciResponsesKey:%d"
+)
+
+// BlockStore is the block store of a stopped node, i.e., `blockstore.db` and `state.db` of its data directory
+type BlockStore struct {
+	decoder
+	blocks db
+	state  db
+}
+
+// OpenBlockStore opens the databases of the data directory of a node, read-only. The node must be stopped.
+// The blocks are decoded with the descriptors of the chain.
+func OpenBlockStore(dataDir string, backend Backend, resolver *reflection.CustomResolver) (*BlockStore, error) {
+	blocks, err := openDB(backend, filepath.Join(dataDir, "blockstore.db"))
+	if err != nil {
+		return nil, err
+	}
+	state, err := openDB(backend, filepath.Join(dataDir, "state.db"))
+	if err != nil {
+		blocks.Close()
+		return nil, err
+	}
+	return &BlockStore{decoder: decoder{resolver: resolver}, blocks: blocks, state: state}, nil
+}
+
+func (s *BlockStore) Close() error {
+	return errors.Join(s.blocks.Close(), s.state.Close())
+}
+
+func (s *BlockStore) ChainID(ctx context.Context) (string, error) {
+	height, err := s.LatestHeight(ctx)
+	if err != nil {
+		return "", err
+	}
+	block, _, err := s.Block(ctx, height)
+	if err != nil {
+		return "", err
+	}
+	return block.ChainID, nil
+}
+
+func (s *BlockStore) EarliestHeight(_ context.Context) (uint64, error) {
+	base, _, err := s.heights()
+	return base, err
+}
+
+func (s *BlockStore) LatestHeight(_ context.Context) (uint64, error) {
+	_, height, err := s.heights()
+	return height, err
+}
+
+// heights returns the heights of the earliest and latest blocks of the store
+func (s *BlockStore) heights() (uint64, uint64, error) {
+	value, err := s.blocks.Get([]byte(blockStoreStateKey))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read the block store state: %w", err)
+	}
+	if value == nil {
+		return 0, 0, fmt.Errorf("the block store holds no block")
+	}
+	return decodeBlockStoreState(value)
+}
+
+// decodeBlockStoreState decodes a `tendermint.store.BlockStoreState`, which is not part of the chain descriptors
+func decodeBlockStoreState(value []byte) (uint64, uint64, error) {
+	var base, height uint64
+	for len(value) > 0 {
+		num, typ, n := protowire.ConsumeTag(value)
+		if n < 0 {
+			return 0, 0, fmt.Errorf("invalid block store state: %w", protowire.ParseError(n))
+		}
+		value = value[n:]
+		if typ != protowire.VarintType {
+			n = protowire.ConsumeFieldValue(num, typ, value)
+		} else {
+			var v uint64
+			v, n = protowire.ConsumeVarint(value)
+			switch num {
+			case 1:
+				base = v
+			case 2:
+				height = v
+			}
+		}
+		if n < 0 {
+			return 0, 0, fmt.Errorf("invalid block store state: %w", protowire.ParseError(n))
+		}
+		value = value[n:]
+	}
+	if height == 0 {
+		return 0, 0, fmt.Errorf("the block store holds no block")
+	}
+	// The base is not set by the nodes that never pruned their blocks
+	return max(base, 1), height, nil
+}
+
+func (s *BlockStore) Block(_ context.Context, height uint64) (*models.Block, []*models.Transaction, error) {
+	meta, err := s.read(s.blocks, fmt.Sprintf(blockMetaKeyFormat, height), "tendermint.types.BlockMeta")
+	if err != nil {
+		return nil, nil, err
+	}
+	if meta == nil {
+		return nil, nil, fmt.Errorf("block %d is not in the block store", height)
+	}
+	blockID := get(meta, "block_id").Message()
+
+	// The block is split into parts
+	var data []byte
+	total := get(get(blockID, "part_set_header").Message(), "total").Uint()
+	for i := uint64(0); i < total; i++ {
+		part, err := s.read(s.blocks, fmt.Sprintf(blockPartKeyFormat, height, i), "tendermint.types.Part")
+		if err != nil {
+			return nil, nil, err
+		}
+		if part == nil {
+			return nil, nil, fmt.Errorf("part %d of block %d is not in the block store", i, height)
+		}
+		data = append(data, get(part, "bytes").Bytes()...)
+	}
+	block, err := s.unmarshal(data, "tendermint.types.Block")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode block %d: %w", height, err)
+	}
+
+	results, events, err := s.blockResults(height)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.block(height, blockID, block, results, events)
+}
+
+// blockResults returns the results of the transactions of a block and the events of the block:
+//   - since CometBFT v0.38, the `tendermint.abci.ExecTxResult` and the events of a `tendermint.abci.ResponseFinalizeBlock`,
+//   - before, the `tendermint.abci.ResponseDeliverTx` and the begin and end block events of a `tendermint.state.ABCIResponses`.
+func (s *BlockStore) blockResults(height uint64) ([]protoreflect.Message, []protoreflect.Message, error) {
+	key := fmt.Sprintf(abciResponsesKey, height)
+	value, err := s.state.Get([]byte(key))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if value == nil {
+		return nil, nil, fmt.Errorf("the results of block %d are not in the state store, the node may discard them (discard_abci_responses)", height)
+	}
+
+	if s.hasMessage("tendermint.abci.ResponseFinalizeBlock") {
+		response, err := s.unmarshal(value, "tendermint.abci.ResponseFinalizeBlock")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the results of block %d: %w", height, err)
+		}
+		return messages(get(response, "tx_results").List()), messages(get(response, "events").List()), nil
+	}
+
+	// `tendermint.state.ABCIResponses` is not part of the chain descriptors
+	var results, endBlockEvents, beginBlockEvents []protoreflect.Message
+	for len(value) > 0 {
+		num, typ, n := protowire.ConsumeTag(value)
+		if n < 0 {
+			return nil, nil, fmt.Errorf("failed to decode the results of block %d: %w", height, protowire.ParseError(n))
+		}
+		value = value[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, value)
+		} else {
+			var data []byte
+			if data, n = protowire.ConsumeBytes(value); n >= 0 {
+				err = nil
+				switch num {
+				case 1:
+					var result protoreflect.Message
+					if result, err = s.unmarshal(data, "tendermint.abci.ResponseDeliverTx"); err == nil {
+						results = append(results, result)
+					}
+				case 2:
+					var endBlock protoreflect.Message
+					if endBlock, err = s.unmarshal(data, "tendermint.abci.ResponseEndBlock"); err == nil {
+						endBlockEvents = messages(get(endBlock, "events").List())
+					}
+				case 3:
+					var beginBlock protoreflect.Message
+					if beginBlock, err = s.unmarshal(data, "tendermint.abci.ResponseBeginBlock"); err == nil {
+						beginBlockEvents = messages(get(beginBlock, "events").List())
+					}
+				}
+				if err != nil {
+					return nil, nil, fmt.Errorf("failed to decode the results of block %d: %w", height, err)
+				}
+			}
+		}
+		if n < 0 {
+			return nil, nil, fmt.Errorf("failed to decode the results of block %d: %w", height, protowire.ParseError(n))
+		}
+		value = value[n:]
+	}
+	return results, append(beginBlockEvents, endBlockEvents...), nil
+}
+
+// read returns the message of a key, or nil if the key does not exist
+func (s *BlockStore) read(d db, key string, name protoreflect.FullName) (protoreflect.Message, error) {
+	value, err := d.Get([]byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	msg, err := s.unmarshal(value, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", key, err)
+	}
+	return msg, nil
+}
+
+// messages returns the messages of a list
+func messages(list protoreflect.List) []protoreflect.Message {
+	msgs := make([]protoreflect.Message, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		msgs = append(msgs, list.Get(i).Message())
+	}
+	return msgs
+}
@@ -0,0 +1,46 @@
+package source_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/manifest-network/yaci/internal/source"
+)
+
+func TestBlockStoreHeights(t *testing.T) {
+	dataDir := t.TempDir()
+	for _, name := range []string{"blockstore.db", "state.db"} {
+		db, err := leveldb.OpenFile(filepath.Join(dataDir, name), nil)
+		require.NoError(t, err)
+		if name == "blockstore.db" {
+			// tendermint.store.BlockStoreState{Base: 100, Height: 250}
+			var state []byte
+			state = protowire.AppendTag(state, 1, protowire.VarintType)
+			state = protowire.AppendVarint(state, 100)
+			state = protowire.AppendTag(state, 2, protowire.VarintType)
+			state = protowire.AppendVarint(state, 250)
+			require.NoError(t, db.Put([]byte("blockStore"), state, nil))
+		}
+		require.NoError(t, db.Close())
+	}
+
+	store, err := source.OpenBlockStore(dataDir, source.BackendGoLevelDB, nil)
+	require.NoError(t, err)
+	defer store.Close()
+
+	earliest, err := store.EarliestHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), earliest)
+	latest, err := store.LatestHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(250), latest)
+
+	_, err = source.OpenBlockStore(t.TempDir(), source.BackendGoLevelDB, nil)
+	require.Error(t, err)
+	require.Error(t, source.Backend("rocksdb").Validate())
+}
@@ -0,0 +1,453 @@
+package source
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+const (
+	// fileDescriptorsPath is the ABCI query of the descriptors of the chain, see `cosmos.reflection.v1.ReflectionService`
+	fileDescriptorsPath = "/cosmos.reflection.v1.ReflectionService/FileDescriptors"
+	// proposalPath is the ABCI query of a gov proposal, see ProposalSource
+	proposalPath = "/cosmos.gov.v1.Query/Proposal"
+	// validatorsPerPage is the maximum number of validators returned per page by CometBFT
+	validatorsPerPage = 100
+)
+
+// CometBFT is the RPC server of a node, queried with the JSON-RPC over HTTP endpoints of CometBFT.
+// It provides the block results, i.e., the block events, which the gRPC server does not.
+type CometBFT struct {
+	decoder
+	url        string
+	httpClient *http.Client
+	maxRetries uint
+}
+
+// NewCometBFT returns the source of the RPC server at a URL, e.g., http://localhost:26657. The blocks are decoded with
+// the descriptors of the chain, see FetchCometBFTDescriptors.
+func NewCometBFT(rpcURL string, resolver *reflection.CustomResolver, maxRetries uint) *CometBFT {
+	return &CometBFT{
+		decoder:    decoder{resolver: resolver},
+		url:        strings.TrimSuffix(rpcURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		maxRetries: maxRetries,
+	}
+}
+
+// IsCometBFTAddress returns whether an address is the URL of a CometBFT RPC server rather than a gRPC address
+func IsCometBFTAddress(address string) bool {
+	return strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://")
+}
+
+// FetchCometBFTDescriptors returns the descriptors of a chain from the RPC server of a node, queried with the
+// reflection service of the Cosmos SDK
+func FetchCometBFTDescriptors(ctx context.Context, rpcURL string, maxRetries uint) ([]*descriptorpb.FileDescriptorProto, error) {
+	s := &CometBFT{url: strings.TrimSuffix(rpcURL, "/"), httpClient: &http.Client{Timeout: 30 * time.Second}, maxRetries: maxRetries}
+
+	var result struct {
+		Response struct {
+			Code  uint32 `json:"code"`
+			Log   string `json:"log"`
+			Value []byte `json:"value"`
+		} `json:"response"`
+	}
+	if err := s.call(ctx, "abci_query", url.Values{"path": {strconv.Quote(fileDescriptorsPath)}}, &result); err != nil {
+		return nil, err
+	}
+	if result.Response.Code != 0 {
+		return nil, fmt.Errorf("failed to query the descriptors: %s", result.Response.Log)
+	}
+
+	// `cosmos.reflection.v1.FileDescriptorsResponse` holds the descriptors in its first field
+	var descriptors []*descriptorpb.FileDescriptorProto
+	value := result.Response.Value
+	for len(value) > 0 {
+		num, typ, n := protowire.ConsumeTag(value)
+		if n < 0 {
+			return nil, fmt.Errorf("invalid descriptors: %w", protowire.ParseError(n))
+		}
+		value = value[n:]
+		if num == 1 && typ == protowire.BytesType {
+			var data []byte
+			if data, n = protowire.ConsumeBytes(value); n >= 0 {
+				descriptor := &descriptorpb.FileDescriptorProto{}
+				if err := proto.Unmarshal(data, descriptor); err != nil {
+					return nil, fmt.Errorf("invalid descriptor: %w", err)
+				}
+				descriptors = append(descriptors, descriptor)
+			}
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, value)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("invalid descriptors: %w", protowire.ParseError(n))
+		}
+		value = value[n:]
+	}
+	if len(descriptors) == 0 {
+		return nil, fmt.Errorf("the node returned no descriptor")
+	}
+	return descriptors, nil
+}
+
+func (s *CometBFT) ChainID(ctx context.Context) (string, error) {
+	status, err := s.status(ctx)
+	if err != nil {
+		return "", err
+	}
+	return status.NodeInfo.Network, nil
+}
+
+func (s *CometBFT) EarliestHeight(ctx context.Context) (uint64, error) {
+	status, err := s.status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(status.SyncInfo.EarliestBlockHeight, 10, 64)
+}
+
+func (s *CometBFT) LatestHeight(ctx context.Context) (uint64, error) {
+	status, err := s.status(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(status.SyncInfo.LatestBlockHeight, 10, 64)
+}
+
+type cometBFTStatus struct {
+	NodeInfo struct {
+		Network string `json:"network"`
+	} `json:"node_info"`
+	SyncInfo struct {
+		EarliestBlockHeight string `json:"earliest_block_height"`
+		LatestBlockHeight   string `json:"latest_block_height"`
+	} `json:"sync_info"`
+}
+
+func (s *CometBFT) status(ctx context.Context) (*cometBFTStatus, error) {
+	var status cometBFTStatus
+	if err := s.call(ctx, "status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (s *CometBFT) Block(ctx context.Context, height uint64) (*models.Block, []*models.Transaction, error) {
+	params := url.Values{"height": {strconv.FormatUint(height, 10)}}
+
+	var blockResult struct {
+		BlockID json.RawMessage `json:"block_id"`
+		Block   json.RawMessage `json:"block"`
+	}
+	if err := s.call(ctx, "block", params, &blockResult); err != nil {
+		return nil, nil, fmt.Errorf("failed to get block data: %w", err)
+	}
+	blockID, err := s.convert(blockResult.BlockID, "tendermint.types.BlockID")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the ID of block %d: %w", height, err)
+	}
+	block, err := s.convert(blockResult.Block, "tendermint.types.Block")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode block %d: %w", height, err)
+	}
+
+	// The events of the block are returned by finalize block since CometBFT v0.38, by begin and end block before
+	var results struct {
+		TxsResults          []json.RawMessage `json:"txs_results"`
+		BeginBlockEvents    []json.RawMessage `json:"begin_block_events"`
+		FinalizeBlockEvents []json.RawMessage `json:"finalize_block_events"`
+		EndBlockEvents      []json.RawMessage `json:"end_block_events"`
+	}
+	if err := s.call(ctx, "block_results", params, &results); err != nil {
+		return nil, nil, fmt.Errorf("failed to get block results: %w", err)
+	}
+	resultName := protoreflect.FullName("tendermint.abci.ResponseDeliverTx")
+	if s.hasMessage("tendermint.abci.ExecTxResult") {
+		resultName = "tendermint.abci.ExecTxResult"
+	}
+	txResults := make([]protoreflect.Message, 0, len(results.TxsResults))
+	for _, raw := range results.TxsResults {
+		result, err := s.convert(raw, resultName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the results of block %d: %w", height, err)
+		}
+		txResults = append(txResults, result)
+	}
+	var events []protoreflect.Message
+	for _, raw := range append(append(results.BeginBlockEvents, results.FinalizeBlockEvents...), results.EndBlockEvents...) {
+		event, err := s.convert(raw, "tendermint.abci.Event")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the events of block %d: %w", height, err)
+		}
+		events = append(events, event)
+	}
+
+	return s.block(height, blockID, block, txResults, events)
+}
+
+// ValidatorSet returns the validator set of a block. The validators have the public key and voting power of the
+// `cosmos.base.tendermint.v1beta1.Service.GetValidatorSetByHeight` response, but not its Bech32 address.
+func (s *CometBFT) ValidatorSet(ctx context.Context, height uint64) ([]json.RawMessage, error) {
+	var validators []json.RawMessage
+	for page := 1; ; page++ {
+		var result struct {
+			Validators []struct {
+				PubKey struct {
+					Type  string `json:"type"`
+					Value []byte `json:"value"`
+				} `json:"pub_key"`
+				VotingPower      string `json:"voting_power"`
+				ProposerPriority string `json:"proposer_priority"`
+			} `json:"validators"`
+			Total string `json:"total"`
+		}
+		params := url.Values{
+			"height":   {strconv.FormatUint(height, 10)},
+			"page":     {strconv.Itoa(page)},
+			"per_page": {strconv.Itoa(validatorsPerPage)},
+		}
+		if err := s.call(ctx, "validators", params, &result); err != nil {
+			return nil, err
+		}
+
+		for _, validator := range result.Validators {
+			var typeURL string
+			switch validator.PubKey.Type {
+			case "tendermint/PubKeyEd25519":
+				typeURL = "/cosmos.crypto.ed25519.PubKey"
+			case "tendermint/PubKeySecp256k1":
+				typeURL = "/cosmos.crypto.secp256k1.PubKey"
+			default:
+				return nil, fmt.Errorf("unsupported public key type %s", validator.PubKey.Type)
+			}
+			raw, err := json.Marshal(map[string]any{
+				"pubKey":           map[string]any{"@type": typeURL, "key": validator.PubKey.Value},
+				"votingPower":      validator.VotingPower,
+				"proposerPriority": validator.ProposerPriority,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal validator: %w", err)
+			}
+			validators = append(validators, raw)
+		}
+
+		total, _ := strconv.Atoi(result.Total)
+		if len(result.Validators) == 0 || len(validators) >= total {
+			return validators, nil
+		}
+	}
+}
+
+// GovProposal queries a gov proposal with the ABCI query of the gov module at a height
+func (s *CometBFT) GovProposal(ctx context.Context, height, proposalID uint64) (json.RawMessage, error) {
+	// `cosmos.gov.v1.QueryProposalRequest` holds the proposal ID in its first field
+	request := protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), proposalID)
+
+	var result struct {
+		Response struct {
+			Code  uint32 `json:"code"`
+			Log   string `json:"log"`
+			Value []byte `json:"value"`
+		} `json:"response"`
+	}
+	params := url.Values{
+		"path":   {strconv.Quote(proposalPath)},
+		"data":   {"0x" + hex.EncodeToString(request)},
+		"height": {strconv.FormatUint(height, 10)},
+	}
+	if err := s.call(ctx, "abci_query", params, &result); err != nil {
+		return nil, err
+	}
+	if result.Response.Code != 0 {
+		return nil, fmt.Errorf("failed to query proposal %d: %s", proposalID, result.Response.Log)
+	}
+
+	response, err := s.unmarshal(result.Response.Value, "cosmos.gov.v1.QueryProposalResponse")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode proposal %d: %w", proposalID, err)
+	}
+	return s.marshal(response)
+}
+
+// call calls a JSON-RPC method with retries and unmarshals its result
+func (s *CometBFT) call(ctx context.Context, method string, params url.Values, result any) error {
+	var err error
+	for attempt := uint(1); attempt <= max(s.maxRetries, 1); attempt++ {
+		if err = s.get(ctx, method, params, result); err == nil || ctx.Err() != nil {
+			return err
+		}
+		slog.Debug("Retrying CometBFT RPC call", "method", method, "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(2*attempt) * time.Second):
+		}
+	}
+	return fmt.Errorf("failed after %d retries: %w", s.maxRetries, err)
+}
+
+func (s *CometBFT) get(ctx context.Context, method string, params url.Values, result any) error {
+	endpoint := s.url + "/" + method
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", method, err)
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("invalid %s response (HTTP %d): %w", method, resp.StatusCode, err)
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s: %s %s", method, response.Error.Message, response.Error.Data)
+	}
+	if err := json.Unmarshal(response.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal %s result: %w", method, err)
+	}
+	return nil
+}
+
+// convert decodes the CometBFT JSON of a message, which differs from its protobuf JSON:
+//   - the part set headers are named `parts`,
+//   - the hashes and addresses are hex encoded,
+//   - the field names of some types are not snake case, e.g., `TotalVotingPower`,
+//   - the evidences and public keys are Amino JSON, i.e., `{"type": "tendermint/PubKeyEd25519", "value": ...}`.
+func (s *CometBFT) convert(data []byte, name protoreflect.FullName) (protoreflect.Message, error) {
+	msg, err := s.newMessage(name)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	protoJSON, err := json.Marshal(convertMessage(msg.Descriptor(), value))
+	if err != nil {
+		return nil, err
+	}
+	return s.unmarshalJSON(protoJSON, name)
+}
+
+// convertMessage converts the CometBFT JSON of a message to its protobuf JSON. The unknown fields are left as is.
+func convertMessage(descriptor protoreflect.MessageDescriptor, value any) any {
+	object, ok := value.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	// Amino JSON of a type of a oneof, e.g., the public key of a `tendermint.crypto.PublicKey`
+	if typeName, ok := object["type"].(string); ok && len(object) == 2 && object["value"] != nil {
+		if fd := descriptor.Fields().ByName(aminoFieldName(typeName)); fd != nil {
+			return map[string]any{string(fd.Name()): convertField(fd, object["value"])}
+		}
+	}
+
+	fields := make(map[string]protoreflect.FieldDescriptor, descriptor.Fields().Len())
+	for i := 0; i < descriptor.Fields().Len(); i++ {
+		fd := descriptor.Fields().Get(i)
+		fields[fieldKey(string(fd.Name()))] = fd
+	}
+	converted := make(map[string]any, len(object))
+	for key, v := range object {
+		if key == "parts" {
+			key = "part_set_header"
+		}
+		if fd, ok := fields[fieldKey(key)]; ok {
+			converted[string(fd.Name())] = convertField(fd, v)
+		} else {
+			converted[key] = v
+		}
+	}
+	return converted
+}
+
+func convertField(fd protoreflect.FieldDescriptor, value any) any {
+	if values, ok := value.([]any); ok && fd.IsList() {
+		converted := make([]any, 0, len(values))
+		for _, v := range values {
+			converted = append(converted, convertValue(fd, v))
+		}
+		return converted
+	}
+	return convertValue(fd, value)
+}
+
+func convertValue(fd protoreflect.FieldDescriptor, value any) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		if fd.IsMap() || fd.Message().FullName() == "google.protobuf.Timestamp" {
+			return value
+		}
+		return convertMessage(fd.Message(), value)
+	case protoreflect.BytesKind:
+		// The hashes and addresses are hex encoded, the other bytes base64 encoded
+		s, ok := value.(string)
+		name := string(fd.Name())
+		if !ok || !(strings.HasSuffix(name, "hash") || strings.HasSuffix(name, "address")) {
+			return value
+		}
+		if decoded, err := hex.DecodeString(s); err == nil {
+			return base64.StdEncoding.EncodeToString(decoded)
+		}
+	}
+	return value
+}
+
+// fieldKey returns the key matching the CometBFT JSON and protobuf names of a field, e.g., `TotalVotingPower` and
+// `total_voting_power`
+func fieldKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// aminoFieldName returns the oneof field name of an Amino type, e.g., `ed25519` for `tendermint/PubKeyEd25519` and
+// `duplicate_vote_evidence` for `tendermint/DuplicateVoteEvidence`
+func aminoFieldName(typeName string) protoreflect.Name {
+	typeName = strings.TrimPrefix(strings.TrimPrefix(typeName, "tendermint/"), "PubKey")
+	var name strings.Builder
+	for i, r := range typeName {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				name.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		name.WriteRune(r)
+	}
+	return protoreflect.Name(name.String())
+}
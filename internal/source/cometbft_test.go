@@ -0,0 +1,113 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestCometBFTStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/status", r.URL.Path)
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": -1, "result": {
+			"node_info": {"network": "test-1"},
+			"sync_info": {"earliest_block_height": "100", "latest_block_height": "250"}
+		}}`))
+	}))
+	defer server.Close()
+
+	src := NewCometBFT(server.URL+"/", nil, 1)
+	chainID, err := src.ChainID(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "test-1", chainID)
+	earliest, err := src.EarliestHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), earliest)
+	latest, err := src.LatestHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(250), latest)
+
+	require.True(t, IsCometBFTAddress("https://rpc.example.com"))
+	require.False(t, IsCometBFTAddress("grpc.example.com:443"))
+}
+
+func TestConvertMessage(t *testing.T) {
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string) *descriptorpb.FieldDescriptorProto {
+		f := &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test.proto"),
+		Package: proto.String("test"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: proto.String("PartSetHeader"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("total", 1, descriptorpb.FieldDescriptorProto_TYPE_UINT32, ""),
+					field("hash", 2, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				},
+			},
+			{
+				Name: proto.String("BlockID"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("hash", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+					field("part_set_header", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.PartSetHeader"),
+				},
+			},
+			{
+				Name: proto.String("PublicKey"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("ed25519", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+				},
+			},
+			{
+				Name: proto.String("Validator"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					field("address", 1, descriptorpb.FieldDescriptorProto_TYPE_BYTES, ""),
+					field("pub_key", 2, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".test.PublicKey"),
+					field("voting_power", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64, ""),
+				},
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	blockID := convertMessage(file.Messages().ByName("BlockID"), map[string]any{
+		"hash":  "0A0B",
+		"parts": map[string]any{"total": 1.0, "hash": "0C0D"},
+	})
+	require.Equal(t, map[string]any{
+		"hash":            "Cgs=",
+		"part_set_header": map[string]any{"total": 1.0, "hash": "DA0="},
+	}, blockID)
+
+	validator := convertMessage(file.Messages().ByName("Validator"), map[string]any{
+		"address":     "0A0B",
+		"pub_key":     map[string]any{"type": "tendermint/PubKeyEd25519", "value": "AAEC"},
+		"VotingPower": "10",
+		"unknown":     true,
+	})
+	require.Equal(t, map[string]any{
+		"address":      "Cgs=",
+		"pub_key":      map[string]any{"ed25519": "AAEC"},
+		"voting_power": "10",
+		"unknown":      true,
+	}, validator)
+
+	require.Equal(t, "duplicate_vote_evidence", string(aminoFieldName("tendermint/DuplicateVoteEvidence")))
+	require.Equal(t, "secp256k1", string(aminoFieldName("tendermint/PubKeySecp256k1")))
+}
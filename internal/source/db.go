@@ -0,0 +1,91 @@
+package source
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// Backend is the database backend of a node, i.e., its `db_backend` setting
+type Backend string
+
+const (
+	BackendGoLevelDB Backend = "goleveldb"
+	BackendPebble    Backend = "pebble"
+)
+
+func (b Backend) Validate() error {
+	switch b {
+	case BackendGoLevelDB, BackendPebble:
+		return nil
+	default:
+		return fmt.Errorf("invalid database backend %q, expected %s or %s", b, BackendGoLevelDB, BackendPebble)
+	}
+}
+
+// db is a database of a node opened read-only
+type db interface {
+	// Get returns the value of a key, or nil if the key does not exist
+	Get(key []byte) ([]byte, error)
+	Close() error
+}
+
+func openDB(backend Backend, path string) (db, error) {
+	switch backend {
+	case BackendGoLevelDB:
+		ldb, err := leveldb.OpenFile(path, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		return goLevelDB{ldb}, nil
+	case BackendPebble:
+		pdb, err := pebble.Open(path, &pebble.Options{ReadOnly: true, ErrorIfNotExists: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		return pebbleDB{pdb}, nil
+	default:
+		return nil, backend.Validate()
+	}
+}
+
+type goLevelDB struct {
+	db *leveldb.DB
+}
+
+func (d goLevelDB) Get(key []byte) ([]byte, error) {
+	value, err := d.db.Get(key, nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (d goLevelDB) Close() error {
+	return d.db.Close()
+}
+
+type pebbleDB struct {
+	db *pebble.DB
+}
+
+func (d pebbleDB) Get(key []byte) ([]byte, error) {
+	value, closer, err := d.db.Get(key)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	// The value is only valid until the closer is closed
+	return slices.Clone(value), nil
+}
+
+func (d pebbleDB) Close() error {
+	return d.db.Close()
+}
@@ -0,0 +1,191 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/normalize"
+	"github.com/manifest-network/yaci/internal/reflection"
+)
+
+// decoder builds the `cosmos.tx.v1beta1.Service` responses of the blocks read from CometBFT, i.e., the responses the
+// gRPC server of the node would return. The messages are decoded with the descriptors of the chain, which must include
+// the CometBFT types and the transaction service of the Cosmos SDK.
+type decoder struct {
+	resolver *reflection.CustomResolver
+}
+
+// block returns a block and its transactions from a `tendermint.types.BlockID`, a `tendermint.types.Block`, the results
+// of its transactions and the events of its block results, as `tendermint.abci.Event`
+func (d decoder) block(height uint64, blockID, block protoreflect.Message, results, events []protoreflect.Message) (*models.Block, []*models.Transaction, error) {
+	rawTxs := get(get(block, "data").Message(), "txs").List()
+	if len(results) != rawTxs.Len() {
+		return nil, nil, fmt.Errorf("block %d has %d transactions but %d results", height, rawTxs.Len(), len(results))
+	}
+
+	response, err := d.newMessage("cosmos.tx.v1beta1.GetBlockWithTxsResponse")
+	if err != nil {
+		return nil, nil, err
+	}
+	txs := mutable(response, "txs").List()
+	header := get(block, "header").Message()
+	var transactions []*models.Transaction
+	for i := 0; i < rawTxs.Len(); i++ {
+		txBytes := rawTxs.Get(i).Bytes()
+		// The encoding of a `cosmos.tx.v1beta1.TxRaw` is that of a `cosmos.tx.v1beta1.Tx`
+		tx, err := d.unmarshal(txBytes, "cosmos.tx.v1beta1.Tx")
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode transaction %d of block %d: %w", i, height, err)
+		}
+		txs.Append(protoreflect.ValueOfMessage(tx))
+
+		transaction, err := d.transaction(height, header, txBytes, tx, results[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+	set(response, "block_id", protoreflect.ValueOfMessage(blockID))
+	set(response, "block", protoreflect.ValueOfMessage(block))
+	pagination := mutable(response, "pagination").Message()
+	set(pagination, "total", protoreflect.ValueOfUint64(uint64(rawTxs.Len())))
+
+	blockJSON, err := d.marshal(response)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal block %d: %w", height, err)
+	}
+
+	rawEvents := make([]json.RawMessage, 0, len(events))
+	for _, event := range events {
+		eventJSON, err := d.marshal(event)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal the events of block %d: %w", height, err)
+		}
+		rawEvents = append(rawEvents, eventJSON)
+	}
+	blockEvents, err := normalize.Events(rawEvents)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to normalize the events of block %d: %w", height, err)
+	}
+
+	return &models.Block{
+		ID:        height,
+		ChainID:   get(header, "chain_id").String(),
+		Data:      blockJSON,
+		Events:    blockEvents,
+		HasEvents: true,
+	}, transactions, nil
+}
+
+// transaction returns the `cosmos.tx.v1beta1.Service.GetTx` response of a transaction, as built by the Cosmos SDK
+func (d decoder) transaction(height uint64, header protoreflect.Message, txBytes []byte, tx, result protoreflect.Message) (*models.Transaction, error) {
+	hash := sha256.Sum256(txBytes)
+
+	txResponse, err := d.newMessage("cosmos.base.abci.v1beta1.TxResponse")
+	if err != nil {
+		return nil, err
+	}
+	set(txResponse, "height", protoreflect.ValueOfInt64(int64(height)))
+	set(txResponse, "txhash", protoreflect.ValueOfString(strings.ToUpper(hex.EncodeToString(hash[:]))))
+	set(txResponse, "data", protoreflect.ValueOfString(strings.ToUpper(hex.EncodeToString(get(result, "data").Bytes()))))
+	for _, name := range []protoreflect.Name{"codespace", "code", "info", "gas_wanted", "gas_used"} {
+		set(txResponse, name, get(result, name))
+	}
+	// The raw log is patched to bytes, see reflection.BuildFileDescriptorSet
+	rawLog := get(result, "log").String()
+	if field(txResponse, "raw_log").Kind() == protoreflect.BytesKind {
+		set(txResponse, "raw_log", protoreflect.ValueOfBytes([]byte(rawLog)))
+	} else {
+		set(txResponse, "raw_log", protoreflect.ValueOfString(rawLog))
+	}
+	events := mutable(txResponse, "events").List()
+	resultEvents := get(result, "events").List()
+	for i := 0; i < resultEvents.Len(); i++ {
+		events.Append(resultEvents.Get(i))
+	}
+	timestamp := get(header, "time").Message()
+	blockTime := time.Unix(get(timestamp, "seconds").Int(), get(timestamp, "nanos").Int()).UTC()
+	set(txResponse, "timestamp", protoreflect.ValueOfString(blockTime.Format(time.RFC3339)))
+	anyTx := mutable(txResponse, "tx").Message()
+	set(anyTx, "type_url", protoreflect.ValueOfString("/cosmos.tx.v1beta1.Tx"))
+	set(anyTx, "value", protoreflect.ValueOfBytes(txBytes))
+
+	response, err := d.newMessage("cosmos.tx.v1beta1.GetTxResponse")
+	if err != nil {
+		return nil, err
+	}
+	set(response, "tx", protoreflect.ValueOfMessage(tx))
+	set(response, "tx_response", protoreflect.ValueOfMessage(txResponse))
+
+	txJSON, err := d.marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction of block %d: %w", height, err)
+	}
+	return &models.Transaction{Hash: hex.EncodeToString(hash[:]), Data: txJSON}, nil
+}
+
+// hasMessage returns true if the descriptors include a message, e.g., to detect the CometBFT version of the chain
+func (d decoder) hasMessage(name protoreflect.FullName) bool {
+	_, err := d.resolver.FindMessageByName(name)
+	return err == nil
+}
+
+func (d decoder) newMessage(name protoreflect.FullName) (protoreflect.Message, error) {
+	messageType, err := d.resolver.FindMessageByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("the descriptors have no %s: %w", name, err)
+	}
+	return messageType.New(), nil
+}
+
+func (d decoder) unmarshal(data []byte, name protoreflect.FullName) (protoreflect.Message, error) {
+	msg, err := d.newMessage(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := (proto.UnmarshalOptions{Resolver: d.resolver}).Unmarshal(data, msg.Interface()); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// unmarshalJSON decodes the protobuf JSON of a message; the fields unknown to the descriptors are ignored
+func (d decoder) unmarshalJSON(data []byte, name protoreflect.FullName) (protoreflect.Message, error) {
+	msg, err := d.newMessage(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := (protojson.UnmarshalOptions{Resolver: d.resolver, DiscardUnknown: true}).Unmarshal(data, msg.Interface()); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (d decoder) marshal(msg protoreflect.Message) ([]byte, error) {
+	return protojson.MarshalOptions{Resolver: d.resolver}.Marshal(msg.Interface())
+}
+
+func field(msg protoreflect.Message, name protoreflect.Name) protoreflect.FieldDescriptor {
+	return msg.Descriptor().Fields().ByName(name)
+}
+
+func get(msg protoreflect.Message, name protoreflect.Name) protoreflect.Value {
+	return msg.Get(field(msg, name))
+}
+
+func set(msg protoreflect.Message, name protoreflect.Name, value protoreflect.Value) {
+	msg.Set(field(msg, name), value)
+}
+
+func mutable(msg protoreflect.Message, name protoreflect.Name) protoreflect.Value {
+	return msg.Mutable(field(msg, name))
+}
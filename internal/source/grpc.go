@@ -0,0 +1,123 @@
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/manifest-network/yaci/internal/client"
+	"github.com/manifest-network/yaci/internal/models"
+	"github.com/manifest-network/yaci/internal/utils"
+)
+
+const (
+	blockMethodFullName = "cosmos.tx.v1beta1.Service.GetBlockWithTxs"
+	txMethodFullName    = "cosmos.tx.v1beta1.Service.GetTx"
+	// proposalMethodFullName is the gov proposal query, see ProposalSource
+	proposalMethodFullName = "cosmos.gov.v1.Query.Proposal"
+	// proposalsMethodFullName is the gov proposals query, see VotingProposalSource
+	proposalsMethodFullName = "cosmos.gov.v1.Query.Proposals"
+)
+
+// GRPC is the gRPC server of a node. It does not provide the block results.
+type GRPC struct {
+	gRPCClient *client.GRPCClient
+	maxRetries uint
+}
+
+func NewGRPC(gRPCClient *client.GRPCClient, maxRetries uint) *GRPC {
+	return &GRPC{gRPCClient: gRPCClient, maxRetries: maxRetries}
+}
+
+// Client returns the gRPC client of the source, e.g., to query the state of the chain
+func (s *GRPC) Client() *client.GRPCClient {
+	return s.gRPCClient
+}
+
+func (s *GRPC) ChainID(ctx context.Context) (string, error) {
+	return utils.GetChainIDWithRetry(s.withContext(ctx), s.maxRetries)
+}
+
+func (s *GRPC) EarliestHeight(_ context.Context) (uint64, error) {
+	// TODO: Get the earliest block from the gRPC server
+	// See https://github.com/manifest-network/yaci/issues/28
+	return 1, nil
+}
+
+func (s *GRPC) LatestHeight(ctx context.Context) (uint64, error) {
+	return utils.GetLatestBlockHeightWithRetry(s.withContext(ctx), s.maxRetries)
+}
+
+func (s *GRPC) Block(ctx context.Context, height uint64) (*models.Block, []*models.Transaction, error) {
+	blockJsonBytes, err := s.FetchBlock(ctx, height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get block data: %w", err)
+	}
+
+	var data struct {
+		Block struct {
+			Header struct {
+				ChainID string `json:"chainId"`
+			} `json:"header"`
+			Data struct {
+				Txs []string `json:"txs"`
+			} `json:"data"`
+		} `json:"block"`
+	}
+	if err := json.Unmarshal(blockJsonBytes, &data); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal block JSON: %w", err)
+	}
+
+	var transactions []*models.Transaction
+	for _, tx := range data.Block.Data.Txs {
+		decodedBytes, err := base64.StdEncoding.DecodeString(tx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode tx: %w", err)
+		}
+		hash := sha256.Sum256(decodedBytes)
+		hashStr := hex.EncodeToString(hash[:])
+
+		txJsonBytes, err := s.FetchTransaction(ctx, hashStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get transaction %s: %w", hashStr, err)
+		}
+		transactions = append(transactions, &models.Transaction{Hash: hashStr, Data: txJsonBytes})
+	}
+
+	return &models.Block{ID: height, ChainID: data.Block.Header.ChainID, Data: blockJsonBytes}, transactions, nil
+}
+
+func (s *GRPC) ValidatorSet(ctx context.Context, height uint64) ([]json.RawMessage, error) {
+	return utils.GetValidatorSetWithRetry(s.withContext(ctx), height, s.maxRetries)
+}
+
+// FetchBlock returns the `cosmos.tx.v1beta1.Service.GetBlockWithTxs` response of a block, e.g., to serve the raw JSON
+// that the output does not store
+func (s *GRPC) FetchBlock(ctx context.Context, height uint64) ([]byte, error) {
+	return utils.GetGRPCResponse(s.withContext(ctx), blockMethodFullName, s.maxRetries, []byte(fmt.Sprintf(`{"height": %d}`, height)))
+}
+
+// FetchTransaction returns the `cosmos.tx.v1beta1.Service.GetTx` response of a transaction
+func (s *GRPC) FetchTransaction(ctx context.Context, hash string) ([]byte, error) {
+	return utils.GetGRPCResponse(s.withContext(ctx), txMethodFullName, s.maxRetries, []byte(fmt.Sprintf(`{"hash": "%s"}`, hash)))
+}
+
+func (s *GRPC) GovProposal(ctx context.Context, height, proposalID uint64) (json.RawMessage, error) {
+	return utils.GetGRPCResponse(utils.AtHeight(s.withContext(ctx), height), proposalMethodFullName, s.maxRetries, []byte(fmt.Sprintf(`{"proposal_id": "%d"}`, proposalID)))
+}
+
+func (s *GRPC) GovVotingProposals(ctx context.Context, height uint64) ([]json.RawMessage, error) {
+	params := map[string]any{"proposal_status": "PROPOSAL_STATUS_VOTING_PERIOD"}
+	return utils.GetAllPagesWithRetry(utils.AtHeight(s.withContext(ctx), height), proposalsMethodFullName, s.maxRetries, params, "proposals")
+}
+
+func (s *GRPC) withContext(ctx context.Context) *client.GRPCClient {
+	return &client.GRPCClient{
+		Conn:     s.gRPCClient.Conn,
+		Ctx:      ctx,
+		Resolver: s.gRPCClient.Resolver,
+	}
+}
@@ -0,0 +1,64 @@
+// Package source provides the sources of the extracted blocks: a gRPC server, a CometBFT RPC server or the block store
+// of a stopped node. Whatever the source, the blocks and transactions are returned as by the `cosmos.tx.v1beta1.Service`
+// gRPC methods.
+package source
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/manifest-network/yaci/internal/models"
+)
+
+// BlockSource is a source of the blocks of a chain
+type BlockSource interface {
+	// ChainID returns the chain ID of the blocks
+	ChainID(ctx context.Context) (string, error)
+
+	// EarliestHeight returns the height of the earliest block of the source
+	EarliestHeight(ctx context.Context) (uint64, error)
+
+	// LatestHeight returns the height of the latest block of the source
+	LatestHeight(ctx context.Context) (uint64, error)
+
+	// Block returns a block, i.e., the `cosmos.tx.v1beta1.Service.GetBlockWithTxs` response, and its transactions with
+	// their results, i.e., the `cosmos.tx.v1beta1.Service.GetTx` responses. The block events are set when the source
+	// provides the block results.
+	Block(ctx context.Context, height uint64) (*models.Block, []*models.Transaction, error)
+}
+
+// ValidatorSetSource is a block source that returns the validator sets, required to index the validator signatures
+type ValidatorSetSource interface {
+	BlockSource
+
+	// ValidatorSet returns the validator set of a block, in the order of the commit signatures, as the validators of
+	// the `cosmos.base.tendermint.v1beta1.Service.GetValidatorSetByHeight` response
+	ValidatorSet(ctx context.Context, height uint64) ([]json.RawMessage, error)
+}
+
+// ProposalSource is a block source that queries the gov proposals, e.g., for their final tally, which the end block
+// events do not carry
+type ProposalSource interface {
+	BlockSource
+
+	// GovProposal returns the `cosmos.gov.v1.Query.Proposal` response of a proposal at a height. The node must not have
+	// pruned the state at that height.
+	GovProposal(ctx context.Context, height, proposalID uint64) (json.RawMessage, error)
+}
+
+// VotingProposalSource is a proposal source that lists the gov proposals in their voting period, to find the proposals
+// ended by a block when the source does not provide the end block events
+type VotingProposalSource interface {
+	ProposalSource
+
+	// GovVotingProposals returns the gov proposals in their voting period at a height, as the proposals of the
+	// `cosmos.gov.v1.Query.Proposals` responses. The node must not have pruned the state at that height.
+	GovVotingProposals(ctx context.Context, height uint64) ([]json.RawMessage, error)
+}
+
+// ProvidesBlockEvents returns whether a source provides the block events, see models.Block.HasEvents. A gRPC server
+// does not provide them.
+func ProvidesBlockEvents(src BlockSource) bool {
+	_, ok := src.(*GRPC)
+	return !ok
+}
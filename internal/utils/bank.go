@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/manifest-network/yaci/internal/client"
+)
+
+const balanceMethod = "cosmos.bank.v1beta1.Query.Balance"
+
+// GetBalanceAtHeightWithRetry retrieves the balance of an address in a denomination at a height from the gRPC server with retry logic.
+func GetBalanceAtHeightWithRetry(gRPCClient *client.GRPCClient, address, denom string, height uint64, maxRetries uint) (string, error) {
+	params, err := json.Marshal(map[string]string{"address": address, "denom": denom})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal balance request: %w", err)
+	}
+
+	resp, err := GetGRPCResponse(AtHeight(gRPCClient, height), balanceMethod, maxRetries, params)
+	if err != nil {
+		return "", err
+	}
+
+	var balance struct {
+		Balance struct {
+			Amount string `json:"amount"`
+		} `json:"balance"`
+	}
+	if err := json.Unmarshal(resp, &balance); err != nil {
+		return "", fmt.Errorf("failed to parse balance response: %w", err)
+	}
+	if balance.Balance.Amount == "" {
+		return "0", nil
+	}
+	return balance.Balance.Amount, nil
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/manifest-network/yaci/internal/client"
+)
+
+const nodeInfoMethod = "cosmos.base.tendermint.v1beta1.Service.GetNodeInfo"
+
+// GetChainIDWithRetry retrieves the chain ID from the node information of the gRPC server with retry logic.
+func GetChainIDWithRetry(gRPCClient *client.GRPCClient, maxRetries uint) (string, error) {
+	resp, err := GetGRPCResponse(gRPCClient, nodeInfoMethod, maxRetries, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var nodeInfo struct {
+		DefaultNodeInfo struct {
+			Network string `json:"network"`
+		} `json:"defaultNodeInfo"`
+	}
+	if err := json.Unmarshal(resp, &nodeInfo); err != nil {
+		return "", fmt.Errorf("failed to parse node information: %w", err)
+	}
+	if nodeInfo.DefaultNodeInfo.Network == "" {
+		return "", fmt.Errorf("node information has no chain ID")
+	}
+	return nodeInfo.DefaultNodeInfo.Network, nil
+}
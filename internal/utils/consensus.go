@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/manifest-network/yaci/internal/client"
+)
+
+const consensusParamsMethod = "cosmos.consensus.v1.Query.Params"
+
+// GetMaxBlockGasWithRetry retrieves the maximum gas of a block from the consensus parameters with retry logic.
+// It returns -1 when the block gas is unlimited.
+func GetMaxBlockGasWithRetry(gRPCClient *client.GRPCClient, maxRetries uint) (int64, error) {
+	resp, err := GetGRPCResponse(gRPCClient, consensusParamsMethod, maxRetries, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var params struct {
+		Params struct {
+			Block struct {
+				MaxGas string `json:"maxGas"`
+			} `json:"block"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(resp, &params); err != nil {
+		return 0, fmt.Errorf("failed to parse consensus parameters: %w", err)
+	}
+
+	maxGas, err := strconv.ParseInt(params.Params.Block.MaxGas, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid max block gas %q: %w", params.Params.Block.MaxGas, err)
+	}
+	return maxGas, nil
+}
@@ -3,11 +3,13 @@ package utils
 import (
 	"fmt"
 	"log/slog"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/manifest-network/yaci/internal/client"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/dynamicpb"
@@ -71,7 +73,7 @@ func invokeGRPC(
 	return outputMsg, nil
 }
 
-// RetryGRPCCall retries a gRPC call with exponential backoff
+// RetryGRPCCall retries a gRPC call with exponential backoff, except when the node pruned the state of the call
 func RetryGRPCCall[T any](
 	gRPCClient *client.GRPCClient,
 	methodFullName string,
@@ -97,6 +99,10 @@ func RetryGRPCCall[T any](
 		if err == nil {
 			return result, nil
 		}
+		if isStatePrunedError(err) {
+			var zero T
+			return zero, err
+		}
 		slog.Debug("Retrying gRPC call", "method", methodFullName, "attempt", attempt, "error", err)
 		time.Sleep(time.Duration(2*attempt) * time.Second)
 	}
@@ -105,6 +111,13 @@ func RetryGRPCCall[T any](
 	return zero, errors.WithMessage(err, fmt.Sprintf("Failed after %d retries", maxRetries))
 }
 
+// isStatePrunedError returns whether a gRPC error is returned because the node pruned its state at the queried height,
+// which retrying cannot fix
+func isStatePrunedError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "version does not exist") || strings.Contains(msg, "was pruned") || strings.Contains(msg, "is pruned")
+}
+
 // ExtractGRPCField calls a gRPC method and extracts a specific field from the response
 func ExtractGRPCField[T any](
 	gRPCClient *client.GRPCClient,
@@ -163,3 +176,14 @@ func GetGRPCResponse(
 		},
 	)
 }
+
+// blockHeightHeader is the gRPC header used by the Cosmos SDK to query the state at a given height
+const blockHeightHeader = "x-cosmos-block-height"
+
+// AtHeight returns a copy of the gRPC client querying the state at the given height.
+// The node must not have pruned the state at that height.
+func AtHeight(gRPCClient *client.GRPCClient, height uint64) *client.GRPCClient {
+	atHeight := *gRPCClient
+	atHeight.Ctx = metadata.AppendToOutgoingContext(gRPCClient.Ctx, blockHeightHeader, strconv.FormatUint(height, 10))
+	return &atHeight
+}
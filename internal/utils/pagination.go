@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/manifest-network/yaci/internal/client"
+)
+
+// pageSize is the number of items requested per page by GetAllPagesWithRetry
+const pageSize = 200
+
+// GetAllPagesWithRetry calls a paginated gRPC method until the last page, with retry logic.
+// It returns the items of the `field` repeated field of all the pages.
+func GetAllPagesWithRetry(gRPCClient *client.GRPCClient, methodFullName string, maxRetries uint, params map[string]any, field string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	var nextKey string
+	for {
+		pagination := map[string]any{"limit": fmt.Sprint(pageSize)}
+		if nextKey != "" {
+			pagination["key"] = nextKey
+		}
+		request := map[string]any{"pagination": pagination}
+		for k, v := range params {
+			request[k] = v
+		}
+		requestBytes, err := json.Marshal(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		resp, err := GetGRPCResponse(gRPCClient, methodFullName, maxRetries, requestBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		var pageItems []json.RawMessage
+		if raw, ok := page[field]; ok {
+			if err := json.Unmarshal(raw, &pageItems); err != nil {
+				return nil, fmt.Errorf("failed to parse `%s` field: %w", field, err)
+			}
+		}
+		var pageInfo struct {
+			NextKey string `json:"nextKey"`
+		}
+		if raw, ok := page["pagination"]; ok {
+			if err := json.Unmarshal(raw, &pageInfo); err != nil {
+				return nil, fmt.Errorf("failed to parse pagination: %w", err)
+			}
+		}
+
+		items = append(items, pageItems...)
+		if pageInfo.NextKey == "" {
+			return items, nil
+		}
+		nextKey = pageInfo.NextKey
+	}
+}
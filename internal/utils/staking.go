@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"encoding/json"
+
+	"github.com/manifest-network/yaci/internal/client"
+)
+
+const validatorsMethod = "cosmos.staking.v1beta1.Query.Validators"
+
+// GetValidatorsAtHeightWithRetry retrieves all the validators, whatever their status, at a height from the gRPC server with retry logic.
+func GetValidatorsAtHeightWithRetry(gRPCClient *client.GRPCClient, height uint64, maxRetries uint) ([]json.RawMessage, error) {
+	return GetAllPagesWithRetry(AtHeight(gRPCClient, height), validatorsMethod, maxRetries, nil, "validators")
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/manifest-network/yaci/internal/client"
+)
+
+const validatorSetMethod = "cosmos.base.tendermint.v1beta1.Service.GetValidatorSetByHeight"
+
+// validatorSetPageSize is the maximum number of validators returned per page by CometBFT
+const validatorSetPageSize = 100
+
+// GetValidatorSetWithRetry retrieves the CometBFT validator set at a height from the gRPC server with retry logic.
+// The validators are in the validator set order, i.e., the order of the commit signatures.
+func GetValidatorSetWithRetry(gRPCClient *client.GRPCClient, height uint64, maxRetries uint) ([]json.RawMessage, error) {
+	var validators []json.RawMessage
+	for {
+		// The validator set is paginated with an offset, the next key is never set
+		request, err := json.Marshal(map[string]any{
+			"height": fmt.Sprint(height),
+			"pagination": map[string]any{
+				"offset": fmt.Sprint(len(validators)),
+				"limit":  fmt.Sprint(validatorSetPageSize),
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+
+		resp, err := GetGRPCResponse(gRPCClient, validatorSetMethod, maxRetries, request)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Validators []json.RawMessage `json:"validators"`
+			Pagination struct {
+				Total string `json:"total"`
+			} `json:"pagination"`
+		}
+		if err := json.Unmarshal(resp, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+
+		validators = append(validators, page.Validators...)
+		total, _ := strconv.Atoi(page.Pagination.Total)
+		if len(page.Validators) == 0 || len(validators) >= total {
+			return validators, nil
+		}
+	}
+}
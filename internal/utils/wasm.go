@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/manifest-network/yaci/internal/client"
+)
+
+const (
+	wasmCodesMethod           = "cosmwasm.wasm.v1.Query.Codes"
+	wasmContractsByCodeMethod = "cosmwasm.wasm.v1.Query.ContractsByCode"
+	wasmContractInfoMethod    = "cosmwasm.wasm.v1.Query.ContractInfo"
+)
+
+// GetWasmContractsAtHeightWithRetry retrieves the information of all the contracts at a height from the gRPC server with retry logic.
+// Each item is a `cosmwasm.wasm.v1.Query/ContractInfo` response.
+func GetWasmContractsAtHeightWithRetry(gRPCClient *client.GRPCClient, height uint64, maxRetries uint) ([]json.RawMessage, error) {
+	atHeight := AtHeight(gRPCClient, height)
+
+	codeInfos, err := GetAllPagesWithRetry(atHeight, wasmCodesMethod, maxRetries, nil, "codeInfos")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get codes: %w", err)
+	}
+
+	var contracts []json.RawMessage
+	for _, rawCodeInfo := range codeInfos {
+		var codeInfo struct {
+			CodeID string `json:"codeId"`
+		}
+		if err := json.Unmarshal(rawCodeInfo, &codeInfo); err != nil {
+			return nil, fmt.Errorf("failed to parse code info: %w", err)
+		}
+
+		addresses, err := GetAllPagesWithRetry(atHeight, wasmContractsByCodeMethod, maxRetries, map[string]any{"codeId": codeInfo.CodeID}, "contracts")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get contracts of code %s: %w", codeInfo.CodeID, err)
+		}
+
+		for _, rawAddress := range addresses {
+			var address string
+			if err := json.Unmarshal(rawAddress, &address); err != nil {
+				return nil, fmt.Errorf("failed to parse contract address: %w", err)
+			}
+			params, err := json.Marshal(map[string]string{"address": address})
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal contract info request: %w", err)
+			}
+			info, err := GetGRPCResponse(atHeight, wasmContractInfoMethod, maxRetries, params)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get contract info of %s: %w", address, err)
+			}
+			contracts = append(contracts, info)
+		}
+	}
+	return contracts, nil
+}
@@ -0,0 +1,110 @@
+// Package verify checks the indexed data against the chain.
+package verify
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/manifest-network/yaci/internal/client"
+	"github.com/manifest-network/yaci/internal/utils"
+)
+
+// BalanceTarget is an address and denomination whose balance is verified
+type BalanceTarget struct {
+	Address string
+	Denom   string
+}
+
+// BalanceCheck is the comparison of a balance reconstructed from the indexed balance changes with the balance returned by the node.
+// Error is set when the node could not be queried, e.g., because its state at the height is pruned.
+type BalanceCheck struct {
+	BalanceTarget
+	Height  uint64
+	Indexed string
+	Node    string
+	Error   error
+}
+
+// Match returns whether the indexed balance matches the balance returned by the node
+func (c BalanceCheck) Match() bool {
+	return c.Error == nil && c.Indexed == c.Node
+}
+
+// SampleBalanceTargets returns up to n random addresses and denominations with genesis balances or balance changes of
+// the chain up to the height. An empty denomination selects all denominations.
+func SampleBalanceTargets(ctx context.Context, pool *pgxpool.Pool, chainID, denom string, height uint64, n uint) ([]BalanceTarget, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT address, denom
+		FROM (
+			SELECT address, denom
+			FROM balance_changes
+			WHERE chain_id = $4 AND height <= $1::bigint AND ($2 = '' OR denom = $2)
+			UNION
+			SELECT address, denom
+			FROM genesis_balances
+			WHERE chain_id = $4 AND ($2 = '' OR denom = $2)
+		) t
+		ORDER BY random()
+		LIMIT $3
+	`, height, denom, n, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample balances: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (BalanceTarget, error) {
+		var t BalanceTarget
+		err := row.Scan(&t.Address, &t.Denom)
+		return t, err
+	})
+}
+
+// AddressBalanceTargets returns the denominations of the genesis balances and the balance changes of an address of the
+// chain up to the height
+func AddressBalanceTargets(ctx context.Context, pool *pgxpool.Pool, chainID, address string, height uint64) ([]BalanceTarget, error) {
+	rows, err := pool.Query(ctx, `
+		SELECT denom FROM balance_changes WHERE chain_id = $3 AND address = $1 AND height <= $2::bigint
+		UNION
+		SELECT denom FROM genesis_balances WHERE chain_id = $3 AND address = $1
+		ORDER BY denom
+	`, address, height, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list address denoms: %w", err)
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (BalanceTarget, error) {
+		t := BalanceTarget{Address: address}
+		err := row.Scan(&t.Denom)
+		return t, err
+	})
+}
+
+// Balances compares the indexed balances of the targets at the height with the balances returned by `cosmos.bank.v1beta1.Query/Balance`.
+// An error is returned if the indexed balances cannot be queried, or if the balance ledger is incomplete at the height,
+// e.g., because the blocks were indexed without their block events or the genesis is not imported, as the balances
+// would not match; node errors are reported in the checks.
+func Balances(ctx context.Context, pool *pgxpool.Pool, gRPCClient *client.GRPCClient, chainID string, targets []BalanceTarget, height uint64, maxRetries uint) ([]BalanceCheck, error) {
+	var complete bool
+	if err := pool.QueryRow(ctx, `SELECT balance_ledger_complete($1::bigint, $2)`, height, chainID).Scan(&complete); err != nil {
+		return nil, fmt.Errorf("failed to check the balance ledger: %w", err)
+	}
+	if !complete {
+		return nil, fmt.Errorf("the balance ledger is incomplete at height %d: import the genesis and index the chain from its initial height with a source providing the block events", height)
+	}
+
+	checks := make([]BalanceCheck, 0, len(targets))
+	for _, target := range targets {
+		check := BalanceCheck{BalanceTarget: target, Height: height}
+
+		err := pool.QueryRow(ctx, `
+			SELECT COALESCE((SELECT amount::text FROM get_balance_at($1, $2::bigint, $4) WHERE denom = $3), '0')
+		`, target.Address, height, target.Denom, chainID).Scan(&check.Indexed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get indexed balance: %w", err)
+		}
+
+		check.Node, check.Error = utils.GetBalanceAtHeightWithRetry(gRPCClient, target.Address, target.Denom, height, maxRetries)
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
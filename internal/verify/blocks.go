@@ -0,0 +1,312 @@
+package verify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/manifest-network/yaci/internal/output"
+)
+
+// Block issues, i.e., the discrepancies between an indexed block and the block returned by the node
+const (
+	// IssueMissingBlock is a block that is not indexed
+	IssueMissingBlock = "missing_block"
+	// IssueHashMismatch is an indexed block whose hash differs from the hash of the block
+	IssueHashMismatch = "hash_mismatch"
+	// IssueTxCountMismatch is an indexed block whose number of transactions differs from that of the block
+	IssueTxCountMismatch = "tx_count_mismatch"
+	// IssueMissingTxs is a block whose transactions are not all indexed
+	IssueMissingTxs = "missing_txs"
+	// IssueExtraTxs is a block with indexed transactions that are not in the block
+	IssueExtraTxs = "extra_txs"
+	// IssueBrokenHashLink is an indexed block whose header references a previous block hash that differs from the
+	// indexed hash of the previous block
+	IssueBrokenHashLink = "broken_hash_link"
+)
+
+// BlockFetcher fetches the `cosmos.tx.v1beta1.Service.GetBlockWithTxs` response of a block
+type BlockFetcher interface {
+	FetchBlock(ctx context.Context, height uint64) ([]byte, error)
+}
+
+// BlockCheck is the comparison of an indexed block with the block returned by the node.
+// Error is set when the node could not be queried, e.g., because it pruned the block.
+type BlockCheck struct {
+	Height     uint64   `json:"height"`
+	Issues     []string `json:"issues,omitempty"`
+	MissingTxs []string `json:"missing_txs,omitempty"`
+	ExtraTxs   []string `json:"extra_txs,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	Repaired   bool     `json:"repaired,omitempty"`
+}
+
+// Match returns whether the indexed block matches the block returned by the node
+func (c BlockCheck) Match() bool {
+	return c.Error == "" && len(c.Issues) == 0
+}
+
+// indexedBlock is the summary of an indexed block
+type indexedBlock struct {
+	hash *string
+	// lastHash is the previous block hash referenced by the header, or nil if it is unknown, i.e., the block was
+	// written before it was stored apart from the raw JSON, which was dropped
+	lastHash *string
+	txCount  int
+	txs      []string
+}
+
+// nodeBlock is the part of a `cosmos.tx.v1beta1.Service.GetBlockWithTxs` response that is verified, returned by the node
+// or indexed
+type nodeBlock struct {
+	BlockID struct {
+		Hash string `json:"hash"`
+	} `json:"blockId"`
+	Block struct {
+		Header struct {
+			LastBlockID struct {
+				Hash string `json:"hash"`
+			} `json:"lastBlockId"`
+		} `json:"header"`
+		Data struct {
+			Txs []string `json:"txs"`
+		} `json:"data"`
+	} `json:"block"`
+}
+
+// BlockRange returns the range of the indexed blocks of the chain above its pruning floor, or zeros if none is indexed
+func BlockRange(ctx context.Context, pool *pgxpool.Pool, chainID string) (uint64, uint64, error) {
+	var from, to *int64
+	err := pool.QueryRow(ctx, `
+		SELECT MIN(id), MAX(id)
+		FROM blocks_raw
+		WHERE chain_id = $1 AND id >= COALESCE((SELECT height FROM pruning_floors WHERE chain_id = $1), 0)
+	`, chainID).Scan(&from, &to)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get the indexed block range: %w", err)
+	}
+	if to == nil {
+		return 0, 0, nil
+	}
+	return uint64(*from), uint64(*to), nil
+}
+
+// Blocks compares the indexed blocks of the chain at the heights with the blocks returned by the node, concurrently.
+// An error is returned if the indexed blocks cannot be queried; node errors are reported in the checks.
+func Blocks(ctx context.Context, pool *pgxpool.Pool, fetcher BlockFetcher, chainID string, heights []uint64, maxConcurrency uint) ([]BlockCheck, error) {
+	checks := make([]BlockCheck, len(heights))
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.SetLimit(int(max(maxConcurrency, 1)))
+	for i, height := range heights {
+		eg.Go(func() error {
+			check, err := checkBlock(ctx, pool, fetcher, chainID, height)
+			checks[i] = check
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
+
+// Block compares the indexed block of the chain at a height with the block returned by the node, e.g., once it is repaired
+func Block(ctx context.Context, pool *pgxpool.Pool, fetcher BlockFetcher, chainID string, height uint64) (BlockCheck, error) {
+	return checkBlock(ctx, pool, fetcher, chainID, height)
+}
+
+// IndexedBlockHasEvents returns whether the indexed block of the chain at a height was extracted with its block events,
+// which a repair from a source that does not provide them would delete
+func IndexedBlockHasEvents(ctx context.Context, pool *pgxpool.Pool, chainID string, height uint64) (bool, error) {
+	var hasEvents bool
+	err := pool.QueryRow(ctx, `
+		SELECT COALESCE(has_events, FALSE) FROM blocks_raw WHERE chain_id = $1 AND id = $2
+	`, chainID, height).Scan(&hasEvents)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("failed to get indexed block %d: %w", height, err)
+	}
+	return hasEvents, nil
+}
+
+func checkBlock(ctx context.Context, pool *pgxpool.Pool, fetcher BlockFetcher, chainID string, height uint64) (BlockCheck, error) {
+	check := BlockCheck{Height: height}
+
+	raw, err := fetcher.FetchBlock(ctx, height)
+	if err != nil {
+		check.Error = err.Error()
+		return check, nil
+	}
+	var node nodeBlock
+	if err := json.Unmarshal(raw, &node); err != nil {
+		check.Error = fmt.Sprintf("failed to unmarshal block JSON: %v", err)
+		return check, nil
+	}
+	nodeTxs, err := txHashes(node.Block.Data.Txs)
+	if err != nil {
+		check.Error = err.Error()
+		return check, nil
+	}
+
+	indexed, err := getIndexedBlock(ctx, pool, chainID, height)
+	if err != nil {
+		return check, err
+	}
+	if indexed == nil {
+		check.Issues = append(check.Issues, IssueMissingBlock)
+		return check, nil
+	}
+
+	if indexed.hash == nil || *indexed.hash != node.BlockID.Hash {
+		check.Issues = append(check.Issues, IssueHashMismatch)
+	}
+	if indexed.txCount != len(nodeTxs) {
+		check.Issues = append(check.Issues, IssueTxCountMismatch)
+	}
+	for _, hash := range nodeTxs {
+		if !slices.Contains(indexed.txs, hash) {
+			check.MissingTxs = append(check.MissingTxs, hash)
+		}
+	}
+	for _, hash := range indexed.txs {
+		if !slices.Contains(nodeTxs, hash) {
+			check.ExtraTxs = append(check.ExtraTxs, hash)
+		}
+	}
+	if len(check.MissingTxs) > 0 {
+		check.Issues = append(check.Issues, IssueMissingTxs)
+	}
+	if len(check.ExtraTxs) > 0 {
+		check.Issues = append(check.Issues, IssueExtraTxs)
+	}
+
+	// The indexed header is linked to the indexed previous block, which the node does not verify, e.g., when the
+	// blocks were indexed from different forks. The link is only verified when the previous block is indexed.
+	if height > 1 && indexed.lastHash != nil && *indexed.lastHash != "" {
+		var previousHash *string
+		err := pool.QueryRow(ctx, `
+			SELECT COALESCE(hash, data->'blockId'->>'hash') FROM blocks_raw WHERE chain_id = $1 AND id = $2
+		`, chainID, height-1).Scan(&previousHash)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return check, fmt.Errorf("failed to get indexed block %d: %w", height-1, err)
+		}
+		if err == nil && (previousHash == nil || *previousHash != *indexed.lastHash) {
+			check.Issues = append(check.Issues, IssueBrokenHashLink)
+		}
+	}
+
+	return check, nil
+}
+
+// getIndexedBlock returns the summary of an indexed block, or nil if it is not indexed
+func getIndexedBlock(ctx context.Context, pool *pgxpool.Pool, chainID string, height uint64) (*indexedBlock, error) {
+	var block indexedBlock
+	var compressed []byte
+	err := pool.QueryRow(ctx, `
+		SELECT
+		  COALESCE(hash, data->'blockId'->>'hash'),
+		  COALESCE(last_block_hash, data->'block'->'header'->'lastBlockId'->>'hash'),
+		  CASE WHEN last_block_hash IS NULL AND data IS NULL THEN data_zstd END,
+		  COALESCE(tx_count, jsonb_array_length(data->'block'->'data'->'txs'), 0),
+		  ARRAY(SELECT id FROM transactions_main WHERE chain_id = $1 AND height = $2 ORDER BY id)
+		FROM blocks_raw
+		WHERE chain_id = $1 AND id = $2
+	`, chainID, height).Scan(&block.hash, &block.lastHash, &compressed, &block.txCount, &block.txs)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexed block %d: %w", height, err)
+	}
+
+	// The previous block hash of the compressed blocks written before it was stored apart is read from their raw JSON
+	if compressed != nil {
+		data, err := output.DecodeRaw(nil, compressed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode indexed block %d: %w", height, err)
+		}
+		var raw nodeBlock
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal indexed block %d: %w", height, err)
+		}
+		block.lastHash = &raw.Block.Header.LastBlockID.Hash
+	}
+	return &block, nil
+}
+
+// txHashes returns the hashes of the base64 encoded transactions of a block, as indexed
+func txHashes(txs []string) ([]string, error) {
+	hashes := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		decoded, err := base64.StdEncoding.DecodeString(tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode tx: %w", err)
+		}
+		hash := sha256.Sum256(decoded)
+		hashes = append(hashes, hex.EncodeToString(hash[:]))
+	}
+	return hashes, nil
+}
+
+// BlocksReport is the report of a block verification
+type BlocksReport struct {
+	ChainID       string       `json:"chain_id"`
+	From          uint64       `json:"from"`
+	To            uint64       `json:"to"`
+	Checked       int          `json:"checked"`
+	Discrepancies int          `json:"discrepancies"`
+	Failures      int          `json:"failures"`
+	Repaired      int          `json:"repaired"`
+	Blocks        []BlockCheck `json:"blocks"`
+}
+
+// NewBlocksReport returns the report of the checks of a chain's blocks from a height to another
+func NewBlocksReport(chainID string, from, to uint64, checks []BlockCheck) BlocksReport {
+	report := BlocksReport{ChainID: chainID, From: from, To: to, Checked: len(checks), Blocks: checks}
+	for _, check := range checks {
+		switch {
+		case check.Error != "":
+			report.Failures++
+		case len(check.Issues) > 0:
+			report.Discrepancies++
+			if check.Repaired {
+				report.Repaired++
+			}
+		}
+	}
+	return report
+}
+
+// SampleHeights returns up to n distinct random heights from a height to another, in increasing order, or every height
+// if n is zero or the range does not hold more than n heights
+func SampleHeights(from, to uint64, n uint) []uint64 {
+	if to < from {
+		return nil
+	}
+	size := to - from + 1
+	if n == 0 || uint64(n) >= size {
+		heights := make([]uint64, 0, size)
+		for height := from; height <= to; height++ {
+			heights = append(heights, height)
+		}
+		return heights
+	}
+
+	sampled := make(map[uint64]struct{}, n)
+	for uint(len(sampled)) < n {
+		sampled[from+rand.Uint64N(size)] = struct{}{}
+	}
+	heights := slices.Collect(maps.Keys(sampled))
+	slices.Sort(heights)
+	return heights
+}